@@ -2,58 +2,174 @@ package main
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata"
 
+	"github.com/gin-gonic/gin"
+	"github.com/sanjaykishor/Glofox/internal/config"
 	"github.com/sanjaykishor/Glofox/internal/handler"
+	"github.com/sanjaykishor/Glofox/internal/logging"
+	"github.com/sanjaykishor/Glofox/internal/middleware"
 	"github.com/sanjaykishor/Glofox/internal/repository"
+	"github.com/sanjaykishor/Glofox/internal/repository/journal"
+	"github.com/sanjaykishor/Glofox/internal/repository/sqlite"
 	"github.com/sanjaykishor/Glofox/internal/router"
 	"github.com/sanjaykishor/Glofox/internal/service"
 )
 
 func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		fatal("Failed to load configuration", "error", err)
+	}
+
+	logger := logging.New(cfg.Log, os.Stdout)
+	slog.SetDefault(logger)
+
+	// Gin's debug output is unstructured, so it is only printed on request
+	if os.Getenv(gin.EnvGinMode) == "" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
 	// Initialize repositories
-	classRepo := repository.NewClassRepository()
-	bookingRepo := repository.NewBookingRepository()
+	stores, closeStores, err := openStores(cfg.Storage)
+	if err != nil {
+		fatal("Failed to open storage", "backend", cfg.Storage.Backend, "error", err)
+	}
+	defer func() {
+		if err := closeStores(); err != nil {
+			slog.Error("Failed to close storage", "error", err)
+		}
+	}()
 
 	// Initialize services
-	classService := service.NewClassService(classRepo)
-	bookingService := service.NewBookingService(bookingRepo, classRepo)
+	classService := service.NewClassService(stores.Classes, stores.Bookings, stores.Waitlist, stores.Occurrences, stores.Instructors, stores.Rooms)
+	bookingService := service.NewBookingService(stores.Bookings, stores.Classes, stores.Waitlist, stores.Members, stores.Occurrences)
+	waitlistService := service.NewWaitlistService(stores.Waitlist, stores.Bookings, stores.Classes, stores.Members, stores.Occurrences)
+	memberService := service.NewMemberService(stores.Members)
+	instructorService := service.NewInstructorService(stores.Instructors, stores.Classes, stores.Occurrences)
+	locationService := service.NewLocationService(stores.Locations, stores.Rooms)
+	roomService := service.NewRoomService(stores.Rooms, stores.Locations, stores.Classes, stores.Occurrences)
+	apiKeyService := service.NewAPIKeyService(stores.APIKeys, middleware.IntegrationScopes)
+	occurrenceService := service.NewOccurrenceService(stores.Occurrences, stores.Classes, stores.Bookings, stores.Waitlist, stores.Instructors, stores.Rooms)
+
+	// Dates are the studio's calendar days, so today is decided in its time zone
+	classService.SetLocation(cfg.Studio.Location)
+	bookingService.SetLocation(cfg.Studio.Location)
+	waitlistService.SetLocation(cfg.Studio.Location)
+	occurrenceService.SetLocation(cfg.Studio.Location)
 
 	// Initialize handlers
 	classHandler := handler.NewClassHandler(classService)
 	bookingHandler := handler.NewBookingHandler(bookingService)
+	waitlistHandler := handler.NewWaitlistHandler(waitlistService)
+	memberHandler := handler.NewMemberHandler(memberService)
+	occurrenceHandler := handler.NewOccurrenceHandler(occurrenceService)
+	instructorHandler := handler.NewInstructorHandler(instructorService)
+	locationHandler := handler.NewLocationHandler(locationService)
+	roomHandler := handler.NewRoomHandler(roomService)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
+
+	// Initialize authentication
+	var auth *middleware.Authenticator
+	if cfg.Auth.Enabled() {
+		auth, err = middleware.NewAuthenticator(cfg.Auth)
+		if err != nil {
+			fatal("Failed to set up authentication", "error", err)
+		}
+	} else {
+		slog.Warn("Authentication is disabled by GLOFOX_AUTH_DISABLED and every request acts as anonymous staff; do not expose this server")
+	}
+
+	// Initialize rate limiting
+	var limiter *middleware.RateLimiter
+	if cfg.RateLimit.Enabled {
+		limiter = middleware.NewRateLimiter(cfg.RateLimit)
+		stopEviction := limiter.StartEviction(time.Minute)
+		defer stopEviction()
+	}
 
 	// Initialize router
-	r := router.Setup(classHandler, bookingHandler)
+	r, err := router.Setup(cfg, auth, apiKeyService, limiter, classHandler, bookingHandler, waitlistHandler, memberHandler, occurrenceHandler, instructorHandler, locationHandler, roomHandler, apiKeyHandler)
+	if err != nil {
+		fatal("Failed to set up router", "error", err)
+	}
 
 	server := &http.Server{
-		Addr:    ":8080",
-		Handler: r,
+		Addr:     cfg.Addr,
+		Handler:  r,
+		ErrorLog: slog.NewLogLogger(logger.Handler(), slog.LevelError),
 	}
 
 	go func() {
-		log.Println("Server starting on :8080")
+		slog.Info("Server starting", "addr", cfg.Addr)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start server: %v", err)
+			fatal("Failed to start server", "error", err)
 		}
 	}()
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
-	log.Println("Shutting down server...")
+	slog.Info("Shutting down server")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	if err := server.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+		slog.Error("Server forced to shutdown", "error", err)
 	}
 
-	log.Println("Server exited properly")
+	slog.Info("Server exited properly")
+}
+
+// fatal logs a failure the server cannot run with and exits
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// openStores creates the repositories for the configured storage backend.
+// The returned function releases any resources held by the backend.
+func openStores(cfg config.StorageConfig) (*repository.Stores, func() error, error) {
+	switch cfg.Backend {
+	case config.StorageMemory:
+		return repository.NewMemoryStores(), func() error { return nil }, nil
+	case config.StorageSQLite:
+		db, err := sqlite.Open(cfg.SQLitePath)
+		if err != nil {
+			return nil, nil, err
+		}
+		slog.Info("Using SQLite storage", "path", cfg.SQLitePath)
+		return &repository.Stores{
+			Classes:     sqlite.NewClassRepository(db),
+			Bookings:    sqlite.NewBookingRepository(db),
+			Waitlist:    sqlite.NewWaitlistRepository(db),
+			Members:     sqlite.NewMemberRepository(db),
+			Occurrences: sqlite.NewOccurrenceRepository(db),
+			Instructors: sqlite.NewInstructorRepository(db),
+			Locations:   sqlite.NewLocationRepository(db),
+			Rooms:       sqlite.NewRoomRepository(db),
+			APIKeys:     sqlite.NewAPIKeyRepository(db),
+		}, db.Close, nil
+	case config.StorageJournal:
+		j, err := journal.Open(cfg.JournalDir)
+		if err != nil {
+			return nil, nil, err
+		}
+		slog.Info("Using journal storage", "dir", cfg.JournalDir)
+		stopSnapshots := j.StartSnapshots(cfg.SnapshotInterval)
+		return j.Stores(), func() error {
+			stopSnapshots()
+			return j.Close()
+		}, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported storage backend %q", cfg.Backend)
+	}
 }
@@ -0,0 +1,435 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Storage backends supported by the application
+const (
+	StorageMemory  = "memory"
+	StorageSQLite  = "sqlite"
+	StorageJournal = "journal"
+)
+
+// Log formats supported by the application
+const (
+	LogFormatJSON = "json"
+	LogFormatText = "text"
+)
+
+// Config holds the application configuration
+type Config struct {
+	// Addr is the address the HTTP server listens on
+	Addr string
+	// TrustedProxies lists the IP addresses and CIDR ranges of the proxies
+	// whose X-Forwarded-For header is believed. The client IP of requests
+	// from anywhere else is the address they came from.
+	TrustedProxies []string
+	// Storage selects and configures the repository backend
+	Storage StorageConfig
+	// Studio holds the settings of the studio the classes run in
+	Studio StudioConfig
+	// Auth configures how API requests are authenticated
+	Auth AuthConfig
+	// CORS configures which browser origins may call the API
+	CORS CORSConfig
+	// RateLimit configures how many requests each client may make
+	RateLimit RateLimitConfig
+	// Log configures how log lines are written
+	Log LogConfig
+}
+
+// LogConfig holds the logging configuration
+type LogConfig struct {
+	// Format is LogFormatJSON for one JSON object per line, or
+	// LogFormatText for key=value pairs
+	Format string
+	// Level is the least severe level written
+	Level slog.Level
+}
+
+// StorageConfig holds the repository backend configuration
+type StorageConfig struct {
+	// Backend is the name of the repository backend to use
+	Backend string
+	// SQLitePath is the database file used by the sqlite backend
+	SQLitePath string
+	// JournalDir is the directory holding the journal backend's log and snapshot
+	JournalDir string
+	// SnapshotInterval is how often the journal backend compacts its log into a snapshot
+	SnapshotInterval time.Duration
+}
+
+// StudioConfig holds the studio settings
+type StudioConfig struct {
+	// Location is the studio's IANA time zone. Class dates and times are
+	// wall-clock values there, and it decides which day is today.
+	Location *time.Location
+}
+
+// AuthConfig holds the keys bearer tokens are verified with. Authentication
+// is enabled once a secret or a public key is configured; running without
+// either must be asked for with Disabled.
+type AuthConfig struct {
+	// Disabled opts out of authentication, leaving the API open to anyone
+	// who can reach it. It is meant for local development only.
+	Disabled bool
+	// JWTSecret is the shared secret HS256 tokens are signed with
+	JWTSecret string
+	// JWTPublicKeyFile is a PEM file holding the RSA public key RS256
+	// tokens are verified with
+	JWTPublicKeyFile string
+	// Issuer, if set, must match the iss claim of every token
+	Issuer string
+	// Audience, if set, must be among the aud claims of every token
+	Audience string
+}
+
+// Enabled reports whether requests must carry a valid token
+func (c AuthConfig) Enabled() bool {
+	return c.JWTSecret != "" || c.JWTPublicKeyFile != ""
+}
+
+// CORSConfig holds the Cross-Origin Resource Sharing policy
+type CORSConfig struct {
+	// AllowedOrigins lists the origins browsers may call the API from.
+	// An entry is an exact origin such as https://app.example.com, a
+	// pattern such as https://*.example.com matching every subdomain, or
+	// * matching any origin.
+	AllowedOrigins []string
+	// AllowedMethods lists the methods allowed in cross-origin requests
+	AllowedMethods []string
+	// AllowedHeaders lists the request headers allowed in cross-origin
+	// requests, or * for any header
+	AllowedHeaders []string
+	// MaxAge is how long browsers may cache a preflight response
+	MaxAge time.Duration
+	// AllowCredentials lets browsers send cookies and read responses to
+	// credentialed requests
+	AllowCredentials bool
+}
+
+// Route groups that can be given their own rate limit
+const (
+	// RouteGroupCatalog holds the class, occurrence, instructor, location and room routes
+	RouteGroupCatalog = "catalog"
+	// RouteGroupBookings holds the booking and waitlist routes
+	RouteGroupBookings = "bookings"
+	// RouteGroupMembers holds the member routes
+	RouteGroupMembers = "members"
+	// RouteGroupAPIKeys holds the API key routes
+	RouteGroupAPIKeys = "api_keys"
+	// RouteGroupAuth counts every API request by IP address before its
+	// credentials are checked, so guessing credentials is limited too
+	RouteGroupAuth = "auth"
+)
+
+// routeGroups lists every route group
+var routeGroups = []string{RouteGroupCatalog, RouteGroupBookings, RouteGroupMembers, RouteGroupAPIKeys, RouteGroupAuth}
+
+// RateLimit allows a client Requests requests every Period, in bursts of
+// up to Requests
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// ParseRateLimit parses a limit written as requests/period, such as
+// 100/1m. A period of a single unit may leave out the 1, as in 100/m.
+func ParseRateLimit(value string) (RateLimit, error) {
+	requests, period, found := strings.Cut(strings.TrimSpace(value), "/")
+	if !found {
+		return RateLimit{}, fmt.Errorf("rate limit %q must look like 100/1m", value)
+	}
+
+	var limit RateLimit
+	var err error
+	if limit.Requests, err = strconv.Atoi(requests); err != nil || limit.Requests <= 0 {
+		return RateLimit{}, fmt.Errorf("rate limit %q must allow a positive number of requests", value)
+	}
+
+	if period != "" && (period[0] < '0' || period[0] > '9') {
+		period = "1" + period
+	}
+	if limit.Period, err = time.ParseDuration(period); err != nil || limit.Period <= 0 {
+		return RateLimit{}, fmt.Errorf("rate limit %q must have a positive period", value)
+	}
+
+	return limit, nil
+}
+
+// RateLimitConfig holds the rate limits of the API. Every route group has
+// the default limit unless it is given its own.
+type RateLimitConfig struct {
+	// Enabled turns rate limiting on
+	Enabled bool
+	// Default is the limit of route groups without one of their own
+	Default RateLimit
+	// Groups maps route groups to their own limits
+	Groups map[string]RateLimit
+}
+
+// For returns the limit of a route group
+func (c RateLimitConfig) For(group string) RateLimit {
+	if limit, ok := c.Groups[group]; ok {
+		return limit
+	}
+	return c.Default
+}
+
+// minJWTSecretLength is the shortest HS256 secret accepted, matching the
+// size of the hash as RFC 7518 requires
+const minJWTSecretLength = 32
+
+// Load reads the configuration from environment variables, falling back
+// to defaults for anything that is not set
+func Load() (*Config, error) {
+	snapshotInterval, err := getEnvDuration("GLOFOX_SNAPSHOT_INTERVAL", 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	location, err := time.LoadLocation(getEnv("GLOFOX_TIMEZONE", "UTC"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid GLOFOX_TIMEZONE: %w", err)
+	}
+
+	corsMaxAge, err := getEnvDuration("GLOFOX_CORS_MAX_AGE", 10*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	corsAllowCredentials, err := getEnvBool("GLOFOX_CORS_ALLOW_CREDENTIALS", false)
+	if err != nil {
+		return nil, err
+	}
+
+	authDisabled, err := getEnvBool("GLOFOX_AUTH_DISABLED", false)
+	if err != nil {
+		return nil, err
+	}
+
+	rateLimit, err := loadRateLimit()
+	if err != nil {
+		return nil, err
+	}
+
+	var logLevel slog.Level
+	if err := logLevel.UnmarshalText([]byte(getEnv("GLOFOX_LOG_LEVEL", "info"))); err != nil {
+		return nil, fmt.Errorf("invalid GLOFOX_LOG_LEVEL: %w", err)
+	}
+
+	cfg := &Config{
+		Addr:           getEnv("GLOFOX_ADDR", ":8080"),
+		TrustedProxies: getEnvList("GLOFOX_TRUSTED_PROXIES", nil),
+		Storage: StorageConfig{
+			Backend:          getEnv("GLOFOX_STORAGE", StorageMemory),
+			SQLitePath:       getEnv("GLOFOX_SQLITE_PATH", "glofox.db"),
+			JournalDir:       getEnv("GLOFOX_JOURNAL_DIR", "data"),
+			SnapshotInterval: snapshotInterval,
+		},
+		Studio: StudioConfig{
+			Location: location,
+		},
+		Auth: AuthConfig{
+			Disabled:         authDisabled,
+			JWTSecret:        os.Getenv("GLOFOX_JWT_SECRET"),
+			JWTPublicKeyFile: os.Getenv("GLOFOX_JWT_PUBLIC_KEY_FILE"),
+			Issuer:           os.Getenv("GLOFOX_JWT_ISSUER"),
+			Audience:         os.Getenv("GLOFOX_JWT_AUDIENCE"),
+		},
+		CORS: CORSConfig{
+			AllowedOrigins: getEnvList("GLOFOX_CORS_ORIGINS", []string{"*"}),
+			AllowedMethods: getEnvList("GLOFOX_CORS_METHODS", []string{"GET", "POST", "PUT", "PATCH", "DELETE"}),
+			AllowedHeaders: getEnvList("GLOFOX_CORS_HEADERS", []string{
+				"Accept", "Authorization", "Cache-Control", "Content-Type", "X-API-Key", "X-Request-ID", "X-Requested-With",
+			}),
+			MaxAge:           corsMaxAge,
+			AllowCredentials: corsAllowCredentials,
+		},
+		RateLimit: rateLimit,
+		Log: LogConfig{
+			Format: getEnv("GLOFOX_LOG_FORMAT", LogFormatJSON),
+			Level:  logLevel,
+		},
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// validate checks that the configuration values are supported
+func (c *Config) validate() error {
+	switch c.Storage.Backend {
+	case StorageMemory, StorageSQLite, StorageJournal:
+	default:
+		return fmt.Errorf("unsupported storage backend %q", c.Storage.Backend)
+	}
+
+	if c.Storage.SnapshotInterval <= 0 {
+		return fmt.Errorf("snapshot interval must be positive")
+	}
+
+	for _, proxy := range c.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				return fmt.Errorf("invalid trusted proxy %q: must be an IP address or CIDR range", proxy)
+			}
+		}
+	}
+
+	switch {
+	case !c.Auth.Enabled() && !c.Auth.Disabled:
+		return fmt.Errorf("authentication is not configured: set GLOFOX_JWT_SECRET or GLOFOX_JWT_PUBLIC_KEY_FILE, or GLOFOX_AUTH_DISABLED=true to run an open API")
+	case c.Auth.Enabled() && c.Auth.Disabled:
+		return fmt.Errorf("GLOFOX_AUTH_DISABLED cannot be combined with GLOFOX_JWT_SECRET or GLOFOX_JWT_PUBLIC_KEY_FILE")
+	}
+
+	if c.Auth.JWTSecret != "" && len(c.Auth.JWTSecret) < minJWTSecretLength {
+		return fmt.Errorf("GLOFOX_JWT_SECRET must be at least %d bytes", minJWTSecretLength)
+	}
+
+	if err := c.CORS.validate(); err != nil {
+		return err
+	}
+
+	switch c.Log.Format {
+	case LogFormatJSON, LogFormatText:
+	default:
+		return fmt.Errorf("unsupported log format %q", c.Log.Format)
+	}
+
+	return nil
+}
+
+// loadRateLimit reads the default limit and the limits of route groups,
+// written as a comma-separated list such as bookings=30/1m,members=100/1m.
+// A default of off disables rate limiting.
+func loadRateLimit() (RateLimitConfig, error) {
+	cfg := RateLimitConfig{Groups: make(map[string]RateLimit)}
+
+	defaultLimit := getEnv("GLOFOX_RATE_LIMIT", "600/1m")
+	if defaultLimit == "off" {
+		return cfg, nil
+	}
+
+	var err error
+	if cfg.Default, err = ParseRateLimit(defaultLimit); err != nil {
+		return cfg, fmt.Errorf("invalid GLOFOX_RATE_LIMIT: %w", err)
+	}
+	cfg.Enabled = true
+
+	for _, entry := range getEnvList("GLOFOX_RATE_LIMIT_GROUPS", []string{"bookings=120/1m"}) {
+		group, value, _ := strings.Cut(entry, "=")
+		group = strings.TrimSpace(group)
+		if !slices.Contains(routeGroups, group) {
+			return cfg, fmt.Errorf("invalid GLOFOX_RATE_LIMIT_GROUPS: unknown route group %q, expected one of %s", group, strings.Join(routeGroups, ", "))
+		}
+
+		limit, err := ParseRateLimit(value)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid GLOFOX_RATE_LIMIT_GROUPS: %w", err)
+		}
+		cfg.Groups[group] = limit
+	}
+
+	return cfg, nil
+}
+
+// validate checks that every allowed origin is well formed and that
+// credentials are not combined with a wildcard, which browsers reject
+func (c CORSConfig) validate() error {
+	for _, origin := range c.AllowedOrigins {
+		if origin == "*" {
+			if c.AllowCredentials {
+				return fmt.Errorf("GLOFOX_CORS_ORIGINS cannot allow every origin when credentials are allowed")
+			}
+			continue
+		}
+
+		// A subdomain wildcard may only lead the host
+		parsed, err := url.Parse(strings.Replace(origin, "://*.", "://", 1))
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") ||
+			parsed.Host == "" || strings.Contains(parsed.Host, "*") ||
+			parsed.Path != "" || parsed.RawQuery != "" || parsed.User != nil {
+			return fmt.Errorf("invalid CORS origin %q: must look like https://app.example.com or https://*.example.com", origin)
+		}
+	}
+
+	if c.MaxAge < 0 {
+		return fmt.Errorf("GLOFOX_CORS_MAX_AGE must not be negative")
+	}
+
+	if slices.Contains(c.AllowedHeaders, "*") && c.AllowCredentials {
+		return fmt.Errorf("GLOFOX_CORS_HEADERS cannot allow every header when credentials are allowed")
+	}
+
+	return nil
+}
+
+// getEnv returns the value of the environment variable or the fallback if it is unset or empty
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// getEnvDuration parses the environment variable as a duration, returning the fallback if it is unset or empty
+func getEnvDuration(key string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+
+	return duration, nil
+}
+
+// getEnvList parses the environment variable as a comma-separated list,
+// returning the fallback if it is unset or empty
+func getEnvList(key string, fallback []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
+
+// getEnvBool parses the environment variable as a boolean, returning the fallback if it is unset or empty
+func getEnvBool(key string, fallback bool) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %w", key, err)
+	}
+
+	return parsed, nil
+}
@@ -0,0 +1,222 @@
+package config
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	// Authentication has its own subtest; the others run an open API
+	t.Setenv("GLOFOX_AUTH_DISABLED", "true")
+
+	t.Run("Defaults", func(t *testing.T) {
+		t.Setenv("GLOFOX_ADDR", "")
+		t.Setenv("GLOFOX_STORAGE", "")
+
+		cfg, err := Load()
+		assert.NoError(t, err, "Should load configuration without error")
+		assert.Equal(t, ":8080", cfg.Addr, "Should default to port 8080")
+		assert.Equal(t, StorageMemory, cfg.Storage.Backend, "Should default to in-memory storage")
+		assert.Equal(t, 5*time.Minute, cfg.Storage.SnapshotInterval, "Should default to snapshots every 5 minutes")
+		assert.Equal(t, time.UTC, cfg.Studio.Location, "Should default to UTC")
+		assert.False(t, cfg.Auth.Enabled(), "Should not enable authentication without a secret or public key")
+		assert.Equal(t, []string{"*"}, cfg.CORS.AllowedOrigins, "Should default to allowing every origin")
+		assert.False(t, cfg.CORS.AllowCredentials, "Should default to not allowing credentials")
+		assert.Equal(t, RateLimit{Requests: 600, Period: time.Minute}, cfg.RateLimit.Default, "Should default to 600 requests a minute")
+		assert.Equal(t, RateLimit{Requests: 120, Period: time.Minute}, cfg.RateLimit.For(RouteGroupBookings), "Should default to 120 booking requests a minute")
+		assert.Equal(t, LogFormatJSON, cfg.Log.Format, "Should default to JSON logs")
+		assert.Equal(t, slog.LevelInfo, cfg.Log.Level, "Should default to the info level")
+	})
+
+	t.Run("Environment Overrides", func(t *testing.T) {
+		t.Setenv("GLOFOX_ADDR", ":9090")
+		t.Setenv("GLOFOX_STORAGE", StorageSQLite)
+		t.Setenv("GLOFOX_SQLITE_PATH", "/var/lib/glofox/data.db")
+
+		cfg, err := Load()
+		assert.NoError(t, err, "Should load configuration without error")
+		assert.Equal(t, ":9090", cfg.Addr, "Should use the configured address")
+		assert.Equal(t, StorageSQLite, cfg.Storage.Backend, "Should use the configured storage backend")
+		assert.Equal(t, "/var/lib/glofox/data.db", cfg.Storage.SQLitePath, "Should use the configured database path")
+	})
+
+	t.Run("Journal Backend", func(t *testing.T) {
+		t.Setenv("GLOFOX_STORAGE", StorageJournal)
+		t.Setenv("GLOFOX_JOURNAL_DIR", "/var/lib/glofox")
+		t.Setenv("GLOFOX_SNAPSHOT_INTERVAL", "30s")
+
+		cfg, err := Load()
+		assert.NoError(t, err, "Should load configuration without error")
+		assert.Equal(t, "/var/lib/glofox", cfg.Storage.JournalDir, "Should use the configured journal directory")
+		assert.Equal(t, 30*time.Second, cfg.Storage.SnapshotInterval, "Should use the configured snapshot interval")
+	})
+
+	t.Run("Invalid Snapshot Interval", func(t *testing.T) {
+		t.Setenv("GLOFOX_SNAPSHOT_INTERVAL", "often")
+
+		_, err := Load()
+		assert.Error(t, err, "Should return error for an unparsable snapshot interval")
+
+		t.Setenv("GLOFOX_SNAPSHOT_INTERVAL", "-1m")
+
+		_, err = Load()
+		assert.Error(t, err, "Should return error for a negative snapshot interval")
+	})
+
+	t.Run("Time Zone", func(t *testing.T) {
+		t.Setenv("GLOFOX_TIMEZONE", "America/Los_Angeles")
+
+		cfg, err := Load()
+		assert.NoError(t, err, "Should load configuration without error")
+		assert.Equal(t, "America/Los_Angeles", cfg.Studio.Location.String(), "Should use the configured time zone")
+
+		t.Setenv("GLOFOX_TIMEZONE", "Mars/Olympus_Mons")
+
+		_, err = Load()
+		assert.Error(t, err, "Should return error for an unknown time zone")
+	})
+
+	t.Run("Authentication", func(t *testing.T) {
+		t.Setenv("GLOFOX_AUTH_DISABLED", "")
+
+		_, err := Load()
+		assert.Error(t, err, "Should refuse to run without authentication unless it is disabled")
+
+		t.Setenv("GLOFOX_AUTH_DISABLED", "true")
+
+		cfg, err := Load()
+		assert.NoError(t, err, "Should load configuration without error")
+		assert.True(t, cfg.Auth.Disabled, "Should disable authentication when asked to")
+
+		t.Setenv("GLOFOX_AUTH_DISABLED", "")
+		t.Setenv("GLOFOX_JWT_SECRET", "0123456789abcdef0123456789abcdef")
+		t.Setenv("GLOFOX_JWT_ISSUER", "https://auth.example.com")
+
+		cfg, err = Load()
+		assert.NoError(t, err, "Should load configuration without error")
+		assert.True(t, cfg.Auth.Enabled(), "Should enable authentication once a secret is set")
+		assert.Equal(t, "https://auth.example.com", cfg.Auth.Issuer, "Should use the configured issuer")
+
+		t.Setenv("GLOFOX_JWT_SECRET", "too-short")
+
+		_, err = Load()
+		assert.Error(t, err, "Should return error for a secret shorter than 32 bytes")
+
+		t.Setenv("GLOFOX_JWT_SECRET", "0123456789abcdef0123456789abcdef")
+		t.Setenv("GLOFOX_AUTH_DISABLED", "true")
+
+		_, err = Load()
+		assert.Error(t, err, "Should return error when authentication is both configured and disabled")
+	})
+
+	t.Run("CORS", func(t *testing.T) {
+		t.Setenv("GLOFOX_CORS_ORIGINS", "https://app.example.com, https://*.example.org")
+		t.Setenv("GLOFOX_CORS_METHODS", "GET,POST")
+		t.Setenv("GLOFOX_CORS_MAX_AGE", "1h")
+		t.Setenv("GLOFOX_CORS_ALLOW_CREDENTIALS", "true")
+
+		cfg, err := Load()
+		assert.NoError(t, err, "Should load configuration without error")
+		assert.Equal(t, []string{"https://app.example.com", "https://*.example.org"}, cfg.CORS.AllowedOrigins, "Should use the configured origins")
+		assert.Equal(t, []string{"GET", "POST"}, cfg.CORS.AllowedMethods, "Should use the configured methods")
+		assert.Equal(t, time.Hour, cfg.CORS.MaxAge, "Should use the configured max age")
+		assert.True(t, cfg.CORS.AllowCredentials, "Should allow credentials when configured")
+
+		t.Setenv("GLOFOX_CORS_ORIGINS", "*")
+
+		_, err = Load()
+		assert.Error(t, err, "Should return error for credentials with a wildcard origin")
+
+		t.Setenv("GLOFOX_CORS_ALLOW_CREDENTIALS", "sometimes")
+
+		_, err = Load()
+		assert.Error(t, err, "Should return error for an unparsable credentials flag")
+
+		t.Setenv("GLOFOX_CORS_ALLOW_CREDENTIALS", "")
+		for _, origin := range []string{"app.example.com", "https://app.example.com/path", "https://app.*.example.com", "ftp://example.com"} {
+			t.Setenv("GLOFOX_CORS_ORIGINS", origin)
+
+			_, err = Load()
+			assert.Error(t, err, "Should return error for malformed origin %s", origin)
+		}
+	})
+
+	t.Run("Rate Limit", func(t *testing.T) {
+		t.Setenv("GLOFOX_RATE_LIMIT", "100/m")
+		t.Setenv("GLOFOX_RATE_LIMIT_GROUPS", "bookings=10/30s, members=50/1h")
+
+		cfg, err := Load()
+		assert.NoError(t, err, "Should load configuration without error")
+		assert.True(t, cfg.RateLimit.Enabled, "Should enable rate limiting")
+		assert.Equal(t, RateLimit{Requests: 100, Period: time.Minute}, cfg.RateLimit.For(RouteGroupCatalog), "Should use the default limit for groups without their own")
+		assert.Equal(t, RateLimit{Requests: 10, Period: 30 * time.Second}, cfg.RateLimit.For(RouteGroupBookings), "Should use the configured group limit")
+		assert.Equal(t, RateLimit{Requests: 50, Period: time.Hour}, cfg.RateLimit.For(RouteGroupMembers), "Should use the configured group limit")
+
+		t.Setenv("GLOFOX_RATE_LIMIT", "off")
+
+		cfg, err = Load()
+		assert.NoError(t, err, "Should load configuration without error")
+		assert.False(t, cfg.RateLimit.Enabled, "Should disable rate limiting")
+
+		for _, limit := range []string{"100", "0/1m", "100/0s", "lots/1m", "100/fortnight"} {
+			t.Setenv("GLOFOX_RATE_LIMIT", limit)
+
+			_, err = Load()
+			assert.Error(t, err, "Should return error for rate limit %s", limit)
+		}
+
+		t.Setenv("GLOFOX_RATE_LIMIT", "")
+		t.Setenv("GLOFOX_RATE_LIMIT_GROUPS", "checkout=10/1m")
+
+		_, err = Load()
+		assert.Error(t, err, "Should return error for an unknown route group")
+	})
+
+	t.Run("Trusted Proxies", func(t *testing.T) {
+		cfg, err := Load()
+		assert.NoError(t, err, "Should load configuration without error")
+		assert.Empty(t, cfg.TrustedProxies, "Should default to trusting no proxies")
+
+		t.Setenv("GLOFOX_TRUSTED_PROXIES", "10.0.0.0/8, 192.168.1.5, ::1")
+
+		cfg, err = Load()
+		assert.NoError(t, err, "Should load configuration without error")
+		assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.5", "::1"}, cfg.TrustedProxies, "Should use the configured proxies")
+
+		t.Setenv("GLOFOX_TRUSTED_PROXIES", "load-balancer")
+
+		_, err = Load()
+		assert.Error(t, err, "Should return error for a proxy that is not an IP address or range")
+	})
+
+	t.Run("Logging", func(t *testing.T) {
+		t.Setenv("GLOFOX_LOG_FORMAT", LogFormatText)
+		t.Setenv("GLOFOX_LOG_LEVEL", "debug")
+
+		cfg, err := Load()
+		assert.NoError(t, err, "Should load configuration without error")
+		assert.Equal(t, LogFormatText, cfg.Log.Format, "Should use the configured log format")
+		assert.Equal(t, slog.LevelDebug, cfg.Log.Level, "Should use the configured log level")
+
+		t.Setenv("GLOFOX_LOG_LEVEL", "chatty")
+
+		_, err = Load()
+		assert.Error(t, err, "Should return error for an unknown log level")
+
+		t.Setenv("GLOFOX_LOG_LEVEL", "")
+		t.Setenv("GLOFOX_LOG_FORMAT", "xml")
+
+		_, err = Load()
+		assert.Error(t, err, "Should return error for an unsupported log format")
+	})
+
+	t.Run("Unsupported Backend", func(t *testing.T) {
+		t.Setenv("GLOFOX_STORAGE", "mongodb")
+
+		_, err := Load()
+		assert.Error(t, err, "Should return error for unsupported storage backend")
+	})
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sanjaykishor/Glofox/internal/middleware"
+	"github.com/sanjaykishor/Glofox/internal/service"
+	"github.com/sanjaykishor/Glofox/internal/validation"
+)
+
+type APIKeyHandler struct {
+	apiKeyService *service.APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService *service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+	}
+}
+
+func (h *APIKeyHandler) RegisterRoutes(router gin.IRouter) {
+	apiKeysGroup := router.Group("/api-keys", middleware.Require(middleware.ScopeAPIKeysManage))
+	{
+		apiKeysGroup.POST("", h.CreateAPIKey)
+		apiKeysGroup.GET("", h.GetAllAPIKeys)
+		apiKeysGroup.GET("/:id", h.GetAPIKeyByID)
+		apiKeysGroup.DELETE("/:id", h.RevokeAPIKey)
+	}
+}
+
+// CreateAPIKey issues a new API key. The full key is only returned here.
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var request service.CreateAPIKeyRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		validation.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	key, err := h.apiKeyService.CreateAPIKey(&request)
+	if err != nil {
+		validation.ServiceErrorResponse(c, err)
+		return
+	}
+
+	validation.SuccessResponse(c, http.StatusCreated, "API key created successfully", key)
+}
+
+// GetAllAPIKeys returns all API keys, including revoked ones
+func (h *APIKeyHandler) GetAllAPIKeys(c *gin.Context) {
+	keys, err := h.apiKeyService.GetAllAPIKeys()
+	if err != nil {
+		validation.ServiceErrorResponse(c, err)
+		return
+	}
+
+	validation.SuccessResponse(c, http.StatusOK, "", keys)
+}
+
+// GetAPIKeyByID retrieves an API key by its ID
+func (h *APIKeyHandler) GetAPIKeyByID(c *gin.Context) {
+	key, err := h.apiKeyService.GetAPIKeyByID(c.Param("id"))
+	if err != nil {
+		validation.ServiceErrorResponse(c, err)
+		return
+	}
+
+	validation.SuccessResponse(c, http.StatusOK, "", key)
+}
+
+// RevokeAPIKey stops an API key from being used
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	key, err := h.apiKeyService.RevokeAPIKey(c.Param("id"))
+	if err != nil {
+		validation.ServiceErrorResponse(c, err)
+		return
+	}
+
+	validation.SuccessResponse(c, http.StatusOK, "API key revoked successfully", key)
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sanjaykishor/Glofox/internal/middleware"
+	"github.com/sanjaykishor/Glofox/internal/repository"
+	"github.com/sanjaykishor/Glofox/internal/service"
+	"github.com/sanjaykishor/Glofox/internal/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ownerPrincipal stands in for the studio owner's token
+var ownerPrincipal = &middleware.Principal{
+	Subject: "owner-1",
+	Role:    middleware.RoleOwner,
+	Scopes: []string{
+		middleware.ScopeClassesRead, middleware.ScopeClassesWrite,
+		middleware.ScopeMembersRead, middleware.ScopeMembersWrite,
+		middleware.ScopeBookingsRead, middleware.ScopeBookingsCreate, middleware.ScopeBookingsManage,
+		middleware.ScopeAPIKeysManage,
+	},
+}
+
+// setupAPIKeyTestRouter returns a router where requests without an API key
+// act as principal, or as the anonymous caller when principal is nil
+func setupAPIKeyTestRouter(principal *middleware.Principal) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	apiKeyService := service.NewAPIKeyService(repository.NewAPIKeyRepository(), middleware.IntegrationScopes)
+	classService := service.NewClassService(repository.NewClassRepository(), repository.NewBookingRepository(), repository.NewWaitlistRepository(), repository.NewOccurrenceRepository(), repository.NewInstructorRepository(), repository.NewRoomRepository())
+
+	router := gin.New()
+	api := router.Group("/api/v1", middleware.APIKeyAuth(apiKeyService), func(c *gin.Context) {
+		if principal != nil && middleware.CurrentPrincipal(c) == nil {
+			middleware.SetPrincipal(c, principal)
+		}
+	}, middleware.Authenticate(nil))
+	NewAPIKeyHandler(apiKeyService).RegisterRoutes(api)
+	NewClassHandler(classService).RegisterRoutes(api)
+
+	return router
+}
+
+func TestAPIKeyLifecycle(t *testing.T) {
+	router := setupAPIKeyTestRouter(ownerPrincipal)
+
+	send := func(method, path, apiKey string, body interface{}) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&buf).Encode(body)
+		}
+
+		req, _ := http.NewRequest(method, path, &buf)
+		req.Header.Set("Content-Type", "application/json")
+		if apiKey != "" {
+			req.Header.Set(middleware.APIKeyHeader, apiKey)
+		}
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("POST", "/api/v1/api-keys", "", map[string]interface{}{"name": "Booking widget", "scopes": []string{"classes:read"}})
+	require.Equal(t, http.StatusCreated, w.Code, "Should return status code 201")
+
+	var response validation.Response
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err, "Should parse response JSON without error")
+	created := response.Data.(map[string]interface{})
+	key := created["key"].(string)
+	assert.NotEmpty(t, key, "Should return the full key on creation")
+	assert.NotContains(t, created, "hash", "Should never return the key hash")
+
+	w = send("POST", "/api/v1/api-keys", "", map[string]interface{}{"name": "Kiosk", "scopes": []string{"api_keys:manage"}})
+	assert.Equal(t, http.StatusBadRequest, w.Code, "Should not grant keys the right to manage keys")
+
+	w = send("GET", "/api/v1/classes", key, nil)
+	assert.Equal(t, http.StatusOK, w.Code, "Should allow a key to use its scopes")
+
+	w = send("POST", "/api/v1/classes", key, map[string]interface{}{"name": "Yoga"})
+	assert.Equal(t, http.StatusForbidden, w.Code, "Should forbid a key from going beyond its scopes")
+
+	w = send("GET", "/api/v1/api-keys", key, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code, "Should forbid a key from managing keys")
+
+	w = send("GET", "/api/v1/classes", "gfx_unknown", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "Should reject an unknown key")
+
+	w = send("GET", "/api/v1/api-keys", "", nil)
+	require.Equal(t, http.StatusOK, w.Code, "Should return status code 200")
+	assert.NotContains(t, w.Body.String(), key, "Listing should never return the full key")
+	assert.Contains(t, w.Body.String(), "last_used_at", "Listing should show when the key was last used")
+
+	w = send("DELETE", "/api/v1/api-keys/"+created["id"].(string), "", nil)
+	assert.Equal(t, http.StatusOK, w.Code, "Should revoke the key")
+
+	w = send("GET", "/api/v1/classes", key, nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "Should reject a revoked key")
+
+	w = send("DELETE", "/api/v1/api-keys/"+created["id"].(string), "", nil)
+	assert.Equal(t, http.StatusConflict, w.Code, "Should not revoke a key twice")
+}
+
+func TestAPIKeyAnonymous(t *testing.T) {
+	router := setupAPIKeyTestRouter(nil)
+
+	jsonData, _ := json.Marshal(map[string]interface{}{"name": "Booking widget", "scopes": []string{"classes:read"}})
+	req, _ := http.NewRequest("POST", "/api/v1/api-keys", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code, "Should not let an anonymous caller create keys while authentication is disabled")
+
+	req, _ = http.NewRequest("GET", "/api/v1/api-keys", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code, "Should not let an anonymous caller list keys")
+}
@@ -1,9 +1,13 @@
 package handler
 
 import (
+	"errors"
+	"io"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sanjaykishor/Glofox/internal/middleware"
+	"github.com/sanjaykishor/Glofox/internal/repository"
 	"github.com/sanjaykishor/Glofox/internal/service"
 	"github.com/sanjaykishor/Glofox/internal/validation"
 )
@@ -21,21 +25,42 @@ func NewBookingHandler(bookingService *service.BookingService) *BookingHandler {
 func (h *BookingHandler) RegisterRoutes(router gin.IRouter) {
 	bookingsGroup := router.Group("/bookings")
 	{
-		bookingsGroup.POST("", h.CreateBooking)
-		bookingsGroup.GET("", h.GetAllBookings)
-		bookingsGroup.GET("/:id", h.GetBookingByID)
-		bookingsGroup.GET("/date/:date", h.GetBookingsByDate)
+		bookingsGroup.POST("", middleware.Require(middleware.ScopeBookingsCreate), h.CreateBooking)
+		bookingsGroup.GET("", middleware.Require(middleware.ScopeBookingsRead), h.GetAllBookings)
+		bookingsGroup.GET("/:id", middleware.Require(middleware.ScopeBookingsRead), h.GetBookingByID)
+		bookingsGroup.GET("/date/:date", middleware.Require(middleware.ScopeBookingsRead), h.GetBookingsByDate)
+		bookingsGroup.DELETE("/:id", middleware.Require(middleware.ScopeBookingsManage), h.CancelBooking)
+		bookingsGroup.POST("/:id/reschedule", middleware.Require(middleware.ScopeBookingsManage), h.RescheduleBooking)
 	}
+
+	router.GET("/classes/:id/bookings", middleware.Require(middleware.ScopeBookingsRead), h.GetClassBookings)
+}
+
+// ownMemberID returns the member a member principal is limited to, or an
+// empty string when the principal may act for every member
+func ownMemberID(c *gin.Context) string {
+	if principal := middleware.CurrentPrincipal(c); principal != nil && principal.IsMember() {
+		return principal.MemberID
+	}
+	return ""
 }
 
+// CreateBooking books a class for a member. Members can only book for themselves.
 func (h *BookingHandler) CreateBooking(c *gin.Context) {
-	var request service.CreateBookingRequest
+	// Members book as themselves without having to name their member ID
+	ownID := ownMemberID(c)
+	request := service.CreateBookingRequest{MemberID: ownID}
 
 	if err := c.ShouldBindJSON(&request); err != nil {
 		validation.ErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
+	if ownID != "" && request.MemberID != ownID {
+		validation.AbortResponse(c, http.StatusForbidden, validation.CodeForbidden, "members can only book for themselves")
+		return
+	}
+
 	booking, err := h.bookingService.CreateBooking(&request)
 	if err != nil {
 		validation.ServiceErrorResponse(c, err)
@@ -45,10 +70,43 @@ func (h *BookingHandler) CreateBooking(c *gin.Context) {
 	validation.SuccessResponse(c, http.StatusCreated, "Booking created successfully", booking)
 }
 
-// GetAllBookings returns all bookings
+// GetAllBookings returns a page of bookings, filtered and sorted by the query parameters
 func (h *BookingHandler) GetAllBookings(c *gin.Context) {
-	bookings := h.bookingService.GetAllBookings()
-	validation.SuccessResponse(c, http.StatusOK, "", bookings)
+	var request service.ListBookingsRequest
+
+	if err := c.ShouldBindQuery(&request); err != nil {
+		validation.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+	request.MemberID = ownMemberID(c)
+
+	page, err := h.bookingService.ListBookings(&request)
+	if err != nil {
+		validation.ServiceErrorResponse(c, err)
+		return
+	}
+
+	validation.PageResponse(c, page.Bookings, page.PageInfo)
+}
+
+// GetClassBookings returns a page of the bookings for a class, filtered and
+// sorted by the query parameters
+func (h *BookingHandler) GetClassBookings(c *gin.Context) {
+	var request service.ListBookingsRequest
+
+	if err := c.ShouldBindQuery(&request); err != nil {
+		validation.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+	request.MemberID = ownMemberID(c)
+
+	page, err := h.bookingService.ListClassBookings(c.Param("id"), &request)
+	if err != nil {
+		validation.ServiceErrorResponse(c, err)
+		return
+	}
+
+	validation.PageResponse(c, page.Bookings, page.PageInfo)
 }
 
 // GetBookingByID retrieves a booking by its ID
@@ -60,6 +118,12 @@ func (h *BookingHandler) GetBookingByID(c *gin.Context) {
 		return
 	}
 
+	// Other people's bookings are hidden from members rather than forbidden
+	if ownID := ownMemberID(c); ownID != "" && booking.MemberID != ownID {
+		validation.ServiceErrorResponse(c, repository.ErrBookingNotFound)
+		return
+	}
+
 	validation.SuccessResponse(c, http.StatusOK, "", booking)
 }
 
@@ -73,5 +137,53 @@ func (h *BookingHandler) GetBookingsByDate(c *gin.Context) {
 		return
 	}
 
+	if ownID := ownMemberID(c); ownID != "" {
+		own := make([]*repository.Booking, 0)
+		for _, booking := range bookings {
+			if booking.MemberID == ownID {
+				own = append(own, booking)
+			}
+		}
+		bookings = own
+	}
+
 	validation.SuccessResponse(c, http.StatusOK, "", bookings)
 }
+
+// CancelBooking cancels a booking. The request body with a reason is optional.
+func (h *BookingHandler) CancelBooking(c *gin.Context) {
+	var request service.CancelBookingRequest
+
+	if c.Request.Body != nil && c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+			validation.ErrorResponse(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	booking, err := h.bookingService.CancelBooking(c.Request.Context(), c.Param("id"), &request)
+	if err != nil {
+		validation.ServiceErrorResponse(c, err)
+		return
+	}
+
+	validation.SuccessResponse(c, http.StatusOK, "Booking cancelled successfully", booking)
+}
+
+// RescheduleBooking moves a booking to another date and optionally another class
+func (h *BookingHandler) RescheduleBooking(c *gin.Context) {
+	var request service.RescheduleBookingRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		validation.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	booking, err := h.bookingService.RescheduleBooking(c.Request.Context(), c.Param("id"), &request)
+	if err != nil {
+		validation.ServiceErrorResponse(c, err)
+		return
+	}
+
+	validation.SuccessResponse(c, http.StatusOK, "Booking rescheduled successfully", booking)
+}
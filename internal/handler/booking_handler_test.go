@@ -9,12 +9,23 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sanjaykishor/Glofox/internal/middleware"
 	"github.com/sanjaykishor/Glofox/internal/repository"
 	"github.com/sanjaykishor/Glofox/internal/service"
 	"github.com/sanjaykishor/Glofox/internal/validation"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+// The booking tests run on a fixed day, in UTC like the booking service, so
+// they do not depend on the host's clock or time zone
+var (
+	testNow   = time.Date(2025, 4, 22, 9, 0, 0, 0, time.UTC)
+	testToday = time.Date(2025, 4, 22, 0, 0, 0, 0, time.UTC)
+)
+
+const testDate = "2025-04-22"
+
 func setupTestRouter() (*gin.Engine, *repository.BookingRepository, *repository.ClassRepository) {
 	gin.SetMode(gin.TestMode)
 
@@ -24,16 +35,18 @@ func setupTestRouter() (*gin.Engine, *repository.BookingRepository, *repository.
 	class := &repository.Class{
 		ID:        "test-class-1",
 		Name:      "Yoga",
-		StartDate: time.Now().Add(24 * time.Hour), // Future date
-		EndDate:   time.Now().Add(48 * time.Hour),
+		StartDate: testToday,
+		EndDate:   testToday.AddDate(0, 0, 2),
 		Capacity:  20,
 	}
 	classRepo.Create(class)
 
-	bookingService := service.NewBookingService(bookingRepo, classRepo)
+	bookingService := service.NewBookingService(bookingRepo, classRepo, repository.NewWaitlistRepository(), repository.NewMemberRepository(), repository.NewOccurrenceRepository())
+	bookingService.SetClock(func() time.Time { return testNow })
 	bookingHandler := NewBookingHandler(bookingService)
 
 	router := gin.New()
+	router.Use(middleware.Authenticate(nil))
 	bookingHandler.RegisterRoutes(router.Group("/api/v1"))
 
 	return router, bookingRepo, classRepo
@@ -44,7 +57,7 @@ func TestCreateBooking(t *testing.T) {
 
 	validRequest := map[string]interface{}{
 		"name":     "John Doe",
-		"date":     time.Now().Format("2006-01-02"),
+		"date":     testDate,
 		"class_id": "test-class-1",
 	}
 
@@ -79,6 +92,7 @@ func TestCreateBooking(t *testing.T) {
 	assert.NoError(t, err, "Should parse response JSON without error")
 	assert.False(t, response.Success, "Response success should be false")
 	assert.Contains(t, response.Error, "date is required", "Error message should indicate missing date field")
+	assert.Equal(t, validation.CodeValidationFailed, response.Code, "Error code should indicate a validation failure")
 }
 
 func TestGetAllBookings(t *testing.T) {
@@ -88,8 +102,8 @@ func TestGetAllBookings(t *testing.T) {
 		ID:         "test-booking-1",
 		MemberName: "John Doe",
 		ClassID:    "test-class-1",
-		Date:       time.Now(),
-		CreatedAt:  time.Now(),
+		Date:       testToday,
+		CreatedAt:  testNow,
 	}
 	bookingRepo.Create(booking)
 
@@ -109,6 +123,95 @@ func TestGetAllBookings(t *testing.T) {
 	assert.NotEmpty(t, bookingsData, "Should return at least one booking")
 }
 
+func TestGetAllBookingsFilters(t *testing.T) {
+	router, bookingRepo, _ := setupTestRouter()
+
+	date := time.Date(2025, 4, 25, 0, 0, 0, 0, time.UTC)
+	bookingRepo.Create(&repository.Booking{ID: "booking-1", MemberName: "John Doe", ClassID: "test-class-1", Date: date})
+	bookingRepo.Create(&repository.Booking{ID: "booking-2", MemberName: "Jane Smith", ClassID: "test-class-1", Date: date})
+	bookingRepo.Create(&repository.Booking{ID: "booking-3", MemberName: "John Doe", ClassID: "test-class-1", Date: date.AddDate(0, 0, 3)})
+
+	req, _ := http.NewRequest("GET", "/api/v1/bookings?member=john%20doe&from=2025-04-24&to=2025-04-26&class_id=test-class-1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, "Should return status code 200")
+
+	var response struct {
+		validation.Response
+		Data []repository.Booking `json:"data"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err, "Should parse response JSON without error")
+	require.Len(t, response.Data, 1, "Should return only the matching booking")
+	assert.Equal(t, "booking-1", response.Data[0].ID, "Should return the member's booking within the range")
+	require.NotNil(t, response.Pagination, "Should include pagination metadata")
+	assert.False(t, response.Pagination.HasMore, "Should report the last page")
+
+	req, _ = http.NewRequest("GET", "/api/v1/bookings?from=2025-04-26&to=2025-04-24", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code, "Should return status code 400 for a reversed date range")
+}
+
+func TestGetClassBookings(t *testing.T) {
+	router, bookingRepo, classRepo := setupTestRouter()
+
+	classRepo.Create(&repository.Class{ID: "test-class-2", Name: "Pilates", StartDate: testToday, EndDate: testToday, Capacity: 10})
+
+	date := time.Date(2025, 4, 25, 0, 0, 0, 0, time.UTC)
+	bookingRepo.Create(&repository.Booking{ID: "booking-1", MemberName: "John Doe", ClassID: "test-class-1", Date: date})
+	bookingRepo.Create(&repository.Booking{ID: "booking-2", MemberName: "Jane Smith", ClassID: "test-class-1", Date: date.AddDate(0, 0, 1)})
+	bookingRepo.Create(&repository.Booking{ID: "booking-3", MemberName: "John Doe", ClassID: "test-class-2", Date: date})
+
+	tests := []struct {
+		name    string
+		url     string
+		wantIDs []string
+	}{
+		{"All Dates", "/api/v1/classes/test-class-1/bookings", []string{"booking-1", "booking-2"}},
+		{"Single Date", "/api/v1/classes/test-class-1/bookings?date=2025-04-26", []string{"booking-2"}},
+		{"Date Range", "/api/v1/classes/test-class-1/bookings?from=2025-04-20&to=2025-04-25", []string{"booking-1"}},
+		{"Other Class", "/api/v1/classes/test-class-2/bookings", []string{"booking-3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", tt.url, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code, "Should return status code 200")
+
+			var response struct {
+				validation.Response
+				Data []repository.Booking `json:"data"`
+			}
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			require.NoError(t, err, "Should parse response JSON without error")
+
+			ids := make([]string, 0, len(response.Data))
+			for _, booking := range response.Data {
+				ids = append(ids, booking.ID)
+			}
+			assert.Equal(t, tt.wantIDs, ids, "Should return the class bookings for the requested dates")
+		})
+	}
+
+	req, _ := http.NewRequest("GET", "/api/v1/classes/unknown-class/bookings", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code, "Should return status code 404 for an unknown class")
+
+	req, _ = http.NewRequest("GET", "/api/v1/classes/test-class-1/bookings?date=2025-04-25&from=2025-04-20", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code, "Should return status code 400 when date is combined with a range")
+}
+
 func TestGetBookingByID(t *testing.T) {
 	router, bookingRepo, _ := setupTestRouter()
 
@@ -116,8 +219,8 @@ func TestGetBookingByID(t *testing.T) {
 		ID:         "test-booking-1",
 		MemberName: "John Doe",
 		ClassID:    "test-class-1",
-		Date:       time.Now(),
-		CreatedAt:  time.Now(),
+		Date:       testToday,
+		CreatedAt:  testNow,
 	}
 	bookingRepo.Create(booking)
 
@@ -141,4 +244,245 @@ func TestGetBookingByID(t *testing.T) {
 	err = json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err, "Should parse response JSON without error")
 	assert.False(t, response.Success, "Response success should be false")
+	assert.Equal(t, repository.ErrBookingNotFound.Code, response.Code, "Error code should indicate the booking was not found")
+}
+
+func TestCreateBookingClassFull(t *testing.T) {
+	router, _, classRepo := setupTestRouter()
+
+	class, err := classRepo.GetByID("test-class-1")
+	require.NoError(t, err, "Should retrieve test class without error")
+	full := *class
+	full.Capacity = 1
+	require.NoError(t, classRepo.Update(&full), "Should update test class without error")
+
+	request := map[string]any{
+		"name":     "John Doe",
+		"date":     testDate,
+		"class_id": "test-class-1",
+	}
+
+	jsonData, _ := json.Marshal(request)
+	req, _ := http.NewRequest("POST", "/api/v1/bookings", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code, "Should return status code 201")
+
+	request["name"] = "Jane Smith"
+	jsonData, _ = json.Marshal(request)
+	req, _ = http.NewRequest("POST", "/api/v1/bookings", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code, "Should return status code 409")
+
+	var response validation.Response
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err, "Should parse response JSON without error")
+	assert.False(t, response.Success, "Response success should be false")
+	assert.Equal(t, repository.ErrCapacityExceeded.Error(), response.Error, "Error message should indicate the class is full")
+	assert.Equal(t, "capacity_exceeded", response.Code, "Error code should indicate the class is full")
+}
+
+func TestCreateBookingDuplicate(t *testing.T) {
+	router, _, _ := setupTestRouter()
+
+	request := map[string]any{
+		"name":     "John Doe",
+		"date":     testDate,
+		"class_id": "test-class-1",
+	}
+
+	jsonData, _ := json.Marshal(request)
+	req, _ := http.NewRequest("POST", "/api/v1/bookings", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code, "Should return status code 201")
+
+	var response validation.Response
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err, "Should parse response JSON without error")
+	existingID := response.Data.(map[string]any)["id"]
+
+	req, _ = http.NewRequest("POST", "/api/v1/bookings", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code, "Should return status code 409")
+
+	response = validation.Response{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err, "Should parse response JSON without error")
+	assert.False(t, response.Success, "Response success should be false")
+	assert.Contains(t, response.Error, existingID, "Error message should identify the existing booking")
+	assert.Equal(t, "duplicate_booking", response.Code, "Error code should indicate a duplicate booking")
+	assert.Equal(t, existingID, response.Data.(map[string]any)["existing_booking_id"], "Response should include the existing booking ID")
+}
+
+func TestCancelBooking(t *testing.T) {
+	router, bookingRepo, _ := setupTestRouter()
+
+	booking := &repository.Booking{
+		ID:         "test-booking-1",
+		MemberName: "John Doe",
+		ClassID:    "test-class-1",
+		Date:       testToday,
+		Status:     repository.BookingStatusConfirmed,
+		CreatedAt:  testNow,
+	}
+	bookingRepo.Create(booking)
+
+	jsonData, _ := json.Marshal(map[string]any{"reason": "Feeling unwell"})
+	req, _ := http.NewRequest("DELETE", "/api/v1/bookings/test-booking-1", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, "Should return status code 200")
+
+	var response validation.Response
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err, "Should parse response JSON without error")
+	assert.True(t, response.Success, "Response success should be true")
+
+	data := response.Data.(map[string]any)
+	assert.Equal(t, "cancelled", data["status"], "Booking status should be cancelled")
+	assert.Equal(t, "Feeling unwell", data["cancellation_reason"], "Cancellation reason should be returned")
+	assert.NotEmpty(t, data["cancelled_at"], "Cancellation time should be returned")
+
+	// Cancelling again without a body is a conflict
+	req, _ = http.NewRequest("DELETE", "/api/v1/bookings/test-booking-1", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code, "Should return status code 409")
+
+	req, _ = http.NewRequest("DELETE", "/api/v1/bookings/non-existent", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code, "Should return status code 404")
+}
+
+func TestRescheduleBooking(t *testing.T) {
+	router, bookingRepo, _ := setupTestRouter()
+
+	booking := &repository.Booking{
+		ID:         "test-booking-1",
+		MemberName: "John Doe",
+		ClassID:    "test-class-1",
+		Date:       testToday,
+		Status:     repository.BookingStatusConfirmed,
+		CreatedAt:  testNow,
+	}
+	bookingRepo.Create(booking)
+
+	newDate := "2025-04-23"
+	jsonData, _ := json.Marshal(map[string]any{"date": newDate})
+	req, _ := http.NewRequest("POST", "/api/v1/bookings/test-booking-1/reschedule", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, "Should return status code 200")
+
+	moved, _ := bookingRepo.GetByID("test-booking-1")
+	assert.Equal(t, newDate, moved.Date.Format("2006-01-02"), "Booking should be moved to the new date")
+
+	// Missing date
+	req, _ = http.NewRequest("POST", "/api/v1/bookings/test-booking-1/reschedule", bytes.NewBuffer([]byte("{}")))
+	req.Header.Set("Content-Type", "application/json")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code, "Should return status code 400")
+
+	var response validation.Response
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err, "Should parse response JSON without error")
+	assert.Contains(t, response.Error, "date is required", "Error message should indicate missing date field")
+}
+
+func TestMemberBookingAccess(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	bookingRepo := repository.NewBookingRepository()
+	classRepo := repository.NewClassRepository()
+	memberRepo := repository.NewMemberRepository()
+
+	classRepo.Create(&repository.Class{
+		ID:        "test-class-1",
+		Name:      "Yoga",
+		StartDate: testToday,
+		EndDate:   testToday.AddDate(0, 0, 2),
+		Capacity:  20,
+	})
+	memberRepo.Create(&repository.Member{ID: "member-1", Name: "John Doe"})
+	memberRepo.Create(&repository.Member{ID: "member-2", Name: "Jane Doe"})
+	bookingRepo.Create(&repository.Booking{ID: "booking-2", MemberID: "member-2", MemberName: "Jane Doe", ClassID: "test-class-1", Date: testToday})
+
+	bookingService := service.NewBookingService(bookingRepo, classRepo, repository.NewWaitlistRepository(), memberRepo, repository.NewOccurrenceRepository())
+	bookingService.SetClock(func() time.Time { return testNow })
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		middleware.SetPrincipal(c, &middleware.Principal{
+			Subject:  "member-1",
+			Role:     middleware.RoleMember,
+			MemberID: "member-1",
+			Scopes:   []string{middleware.ScopeClassesRead, middleware.ScopeBookingsRead, middleware.ScopeBookingsCreate},
+		})
+	})
+	NewBookingHandler(bookingService).RegisterRoutes(router.Group("/api/v1"))
+
+	post := func(body map[string]interface{}) *httptest.ResponseRecorder {
+		jsonData, _ := json.Marshal(body)
+		req, _ := http.NewRequest("POST", "/api/v1/bookings", bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post(map[string]interface{}{"date": testDate, "class_id": "test-class-1"})
+	assert.Equal(t, http.StatusCreated, w.Code, "Should book for the member without naming them")
+
+	var response validation.Response
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err, "Should parse response JSON without error")
+	ownBookingID := response.Data.(map[string]interface{})["id"].(string)
+	assert.Equal(t, "member-1", response.Data.(map[string]interface{})["member_id"], "Should book for the member")
+
+	w = post(map[string]interface{}{"member_id": "member-2", "date": testDate, "class_id": "test-class-1"})
+	assert.Equal(t, http.StatusForbidden, w.Code, "Should forbid booking for another member")
+
+	req, _ := http.NewRequest("GET", "/api/v1/bookings", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code, "Should return status code 200")
+	assert.Contains(t, w.Body.String(), ownBookingID, "Should list the member's own booking")
+	assert.NotContains(t, w.Body.String(), "booking-2", "Should not list another member's booking")
+
+	req, _ = http.NewRequest("GET", "/api/v1/bookings/booking-2", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "Should hide another member's booking")
+
+	req, _ = http.NewRequest("DELETE", "/api/v1/bookings/"+ownBookingID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code, "Should forbid members from cancelling bookings")
 }
@@ -2,8 +2,10 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sanjaykishor/Glofox/internal/middleware"
 	"github.com/sanjaykishor/Glofox/internal/service"
 	"github.com/sanjaykishor/Glofox/internal/validation"
 )
@@ -21,9 +23,12 @@ func NewClassHandler(classService *service.ClassService) *ClassHandler {
 func (h *ClassHandler) RegisterRoutes(router gin.IRouter) {
 	classesGroup := router.Group("/classes")
 	{
-		classesGroup.POST("", h.CreateClass)
-		classesGroup.GET("", h.GetAllClasses)
-		classesGroup.GET("/:id", h.GetClassByID)
+		classesGroup.POST("", middleware.Require(middleware.ScopeClassesWrite), h.CreateClass)
+		classesGroup.GET("", middleware.Require(middleware.ScopeClassesRead), h.GetAllClasses)
+		classesGroup.GET("/:id", middleware.Require(middleware.ScopeClassesRead), h.GetClassByID)
+		classesGroup.PUT("/:id", middleware.Require(middleware.ScopeClassesWrite), h.ReplaceClass)
+		classesGroup.PATCH("/:id", middleware.Require(middleware.ScopeClassesWrite), h.PatchClass)
+		classesGroup.DELETE("/:id", middleware.Require(middleware.ScopeClassesWrite), h.DeleteClass)
 	}
 }
 
@@ -44,10 +49,22 @@ func (h *ClassHandler) CreateClass(c *gin.Context) {
 	validation.SuccessResponse(c, http.StatusCreated, "Class created successfully", class)
 }
 
-// GetAllClasses returns all classes
+// GetAllClasses returns a page of classes, filtered and sorted by the query parameters
 func (h *ClassHandler) GetAllClasses(c *gin.Context) {
-	classes := h.classService.GetAllClasses()
-	validation.SuccessResponse(c, http.StatusOK, "", classes)
+	var request service.ListClassesRequest
+
+	if err := c.ShouldBindQuery(&request); err != nil {
+		validation.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	page, err := h.classService.ListClasses(&request)
+	if err != nil {
+		validation.ServiceErrorResponse(c, err)
+		return
+	}
+
+	validation.PageResponse(c, page.Classes, page.PageInfo)
 }
 
 // GetClassByID retrieves a class by its ID
@@ -61,3 +78,55 @@ func (h *ClassHandler) GetClassByID(c *gin.Context) {
 
 	validation.SuccessResponse(c, http.StatusOK, "", class)
 }
+
+// ReplaceClass replaces every field of a class
+func (h *ClassHandler) ReplaceClass(c *gin.Context) {
+	var request service.CreateClassRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		validation.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	class, err := h.classService.ReplaceClass(c.Request.Context(), c.Param("id"), &request)
+	if err != nil {
+		validation.ServiceErrorResponse(c, err)
+		return
+	}
+
+	validation.SuccessResponse(c, http.StatusOK, "Class updated successfully", class)
+}
+
+// PatchClass updates the fields of a class present in the request body
+func (h *ClassHandler) PatchClass(c *gin.Context) {
+	var request service.UpdateClassRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		validation.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	class, err := h.classService.PatchClass(c.Request.Context(), c.Param("id"), &request)
+	if err != nil {
+		validation.ServiceErrorResponse(c, err)
+		return
+	}
+
+	validation.SuccessResponse(c, http.StatusOK, "Class updated successfully", class)
+}
+
+// DeleteClass deletes a class, optionally together with its upcoming bookings
+func (h *ClassHandler) DeleteClass(c *gin.Context) {
+	force, err := strconv.ParseBool(c.DefaultQuery("force", "false"))
+	if err != nil {
+		validation.ErrorResponse(c, http.StatusBadRequest, &service.ValidationError{Field: "force", Rule: "boolean", Message: "force must be true or false"})
+		return
+	}
+
+	if err := h.classService.DeleteClass(c.Param("id"), force); err != nil {
+		validation.ServiceErrorResponse(c, err)
+		return
+	}
+
+	validation.SuccessResponse(c, http.StatusOK, "Class deleted successfully", nil)
+}
@@ -3,34 +3,39 @@ package handler
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sanjaykishor/Glofox/internal/middleware"
 	"github.com/sanjaykishor/Glofox/internal/repository"
 	"github.com/sanjaykishor/Glofox/internal/service"
 	"github.com/sanjaykishor/Glofox/internal/validation"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-func setupClassTestRouter() (*gin.Engine, *repository.ClassRepository) {
+func setupClassTestRouter() (*gin.Engine, *repository.ClassRepository, *repository.BookingRepository) {
 	gin.SetMode(gin.TestMode)
 
 	classRepo := repository.NewClassRepository()
+	bookingRepo := repository.NewBookingRepository()
 
-	classService := service.NewClassService(classRepo)
+	classService := service.NewClassService(classRepo, bookingRepo, repository.NewWaitlistRepository(), repository.NewOccurrenceRepository(), repository.NewInstructorRepository(), repository.NewRoomRepository())
 	classHandler := NewClassHandler(classService)
 
 	router := gin.New()
+	router.Use(middleware.Authenticate(nil))
 	classHandler.RegisterRoutes(router.Group("/api/v1"))
 
-	return router, classRepo
+	return router, classRepo, bookingRepo
 }
 
 func TestCreateClass(t *testing.T) {
-	router, _ := setupClassTestRouter()
+	router, _, _ := setupClassTestRouter()
 
 	startDate := time.Now().Add(24 * time.Hour).Format("2006-01-02")
 	endDate := time.Now().Add(48 * time.Hour).Format("2006-01-02")
@@ -76,6 +81,11 @@ func TestCreateClass(t *testing.T) {
 	assert.Contains(t, response.Error, "start_date is required", "Error message should indicate missing start_date field")
 	assert.Contains(t, response.Error, "end_date is required", "Error message should indicate missing end_date field")
 	assert.Contains(t, response.Error, "capacity is required", "Error message should indicate missing capacity field")
+	assert.ElementsMatch(t, []validation.FieldError{
+		{Field: "start_date", Rule: "required", Message: "start_date is required"},
+		{Field: "end_date", Rule: "required", Message: "end_date is required"},
+		{Field: "capacity", Rule: "required", Message: "capacity is required"},
+	}, response.Errors, "Should list each missing field")
 
 	// Invalid date format
 	invalidDateRequest := map[string]any{
@@ -94,13 +104,42 @@ func TestCreateClass(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, w.Code, "Should return status code 400")
 
+	response = validation.Response{}
 	err = json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err, "Should parse response JSON without error")
 	assert.False(t, response.Success, "Response success should be false")
+	require.Len(t, response.Errors, 1, "Should report the invalid start date")
+	assert.Equal(t, "start_date", response.Errors[0].Field, "Should identify the start_date field")
+	assert.Equal(t, "date_format", response.Errors[0].Rule, "Should identify the failed rule")
+
+	// End date before start date
+	invalidRangeRequest := map[string]any{
+		"name":       "Yoga Class",
+		"start_date": endDate,
+		"end_date":   startDate,
+		"capacity":   20,
+	}
+
+	jsonData, _ = json.Marshal(invalidRangeRequest)
+	req, _ = http.NewRequest("POST", "/api/v1/classes", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code, "Should return status code 400")
+
+	response = validation.Response{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err, "Should parse response JSON without error")
+	assert.Equal(t, "end date cannot be before start date", response.Error, "Should keep the error message")
+	assert.Equal(t, []validation.FieldError{
+		{Field: "end_date", Rule: "gtefield", Message: "end date cannot be before start date", Param: "start_date"},
+	}, response.Errors, "Should report the service check as a field error")
 }
 
 func TestGetAllClasses(t *testing.T) {
-	router, classRepo := setupClassTestRouter()
+	router, classRepo, _ := setupClassTestRouter()
 
 	class := &repository.Class{
 		ID:        "11111111-1111-1111-1111-111111111111",
@@ -128,8 +167,73 @@ func TestGetAllClasses(t *testing.T) {
 	assert.NotEmpty(t, classesData, "Should return at least one class")
 }
 
+func TestGetAllClassesPagination(t *testing.T) {
+	router, classRepo, _ := setupClassTestRouter()
+
+	for i, name := range []string{"Yoga", "Pilates", "Spin"} {
+		classRepo.Create(&repository.Class{
+			ID:        fmt.Sprintf("class-%d", i),
+			Name:      name,
+			StartDate: time.Now().AddDate(0, 0, i+1),
+			EndDate:   time.Now().AddDate(0, 0, i+2),
+			Capacity:  20,
+		})
+	}
+
+	var names []string
+	url := "/api/v1/classes?limit=2&sort=name"
+	for pages := 0; url != ""; pages++ {
+		require.Less(t, pages, 3, "Should reach the last page")
+
+		req, _ := http.NewRequest("GET", url, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code, "Should return status code 200")
+
+		var response struct {
+			validation.Response
+			Data []repository.Class `json:"data"`
+		}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err, "Should parse response JSON without error")
+		require.NotNil(t, response.Pagination, "Should include pagination metadata")
+		assert.Equal(t, 2, response.Pagination.Limit, "Should report the page size")
+		assert.Equal(t, response.Pagination.NextCursor != "", response.Pagination.HasMore, "has_more should match the presence of a cursor")
+
+		for _, class := range response.Data {
+			names = append(names, class.Name)
+		}
+
+		url = ""
+		if response.Pagination.HasMore {
+			url = "/api/v1/classes?limit=2&sort=name&cursor=" + response.Pagination.NextCursor
+		}
+	}
+
+	assert.Equal(t, []string{"Pilates", "Spin", "Yoga"}, names, "Should return every class once, sorted by name")
+
+	req, _ := http.NewRequest("GET", "/api/v1/classes?sort=colour", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code, "Should return status code 400 for an unknown sort field")
+
+	req, _ = http.NewRequest("GET", "/api/v1/classes?limit=1000", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code, "Should return status code 400 for a limit above the maximum")
+
+	var response validation.Response
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err, "Should parse response JSON without error")
+	require.Len(t, response.Errors, 1, "Should report the invalid limit")
+	assert.Equal(t, "limit", response.Errors[0].Field, "Should name the query parameter")
+}
+
 func TestGetClassByID(t *testing.T) {
-	router, classRepo := setupClassTestRouter()
+	router, classRepo, _ := setupClassTestRouter()
 
 	class := &repository.Class{
 		ID:        "11111111-1111-1111-1111-111111111111",
@@ -162,3 +266,132 @@ func TestGetClassByID(t *testing.T) {
 	assert.NoError(t, err, "Should parse response JSON without error")
 	assert.False(t, response.Success, "Response success should be false")
 }
+
+func TestUpdateClass(t *testing.T) {
+	router, classRepo, bookingRepo := setupClassTestRouter()
+
+	class := &repository.Class{
+		ID:        "11111111-1111-1111-1111-111111111111",
+		Name:      "Yoga",
+		StartDate: time.Now(),
+		EndDate:   time.Now().Add(48 * time.Hour),
+		Capacity:  20,
+	}
+	classRepo.Create(class)
+
+	for id, name := range map[string]string{"booking-1": "John Doe", "booking-2": "Jane Smith"} {
+		bookingRepo.Create(&repository.Booking{
+			ID:         id,
+			MemberName: name,
+			ClassID:    class.ID,
+			Date:       time.Date(time.Now().Year(), time.Now().Month(), time.Now().Day(), 0, 0, 0, 0, time.UTC),
+		})
+	}
+
+	// PUT replaces the whole class
+	replaceRequest := map[string]any{
+		"name":       "Power Yoga",
+		"start_date": time.Now().Format("2006-01-02"),
+		"end_date":   time.Now().Add(96 * time.Hour).Format("2006-01-02"),
+		"capacity":   25,
+	}
+
+	jsonData, _ := json.Marshal(replaceRequest)
+	req, _ := http.NewRequest("PUT", "/api/v1/classes/"+class.ID, bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, "Should return status code 200")
+
+	var response validation.Response
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err, "Should parse response JSON without error")
+	assert.True(t, response.Success, "Response success should be true")
+
+	// PATCH only changes the given fields
+	jsonData, _ = json.Marshal(map[string]any{"capacity": 2})
+	req, _ = http.NewRequest("PATCH", "/api/v1/classes/"+class.ID, bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, "Should return status code 200")
+
+	updated, _ := classRepo.GetByID(class.ID)
+	assert.Equal(t, "Power Yoga", updated.Name, "Name should be unchanged by PATCH")
+	assert.Equal(t, 2, updated.Capacity, "Capacity should be updated by PATCH")
+
+	// Shrinking capacity below today's bookings is a conflict
+	jsonData, _ = json.Marshal(map[string]any{"capacity": 1})
+	req, _ = http.NewRequest("PATCH", "/api/v1/classes/"+class.ID, bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code, "Should return status code 409")
+
+	// Non-existent class
+	req, _ = http.NewRequest("PATCH", "/api/v1/classes/non-existent-id", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code, "Should return status code 404")
+}
+
+func TestDeleteClass(t *testing.T) {
+	router, classRepo, bookingRepo := setupClassTestRouter()
+
+	class := &repository.Class{
+		ID:        "11111111-1111-1111-1111-111111111111",
+		Name:      "Yoga",
+		StartDate: time.Now(),
+		EndDate:   time.Now().Add(48 * time.Hour),
+		Capacity:  20,
+	}
+	classRepo.Create(class)
+	bookingRepo.Create(&repository.Booking{
+		ID:         "booking-1",
+		MemberName: "John Doe",
+		ClassID:    class.ID,
+		Date:       time.Now().Add(24 * time.Hour),
+	})
+
+	req, _ := http.NewRequest("DELETE", "/api/v1/classes/"+class.ID, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code, "Should return status code 409 when class has upcoming bookings")
+
+	req, _ = http.NewRequest("DELETE", "/api/v1/classes/"+class.ID+"?force=maybe", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code, "Should return status code 400 for an invalid force value")
+
+	req, _ = http.NewRequest("DELETE", "/api/v1/classes/"+class.ID+"?force=true", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, "Should return status code 200")
+
+	var response validation.Response
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err, "Should parse response JSON without error")
+	assert.True(t, response.Success, "Response success should be true")
+
+	booking, err := bookingRepo.GetByID("booking-1")
+	assert.NoError(t, err, "Upcoming booking should be kept")
+	assert.Equal(t, repository.BookingStatusCancelled, booking.Status, "Upcoming booking should be cancelled with the class")
+
+	req, _ = http.NewRequest("DELETE", "/api/v1/classes/"+class.ID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code, "Should return status code 404")
+}
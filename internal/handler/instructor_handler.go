@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sanjaykishor/Glofox/internal/middleware"
+	"github.com/sanjaykishor/Glofox/internal/service"
+	"github.com/sanjaykishor/Glofox/internal/validation"
+)
+
+type InstructorHandler struct {
+	instructorService *service.InstructorService
+}
+
+func NewInstructorHandler(instructorService *service.InstructorService) *InstructorHandler {
+	return &InstructorHandler{
+		instructorService: instructorService,
+	}
+}
+
+func (h *InstructorHandler) RegisterRoutes(router gin.IRouter) {
+	instructorsGroup := router.Group("/instructors")
+	{
+		instructorsGroup.POST("", middleware.Require(middleware.ScopeClassesWrite), h.CreateInstructor)
+		instructorsGroup.GET("", middleware.Require(middleware.ScopeClassesRead), h.GetAllInstructors)
+		instructorsGroup.GET("/:id", middleware.Require(middleware.ScopeClassesRead), h.GetInstructorByID)
+		instructorsGroup.PUT("/:id", middleware.Require(middleware.ScopeClassesWrite), h.ReplaceInstructor)
+		instructorsGroup.DELETE("/:id", middleware.Require(middleware.ScopeClassesWrite), h.DeleteInstructor)
+	}
+}
+
+// CreateInstructor registers a new instructor
+func (h *InstructorHandler) CreateInstructor(c *gin.Context) {
+	var request service.InstructorRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		validation.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	instructor, err := h.instructorService.CreateInstructor(&request)
+	if err != nil {
+		validation.ServiceErrorResponse(c, err)
+		return
+	}
+
+	validation.SuccessResponse(c, http.StatusCreated, "Instructor created successfully", instructor)
+}
+
+// GetAllInstructors returns all instructors
+func (h *InstructorHandler) GetAllInstructors(c *gin.Context) {
+	instructors, err := h.instructorService.GetAllInstructors()
+	if err != nil {
+		validation.ServiceErrorResponse(c, err)
+		return
+	}
+
+	validation.SuccessResponse(c, http.StatusOK, "", instructors)
+}
+
+// GetInstructorByID retrieves an instructor by its ID
+func (h *InstructorHandler) GetInstructorByID(c *gin.Context) {
+	instructor, err := h.instructorService.GetInstructorByID(c.Param("id"))
+	if err != nil {
+		validation.ServiceErrorResponse(c, err)
+		return
+	}
+
+	validation.SuccessResponse(c, http.StatusOK, "", instructor)
+}
+
+// ReplaceInstructor replaces the details of an instructor
+func (h *InstructorHandler) ReplaceInstructor(c *gin.Context) {
+	var request service.InstructorRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		validation.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	instructor, err := h.instructorService.ReplaceInstructor(c.Param("id"), &request)
+	if err != nil {
+		validation.ServiceErrorResponse(c, err)
+		return
+	}
+
+	validation.SuccessResponse(c, http.StatusOK, "Instructor updated successfully", instructor)
+}
+
+// DeleteInstructor removes an instructor
+func (h *InstructorHandler) DeleteInstructor(c *gin.Context) {
+	if err := h.instructorService.DeleteInstructor(c.Param("id")); err != nil {
+		validation.ServiceErrorResponse(c, err)
+		return
+	}
+
+	validation.SuccessResponse(c, http.StatusOK, "Instructor deleted successfully", nil)
+}
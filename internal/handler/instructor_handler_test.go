@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sanjaykishor/Glofox/internal/middleware"
+	"github.com/sanjaykishor/Glofox/internal/repository"
+	"github.com/sanjaykishor/Glofox/internal/service"
+	"github.com/sanjaykishor/Glofox/internal/validation"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupInstructorTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	classRepo := repository.NewClassRepository()
+	occurrenceRepo := repository.NewOccurrenceRepository()
+	instructorRepo := repository.NewInstructorRepository()
+
+	instructorHandler := NewInstructorHandler(service.NewInstructorService(instructorRepo, classRepo, occurrenceRepo))
+	classHandler := NewClassHandler(service.NewClassService(classRepo, repository.NewBookingRepository(), repository.NewWaitlistRepository(), occurrenceRepo, instructorRepo, repository.NewRoomRepository()))
+
+	router := gin.New()
+	router.Use(middleware.Authenticate(nil))
+	instructorHandler.RegisterRoutes(router.Group("/api/v1"))
+	classHandler.RegisterRoutes(router.Group("/api/v1"))
+
+	return router
+}
+
+func TestInstructorCRUD(t *testing.T) {
+	router := setupInstructorTestRouter()
+
+	jsonData, _ := json.Marshal(map[string]any{"name": "Alex Morgan", "email": "alex@example.com", "bio": "Yoga teacher"})
+	req, _ := http.NewRequest("POST", "/api/v1/instructors", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code, "Should return status code 201")
+
+	var response validation.Response
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err, "Should parse response JSON without error")
+	assert.True(t, response.Success, "Response success should be true")
+	instructorID := response.Data.(map[string]any)["id"].(string)
+
+	// Missing name
+	jsonData, _ = json.Marshal(map[string]any{"email": "alex@example.com"})
+	req, _ = http.NewRequest("POST", "/api/v1/instructors", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code, "Should return status code 400")
+
+	// Update
+	jsonData, _ = json.Marshal(map[string]any{"name": "Sam Morgan"})
+	req, _ = http.NewRequest("PUT", "/api/v1/instructors/"+instructorID, bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, "Should return status code 200")
+
+	// Get
+	req, _ = http.NewRequest("GET", "/api/v1/instructors/"+instructorID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, "Should return status code 200")
+	response = validation.Response{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err, "Should parse response JSON without error")
+	assert.Equal(t, "Sam Morgan", response.Data.(map[string]any)["name"], "Instructor name should be updated")
+
+	// List
+	req, _ = http.NewRequest("GET", "/api/v1/instructors", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, "Should return status code 200")
+
+	// Delete
+	req, _ = http.NewRequest("DELETE", "/api/v1/instructors/"+instructorID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, "Should return status code 200")
+
+	req, _ = http.NewRequest("GET", "/api/v1/instructors/"+instructorID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code, "Should return status code 404")
+}
+
+func TestAssignInstructorToClass(t *testing.T) {
+	router := setupInstructorTestRouter()
+
+	jsonData, _ := json.Marshal(map[string]any{"name": "Alex Morgan"})
+	req, _ := http.NewRequest("POST", "/api/v1/instructors", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var response validation.Response
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err, "Should parse response JSON without error")
+	instructorID := response.Data.(map[string]any)["id"].(string)
+
+	class := map[string]any{
+		"name":          "Yoga",
+		"start_date":    "2099-04-01",
+		"end_date":      "2099-04-30",
+		"capacity":      10,
+		"schedule":      map[string]any{"weekdays": []string{"monday"}, "start_time": "18:00", "duration_minutes": 60},
+		"instructor_id": instructorID,
+	}
+	jsonData, _ = json.Marshal(class)
+	req, _ = http.NewRequest("POST", "/api/v1/classes", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code, "Should return status code 201")
+	response = validation.Response{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err, "Should parse response JSON without error")
+	assert.Equal(t, instructorID, response.Data.(map[string]any)["instructor_id"], "Class should be assigned to the instructor")
+
+	// The same instructor cannot teach a second class at the same time
+	class["name"] = "Pilates"
+	jsonData, _ = json.Marshal(class)
+	req, _ = http.NewRequest("POST", "/api/v1/classes", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code, "Should return status code 409")
+	response = validation.Response{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err, "Should parse response JSON without error")
+	assert.Equal(t, service.ErrInstructorDoubleBooked.Code, response.Code, "Error code should report the double booking")
+
+	// An assigned instructor cannot be deleted
+	req, _ = http.NewRequest("DELETE", "/api/v1/instructors/"+instructorID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code, "Should return status code 409")
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sanjaykishor/Glofox/internal/middleware"
+	"github.com/sanjaykishor/Glofox/internal/service"
+	"github.com/sanjaykishor/Glofox/internal/validation"
+)
+
+type LocationHandler struct {
+	locationService *service.LocationService
+}
+
+func NewLocationHandler(locationService *service.LocationService) *LocationHandler {
+	return &LocationHandler{
+		locationService: locationService,
+	}
+}
+
+func (h *LocationHandler) RegisterRoutes(router gin.IRouter) {
+	locationsGroup := router.Group("/locations")
+	{
+		locationsGroup.POST("", middleware.Require(middleware.ScopeClassesWrite), h.CreateLocation)
+		locationsGroup.GET("", middleware.Require(middleware.ScopeClassesRead), h.GetAllLocations)
+		locationsGroup.GET("/:id", middleware.Require(middleware.ScopeClassesRead), h.GetLocationByID)
+		locationsGroup.PUT("/:id", middleware.Require(middleware.ScopeClassesWrite), h.ReplaceLocation)
+		locationsGroup.DELETE("/:id", middleware.Require(middleware.ScopeClassesWrite), h.DeleteLocation)
+	}
+}
+
+// CreateLocation registers a new location
+func (h *LocationHandler) CreateLocation(c *gin.Context) {
+	var request service.LocationRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		validation.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	location, err := h.locationService.CreateLocation(&request)
+	if err != nil {
+		validation.ServiceErrorResponse(c, err)
+		return
+	}
+
+	validation.SuccessResponse(c, http.StatusCreated, "Location created successfully", location)
+}
+
+// GetAllLocations returns all locations
+func (h *LocationHandler) GetAllLocations(c *gin.Context) {
+	locations, err := h.locationService.GetAllLocations()
+	if err != nil {
+		validation.ServiceErrorResponse(c, err)
+		return
+	}
+
+	validation.SuccessResponse(c, http.StatusOK, "", locations)
+}
+
+// GetLocationByID retrieves a location by its ID
+func (h *LocationHandler) GetLocationByID(c *gin.Context) {
+	location, err := h.locationService.GetLocationByID(c.Param("id"))
+	if err != nil {
+		validation.ServiceErrorResponse(c, err)
+		return
+	}
+
+	validation.SuccessResponse(c, http.StatusOK, "", location)
+}
+
+// ReplaceLocation replaces the details of a location
+func (h *LocationHandler) ReplaceLocation(c *gin.Context) {
+	var request service.LocationRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		validation.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	location, err := h.locationService.ReplaceLocation(c.Param("id"), &request)
+	if err != nil {
+		validation.ServiceErrorResponse(c, err)
+		return
+	}
+
+	validation.SuccessResponse(c, http.StatusOK, "Location updated successfully", location)
+}
+
+// DeleteLocation removes a location
+func (h *LocationHandler) DeleteLocation(c *gin.Context) {
+	if err := h.locationService.DeleteLocation(c.Param("id")); err != nil {
+		validation.ServiceErrorResponse(c, err)
+		return
+	}
+
+	validation.SuccessResponse(c, http.StatusOK, "Location deleted successfully", nil)
+}
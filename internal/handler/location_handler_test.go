@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sanjaykishor/Glofox/internal/middleware"
+	"github.com/sanjaykishor/Glofox/internal/repository"
+	"github.com/sanjaykishor/Glofox/internal/service"
+	"github.com/sanjaykishor/Glofox/internal/validation"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupLocationTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	locationRepo := repository.NewLocationRepository()
+	roomRepo := repository.NewRoomRepository()
+
+	locationHandler := NewLocationHandler(service.NewLocationService(locationRepo, roomRepo))
+	roomHandler := NewRoomHandler(service.NewRoomService(roomRepo, locationRepo, repository.NewClassRepository(), repository.NewOccurrenceRepository()))
+
+	router := gin.New()
+	router.Use(middleware.Authenticate(nil))
+	locationHandler.RegisterRoutes(router.Group("/api/v1"))
+	roomHandler.RegisterRoutes(router.Group("/api/v1"))
+
+	return router
+}
+
+func TestLocationCRUD(t *testing.T) {
+	router := setupLocationTestRouter()
+
+	jsonData, _ := json.Marshal(map[string]any{"name": "City Centre", "address": "1 Main Street"})
+	req, _ := http.NewRequest("POST", "/api/v1/locations", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code, "Should return status code 201")
+
+	var response validation.Response
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err, "Should parse response JSON without error")
+	assert.True(t, response.Success, "Response success should be true")
+	location := response.Data.(map[string]any)
+	assert.Equal(t, "City Centre", location["name"], "Location name should match request")
+	assert.Equal(t, "1 Main Street", location["address"], "Location address should match request")
+	locationID := location["id"].(string)
+
+	// Missing name
+	jsonData, _ = json.Marshal(map[string]any{"address": "1 Main Street"})
+	req, _ = http.NewRequest("POST", "/api/v1/locations", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code, "Should return status code 400")
+
+	// List
+	req, _ = http.NewRequest("GET", "/api/v1/locations", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, "Should return status code 200")
+	response = validation.Response{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err, "Should parse response JSON without error")
+	assert.Len(t, response.Data, 1, "Should return 1 location")
+
+	// Update
+	jsonData, _ = json.Marshal(map[string]any{"name": "Docklands"})
+	req, _ = http.NewRequest("PUT", "/api/v1/locations/"+locationID, bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, "Should return status code 200")
+
+	// Get
+	req, _ = http.NewRequest("GET", "/api/v1/locations/"+locationID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, "Should return status code 200")
+	response = validation.Response{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err, "Should parse response JSON without error")
+	location = response.Data.(map[string]any)
+	assert.Equal(t, "Docklands", location["name"], "Location name should be updated")
+	assert.Empty(t, location["address"], "Replacing a location should clear omitted details")
+
+	// Update with a missing name
+	jsonData, _ = json.Marshal(map[string]any{"address": "2 Dock Road"})
+	req, _ = http.NewRequest("PUT", "/api/v1/locations/"+locationID, bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code, "Should return status code 400")
+
+	// Delete
+	req, _ = http.NewRequest("DELETE", "/api/v1/locations/"+locationID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, "Should return status code 200")
+
+	req, _ = http.NewRequest("GET", "/api/v1/locations/"+locationID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code, "Deleted location should return status code 404")
+}
+
+func TestLocationNotFound(t *testing.T) {
+	router := setupLocationTestRouter()
+
+	jsonData, _ := json.Marshal(map[string]any{"name": "Nowhere"})
+	requests := []struct {
+		method string
+		body   []byte
+	}{
+		{"GET", nil},
+		{"PUT", jsonData},
+		{"DELETE", nil},
+	}
+
+	for _, request := range requests {
+		req, _ := http.NewRequest(request.method, "/api/v1/locations/non-existent-location", bytes.NewBuffer(request.body))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code, "%s should return status code 404", request.method)
+
+		var response validation.Response
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err, "Should parse response JSON without error")
+		assert.Equal(t, repository.ErrLocationNotFound.Code, response.Code, "%s should report the location was not found", request.method)
+	}
+}
+
+func TestDeleteLocationWithRooms(t *testing.T) {
+	router := setupLocationTestRouter()
+
+	locationID := createTestRecord(t, router, "/api/v1/locations", map[string]any{"name": "City Centre"})
+	roomID := createTestRecord(t, router, "/api/v1/rooms", map[string]any{"location_id": locationID, "name": "Studio A", "capacity": 20})
+
+	req, _ := http.NewRequest("DELETE", "/api/v1/locations/"+locationID, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code, "Should return status code 409")
+	var response validation.Response
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err, "Should parse response JSON without error")
+	assert.Equal(t, service.ErrLocationHasRooms.Code, response.Code, "Error code should report the location's rooms")
+
+	req, _ = http.NewRequest("GET", "/api/v1/locations/"+locationID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, "Location should still exist after a refused delete")
+
+	// Once its rooms are gone the location can be deleted
+	req, _ = http.NewRequest("DELETE", "/api/v1/rooms/"+roomID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, "Should return status code 200")
+
+	req, _ = http.NewRequest("DELETE", "/api/v1/locations/"+locationID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, "Should return status code 200")
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sanjaykishor/Glofox/internal/middleware"
+	"github.com/sanjaykishor/Glofox/internal/service"
+	"github.com/sanjaykishor/Glofox/internal/validation"
+)
+
+type MemberHandler struct {
+	memberService *service.MemberService
+}
+
+func NewMemberHandler(memberService *service.MemberService) *MemberHandler {
+	return &MemberHandler{
+		memberService: memberService,
+	}
+}
+
+func (h *MemberHandler) RegisterRoutes(router gin.IRouter) {
+	membersGroup := router.Group("/members")
+	{
+		membersGroup.POST("", middleware.Require(middleware.ScopeMembersWrite), h.CreateMember)
+		membersGroup.GET("", middleware.Require(middleware.ScopeMembersRead), h.GetAllMembers)
+		membersGroup.GET("/:id", middleware.Require(middleware.ScopeMembersRead), h.GetMemberByID)
+		membersGroup.PUT("/:id", middleware.Require(middleware.ScopeMembersWrite), h.ReplaceMember)
+		membersGroup.DELETE("/:id", middleware.Require(middleware.ScopeMembersWrite), h.DeleteMember)
+	}
+}
+
+// CreateMember registers a new member
+func (h *MemberHandler) CreateMember(c *gin.Context) {
+	var request service.MemberRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		validation.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	member, err := h.memberService.CreateMember(&request)
+	if err != nil {
+		validation.ServiceErrorResponse(c, err)
+		return
+	}
+
+	validation.SuccessResponse(c, http.StatusCreated, "Member created successfully", member)
+}
+
+// GetAllMembers returns all members
+func (h *MemberHandler) GetAllMembers(c *gin.Context) {
+	members, err := h.memberService.GetAllMembers()
+	if err != nil {
+		validation.ServiceErrorResponse(c, err)
+		return
+	}
+
+	validation.SuccessResponse(c, http.StatusOK, "", members)
+}
+
+// GetMemberByID retrieves a member by its ID
+func (h *MemberHandler) GetMemberByID(c *gin.Context) {
+	member, err := h.memberService.GetMemberByID(c.Param("id"))
+	if err != nil {
+		validation.ServiceErrorResponse(c, err)
+		return
+	}
+
+	validation.SuccessResponse(c, http.StatusOK, "", member)
+}
+
+// ReplaceMember replaces the details of a member
+func (h *MemberHandler) ReplaceMember(c *gin.Context) {
+	var request service.MemberRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		validation.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	member, err := h.memberService.ReplaceMember(c.Param("id"), &request)
+	if err != nil {
+		validation.ServiceErrorResponse(c, err)
+		return
+	}
+
+	validation.SuccessResponse(c, http.StatusOK, "Member updated successfully", member)
+}
+
+// DeleteMember removes a member
+func (h *MemberHandler) DeleteMember(c *gin.Context) {
+	if err := h.memberService.DeleteMember(c.Param("id")); err != nil {
+		validation.ServiceErrorResponse(c, err)
+		return
+	}
+
+	validation.SuccessResponse(c, http.StatusOK, "Member deleted successfully", nil)
+}
@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sanjaykishor/Glofox/internal/middleware"
+	"github.com/sanjaykishor/Glofox/internal/repository"
+	"github.com/sanjaykishor/Glofox/internal/service"
+	"github.com/sanjaykishor/Glofox/internal/validation"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupMemberTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	bookingRepo := repository.NewBookingRepository()
+	classRepo := repository.NewClassRepository()
+	memberRepo := repository.NewMemberRepository()
+
+	class := &repository.Class{
+		ID:        "test-class-1",
+		Name:      "Yoga",
+		StartDate: testToday,
+		EndDate:   testToday.AddDate(0, 0, 2),
+		Capacity:  20,
+	}
+	classRepo.Create(class)
+
+	bookingService := service.NewBookingService(bookingRepo, classRepo, repository.NewWaitlistRepository(), memberRepo, repository.NewOccurrenceRepository())
+	bookingService.SetClock(func() time.Time { return testNow })
+
+	memberHandler := NewMemberHandler(service.NewMemberService(memberRepo))
+	bookingHandler := NewBookingHandler(bookingService)
+
+	router := gin.New()
+	router.Use(middleware.Authenticate(nil))
+	memberHandler.RegisterRoutes(router.Group("/api/v1"))
+	bookingHandler.RegisterRoutes(router.Group("/api/v1"))
+
+	return router
+}
+
+func TestMemberCRUD(t *testing.T) {
+	router := setupMemberTestRouter()
+
+	jsonData, _ := json.Marshal(map[string]any{"name": "Jane Doe", "email": "jane@example.com"})
+	req, _ := http.NewRequest("POST", "/api/v1/members", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code, "Should return status code 201")
+
+	var response validation.Response
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err, "Should parse response JSON without error")
+	assert.True(t, response.Success, "Response success should be true")
+	memberID := response.Data.(map[string]any)["id"].(string)
+
+	// Invalid email
+	jsonData, _ = json.Marshal(map[string]any{"name": "Jane Doe", "email": "not-an-email"})
+	req, _ = http.NewRequest("POST", "/api/v1/members", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code, "Should return status code 400")
+
+	// Update
+	jsonData, _ = json.Marshal(map[string]any{"name": "Jane Smith"})
+	req, _ = http.NewRequest("PUT", "/api/v1/members/"+memberID, bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, "Should return status code 200")
+
+	// Get
+	req, _ = http.NewRequest("GET", "/api/v1/members/"+memberID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, "Should return status code 200")
+	response = validation.Response{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err, "Should parse response JSON without error")
+	assert.Equal(t, "Jane Smith", response.Data.(map[string]any)["name"], "Member name should be updated")
+
+	// List
+	req, _ = http.NewRequest("GET", "/api/v1/members", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, "Should return status code 200")
+
+	// Delete
+	req, _ = http.NewRequest("DELETE", "/api/v1/members/"+memberID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, "Should return status code 200")
+
+	req, _ = http.NewRequest("GET", "/api/v1/members/"+memberID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code, "Should return status code 404")
+}
+
+func TestCreateBookingForMember(t *testing.T) {
+	router := setupMemberTestRouter()
+
+	jsonData, _ := json.Marshal(map[string]any{"name": "Jane Doe"})
+	req, _ := http.NewRequest("POST", "/api/v1/members", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var response validation.Response
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err, "Should parse response JSON without error")
+	memberID := response.Data.(map[string]any)["id"].(string)
+
+	jsonData, _ = json.Marshal(map[string]any{
+		"member_id": memberID,
+		"date":      testDate,
+		"class_id":  "test-class-1",
+	})
+	req, _ = http.NewRequest("POST", "/api/v1/bookings", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code, "Should return status code 201")
+
+	response = validation.Response{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err, "Should parse response JSON without error")
+	data := response.Data.(map[string]any)
+	assert.Equal(t, memberID, data["member_id"], "Booking should reference the member")
+	assert.Equal(t, "Jane Doe", data["member_name"], "Booking should use the member's name")
+
+	// Unknown member
+	jsonData, _ = json.Marshal(map[string]any{
+		"member_id": "non-existent-member",
+		"date":      testDate,
+		"class_id":  "test-class-1",
+	})
+	req, _ = http.NewRequest("POST", "/api/v1/bookings", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code, "Should return status code 404")
+
+	// Neither a member nor a name
+	jsonData, _ = json.Marshal(map[string]any{"date": testDate})
+	req, _ = http.NewRequest("POST", "/api/v1/bookings", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code, "Should return status code 400")
+
+	response = validation.Response{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err, "Should parse response JSON without error")
+	assert.Equal(t, "name or member_id is required", response.Error, "Error message should ask for a member or a name")
+}
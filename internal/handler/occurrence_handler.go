@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sanjaykishor/Glofox/internal/middleware"
+	"github.com/sanjaykishor/Glofox/internal/service"
+	"github.com/sanjaykishor/Glofox/internal/validation"
+)
+
+type OccurrenceHandler struct {
+	occurrenceService *service.OccurrenceService
+}
+
+func NewOccurrenceHandler(occurrenceService *service.OccurrenceService) *OccurrenceHandler {
+	return &OccurrenceHandler{
+		occurrenceService: occurrenceService,
+	}
+}
+
+func (h *OccurrenceHandler) RegisterRoutes(router gin.IRouter) {
+	occurrencesGroup := router.Group("/classes/:id/occurrences")
+	{
+		occurrencesGroup.GET("", middleware.Require(middleware.ScopeClassesRead), h.GetOccurrences)
+		occurrencesGroup.GET("/:date", middleware.Require(middleware.ScopeClassesRead), h.GetOccurrence)
+		occurrencesGroup.PATCH("/:date", middleware.Require(middleware.ScopeClassesWrite), h.UpdateOccurrence)
+		occurrencesGroup.DELETE("/:date", middleware.Require(middleware.ScopeClassesWrite), h.CancelOccurrence)
+	}
+}
+
+// GetOccurrences returns a page of the occurrences of a class
+func (h *OccurrenceHandler) GetOccurrences(c *gin.Context) {
+	var request service.ListOccurrencesRequest
+
+	if err := c.ShouldBindQuery(&request); err != nil {
+		validation.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	page, err := h.occurrenceService.ListOccurrences(c.Param("id"), &request)
+	if err != nil {
+		validation.ServiceErrorResponse(c, err)
+		return
+	}
+
+	validation.PageResponse(c, page.Occurrences, page.PageInfo)
+}
+
+// GetOccurrence retrieves the occurrence of a class on a date
+func (h *OccurrenceHandler) GetOccurrence(c *gin.Context) {
+	occurrence, err := h.occurrenceService.GetOccurrence(c.Param("id"), c.Param("date"))
+	if err != nil {
+		validation.ServiceErrorResponse(c, err)
+		return
+	}
+
+	validation.SuccessResponse(c, http.StatusOK, "", occurrence)
+}
+
+// UpdateOccurrence changes the capacity or time of a single occurrence
+func (h *OccurrenceHandler) UpdateOccurrence(c *gin.Context) {
+	var request service.UpdateOccurrenceRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		validation.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	occurrence, err := h.occurrenceService.UpdateOccurrence(c.Request.Context(), c.Param("id"), c.Param("date"), &request)
+	if err != nil {
+		validation.ServiceErrorResponse(c, err)
+		return
+	}
+
+	validation.SuccessResponse(c, http.StatusOK, "Occurrence updated successfully", occurrence)
+}
+
+// CancelOccurrence cancels a single occurrence together with its bookings
+func (h *OccurrenceHandler) CancelOccurrence(c *gin.Context) {
+	var request service.CancelOccurrenceRequest
+
+	if c.Request.Body != nil && c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+			validation.ErrorResponse(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	occurrence, err := h.occurrenceService.CancelOccurrence(c.Param("id"), c.Param("date"), &request)
+	if err != nil {
+		validation.ServiceErrorResponse(c, err)
+		return
+	}
+
+	validation.SuccessResponse(c, http.StatusOK, "Occurrence cancelled successfully", occurrence)
+}
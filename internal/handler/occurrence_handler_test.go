@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sanjaykishor/Glofox/internal/middleware"
+	"github.com/sanjaykishor/Glofox/internal/repository"
+	"github.com/sanjaykishor/Glofox/internal/schedule"
+	"github.com/sanjaykishor/Glofox/internal/service"
+	"github.com/sanjaykishor/Glofox/internal/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupOccurrenceTestRouter serves a class that meets on Mondays and
+// Wednesdays for the next four weeks
+func setupOccurrenceTestRouter() (*gin.Engine, *repository.Class) {
+	gin.SetMode(gin.TestMode)
+
+	classRepo := repository.NewClassRepository()
+	bookingRepo := repository.NewBookingRepository()
+	waitlistRepo := repository.NewWaitlistRepository()
+	occurrenceRepo := repository.NewOccurrenceRepository()
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	class := &repository.Class{
+		ID:        "test-class-1",
+		Name:      "Yoga",
+		StartDate: today,
+		EndDate:   today.AddDate(0, 0, 27),
+		Capacity:  10,
+		Schedule:  &schedule.Recurrence{Weekdays: []string{"monday", "wednesday"}},
+	}
+	classRepo.Create(class)
+
+	occurrenceService := service.NewOccurrenceService(occurrenceRepo, classRepo, bookingRepo, waitlistRepo, repository.NewInstructorRepository(), repository.NewRoomRepository())
+	occurrenceHandler := NewOccurrenceHandler(occurrenceService)
+
+	router := gin.New()
+	router.Use(middleware.Authenticate(nil))
+	occurrenceHandler.RegisterRoutes(router.Group("/api/v1"))
+
+	return router, class
+}
+
+func TestGetOccurrences(t *testing.T) {
+	router, class := setupOccurrenceTestRouter()
+
+	req, _ := http.NewRequest("GET", "/api/v1/classes/test-class-1/occurrences?limit=3", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, "Should return status code 200")
+
+	var response struct {
+		validation.Response
+		Data []repository.ClassOccurrence `json:"data"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err, "Should parse response JSON without error")
+	require.Len(t, response.Data, 3, "Should return one page of occurrences")
+	for _, occurrence := range response.Data {
+		assert.True(t, class.OccursOn(occurrence.Date), "Should only return scheduled days")
+		assert.Equal(t, 10, occurrence.Capacity, "Occurrence should inherit the class capacity")
+	}
+	require.NotNil(t, response.Pagination, "Response should include pagination")
+	assert.True(t, response.Pagination.HasMore, "Four weeks of classes should not fit on one page")
+
+	req, _ = http.NewRequest("GET", "/api/v1/classes/unknown-class/occurrences", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code, "Should return status code 404 for an unknown class")
+}
+
+func TestUpdateAndCancelOccurrence(t *testing.T) {
+	router, class := setupOccurrenceTestRouter()
+
+	date := class.Occurrences(class.StartDate, class.EndDate, time.UTC)[1].Date.Format("2006-01-02")
+	url := "/api/v1/classes/test-class-1/occurrences/" + date
+
+	jsonData, _ := json.Marshal(map[string]interface{}{"capacity": 4})
+	req, _ := http.NewRequest("PATCH", url, bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, "Should return status code 200")
+
+	var response struct {
+		validation.Response
+		Data repository.ClassOccurrence `json:"data"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err, "Should parse response JSON without error")
+	assert.Equal(t, 4, response.Data.Capacity, "Occurrence capacity should be updated")
+	assert.True(t, response.Data.Modified, "Occurrence should be marked as modified")
+
+	jsonData, _ = json.Marshal(map[string]interface{}{"reason": "studio closed"})
+	req, _ = http.NewRequest("DELETE", url, bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, "Should return status code 200")
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err, "Should parse response JSON without error")
+	assert.Equal(t, repository.OccurrenceStatusCancelled, response.Data.Status, "Occurrence should be cancelled")
+	assert.Equal(t, "studio closed", response.Data.CancellationReason, "Cancellation reason should be returned")
+
+	req, _ = http.NewRequest("GET", url, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err, "Should parse response JSON without error")
+	assert.Equal(t, repository.OccurrenceStatusCancelled, response.Data.Status, "Cancellation should be stored")
+
+	req, _ = http.NewRequest("DELETE", url, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code, "Should return status code 409 when already cancelled")
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err, "Should parse response JSON without error")
+	assert.Equal(t, service.ErrOccurrenceCancelled.Code, response.Code, "Error code should report the cancelled occurrence")
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sanjaykishor/Glofox/internal/middleware"
+	"github.com/sanjaykishor/Glofox/internal/service"
+	"github.com/sanjaykishor/Glofox/internal/validation"
+)
+
+type RoomHandler struct {
+	roomService *service.RoomService
+}
+
+func NewRoomHandler(roomService *service.RoomService) *RoomHandler {
+	return &RoomHandler{
+		roomService: roomService,
+	}
+}
+
+func (h *RoomHandler) RegisterRoutes(router gin.IRouter) {
+	roomsGroup := router.Group("/rooms")
+	{
+		roomsGroup.POST("", middleware.Require(middleware.ScopeClassesWrite), h.CreateRoom)
+		roomsGroup.GET("", middleware.Require(middleware.ScopeClassesRead), h.GetAllRooms)
+		roomsGroup.GET("/:id", middleware.Require(middleware.ScopeClassesRead), h.GetRoomByID)
+		roomsGroup.PUT("/:id", middleware.Require(middleware.ScopeClassesWrite), h.ReplaceRoom)
+		roomsGroup.DELETE("/:id", middleware.Require(middleware.ScopeClassesWrite), h.DeleteRoom)
+	}
+}
+
+// CreateRoom registers a new room
+func (h *RoomHandler) CreateRoom(c *gin.Context) {
+	var request service.RoomRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		validation.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	room, err := h.roomService.CreateRoom(&request)
+	if err != nil {
+		validation.ServiceErrorResponse(c, err)
+		return
+	}
+
+	validation.SuccessResponse(c, http.StatusCreated, "Room created successfully", room)
+}
+
+// GetAllRooms returns all rooms, optionally only those at the location
+// given by the location_id query parameter
+func (h *RoomHandler) GetAllRooms(c *gin.Context) {
+	rooms, err := h.roomService.GetAllRooms(c.Query("location_id"))
+	if err != nil {
+		validation.ServiceErrorResponse(c, err)
+		return
+	}
+
+	validation.SuccessResponse(c, http.StatusOK, "", rooms)
+}
+
+// GetRoomByID retrieves a room by its ID
+func (h *RoomHandler) GetRoomByID(c *gin.Context) {
+	room, err := h.roomService.GetRoomByID(c.Param("id"))
+	if err != nil {
+		validation.ServiceErrorResponse(c, err)
+		return
+	}
+
+	validation.SuccessResponse(c, http.StatusOK, "", room)
+}
+
+// ReplaceRoom replaces the details of a room
+func (h *RoomHandler) ReplaceRoom(c *gin.Context) {
+	var request service.RoomRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		validation.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	room, err := h.roomService.ReplaceRoom(c.Param("id"), &request)
+	if err != nil {
+		validation.ServiceErrorResponse(c, err)
+		return
+	}
+
+	validation.SuccessResponse(c, http.StatusOK, "Room updated successfully", room)
+}
+
+// DeleteRoom removes a room
+func (h *RoomHandler) DeleteRoom(c *gin.Context) {
+	if err := h.roomService.DeleteRoom(c.Param("id")); err != nil {
+		validation.ServiceErrorResponse(c, err)
+		return
+	}
+
+	validation.SuccessResponse(c, http.StatusOK, "Room deleted successfully", nil)
+}
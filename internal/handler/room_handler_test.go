@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sanjaykishor/Glofox/internal/middleware"
+	"github.com/sanjaykishor/Glofox/internal/repository"
+	"github.com/sanjaykishor/Glofox/internal/service"
+	"github.com/sanjaykishor/Glofox/internal/validation"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupRoomTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	classRepo := repository.NewClassRepository()
+	occurrenceRepo := repository.NewOccurrenceRepository()
+	locationRepo := repository.NewLocationRepository()
+	roomRepo := repository.NewRoomRepository()
+
+	locationHandler := NewLocationHandler(service.NewLocationService(locationRepo, roomRepo))
+	roomHandler := NewRoomHandler(service.NewRoomService(roomRepo, locationRepo, classRepo, occurrenceRepo))
+	classHandler := NewClassHandler(service.NewClassService(classRepo, repository.NewBookingRepository(), repository.NewWaitlistRepository(), occurrenceRepo, repository.NewInstructorRepository(), roomRepo))
+
+	router := gin.New()
+	router.Use(middleware.Authenticate(nil))
+	locationHandler.RegisterRoutes(router.Group("/api/v1"))
+	roomHandler.RegisterRoutes(router.Group("/api/v1"))
+	classHandler.RegisterRoutes(router.Group("/api/v1"))
+
+	return router
+}
+
+// createTestRecord posts body to path and returns the ID of the created record
+func createTestRecord(t *testing.T, router *gin.Engine, path string, body map[string]any) string {
+	jsonData, _ := json.Marshal(body)
+	req, _ := http.NewRequest("POST", path, bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code, "Should return status code 201")
+
+	var response validation.Response
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err, "Should parse response JSON without error")
+	return response.Data.(map[string]any)["id"].(string)
+}
+
+func TestLocationAndRoomCRUD(t *testing.T) {
+	router := setupRoomTestRouter()
+
+	locationID := createTestRecord(t, router, "/api/v1/locations", map[string]any{"name": "City Centre", "address": "1 Main Street"})
+	roomID := createTestRecord(t, router, "/api/v1/rooms", map[string]any{"location_id": locationID, "name": "Studio A", "capacity": 20})
+
+	// Unknown location
+	jsonData, _ := json.Marshal(map[string]any{"location_id": "non-existent-location", "name": "Studio B", "capacity": 10})
+	req, _ := http.NewRequest("POST", "/api/v1/rooms", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code, "Should return status code 404")
+
+	// Missing capacity
+	jsonData, _ = json.Marshal(map[string]any{"location_id": locationID, "name": "Studio B"})
+	req, _ = http.NewRequest("POST", "/api/v1/rooms", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code, "Should return status code 400")
+
+	// List the rooms at the location
+	req, _ = http.NewRequest("GET", "/api/v1/rooms?location_id="+locationID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, "Should return status code 200")
+	var response validation.Response
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err, "Should parse response JSON without error")
+	assert.Len(t, response.Data, 1, "Should return the room at the location")
+
+	// Delete
+	req, _ = http.NewRequest("DELETE", "/api/v1/rooms/"+roomID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, "Should return status code 200")
+
+	req, _ = http.NewRequest("GET", "/api/v1/rooms/"+roomID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code, "Deleted room should return status code 404")
+}
+
+func TestAssignRoomToClass(t *testing.T) {
+	router := setupRoomTestRouter()
+
+	locationID := createTestRecord(t, router, "/api/v1/locations", map[string]any{"name": "City Centre"})
+	roomID := createTestRecord(t, router, "/api/v1/rooms", map[string]any{"location_id": locationID, "name": "Studio A", "capacity": 20})
+
+	class := map[string]any{
+		"name":       "Yoga",
+		"start_date": "2099-04-01",
+		"end_date":   "2099-04-30",
+		"capacity":   25,
+		"schedule":   map[string]any{"weekdays": []string{"monday"}, "start_time": "18:00", "duration_minutes": 60},
+		"room_id":    roomID,
+	}
+
+	// Larger than the room
+	jsonData, _ := json.Marshal(class)
+	req, _ := http.NewRequest("POST", "/api/v1/classes", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code, "Should return status code 400")
+
+	class["capacity"] = 20
+	createTestRecord(t, router, "/api/v1/classes", class)
+
+	// Another class cannot use the room at the same time
+	class["name"] = "Pilates"
+	jsonData, _ = json.Marshal(class)
+	req, _ = http.NewRequest("POST", "/api/v1/classes", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code, "Should return status code 409")
+	var response validation.Response
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err, "Should parse response JSON without error")
+	assert.Equal(t, service.ErrRoomDoubleBooked.Code, response.Code, "Error code should report the room clash")
+
+	// A room in use cannot be shrunk below the class
+	jsonData, _ = json.Marshal(map[string]any{"location_id": locationID, "name": "Studio A", "capacity": 15})
+	req, _ = http.NewRequest("PUT", "/api/v1/rooms/"+roomID, bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code, "Should return status code 409")
+}
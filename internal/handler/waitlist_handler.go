@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sanjaykishor/Glofox/internal/middleware"
+	"github.com/sanjaykishor/Glofox/internal/repository"
+	"github.com/sanjaykishor/Glofox/internal/service"
+	"github.com/sanjaykishor/Glofox/internal/validation"
+)
+
+type WaitlistHandler struct {
+	waitlistService *service.WaitlistService
+}
+
+func NewWaitlistHandler(waitlistService *service.WaitlistService) *WaitlistHandler {
+	return &WaitlistHandler{
+		waitlistService: waitlistService,
+	}
+}
+
+func (h *WaitlistHandler) RegisterRoutes(router gin.IRouter) {
+	waitlistGroup := router.Group("/waitlist")
+	{
+		waitlistGroup.POST("", middleware.Require(middleware.ScopeBookingsCreate), h.JoinWaitlist)
+		waitlistGroup.GET("/:id", middleware.Require(middleware.ScopeBookingsRead), h.GetPosition)
+		waitlistGroup.DELETE("/:id", middleware.Require(middleware.ScopeBookingsCreate), h.LeaveWaitlist)
+	}
+}
+
+// JoinWaitlist adds a member to the waitlist of a full class occurrence.
+// Members can only join for themselves.
+func (h *WaitlistHandler) JoinWaitlist(c *gin.Context) {
+	ownID := ownMemberID(c)
+	request := service.JoinWaitlistRequest{MemberID: ownID}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		validation.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if ownID != "" && request.MemberID != ownID {
+		validation.AbortResponse(c, http.StatusForbidden, validation.CodeForbidden, "members can only join waitlists for themselves")
+		return
+	}
+
+	position, err := h.waitlistService.JoinWaitlist(&request)
+	if err != nil {
+		validation.ServiceErrorResponse(c, err)
+		return
+	}
+
+	if position.BookingID != "" {
+		validation.SuccessResponse(c, http.StatusCreated, "A place became available and the booking was confirmed", position)
+		return
+	}
+
+	validation.SuccessResponse(c, http.StatusCreated, "Joined waitlist successfully", position)
+}
+
+// GetPosition retrieves a waitlist entry and its place in the queue
+func (h *WaitlistHandler) GetPosition(c *gin.Context) {
+	position, err := h.waitlistService.GetPosition(c.Param("id"))
+	if err != nil {
+		validation.ServiceErrorResponse(c, err)
+		return
+	}
+
+	if ownID := ownMemberID(c); ownID != "" && position.MemberID != ownID {
+		validation.ServiceErrorResponse(c, repository.ErrWaitlistEntryNotFound)
+		return
+	}
+
+	validation.SuccessResponse(c, http.StatusOK, "", position)
+}
+
+// LeaveWaitlist removes a member from a waitlist. Members can only remove
+// their own entries.
+func (h *WaitlistHandler) LeaveWaitlist(c *gin.Context) {
+	if ownID := ownMemberID(c); ownID != "" {
+		position, err := h.waitlistService.GetPosition(c.Param("id"))
+		if err != nil {
+			validation.ServiceErrorResponse(c, err)
+			return
+		}
+
+		if position.MemberID != ownID {
+			validation.ServiceErrorResponse(c, repository.ErrWaitlistEntryNotFound)
+			return
+		}
+	}
+
+	if err := h.waitlistService.LeaveWaitlist(c.Param("id")); err != nil {
+		validation.ServiceErrorResponse(c, err)
+		return
+	}
+
+	validation.SuccessResponse(c, http.StatusOK, "Left waitlist successfully", nil)
+}
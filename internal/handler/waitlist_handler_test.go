@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sanjaykishor/Glofox/internal/middleware"
+	"github.com/sanjaykishor/Glofox/internal/repository"
+	"github.com/sanjaykishor/Glofox/internal/service"
+	"github.com/sanjaykishor/Glofox/internal/validation"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupWaitlistTestRouter() (*gin.Engine, *repository.BookingRepository) {
+	gin.SetMode(gin.TestMode)
+
+	bookingRepo := repository.NewBookingRepository()
+	classRepo := repository.NewClassRepository()
+	waitlistRepo := repository.NewWaitlistRepository()
+
+	class := &repository.Class{
+		ID:        "test-class-1",
+		Name:      "Yoga",
+		StartDate: testToday,
+		EndDate:   testToday.AddDate(0, 0, 2),
+		Capacity:  1,
+	}
+	classRepo.Create(class)
+
+	bookingService := service.NewBookingService(bookingRepo, classRepo, waitlistRepo, repository.NewMemberRepository(), repository.NewOccurrenceRepository())
+	bookingService.SetClock(func() time.Time { return testNow })
+	waitlistService := service.NewWaitlistService(waitlistRepo, bookingRepo, classRepo, repository.NewMemberRepository(), repository.NewOccurrenceRepository())
+	waitlistService.SetClock(func() time.Time { return testNow })
+
+	bookingHandler := NewBookingHandler(bookingService)
+	waitlistHandler := NewWaitlistHandler(waitlistService)
+
+	router := gin.New()
+	router.Use(middleware.Authenticate(nil))
+	bookingHandler.RegisterRoutes(router.Group("/api/v1"))
+	waitlistHandler.RegisterRoutes(router.Group("/api/v1"))
+
+	return router, bookingRepo
+}
+
+func TestWaitlist(t *testing.T) {
+	router, bookingRepo := setupWaitlistTestRouter()
+
+	booking := &repository.Booking{
+		ID:         "test-booking-1",
+		MemberName: "John Doe",
+		ClassID:    "test-class-1",
+		Date:       testToday,
+		Status:     repository.BookingStatusConfirmed,
+		CreatedAt:  testNow,
+	}
+	bookingRepo.Create(booking)
+
+	jsonData, _ := json.Marshal(map[string]any{
+		"name":     "Jane Smith",
+		"date":     testDate,
+		"class_id": "test-class-1",
+	})
+	req, _ := http.NewRequest("POST", "/api/v1/waitlist", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code, "Should return status code 201")
+
+	var response validation.Response
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err, "Should parse response JSON without error")
+	assert.True(t, response.Success, "Response success should be true")
+
+	data := response.Data.(map[string]any)
+	assert.Equal(t, float64(1), data["position"], "Member should be first on the waitlist")
+	entryID := data["id"].(string)
+
+	// Joining twice is a conflict
+	req, _ = http.NewRequest("POST", "/api/v1/waitlist", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code, "Should return status code 409")
+
+	// Viewing the position
+	req, _ = http.NewRequest("GET", "/api/v1/waitlist/"+entryID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, "Should return status code 200")
+
+	// Cancelling the booking promotes the member
+	req, _ = http.NewRequest("DELETE", "/api/v1/bookings/test-booking-1", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, "Should return status code 200")
+
+	req, _ = http.NewRequest("GET", "/api/v1/waitlist/"+entryID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code, "Promoted entry should leave the waitlist")
+
+	bookings, _ := bookingRepo.GetBookingsByDate(testToday)
+	var names []string
+	for _, b := range bookings {
+		if b.Active() {
+			names = append(names, b.MemberName)
+		}
+	}
+	assert.Equal(t, []string{"Jane Smith"}, names, "Waitlisted member should hold the freed place")
+
+	// Leaving a waitlist the member is no longer on
+	req, _ = http.NewRequest("DELETE", "/api/v1/waitlist/"+entryID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code, "Should return status code 404")
+}
+
+func TestJoinWaitlistClassNotFull(t *testing.T) {
+	router, _ := setupWaitlistTestRouter()
+
+	jsonData, _ := json.Marshal(map[string]any{
+		"name":     "Jane Smith",
+		"date":     testDate,
+		"class_id": "test-class-1",
+	})
+	req, _ := http.NewRequest("POST", "/api/v1/waitlist", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code, "Should return status code 409")
+
+	var response validation.Response
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err, "Should parse response JSON without error")
+	assert.Equal(t, service.ErrClassNotFull.Error(), response.Error, "Error message should send the member to book directly")
+
+	// Missing class
+	jsonData, _ = json.Marshal(map[string]any{"name": "Jane Smith", "date": testDate})
+	req, _ = http.NewRequest("POST", "/api/v1/waitlist", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code, "Should return status code 400")
+}
+
+func TestMemberLeaveWaitlist(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	waitlistRepo := repository.NewWaitlistRepository()
+	date := testToday.AddDate(0, 0, 1)
+	waitlistRepo.Create(&repository.WaitlistEntry{ID: "entry-1", MemberID: "member-1", MemberName: "John Doe", ClassID: "test-class-1", Date: date, CreatedAt: testNow})
+	waitlistRepo.Create(&repository.WaitlistEntry{ID: "entry-2", MemberID: "member-2", MemberName: "Jane Doe", ClassID: "test-class-1", Date: date, CreatedAt: testNow})
+
+	waitlistService := service.NewWaitlistService(waitlistRepo, repository.NewBookingRepository(), repository.NewClassRepository(), repository.NewMemberRepository(), repository.NewOccurrenceRepository())
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		middleware.SetPrincipal(c, &middleware.Principal{
+			Subject:  "member-1",
+			Role:     middleware.RoleMember,
+			MemberID: "member-1",
+			Scopes:   []string{middleware.ScopeClassesRead, middleware.ScopeBookingsRead, middleware.ScopeBookingsCreate},
+		})
+	})
+	NewWaitlistHandler(waitlistService).RegisterRoutes(router.Group("/api/v1"))
+
+	leave := func(id string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("DELETE", "/api/v1/waitlist/"+id, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := leave("entry-2")
+	assert.Equal(t, http.StatusNotFound, w.Code, "Should not let a member remove someone else's entry")
+	_, err := waitlistRepo.GetByID("entry-2")
+	assert.NoError(t, err, "Should keep the other member's entry")
+
+	w = leave("entry-1")
+	assert.Equal(t, http.StatusOK, w.Code, "Should let a member leave their own waitlist")
+	_, err = waitlistRepo.GetByID("entry-1")
+	assert.ErrorIs(t, err, repository.ErrWaitlistEntryNotFound, "Should remove the member's entry")
+
+	w = leave("entry-1")
+	assert.Equal(t, http.StatusNotFound, w.Code, "Should return 404 once the entry is gone")
+}
@@ -0,0 +1,65 @@
+// Package logging builds the application's structured logger and carries
+// the ID of the request being served through contexts.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	"github.com/sanjaykishor/Glofox/internal/config"
+)
+
+// requestIDKey is the context key the request ID is stored under
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the ID of the request it
+// belongs to
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the ID of the request ctx belongs to, or an empty
+// string outside a request
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// New returns a logger writing to w in the configured format. Lines logged
+// with a request's context carry its ID as request_id.
+func New(cfg config.LogConfig, w io.Writer) *slog.Logger {
+	options := &slog.HandlerOptions{Level: cfg.Level}
+
+	var handler slog.Handler
+	if cfg.Format == config.LogFormatText {
+		handler = slog.NewTextHandler(w, options)
+	} else {
+		handler = slog.NewJSONHandler(w, options)
+	}
+
+	return slog.New(&requestIDHandler{Handler: handler})
+}
+
+// requestIDHandler adds the request ID found in the context to each record
+type requestIDHandler struct {
+	slog.Handler
+}
+
+// Handle adds the request ID, if any, and passes the record on
+func (h *requestIDHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := RequestID(ctx); requestID != "" {
+		record.AddAttrs(slog.String("request_id", requestID))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+// WithAttrs returns a handler that also adds the request ID
+func (h *requestIDHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &requestIDHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup returns a handler that also adds the request ID
+func (h *requestIDHandler) WithGroup(name string) slog.Handler {
+	return &requestIDHandler{Handler: h.Handler.WithGroup(name)}
+}
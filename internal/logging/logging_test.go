@@ -0,0 +1,61 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/sanjaykishor/Glofox/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("JSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := New(config.LogConfig{Format: config.LogFormatJSON}, &buf)
+
+		ctx := WithRequestID(context.Background(), "req-1")
+		logger.InfoContext(ctx, "Booked class", "class_id", "class-1")
+
+		var line map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &line), "Should write a JSON line without error")
+		assert.Equal(t, "Booked class", line["msg"])
+		assert.Equal(t, "INFO", line["level"])
+		assert.Equal(t, "class-1", line["class_id"])
+		assert.Equal(t, "req-1", line["request_id"], "Should add the request ID from the context")
+	})
+
+	t.Run("Text", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := New(config.LogConfig{Format: config.LogFormatText}, &buf).With("component", "test")
+
+		logger.InfoContext(WithRequestID(context.Background(), "req-2"), "Booked class")
+
+		assert.Contains(t, buf.String(), "msg=\"Booked class\"")
+		assert.Contains(t, buf.String(), "component=test")
+		assert.Contains(t, buf.String(), "request_id=req-2", "Should add the request ID to derived loggers")
+	})
+
+	t.Run("Without Request", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := New(config.LogConfig{Format: config.LogFormatText}, &buf)
+
+		logger.Info("Server starting")
+
+		assert.NotContains(t, buf.String(), "request_id", "Should not add a request ID outside a request")
+	})
+
+	t.Run("Level", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := New(config.LogConfig{Format: config.LogFormatJSON, Level: slog.LevelWarn}, &buf)
+
+		logger.Info("Ignored")
+		assert.Empty(t, buf.String(), "Should drop lines below the configured level")
+
+		logger.Warn("Kept")
+		assert.Contains(t, buf.String(), "Kept", "Should write lines at the configured level")
+	})
+}
@@ -0,0 +1,301 @@
+package middleware
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sanjaykishor/Glofox/internal/config"
+	"github.com/sanjaykishor/Glofox/internal/service"
+	"github.com/sanjaykishor/Glofox/internal/validation"
+)
+
+// Role is the part a principal plays at the studio
+type Role string
+
+// Roles carried in the role claim of a token
+const (
+	RoleOwner  Role = "owner"
+	RoleStaff  Role = "staff"
+	RoleMember Role = "member"
+	// RoleIntegration is held by API keys and cannot be claimed by a token
+	RoleIntegration Role = "integration"
+)
+
+// Scopes name the groups of operations a principal may perform
+const (
+	// ScopeClassesRead covers viewing classes, occurrences, instructors, locations and rooms
+	ScopeClassesRead = "classes:read"
+	// ScopeClassesWrite covers changing classes, occurrences, instructors, locations and rooms
+	ScopeClassesWrite = "classes:write"
+	// ScopeMembersRead covers viewing members
+	ScopeMembersRead = "members:read"
+	// ScopeMembersWrite covers registering, changing and removing members
+	ScopeMembersWrite = "members:write"
+	// ScopeBookingsRead covers viewing bookings and waitlist entries
+	ScopeBookingsRead = "bookings:read"
+	// ScopeBookingsCreate covers making bookings and joining and leaving
+	// waitlists
+	ScopeBookingsCreate = "bookings:create"
+	// ScopeBookingsManage covers cancelling and rescheduling bookings
+	ScopeBookingsManage = "bookings:manage"
+	// ScopeAPIKeysManage covers creating, viewing and revoking API keys
+	ScopeAPIKeysManage = "api_keys:manage"
+)
+
+// staffScopes are granted to the people running the studio
+var staffScopes = []string{
+	ScopeClassesRead, ScopeClassesWrite,
+	ScopeMembersRead, ScopeMembersWrite,
+	ScopeBookingsRead, ScopeBookingsCreate, ScopeBookingsManage,
+}
+
+// ownerScopes add managing API keys to the staff scopes
+var ownerScopes = slices.Concat(staffScopes, []string{ScopeAPIKeysManage})
+
+// IntegrationScopes are the scopes an API key may be granted. Keys can
+// never manage other keys.
+var IntegrationScopes = slices.Clone(staffScopes)
+
+// roleScopes maps every role a token may claim to the scopes it grants.
+// Members only ever act on their own bookings, which handlers enforce on
+// top of these.
+var roleScopes = map[Role][]string{
+	RoleOwner:  ownerScopes,
+	RoleStaff:  staffScopes,
+	RoleMember: {ScopeClassesRead, ScopeBookingsRead, ScopeBookingsCreate},
+}
+
+// Principal is the authenticated caller of a request. MemberID is set for
+// members and names the member whose bookings they may see and make.
+type Principal struct {
+	Subject  string
+	Role     Role
+	MemberID string
+	Scopes   []string
+}
+
+// HasScope reports whether the principal may perform operations in scope
+func (p *Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, scope)
+}
+
+// IsMember reports whether the principal is limited to its own bookings
+func (p *Principal) IsMember() bool {
+	return p.Role == RoleMember
+}
+
+// anonymousPrincipal acts for every request while authentication is
+// disabled. It is never trusted to manage API keys, as keys would outlive
+// turning authentication on.
+var anonymousPrincipal = &Principal{Subject: "anonymous", Role: RoleStaff, Scopes: staffScopes}
+
+// principalKey is the gin context key the principal is stored under
+const principalKey = "glofox.principal"
+
+// SetPrincipal attaches the authenticated principal to the request
+func SetPrincipal(c *gin.Context, principal *Principal) {
+	c.Set(principalKey, principal)
+}
+
+// CurrentPrincipal returns the principal of the request, or nil if the
+// request has not been authenticated
+func CurrentPrincipal(c *gin.Context) *Principal {
+	if value, exists := c.Get(principalKey); exists {
+		if principal, ok := value.(*Principal); ok {
+			return principal
+		}
+	}
+	return nil
+}
+
+// claims are the token claims read besides the registered ones. A member
+// token names its member in member_id, or failing that in sub.
+type claims struct {
+	Role     Role   `json:"role"`
+	MemberID string `json:"member_id,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Authenticator verifies JWT bearer tokens signed with HS256 or RS256
+type Authenticator struct {
+	secret    []byte
+	publicKey *rsa.PublicKey
+	parser    *jwt.Parser
+}
+
+// tokenLeeway allows for clock skew between the token issuer and the server
+const tokenLeeway = 30 * time.Second
+
+// NewAuthenticator returns an authenticator accepting tokens signed with
+// the configured secret, public key, or both. Tokens must expire, and must
+// match the issuer and audience when those are configured.
+func NewAuthenticator(cfg config.AuthConfig) (*Authenticator, error) {
+	auth := &Authenticator{}
+	var methods []string
+
+	if cfg.JWTSecret != "" {
+		auth.secret = []byte(cfg.JWTSecret)
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+
+	if cfg.JWTPublicKeyFile != "" {
+		data, err := os.ReadFile(cfg.JWTPublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWT public key: %w", err)
+		}
+		if auth.publicKey, err = jwt.ParseRSAPublicKeyFromPEM(data); err != nil {
+			return nil, fmt.Errorf("failed to parse JWT public key: %w", err)
+		}
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+
+	if len(methods) == 0 {
+		return nil, errors.New("no JWT secret or public key configured")
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(tokenLeeway),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+	auth.parser = jwt.NewParser(opts...)
+
+	return auth, nil
+}
+
+// Verify checks a token and returns the principal it stands for
+func (a *Authenticator) Verify(token string) (*Principal, error) {
+	var tokenClaims claims
+	if _, err := a.parser.ParseWithClaims(token, &tokenClaims, a.key); err != nil {
+		return nil, err
+	}
+
+	scopes, ok := roleScopes[tokenClaims.Role]
+	if !ok {
+		return nil, fmt.Errorf("unknown role %q", tokenClaims.Role)
+	}
+
+	principal := &Principal{Subject: tokenClaims.Subject, Role: tokenClaims.Role, Scopes: scopes}
+	if principal.IsMember() {
+		principal.MemberID = tokenClaims.MemberID
+		if principal.MemberID == "" {
+			principal.MemberID = tokenClaims.Subject
+		}
+		if principal.MemberID == "" {
+			return nil, errors.New("member token does not name a member")
+		}
+	}
+
+	return principal, nil
+}
+
+// key returns the key that verifies a token's signature. The parser has
+// already rejected algorithms without a configured key.
+func (a *Authenticator) key(token *jwt.Token) (any, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		return a.secret, nil
+	case *jwt.SigningMethodRSA:
+		return a.publicKey, nil
+	default:
+		return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+	}
+}
+
+// Authenticate returns a middleware that verifies the bearer token of every
+// request and attaches its principal. With a nil authenticator every request
+// acts as anonymous staff, which keeps the API open while authentication is
+// disabled. Requests already authenticated by an API key are passed on.
+func Authenticate(auth *Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if CurrentPrincipal(c) != nil {
+			c.Next()
+			return
+		}
+
+		if auth == nil {
+			SetPrincipal(c, anonymousPrincipal)
+			c.Next()
+			return
+		}
+
+		token, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !found || token == "" {
+			c.Header("WWW-Authenticate", `Bearer realm="glofox"`)
+			validation.AbortResponse(c, http.StatusUnauthorized, validation.CodeUnauthorized, "missing bearer token")
+			return
+		}
+
+		principal, err := auth.Verify(token)
+		if err != nil {
+			c.Header("WWW-Authenticate", `Bearer realm="glofox", error="invalid_token"`)
+			validation.AbortResponse(c, http.StatusUnauthorized, validation.CodeUnauthorized, "invalid or expired token")
+			return
+		}
+
+		SetPrincipal(c, principal)
+		c.Next()
+	}
+}
+
+// APIKeyHeader is the request header carrying an API key
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyAuth returns a middleware that authenticates requests carrying an
+// API key and attaches a principal holding the key's scopes. Requests
+// without a key are passed on to be authenticated by Authenticate.
+func APIKeyAuth(keys *service.APIKeyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		plaintext := c.GetHeader(APIKeyHeader)
+		if plaintext == "" {
+			c.Next()
+			return
+		}
+
+		key, err := keys.VerifyAPIKey(plaintext)
+		if errors.Is(err, service.ErrInvalidAPIKey) {
+			validation.AbortResponse(c, http.StatusUnauthorized, validation.CodeUnauthorized, err.Error())
+			return
+		}
+		if err != nil {
+			validation.ServiceErrorResponse(c, err)
+			c.Abort()
+			return
+		}
+
+		SetPrincipal(c, &Principal{Subject: key.ID, Role: RoleIntegration, Scopes: key.Scopes})
+		c.Next()
+	}
+}
+
+// Require returns a middleware that rejects requests whose principal lacks scope
+func Require(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := CurrentPrincipal(c)
+		if principal == nil {
+			validation.AbortResponse(c, http.StatusUnauthorized, validation.CodeUnauthorized, "authentication required")
+			return
+		}
+
+		if !principal.HasScope(scope) {
+			validation.AbortResponse(c, http.StatusForbidden, validation.CodeForbidden, "not allowed to perform this action")
+			return
+		}
+
+		c.Next()
+	}
+}
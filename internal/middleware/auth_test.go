@@ -0,0 +1,215 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sanjaykishor/Glofox/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "a-test-secret-that-is-long-enough-for-hs256"
+
+func signToken(t *testing.T, method jwt.SigningMethod, key any, tokenClaims claims) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(method, tokenClaims).SignedString(key)
+	require.NoError(t, err, "Should sign token without error")
+	return token
+}
+
+func validClaims(role Role, subject string) claims {
+	return claims{
+		Role: role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   subject,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+}
+
+func setupAuthRouter(auth *Authenticator, scope string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(Authenticate(auth))
+	router.GET("/test", Require(scope), func(c *gin.Context) {
+		principal := CurrentPrincipal(c)
+		c.String(http.StatusOK, string(principal.Role)+":"+principal.MemberID)
+	})
+	return router
+}
+
+func doAuthRequest(router *gin.Engine, token string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", "/test", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestAuthenticate(t *testing.T) {
+	auth, err := NewAuthenticator(config.AuthConfig{JWTSecret: testSecret, Issuer: "glofox-test"})
+	require.NoError(t, err, "Should create authenticator without error")
+	router := setupAuthRouter(auth, ScopeBookingsRead)
+
+	t.Run("Valid HS256 Token", func(t *testing.T) {
+		tokenClaims := validClaims(RoleStaff, "staff-1")
+		tokenClaims.Issuer = "glofox-test"
+
+		recorder := doAuthRequest(router, signToken(t, jwt.SigningMethodHS256, []byte(testSecret), tokenClaims))
+		assert.Equal(t, http.StatusOK, recorder.Code, "Should accept a valid token")
+		assert.Equal(t, "staff:", recorder.Body.String(), "Should attach the staff principal")
+	})
+
+	t.Run("Member Token", func(t *testing.T) {
+		tokenClaims := validClaims(RoleMember, "user-1")
+		tokenClaims.Issuer = "glofox-test"
+		tokenClaims.MemberID = "member-1"
+
+		recorder := doAuthRequest(router, signToken(t, jwt.SigningMethodHS256, []byte(testSecret), tokenClaims))
+		assert.Equal(t, http.StatusOK, recorder.Code, "Should accept a member token")
+		assert.Equal(t, "member:member-1", recorder.Body.String(), "Should take the member ID from member_id")
+
+		tokenClaims.MemberID = ""
+		recorder = doAuthRequest(router, signToken(t, jwt.SigningMethodHS256, []byte(testSecret), tokenClaims))
+		assert.Equal(t, "member:user-1", recorder.Body.String(), "Should fall back to the subject for the member ID")
+	})
+
+	t.Run("Missing Token", func(t *testing.T) {
+		recorder := doAuthRequest(router, "")
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code, "Should reject a request without a token")
+		assert.Contains(t, recorder.Header().Get("WWW-Authenticate"), "Bearer", "Should challenge for a bearer token")
+		assert.Contains(t, recorder.Body.String(), `"unauthorized"`, "Should return the unauthorized code")
+	})
+
+	t.Run("Expired Token", func(t *testing.T) {
+		tokenClaims := validClaims(RoleStaff, "staff-1")
+		tokenClaims.Issuer = "glofox-test"
+		tokenClaims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+
+		recorder := doAuthRequest(router, signToken(t, jwt.SigningMethodHS256, []byte(testSecret), tokenClaims))
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code, "Should reject an expired token")
+	})
+
+	t.Run("Token Without Expiry", func(t *testing.T) {
+		tokenClaims := validClaims(RoleStaff, "staff-1")
+		tokenClaims.Issuer = "glofox-test"
+		tokenClaims.ExpiresAt = nil
+
+		recorder := doAuthRequest(router, signToken(t, jwt.SigningMethodHS256, []byte(testSecret), tokenClaims))
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code, "Should reject a token that never expires")
+	})
+
+	t.Run("Wrong Issuer", func(t *testing.T) {
+		tokenClaims := validClaims(RoleStaff, "staff-1")
+		tokenClaims.Issuer = "someone-else"
+
+		recorder := doAuthRequest(router, signToken(t, jwt.SigningMethodHS256, []byte(testSecret), tokenClaims))
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code, "Should reject a token from another issuer")
+	})
+
+	t.Run("Wrong Secret", func(t *testing.T) {
+		tokenClaims := validClaims(RoleStaff, "staff-1")
+		tokenClaims.Issuer = "glofox-test"
+
+		recorder := doAuthRequest(router, signToken(t, jwt.SigningMethodHS256, []byte("another-secret-that-is-long-enough!!"), tokenClaims))
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code, "Should reject a token signed with another secret")
+	})
+
+	t.Run("Unsigned Token", func(t *testing.T) {
+		tokenClaims := validClaims(RoleOwner, "owner-1")
+		tokenClaims.Issuer = "glofox-test"
+
+		recorder := doAuthRequest(router, signToken(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, tokenClaims))
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code, "Should reject an unsigned token")
+	})
+
+	t.Run("Unknown Role", func(t *testing.T) {
+		tokenClaims := validClaims("admin", "admin-1")
+		tokenClaims.Issuer = "glofox-test"
+
+		recorder := doAuthRequest(router, signToken(t, jwt.SigningMethodHS256, []byte(testSecret), tokenClaims))
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code, "Should reject a token with an unknown role")
+	})
+}
+
+func TestAuthenticateRS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "Should generate RSA key without error")
+
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err, "Should marshal public key without error")
+
+	keyFile := filepath.Join(t.TempDir(), "public.pem")
+	err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600)
+	require.NoError(t, err, "Should write public key without error")
+
+	auth, err := NewAuthenticator(config.AuthConfig{JWTPublicKeyFile: keyFile})
+	require.NoError(t, err, "Should create authenticator without error")
+	router := setupAuthRouter(auth, ScopeClassesWrite)
+
+	recorder := doAuthRequest(router, signToken(t, jwt.SigningMethodRS256, key, validClaims(RoleOwner, "owner-1")))
+	assert.Equal(t, http.StatusOK, recorder.Code, "Should accept a token signed with the private key")
+
+	// An HS256 token must not be verified with the public key as its secret
+	recorder = doAuthRequest(router, signToken(t, jwt.SigningMethodHS256, der, validClaims(RoleOwner, "owner-1")))
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code, "Should reject an algorithm without a configured key")
+
+	_, err = NewAuthenticator(config.AuthConfig{JWTPublicKeyFile: filepath.Join(t.TempDir(), "missing.pem")})
+	assert.Error(t, err, "Should fail when the public key file is missing")
+
+	_, err = NewAuthenticator(config.AuthConfig{})
+	assert.Error(t, err, "Should fail without a secret or public key")
+}
+
+func TestRequire(t *testing.T) {
+	auth, err := NewAuthenticator(config.AuthConfig{JWTSecret: testSecret})
+	require.NoError(t, err, "Should create authenticator without error")
+
+	t.Run("Missing Scope", func(t *testing.T) {
+		router := setupAuthRouter(auth, ScopeClassesWrite)
+
+		tokenClaims := validClaims(RoleMember, "member-1")
+		recorder := doAuthRequest(router, signToken(t, jwt.SigningMethodHS256, []byte(testSecret), tokenClaims))
+		assert.Equal(t, http.StatusForbidden, recorder.Code, "Should forbid a member from changing classes")
+		assert.Contains(t, recorder.Body.String(), `"forbidden"`, "Should return the forbidden code")
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		router := gin.New()
+		router.GET("/test", Require(ScopeClassesRead), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		recorder := doAuthRequest(router, "")
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code, "Should reject a request without a principal")
+	})
+
+	t.Run("Authentication Disabled", func(t *testing.T) {
+		router := setupAuthRouter(nil, ScopeMembersWrite)
+
+		recorder := doAuthRequest(router, "")
+		assert.Equal(t, http.StatusOK, recorder.Code, "Should act as staff while authentication is disabled")
+		assert.Equal(t, "staff:", recorder.Body.String(), "Should attach the anonymous staff principal")
+
+		router = setupAuthRouter(nil, ScopeAPIKeysManage)
+
+		recorder = doAuthRequest(router, "")
+		assert.Equal(t, http.StatusForbidden, recorder.Code, "Should never let an anonymous caller manage API keys")
+	})
+}
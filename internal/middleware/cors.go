@@ -0,0 +1,178 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sanjaykishor/Glofox/internal/config"
+	"github.com/sanjaykishor/Glofox/internal/validation"
+)
+
+// exposedHeaders are the response headers browsers let scripts read
+// besides the CORS-safelisted ones
+const exposedHeaders = "X-Request-ID, Retry-After, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset"
+
+// corsPolicy is a CORSConfig prepared for matching requests against
+type corsPolicy struct {
+	anyOrigin        bool
+	origins          []string
+	wildcards        []originWildcard
+	methods          []string
+	anyHeader        bool
+	headers          []string
+	allowMethods     string
+	allowHeaders     string
+	maxAge           string
+	allowCredentials bool
+}
+
+// originWildcard matches every subdomain of a host, such as
+// https://*.example.com, split around the wildcard
+type originWildcard struct {
+	prefix string
+	suffix string
+}
+
+// matches reports whether origin is a subdomain covered by the wildcard.
+// The host itself is not covered, so https://example.com does not match.
+func (w originWildcard) matches(origin string) bool {
+	if !strings.HasPrefix(origin, w.prefix) || !strings.HasSuffix(origin, w.suffix) {
+		return false
+	}
+
+	subdomain := origin[len(w.prefix) : len(origin)-len(w.suffix)]
+	return subdomain != "" && !strings.ContainsAny(subdomain, "/:@")
+}
+
+// newCORSPolicy prepares cfg for matching. Origins, methods and headers
+// are compared case-insensitively, as browsers may send them in any case.
+func newCORSPolicy(cfg config.CORSConfig) *corsPolicy {
+	policy := &corsPolicy{
+		allowMethods:     strings.Join(cfg.AllowedMethods, ", "),
+		allowHeaders:     strings.Join(cfg.AllowedHeaders, ", "),
+		maxAge:           strconv.Itoa(int(cfg.MaxAge.Seconds())),
+		allowCredentials: cfg.AllowCredentials,
+	}
+
+	for _, origin := range cfg.AllowedOrigins {
+		origin = strings.ToLower(origin)
+		switch {
+		case origin == "*":
+			policy.anyOrigin = true
+		case strings.Contains(origin, "://*."):
+			scheme, host, _ := strings.Cut(origin, "://*")
+			policy.wildcards = append(policy.wildcards, originWildcard{prefix: scheme + "://", suffix: host})
+		default:
+			policy.origins = append(policy.origins, origin)
+		}
+	}
+
+	for _, method := range cfg.AllowedMethods {
+		policy.methods = append(policy.methods, strings.ToUpper(method))
+	}
+
+	for _, header := range cfg.AllowedHeaders {
+		if header == "*" {
+			policy.anyHeader = true
+			continue
+		}
+		policy.headers = append(policy.headers, strings.ToLower(header))
+	}
+
+	return policy
+}
+
+// allowsOrigin reports whether browsers may call the API from origin
+func (p *corsPolicy) allowsOrigin(origin string) bool {
+	if p.anyOrigin {
+		return true
+	}
+
+	origin = strings.ToLower(origin)
+	if slices.Contains(p.origins, origin) {
+		return true
+	}
+
+	return slices.ContainsFunc(p.wildcards, func(w originWildcard) bool { return w.matches(origin) })
+}
+
+// allowsMethod reports whether a preflight for method can succeed
+func (p *corsPolicy) allowsMethod(method string) bool {
+	return slices.Contains(p.methods, strings.ToUpper(method))
+}
+
+// allowsHeaders reports whether every header in a preflight's
+// comma-separated Access-Control-Request-Headers is allowed
+func (p *corsPolicy) allowsHeaders(requested string) bool {
+	if p.anyHeader {
+		return true
+	}
+
+	for _, header := range strings.Split(requested, ",") {
+		header = strings.ToLower(strings.TrimSpace(header))
+		if header != "" && !slices.Contains(p.headers, header) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// CORS returns a middleware applying the Cross-Origin Resource Sharing
+// policy in cfg. The request's origin is echoed back when it is allowed,
+// so responses vary by Origin. A preflight for a disallowed origin,
+// method or header is rejected with 403 Forbidden instead of being
+// passed on to the routes.
+func CORS(cfg config.CORSConfig) gin.HandlerFunc {
+	policy := newCORSPolicy(cfg)
+
+	return func(c *gin.Context) {
+		header := c.Writer.Header()
+		header.Add("Vary", "Origin")
+
+		origin := c.GetHeader("Origin")
+		requestedMethod := c.GetHeader("Access-Control-Request-Method")
+		preflight := c.Request.Method == http.MethodOptions && origin != "" && requestedMethod != ""
+
+		if preflight {
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+
+			requestedHeaders := c.GetHeader("Access-Control-Request-Headers")
+			if !policy.allowsOrigin(origin) || !policy.allowsMethod(requestedMethod) || !policy.allowsHeaders(requestedHeaders) {
+				validation.AbortResponse(c, http.StatusForbidden, validation.CodeForbidden, "cross-origin request not allowed")
+				return
+			}
+
+			policy.setAllowOrigin(c, origin)
+			header.Set("Access-Control-Allow-Methods", policy.allowMethods)
+			if policy.anyHeader {
+				header.Set("Access-Control-Allow-Headers", requestedHeaders)
+			} else {
+				header.Set("Access-Control-Allow-Headers", policy.allowHeaders)
+			}
+			header.Set("Access-Control-Max-Age", policy.maxAge)
+
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		if origin != "" && policy.allowsOrigin(origin) {
+			policy.setAllowOrigin(c, origin)
+			header.Set("Access-Control-Expose-Headers", exposedHeaders)
+		}
+
+		c.Next()
+	}
+}
+
+// setAllowOrigin allows origin to read the response
+func (p *corsPolicy) setAllowOrigin(c *gin.Context, origin string) {
+	c.Header("Access-Control-Allow-Origin", origin)
+	if p.allowCredentials {
+		c.Header("Access-Control-Allow-Credentials", "true")
+	}
+}
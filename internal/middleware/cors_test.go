@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sanjaykishor/Glofox/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupCORSRouter(cfg config.CORSConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(CORS(cfg))
+	router.GET("/test", func(c *gin.Context) {
+		c.String(http.StatusOK, "test")
+	})
+	return router
+}
+
+func doCORSRequest(router *gin.Engine, method, origin string, headers map[string]string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, "/test", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestCORS(t *testing.T) {
+	router := setupCORSRouter(config.CORSConfig{
+		AllowedOrigins:   []string{"https://app.example.com", "https://*.example.org"},
+		AllowedMethods:   []string{"GET", "POST"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		MaxAge:           time.Hour,
+		AllowCredentials: true,
+	})
+
+	t.Run("Allowed Origin", func(t *testing.T) {
+		recorder := doCORSRequest(router, "GET", "https://app.example.com", nil)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "https://app.example.com", recorder.Header().Get("Access-Control-Allow-Origin"), "Should echo the allowed origin")
+		assert.Equal(t, "true", recorder.Header().Get("Access-Control-Allow-Credentials"), "Should allow credentials when configured")
+		assert.Contains(t, recorder.Header().Values("Vary"), "Origin", "Should vary by origin")
+		assert.Contains(t, recorder.Header().Get("Access-Control-Expose-Headers"), "RateLimit-Remaining", "Should let scripts read the rate limit headers")
+		assert.Contains(t, recorder.Header().Get("Access-Control-Expose-Headers"), "X-Request-ID", "Should let scripts read the request ID")
+	})
+
+	t.Run("Wildcard Subdomain", func(t *testing.T) {
+		for _, origin := range []string{"https://shop.example.org", "https://eu.shop.example.org", "HTTPS://Shop.Example.org"} {
+			recorder := doCORSRequest(router, "GET", origin, nil)
+			assert.Equal(t, origin, recorder.Header().Get("Access-Control-Allow-Origin"), "Should allow subdomain %s", origin)
+		}
+
+		for _, origin := range []string{"https://example.org", "http://shop.example.org", "https://shopexample.org", "https://example.org.evil.com"} {
+			recorder := doCORSRequest(router, "GET", origin, nil)
+			assert.Empty(t, recorder.Header().Get("Access-Control-Allow-Origin"), "Should not allow %s", origin)
+		}
+	})
+
+	t.Run("Disallowed Origin", func(t *testing.T) {
+		recorder := doCORSRequest(router, "GET", "https://evil.com", nil)
+
+		assert.Equal(t, http.StatusOK, recorder.Code, "Should still serve the request and leave blocking to the browser")
+		assert.Empty(t, recorder.Header().Get("Access-Control-Allow-Origin"), "Should not allow the origin")
+		assert.Empty(t, recorder.Header().Get("Access-Control-Allow-Credentials"), "Should not allow credentials")
+		assert.Contains(t, recorder.Header().Values("Vary"), "Origin", "Should vary by origin")
+	})
+
+	t.Run("Same Origin", func(t *testing.T) {
+		recorder := doCORSRequest(router, "GET", "", nil)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "test", recorder.Body.String())
+		assert.Empty(t, recorder.Header().Get("Access-Control-Allow-Origin"), "Should not add CORS headers without an origin")
+	})
+
+	t.Run("Preflight", func(t *testing.T) {
+		recorder := doCORSRequest(router, "OPTIONS", "https://app.example.com", map[string]string{
+			"Access-Control-Request-Method":  "POST",
+			"Access-Control-Request-Headers": "content-type, authorization",
+		})
+
+		assert.Equal(t, http.StatusNoContent, recorder.Code)
+		assert.Empty(t, recorder.Body.String())
+		assert.Equal(t, "https://app.example.com", recorder.Header().Get("Access-Control-Allow-Origin"), "Should echo the allowed origin")
+		assert.Equal(t, "GET, POST", recorder.Header().Get("Access-Control-Allow-Methods"), "Should list the allowed methods")
+		assert.Equal(t, "Content-Type, Authorization", recorder.Header().Get("Access-Control-Allow-Headers"), "Should list the allowed headers")
+		assert.Equal(t, "3600", recorder.Header().Get("Access-Control-Max-Age"), "Should send the max age in seconds")
+		assert.Equal(t, "true", recorder.Header().Get("Access-Control-Allow-Credentials"), "Should allow credentials when configured")
+	})
+
+	t.Run("Rejected Preflight", func(t *testing.T) {
+		cases := map[string]struct {
+			origin  string
+			headers map[string]string
+		}{
+			"origin": {"https://evil.com", map[string]string{"Access-Control-Request-Method": "GET"}},
+			"method": {"https://app.example.com", map[string]string{"Access-Control-Request-Method": "DELETE"}},
+			"header": {"https://app.example.com", map[string]string{"Access-Control-Request-Method": "GET", "Access-Control-Request-Headers": "X-Custom"}},
+		}
+
+		for name, tc := range cases {
+			recorder := doCORSRequest(router, "OPTIONS", tc.origin, tc.headers)
+
+			assert.Equal(t, http.StatusForbidden, recorder.Code, "Should reject a preflight with a disallowed %s", name)
+			assert.Empty(t, recorder.Header().Get("Access-Control-Allow-Origin"), "Should not allow the origin for a disallowed %s", name)
+			assert.Empty(t, recorder.Header().Get("Access-Control-Allow-Methods"), "Should not list methods for a disallowed %s", name)
+		}
+	})
+}
+
+func TestCORSAnyOrigin(t *testing.T) {
+	router := setupCORSRouter(config.CORSConfig{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{"GET"},
+		AllowedHeaders: []string{"*"},
+	})
+
+	recorder := doCORSRequest(router, "GET", "https://anywhere.example.com", nil)
+	assert.Equal(t, "https://anywhere.example.com", recorder.Header().Get("Access-Control-Allow-Origin"), "Should echo any origin")
+	assert.Empty(t, recorder.Header().Get("Access-Control-Allow-Credentials"), "Should not allow credentials unless configured")
+
+	recorder = doCORSRequest(router, "OPTIONS", "https://anywhere.example.com", map[string]string{
+		"Access-Control-Request-Method":  "GET",
+		"Access-Control-Request-Headers": "X-Custom",
+	})
+	assert.Equal(t, http.StatusNoContent, recorder.Code, "Should accept the preflight")
+	assert.Equal(t, "X-Custom", recorder.Header().Get("Access-Control-Allow-Headers"), "Should allow the requested headers")
+}
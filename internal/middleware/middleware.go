@@ -1,60 +1,134 @@
 package middleware
 
 import (
-	"fmt"
-	"log"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sanjaykishor/Glofox/internal/config"
+	"github.com/sanjaykishor/Glofox/internal/logging"
+	"github.com/sanjaykishor/Glofox/internal/validation"
 )
 
+// RequestIDHeader carries the ID of a request, which is given by the
+// client or a proxy in front of the API, or else generated
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds request IDs taken from clients
+const maxRequestIDLength = 128
 
 // Setup configures global middleware for the application
-func Setup(router *gin.Engine) {
+func Setup(router *gin.Engine, cfg *config.Config) {
+	router.Use(RequestID())
 	router.Use(RequestLogger())
-	router.Use(CORS())
+	router.Use(Recovery())
+	router.Use(CORS(cfg.CORS))
+}
+
+// RequestID returns a middleware that gives each request an ID, echoed in
+// the X-Request-ID response header and added to the request's context so
+// log lines and error responses can carry it. An ID sent by the client is
+// kept unless it is too long or holds anything but printable ASCII.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = uuid.New().String()
+		}
+
+		c.Header(RequestIDHeader, requestID)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), requestID))
+
+		c.Next()
+	}
+}
+
+// validRequestID reports whether a request ID sent by a client can be used
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] <= ' ' || requestID[i] > '~' {
+			return false
+		}
+	}
+	return true
 }
 
-// RequestLogger returns a middleware that logs request details
+// RequestLogger returns a middleware that logs each request once it has
+// been served. Client errors are logged as warnings and server errors as
+// errors.
 func RequestLogger() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 
 		c.Next()
 
-		latency := time.Since(start)
-		path := c.Request.URL.Path
-		method := c.Request.Method
-		statusCode := c.Writer.Status()
-
-		logMessage := fmt.Sprintf("[GIN] %s | %s | %s | %d | %s\n",
-			time.Now().Format("2006/01/02 - 15:04:05"),
-			method,
-			path,
-			statusCode,
-			latency.String())
-
-		_, err := gin.DefaultWriter.Write([]byte(logMessage))
-		if err != nil {
-			log.Println("Failed to write request log:", err)
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("ip", c.ClientIP()),
+			slog.String("user_agent", c.Request.UserAgent()),
+			slog.Int("bytes", max(c.Writer.Size(), 0)),
+		}
+
+		if principal := CurrentPrincipal(c); principal != nil {
+			attrs = append(attrs, slog.Group("principal",
+				slog.String("role", string(principal.Role)),
+				slog.String("subject", principal.Subject),
+			))
 		}
+
+		slog.LogAttrs(c.Request.Context(), level, "Handled request", attrs...)
 	}
 }
 
-// CORS returns a middleware for handling Cross-Origin Resource Sharing
-func CORS() gin.HandlerFunc {
+// Recovery returns a middleware that turns a panic in a later handler into
+// a 500 response and logs it with its stack trace
+func Recovery() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*") // TODO: Change to specific origin
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
-
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			// The server aborts the response quietly for this one
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			slog.ErrorContext(c.Request.Context(), "Recovered from panic handling request",
+				"method", c.Request.Method,
+				"path", c.Request.URL.Path,
+				"panic", recovered,
+				"stack", string(debug.Stack()),
+			)
+
+			// A response already under way cannot be replaced
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			validation.AbortResponse(c, http.StatusInternalServerError, validation.CodeInternal, "internal server error")
+		}()
 
 		c.Next()
 	}
 }
-
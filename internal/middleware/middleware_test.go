@@ -2,82 +2,163 @@ package middleware
 
 import (
 	"bytes"
+	"encoding/json"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sanjaykishor/Glofox/internal/config"
+	"github.com/sanjaykishor/Glofox/internal/logging"
+	"github.com/sanjaykishor/Glofox/internal/validation"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-func TestRequestLogger(t *testing.T) {
-	gin.SetMode(gin.TestMode)
+// captureLogs sends the default logger's JSON lines to the returned buffer
+// for the rest of the test
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
 
 	var buf bytes.Buffer
-	gin.DefaultWriter = &buf
+	previous := slog.Default()
+	slog.SetDefault(logging.New(config.LogConfig{Format: config.LogFormatJSON, Level: slog.LevelDebug}, &buf))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &buf
+}
 
-	router := gin.New()
-	router.Use(RequestLogger())
+// logLines decodes the JSON log lines written to buf
+func logLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+
+	var lines []map[string]any
+	for _, raw := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if raw == "" {
+			continue
+		}
+		var line map[string]any
+		require.NoError(t, json.Unmarshal([]byte(raw), &line), "Should decode log line without error")
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func setupLoggedRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
 
-	router.GET("/test", func(c *gin.Context) {
+	router := gin.New()
+	router.Use(RequestID(), RequestLogger(), Recovery(), Authenticate(nil))
+	router.GET("/test/:id", func(c *gin.Context) {
 		c.String(http.StatusOK, "test")
 	})
+	router.GET("/missing", func(c *gin.Context) {
+		validation.AbortResponse(c, http.StatusNotFound, "class_not_found", "class not found")
+	})
+	router.GET("/panic", func(c *gin.Context) {
+		panic("something broke")
+	})
+	return router
+}
+
+func TestRequestLogger(t *testing.T) {
+	buf := captureLogs(t)
+	router := setupLoggedRouter()
 
 	recorder := httptest.NewRecorder()
-	req, _ := http.NewRequest("GET", "/test", nil)
+	req, _ := http.NewRequest("GET", "/test/42", nil)
+	req.Header.Set("User-Agent", "glofox-test/1.0")
+	req.Header.Set(RequestIDHeader, "req-123")
+	req.RemoteAddr = "203.0.113.7:51234"
 	router.ServeHTTP(recorder, req)
 
-	logOutput := buf.String()
-	assert.Contains(t, logOutput, "[GIN]")
-	assert.Contains(t, logOutput, "GET")
-	assert.Contains(t, logOutput, "/test")
-	assert.Contains(t, logOutput, "200")
-
-	elapsedTimePattern := `\d+(\.\d+)?(µs|ms|s|m|h)`
-	assert.Regexp(t, elapsedTimePattern, logOutput)
-}
-
-func TestCORS(t *testing.T) {
-	t.Run("Regular Request", func(t *testing.T) {
-		router := gin.New()
-		router.Use(CORS())
-
-		router.GET("/test", func(c *gin.Context) {
-			c.String(http.StatusOK, "test")
-		})
+	lines := logLines(t, buf)
+	require.Len(t, lines, 1, "Should log the request once")
+
+	line := lines[0]
+	assert.Equal(t, "INFO", line["level"])
+	assert.Equal(t, "GET", line["method"])
+	assert.Equal(t, "/test/42", line["path"])
+	assert.Equal(t, "/test/:id", line["route"], "Should log the matched route")
+	assert.EqualValues(t, 200, line["status"])
+	assert.Contains(t, line, "latency_ms")
+	assert.Equal(t, "203.0.113.7", line["ip"], "Should log the client IP")
+	assert.Equal(t, "glofox-test/1.0", line["user_agent"], "Should log the user agent")
+	assert.EqualValues(t, len("test"), line["bytes"], "Should log the response size")
+	assert.Equal(t, "req-123", line["request_id"], "Should log the request ID")
+	assert.Equal(t, map[string]any{"role": "staff", "subject": "anonymous"}, line["principal"], "Should log the principal")
+
+	t.Run("Client Error", func(t *testing.T) {
+		buf.Reset()
 
 		recorder := httptest.NewRecorder()
-		req, _ := http.NewRequest("GET", "/test", nil)
+		req, _ := http.NewRequest("GET", "/missing", nil)
 		router.ServeHTTP(recorder, req)
 
-		assert.Equal(t, "*", recorder.Header().Get("Access-Control-Allow-Origin"))
-		assert.Equal(t, "true", recorder.Header().Get("Access-Control-Allow-Credentials"))
-		assert.NotEmpty(t, recorder.Header().Get("Access-Control-Allow-Headers"))
-		assert.NotEmpty(t, recorder.Header().Get("Access-Control-Allow-Methods"))
-
-		assert.Equal(t, http.StatusOK, recorder.Code)
-		assert.Equal(t, "test", recorder.Body.String())
+		lines := logLines(t, buf)
+		require.Len(t, lines, 1, "Should log the request once")
+		assert.Equal(t, "WARN", lines[0]["level"], "Should log client errors as warnings")
+		assert.EqualValues(t, 404, lines[0]["status"])
 	})
+}
 
-	t.Run("OPTIONS Request", func(t *testing.T) {
-		router := gin.New()
-		router.Use(CORS())
-
-		router.GET("/test", func(c *gin.Context) {
-			c.String(http.StatusOK, "test")
-		})
+func TestRequestID(t *testing.T) {
+	captureLogs(t)
+	router := setupLoggedRouter()
 
+	t.Run("Propagated", func(t *testing.T) {
 		recorder := httptest.NewRecorder()
-		req, _ := http.NewRequest("OPTIONS", "/test", nil)
+		req, _ := http.NewRequest("GET", "/missing", nil)
+		req.Header.Set(RequestIDHeader, "lb-4f1c9a")
 		router.ServeHTTP(recorder, req)
 
-		assert.Equal(t, "*", recorder.Header().Get("Access-Control-Allow-Origin"))
-		assert.Equal(t, "true", recorder.Header().Get("Access-Control-Allow-Credentials"))
-		assert.NotEmpty(t, recorder.Header().Get("Access-Control-Allow-Headers"))
-		assert.NotEmpty(t, recorder.Header().Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, "lb-4f1c9a", recorder.Header().Get(RequestIDHeader), "Should echo the client's request ID")
 
-		assert.Equal(t, http.StatusNoContent, recorder.Code) // 204 No Content
-		assert.Empty(t, recorder.Body.String())
+		var response validation.Response
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response), "Should decode response without error")
+		assert.Equal(t, "lb-4f1c9a", response.RequestID, "Should include the request ID in the error response")
+	})
+
+	t.Run("Generated", func(t *testing.T) {
+		for _, sent := range []string{"", "has spaces", "line\nbreak", strings.Repeat("a", maxRequestIDLength+1)} {
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/test/1", nil)
+			req.Header.Set(RequestIDHeader, sent)
+			router.ServeHTTP(recorder, req)
+
+			requestID := recorder.Header().Get(RequestIDHeader)
+			assert.NotEmpty(t, requestID, "Should generate a request ID for %q", sent)
+			assert.NotEqual(t, sent, requestID, "Should replace the request ID %q", sent)
+		}
+
+		first := httptest.NewRecorder()
+		second := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/test/1", nil)
+		router.ServeHTTP(first, req)
+		router.ServeHTTP(second, req)
+		assert.NotEqual(t, first.Header().Get(RequestIDHeader), second.Header().Get(RequestIDHeader), "Should generate a new ID for each request")
 	})
 }
 
+func TestRecovery(t *testing.T) {
+	buf := captureLogs(t)
+	router := setupLoggedRouter()
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/panic", nil)
+	req.Header.Set(RequestIDHeader, "req-panic")
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code, "Should turn a panic into a server error")
+	assert.Contains(t, recorder.Body.String(), `"internal_error"`, "Should return the internal error code")
+	assert.NotContains(t, recorder.Body.String(), "something broke", "Should not show the panic to the client")
+
+	lines := logLines(t, buf)
+	require.Len(t, lines, 2, "Should log the panic and the request")
+	assert.Equal(t, "something broke", lines[0]["panic"], "Should log the panic")
+	assert.Contains(t, lines[0]["stack"], "runtime/debug.Stack", "Should log the stack trace")
+	assert.Equal(t, "req-panic", lines[0]["request_id"], "Should log the panic with the request ID")
+	assert.Equal(t, "ERROR", lines[1]["level"], "Should log the failed request as an error")
+	assert.EqualValues(t, 500, lines[1]["status"])
+}
@@ -0,0 +1,214 @@
+package middleware
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sanjaykishor/Glofox/internal/config"
+	"github.com/sanjaykishor/Glofox/internal/validation"
+)
+
+// bucketKey identifies the bucket of a client in a route group
+type bucketKey struct {
+	group  string
+	client string
+}
+
+// bucket holds the tokens a client has left. Tokens are refilled
+// continuously and each request takes one.
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	limit   config.RateLimit
+}
+
+// refill adds the tokens earned since the bucket was last updated
+func (b *bucket) refill(now time.Time) {
+	elapsed := now.Sub(b.updated)
+	if elapsed <= 0 {
+		return
+	}
+
+	b.tokens = math.Min(float64(b.limit.Requests), b.tokens+elapsed.Seconds()*b.rate())
+	b.updated = now
+}
+
+// rate returns the tokens added per second
+func (b *bucket) rate() float64 {
+	return float64(b.limit.Requests) / b.limit.Period.Seconds()
+}
+
+// untilTokens returns how long the bucket takes to hold n tokens
+func (b *bucket) untilTokens(n float64) time.Duration {
+	if b.tokens >= n {
+		return 0
+	}
+	return time.Duration((n - b.tokens) / b.rate() * float64(time.Second))
+}
+
+// RateLimiter limits how many requests each client makes in every route
+// group, with a token bucket per client and group
+type RateLimiter struct {
+	cfg     config.RateLimitConfig
+	buckets map[bucketKey]*bucket
+	now     func() time.Time
+	mutex   sync.Mutex
+}
+
+// NewRateLimiter creates a rate limiter enforcing the limits in cfg
+func NewRateLimiter(cfg config.RateLimitConfig) *RateLimiter {
+	return &RateLimiter{
+		cfg:     cfg,
+		buckets: make(map[bucketKey]*bucket),
+		now:     time.Now,
+	}
+}
+
+// SetClock overrides the clock used to refill buckets
+func (l *RateLimiter) SetClock(now func() time.Time) {
+	l.now = now
+}
+
+// take takes a token from the client's bucket in group, reporting whether
+// one was left, together with the state of the bucket afterwards
+func (l *RateLimiter) take(group, client string) (bool, bucket) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	key := bucketKey{group: group, client: client}
+
+	b, exists := l.buckets[key]
+	if !exists {
+		limit := l.cfg.For(group)
+		b = &bucket{tokens: float64(limit.Requests), updated: now, limit: limit}
+		l.buckets[key] = b
+	}
+	b.refill(now)
+
+	if b.tokens < 1 {
+		return false, *b
+	}
+
+	b.tokens--
+	return true, *b
+}
+
+// EvictIdle removes the buckets that have refilled completely, as they
+// are no different from the fresh bucket a returning client gets, and
+// returns how many were removed
+func (l *RateLimiter) EvictIdle() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	evicted := 0
+	for key, b := range l.buckets {
+		b.refill(now)
+		if b.tokens >= float64(b.limit.Requests) {
+			delete(l.buckets, key)
+			evicted++
+		}
+	}
+
+	return evicted
+}
+
+// StartEviction evicts idle buckets every interval until the returned
+// stop function is called
+func (l *RateLimiter) StartEviction(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if evicted := l.EvictIdle(); evicted > 0 {
+					slog.Debug("Evicted idle rate limit buckets", "count", evicted)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
+
+// rateLimitClient returns the key requests are counted under: the API key
+// or user of an authenticated request, or else the client's IP address. A
+// token without a subject is counted by its member, if any, so such tokens
+// never share one bucket.
+func rateLimitClient(c *gin.Context) string {
+	principal := CurrentPrincipal(c)
+	switch {
+	case principal == nil || principal == anonymousPrincipal:
+		return "ip:" + c.ClientIP()
+	case principal.Role == RoleIntegration:
+		return "key:" + principal.Subject
+	case principal.Subject != "":
+		return "user:" + principal.Subject
+	case principal.MemberID != "":
+		return "member:" + principal.MemberID
+	default:
+		return "ip:" + c.ClientIP()
+	}
+}
+
+// RateLimit returns a middleware limiting the requests each client makes
+// to the routes of group. Every response carries RateLimit-Limit,
+// RateLimit-Remaining and RateLimit-Reset headers, and a request over the
+// limit is rejected with 429 Too Many Requests and a Retry-After header.
+// With a nil limiter requests are not limited. It must run after the
+// request is authenticated so clients are told apart by their credentials.
+func RateLimit(limiter *RateLimiter, group string) gin.HandlerFunc {
+	return rateLimit(limiter, group, rateLimitClient)
+}
+
+// RateLimitIP returns a middleware limiting the requests made to the
+// routes of group from each IP address, whatever their credentials. It runs
+// before authentication, so requests with missing or wrong credentials are
+// limited as well.
+func RateLimitIP(limiter *RateLimiter, group string) gin.HandlerFunc {
+	return rateLimit(limiter, group, func(c *gin.Context) string {
+		return "ip:" + c.ClientIP()
+	})
+}
+
+// rateLimit returns a middleware limiting the requests to the routes of
+// group, counting them against the key returned by client
+func rateLimit(limiter *RateLimiter, group string, client func(c *gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limiter == nil {
+			c.Next()
+			return
+		}
+
+		allowed, b := limiter.take(group, client(c))
+
+		c.Header("RateLimit-Limit", strconv.Itoa(b.limit.Requests))
+		c.Header("RateLimit-Remaining", strconv.Itoa(int(b.tokens)))
+		c.Header("RateLimit-Reset", ceilSeconds(b.untilTokens(float64(b.limit.Requests))))
+
+		if !allowed {
+			c.Header("Retry-After", ceilSeconds(b.untilTokens(1)))
+			validation.AbortResponse(c, http.StatusTooManyRequests, validation.CodeRateLimited, "too many requests, please retry later")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// ceilSeconds formats a duration as whole seconds, rounded up
+func ceilSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
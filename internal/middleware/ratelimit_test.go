@@ -0,0 +1,172 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sanjaykishor/Glofox/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupRateLimitRouter returns a router whose /bookings and /classes
+// routes are limited as separate groups, and a clock to move forward
+func setupRateLimitRouter(principal *Principal) (*gin.Engine, *RateLimiter, *time.Time) {
+	gin.SetMode(gin.TestMode)
+
+	limiter := NewRateLimiter(config.RateLimitConfig{
+		Enabled: true,
+		Default: config.RateLimit{Requests: 5, Period: time.Minute},
+		Groups:  map[string]config.RateLimit{config.RouteGroupBookings: {Requests: 2, Period: time.Minute}},
+	})
+	now := time.Date(2025, 4, 1, 9, 0, 0, 0, time.UTC)
+	limiter.SetClock(func() time.Time { return now })
+
+	router := gin.New()
+	if principal != nil {
+		router.Use(func(c *gin.Context) { SetPrincipal(c, principal) })
+	}
+	router.POST("/bookings", RateLimit(limiter, config.RouteGroupBookings), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+	router.GET("/classes", RateLimit(limiter, config.RouteGroupCatalog), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	return router, limiter, &now
+}
+
+func doRateLimitRequest(router *gin.Engine, method, path, ip string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, nil)
+	req.RemoteAddr = ip + ":12345"
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestRateLimit(t *testing.T) {
+	router, _, now := setupRateLimitRouter(nil)
+
+	recorder := doRateLimitRequest(router, "POST", "/bookings", "10.0.0.1")
+	assert.Equal(t, http.StatusCreated, recorder.Code, "Should allow a request within the limit")
+	assert.Equal(t, "2", recorder.Header().Get("RateLimit-Limit"), "Should report the limit of the group")
+	assert.Equal(t, "1", recorder.Header().Get("RateLimit-Remaining"), "Should report the requests left")
+	assert.Equal(t, "30", recorder.Header().Get("RateLimit-Reset"), "Should report when the bucket is full again")
+
+	doRateLimitRequest(router, "POST", "/bookings", "10.0.0.1")
+	recorder = doRateLimitRequest(router, "POST", "/bookings", "10.0.0.1")
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code, "Should reject a request over the limit")
+	assert.Equal(t, "30", recorder.Header().Get("Retry-After"), "Should say when a request will be allowed")
+	assert.Equal(t, "0", recorder.Header().Get("RateLimit-Remaining"), "Should report no requests left")
+	assert.Contains(t, recorder.Body.String(), `"rate_limited"`, "Should return the rate limited code")
+
+	recorder = doRateLimitRequest(router, "GET", "/classes", "10.0.0.1")
+	assert.Equal(t, http.StatusOK, recorder.Code, "Should count other route groups separately")
+	assert.Equal(t, "5", recorder.Header().Get("RateLimit-Limit"), "Should use the default limit for other groups")
+
+	recorder = doRateLimitRequest(router, "POST", "/bookings", "10.0.0.2")
+	assert.Equal(t, http.StatusCreated, recorder.Code, "Should count other clients separately")
+
+	*now = now.Add(30 * time.Second)
+	recorder = doRateLimitRequest(router, "POST", "/bookings", "10.0.0.1")
+	assert.Equal(t, http.StatusCreated, recorder.Code, "Should allow requests again once a token is refilled")
+}
+
+func TestRateLimitClients(t *testing.T) {
+	t.Run("API Key", func(t *testing.T) {
+		router, _, _ := setupRateLimitRouter(&Principal{Subject: "key-1", Role: RoleIntegration})
+
+		doRateLimitRequest(router, "POST", "/bookings", "10.0.0.1")
+		doRateLimitRequest(router, "POST", "/bookings", "10.0.0.2")
+		recorder := doRateLimitRequest(router, "POST", "/bookings", "10.0.0.3")
+		assert.Equal(t, http.StatusTooManyRequests, recorder.Code, "Should count an API key's requests together wherever they come from")
+	})
+
+	t.Run("User", func(t *testing.T) {
+		router, limiter, _ := setupRateLimitRouter(&Principal{Subject: "member-1", Role: RoleMember})
+
+		doRateLimitRequest(router, "POST", "/bookings", "10.0.0.1")
+		doRateLimitRequest(router, "POST", "/bookings", "10.0.0.2")
+		recorder := doRateLimitRequest(router, "POST", "/bookings", "10.0.0.3")
+		assert.Equal(t, http.StatusTooManyRequests, recorder.Code, "Should count a user's requests together wherever they come from")
+		assert.Contains(t, limiter.buckets, bucketKey{group: config.RouteGroupBookings, client: "user:member-1"}, "Should key the bucket by user")
+	})
+
+	t.Run("User Without Subject", func(t *testing.T) {
+		router, limiter, _ := setupRateLimitRouter(&Principal{Role: RoleMember, MemberID: "member-1"})
+
+		doRateLimitRequest(router, "POST", "/bookings", "10.0.0.1")
+		assert.Contains(t, limiter.buckets, bucketKey{group: config.RouteGroupBookings, client: "member:member-1"}, "Should key the bucket by member when the token has no subject")
+
+		router, limiter, _ = setupRateLimitRouter(&Principal{Role: RoleStaff})
+
+		doRateLimitRequest(router, "POST", "/bookings", "10.0.0.1")
+		assert.Contains(t, limiter.buckets, bucketKey{group: config.RouteGroupBookings, client: "ip:10.0.0.1"}, "Should key the bucket by IP when the token names nobody")
+	})
+
+	t.Run("Anonymous", func(t *testing.T) {
+		router, limiter, _ := setupRateLimitRouter(anonymousPrincipal)
+
+		doRateLimitRequest(router, "POST", "/bookings", "10.0.0.1")
+		assert.Contains(t, limiter.buckets, bucketKey{group: config.RouteGroupBookings, client: "ip:10.0.0.1"}, "Should key the bucket by IP while authentication is disabled")
+	})
+}
+
+func TestRateLimitIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	limiter := NewRateLimiter(config.RateLimitConfig{
+		Enabled: true,
+		Default: config.RateLimit{Requests: 1, Period: time.Minute},
+	})
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		SetPrincipal(c, &Principal{Subject: "member-1", Role: RoleMember})
+	})
+	router.GET("/classes", RateLimitIP(limiter, config.RouteGroupAuth), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	assert.Equal(t, http.StatusOK, doRateLimitRequest(router, "GET", "/classes", "10.0.0.1").Code)
+	assert.Equal(t, http.StatusOK, doRateLimitRequest(router, "GET", "/classes", "10.0.0.2").Code, "Should count each IP address separately")
+	assert.Equal(t, http.StatusTooManyRequests, doRateLimitRequest(router, "GET", "/classes", "10.0.0.1").Code, "Should limit the IP address whatever the principal")
+	assert.Contains(t, limiter.buckets, bucketKey{group: config.RouteGroupAuth, client: "ip:10.0.0.1"}, "Should key the bucket by IP")
+}
+
+func TestRateLimitEviction(t *testing.T) {
+	router, limiter, now := setupRateLimitRouter(nil)
+
+	// The booking bucket is emptied and takes a minute to refill, while
+	// the catalog bucket is one token short for 12 seconds
+	doRateLimitRequest(router, "POST", "/bookings", "10.0.0.1")
+	doRateLimitRequest(router, "POST", "/bookings", "10.0.0.1")
+	doRateLimitRequest(router, "GET", "/classes", "10.0.0.1")
+	assert.Equal(t, 0, limiter.EvictIdle(), "Should keep buckets that are still refilling")
+
+	*now = now.Add(30 * time.Second)
+	assert.Equal(t, 1, limiter.EvictIdle(), "Should evict the bucket that has refilled")
+	assert.Len(t, limiter.buckets, 1, "Should keep the bucket that is still refilling")
+
+	*now = now.Add(30 * time.Second)
+	assert.Equal(t, 1, limiter.EvictIdle(), "Should evict the remaining idle bucket")
+	assert.Empty(t, limiter.buckets, "Should hold no buckets once every client is idle")
+}
+
+func TestRateLimitDisabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/classes", RateLimit(nil, config.RouteGroupCatalog), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for i := 0; i < 10; i++ {
+		recorder := doRateLimitRequest(router, "GET", "/classes", "10.0.0.1")
+		assert.Equal(t, http.StatusOK, recorder.Code, "Should not limit requests without a limiter")
+		assert.Empty(t, recorder.Header().Get("RateLimit-Limit"), "Should not send rate limit headers without a limiter")
+	}
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"sync"
+	"time"
+)
+
+// APIKey is a machine credential used by integrations such as the website
+// booking widget. Only a hash of the key is stored; Prefix is the start of
+// the key, kept so people can tell their keys apart.
+type APIKey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Hash       string     `json:"hash"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Revoked reports whether the key can no longer be used
+func (k *APIKey) Revoked() bool {
+	return k.RevokedAt != nil
+}
+
+// APIKeyRepository handles API key data storage
+type APIKeyRepository struct {
+	keys   map[string]*APIKey
+	byHash map[string]*APIKey
+	mutex  sync.RWMutex
+}
+
+// NewAPIKeyRepository creates a new instance of APIKeyRepository
+func NewAPIKeyRepository() *APIKeyRepository {
+	return &APIKeyRepository{
+		keys:   make(map[string]*APIKey),
+		byHash: make(map[string]*APIKey),
+	}
+}
+
+// Create adds a new API key to the repository
+func (r *APIKeyRepository) Create(key *APIKey) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.keys[key.ID]; exists {
+		return ErrAPIKeyExists
+	}
+	if _, exists := r.byHash[key.Hash]; exists {
+		return ErrAPIKeyExists
+	}
+
+	r.keys[key.ID] = key
+	r.byHash[key.Hash] = key
+	return nil
+}
+
+// GetAll returns all API keys, including revoked ones
+func (r *APIKeyRepository) GetAll() ([]*APIKey, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	keys := make([]*APIKey, 0, len(r.keys))
+	for _, key := range r.keys {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// GetByID retrieves an API key by its ID
+func (r *APIKeyRepository) GetByID(id string) (*APIKey, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	key, exists := r.keys[id]
+	if !exists {
+		return nil, ErrAPIKeyNotFound
+	}
+
+	return key, nil
+}
+
+// GetByHash retrieves an API key by the hash of the key
+func (r *APIKeyRepository) GetByHash(hash string) (*APIKey, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	key, exists := r.byHash[hash]
+	if !exists {
+		return nil, ErrAPIKeyNotFound
+	}
+
+	return key, nil
+}
+
+// Update replaces an existing API key
+func (r *APIKeyRepository) Update(key *APIKey) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	existing, exists := r.keys[key.ID]
+	if !exists {
+		return ErrAPIKeyNotFound
+	}
+
+	delete(r.byHash, existing.Hash)
+	r.keys[key.ID] = key
+	r.byHash[key.Hash] = key
+	return nil
+}
@@ -1,30 +1,169 @@
 package repository
 
 import (
-	"errors"
+	"fmt"
+	"strings"
 	"sync"
 	"time"
 )
 
-// Booking represents a class booking by a studio member
+// BookingStatus is the lifecycle state of a booking
+type BookingStatus string
+
+// Booking statuses. Every status except cancelled takes up a place in the class.
+const (
+	BookingStatusConfirmed BookingStatus = "confirmed"
+	BookingStatusCancelled BookingStatus = "cancelled"
+	BookingStatusAttended  BookingStatus = "attended"
+	BookingStatusNoShow    BookingStatus = "no_show"
+)
+
+// Booking represents a class booking by a studio member. MemberID is empty
+// for bookings made by name only; MemberName always holds the name the
+// booking was made under.
 type Booking struct {
-	ID         string    `json:"id"`
-	MemberName string    `json:"member_name"`
-	ClassID    string    `json:"class_id,omitempty"`
-	Date       time.Time `json:"date"`
-	CreatedAt  time.Time `json:"created_at"`
+	ID                 string        `json:"id"`
+	MemberID           string        `json:"member_id,omitempty"`
+	MemberName         string        `json:"member_name"`
+	ClassID            string        `json:"class_id,omitempty"`
+	Date               time.Time     `json:"date"`
+	Status             BookingStatus `json:"status"`
+	CreatedAt          time.Time     `json:"created_at"`
+	CancelledAt        *time.Time    `json:"cancelled_at,omitempty"`
+	CancellationReason string        `json:"cancellation_reason,omitempty"`
+}
+
+// Active reports whether the booking takes up a place in its class
+func (b *Booking) Active() bool {
+	return b.Status != BookingStatusCancelled
+}
+
+// SameMember reports whether two bookings or waitlist entries belong to the
+// same member: the same registered member, or the same name when neither
+// was made by a registered member
+func SameMember(memberIDA, memberNameA, memberIDB, memberNameB string) bool {
+	if memberIDA != "" || memberIDB != "" {
+		return memberIDA == memberIDB
+	}
+	return memberNameA == memberNameB
+}
+
+// DuplicateBookingError identifies the booking a new or updated booking
+// would duplicate. It wraps ErrDuplicateBooking.
+type DuplicateBookingError struct {
+	ExistingID string
 }
 
-// BookingRepository handles booking data storage
+func (e *DuplicateBookingError) Error() string {
+	return fmt.Sprintf("%s: existing booking %s", ErrDuplicateBooking, e.ExistingID)
+}
+
+// Unwrap returns ErrDuplicateBooking
+func (e *DuplicateBookingError) Unwrap() error {
+	return ErrDuplicateBooking
+}
+
+// civilDate is a calendar day, independent of time of day. Bookings are
+// indexed by the day of their date in the date's own location.
+type civilDate struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+func civilDateOf(t time.Time) civilDate {
+	year, month, day := t.Date()
+	return civilDate{year: year, month: month, day: day}
+}
+
+// occurrence identifies a class on a given day
+type occurrence struct {
+	classID string
+	date    civilDate
+}
+
+// bookingIndex maps a key to the bookings with that key, by booking ID
+type bookingIndex[K comparable] map[K]map[string]*Booking
+
+func (idx bookingIndex[K]) add(key K, booking *Booking) {
+	bookings, ok := idx[key]
+	if !ok {
+		bookings = make(map[string]*Booking)
+		idx[key] = bookings
+	}
+	bookings[booking.ID] = booking
+}
+
+func (idx bookingIndex[K]) remove(key K, id string) {
+	bookings := idx[key]
+	delete(bookings, id)
+	if len(bookings) == 0 {
+		delete(idx, key)
+	}
+}
+
+// BookingRepository handles booking data storage. Besides the bookings by
+// ID it keeps indexes by date, class, class occurrence and member, updated
+// on every write, so lookups cost the size of the result rather than the
+// size of the booking history. Stored bookings must not be modified in
+// place, or the indexes go stale; store a modified copy with Update instead.
 type BookingRepository struct {
-	bookings map[string]*Booking
+	bookings     map[string]*Booking
+	byDate       bookingIndex[civilDate]
+	byClass      bookingIndex[string]
+	byOccurrence bookingIndex[occurrence]
+	// byMember holds each booking under its member ID, if any, and under
+	// its lowercased member name
+	byMember bookingIndex[string]
 	mutex    sync.RWMutex
 }
 
 // NewBookingRepository creates a new instance of BookingRepository
 func NewBookingRepository() *BookingRepository {
 	return &BookingRepository{
-		bookings: make(map[string]*Booking),
+		bookings:     make(map[string]*Booking),
+		byDate:       make(bookingIndex[civilDate]),
+		byClass:      make(bookingIndex[string]),
+		byOccurrence: make(bookingIndex[occurrence]),
+		byMember:     make(bookingIndex[string]),
+	}
+}
+
+// memberKeys returns the byMember keys of a booking
+func memberKeys(booking *Booking) []string {
+	keys := []string{"name:" + strings.ToLower(booking.MemberName)}
+	if booking.MemberID != "" {
+		keys = append(keys, "id:"+booking.MemberID)
+	}
+	return keys
+}
+
+// put stores a booking, replacing any booking with the same ID, and
+// updates the indexes; callers must hold the write lock
+func (r *BookingRepository) put(booking *Booking) {
+	if existing, ok := r.bookings[booking.ID]; ok {
+		r.unindex(existing)
+	}
+
+	r.bookings[booking.ID] = booking
+
+	date := civilDateOf(booking.Date)
+	r.byDate.add(date, booking)
+	r.byClass.add(booking.ClassID, booking)
+	r.byOccurrence.add(occurrence{classID: booking.ClassID, date: date}, booking)
+	for _, key := range memberKeys(booking) {
+		r.byMember.add(key, booking)
+	}
+}
+
+// unindex removes a booking from the indexes; callers must hold the write lock
+func (r *BookingRepository) unindex(booking *Booking) {
+	date := civilDateOf(booking.Date)
+	r.byDate.remove(date, booking.ID)
+	r.byClass.remove(booking.ClassID, booking.ID)
+	r.byOccurrence.remove(occurrence{classID: booking.ClassID, date: date}, booking.ID)
+	for _, key := range memberKeys(booking) {
+		r.byMember.remove(key, booking.ID)
 	}
 }
 
@@ -34,15 +173,113 @@ func (r *BookingRepository) Create(booking *Booking) error {
 	defer r.mutex.Unlock()
 
 	if _, exists := r.bookings[booking.ID]; exists {
-		return errors.New("booking with this ID already exists")
+		return ErrBookingExists
 	}
 
-	r.bookings[booking.ID] = booking
+	if err := r.checkDuplicate(booking); err != nil {
+		return err
+	}
+
+	r.put(booking)
+	return nil
+}
+
+// CreateWithCapacity adds a new booking only if the class has fewer than
+// capacity active bookings on the booking date. The check and the insert
+// happen under the same lock so concurrent requests cannot overbook a class.
+func (r *BookingRepository) CreateWithCapacity(booking *Booking, capacity int) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.bookings[booking.ID]; exists {
+		return ErrBookingExists
+	}
+
+	if err := r.checkDuplicate(booking); err != nil {
+		return err
+	}
+
+	if r.countByClassAndDate(booking.ClassID, booking.Date, "") >= capacity {
+		return ErrCapacityExceeded
+	}
+
+	r.put(booking)
+	return nil
+}
+
+// Update replaces an existing booking
+func (r *BookingRepository) Update(booking *Booking) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.bookings[booking.ID]; !exists {
+		return ErrBookingNotFound
+	}
+
+	if err := r.checkDuplicate(booking); err != nil {
+		return err
+	}
+
+	r.put(booking)
+	return nil
+}
+
+// UpdateConfirmed replaces a booking read as previous only while it is
+// still confirmed for the same class and date, so two requests cancelling
+// or moving the same booking cannot both succeed. A capacity of zero leaves
+// the class unlimited.
+func (r *BookingRepository) UpdateConfirmed(previous, booking *Booking, capacity int) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	existing, exists := r.bookings[booking.ID]
+	if !exists {
+		return ErrBookingNotFound
+	}
+	if err := CheckUnchanged(previous, existing); err != nil {
+		return err
+	}
+
+	if capacity <= 0 {
+		if err := r.checkDuplicate(booking); err != nil {
+			return err
+		}
+		r.put(booking)
+		return nil
+	}
+
+	return r.updateWithCapacity(booking, capacity)
+}
+
+// CheckUnchanged reports whether current, the stored booking, is still the
+// confirmed booking read as previous for the same class and date
+func CheckUnchanged(previous, current *Booking) error {
+	if current.Status != BookingStatusConfirmed {
+		return ErrBookingNotConfirmed
+	}
+	if current.ClassID != previous.ClassID || civilDateOf(current.Date) != civilDateOf(previous.Date) {
+		return ErrBookingChanged
+	}
+	return nil
+}
+
+// updateWithCapacity replaces an existing booking if it is no duplicate and
+// its class has room; callers must hold the mutex
+func (r *BookingRepository) updateWithCapacity(booking *Booking, capacity int) error {
+	if err := r.checkDuplicate(booking); err != nil {
+		return err
+	}
+
+	if r.countByClassAndDate(booking.ClassID, booking.Date, booking.ID) >= capacity {
+		return ErrCapacityExceeded
+	}
+
+	r.put(booking)
 	return nil
 }
 
 // GetAll returns all bookings
-func (r *BookingRepository) GetAll() []*Booking {
+func (r *BookingRepository) GetAll() ([]*Booking, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
@@ -50,7 +287,23 @@ func (r *BookingRepository) GetAll() []*Booking {
 	for _, booking := range r.bookings {
 		bookings = append(bookings, booking)
 	}
-	return bookings
+	return bookings, nil
+}
+
+// List returns the bookings matching filter, one page at a time, together
+// with the cursor of the next page or nil on the last page
+func (r *BookingRepository) List(filter BookingFilter, opts ListOptions) ([]*Booking, *Cursor, error) {
+	r.mutex.RLock()
+	bookings := make([]*Booking, 0)
+	r.candidates(filter, func(booking *Booking) {
+		if filter.Matches(booking) {
+			bookings = append(bookings, booking)
+		}
+	})
+	r.mutex.RUnlock()
+
+	page, next := paginate(bookings, opts, BookingSortValue, func(booking *Booking) string { return booking.ID })
+	return page, next, nil
 }
 
 // GetByID retrieves a booking by its ID
@@ -60,41 +313,133 @@ func (r *BookingRepository) GetByID(id string) (*Booking, error) {
 
 	booking, exists := r.bookings[id]
 	if !exists {
-		return nil, errors.New("booking not found")
+		return nil, ErrBookingNotFound
 	}
 
 	return booking, nil
 }
 
 // GetBookingsByDate retrieves all bookings for a specific date
-func (r *BookingRepository) GetBookingsByDate(date time.Time) []*Booking {
+func (r *BookingRepository) GetBookingsByDate(date time.Time) ([]*Booking, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
-	// Format date to compare only year, month, day
-	targetDate := date.Format("2006-01-02")
+	return collect(r.byDate[civilDateOf(date)]), nil
+}
 
-	bookings := make([]*Booking, 0)
-	for _, booking := range r.bookings {
-		if booking.Date.Format("2006-01-02") == targetDate {
-			bookings = append(bookings, booking)
-		}
+// GetByClassID retrieves all bookings for a specific class
+func (r *BookingRepository) GetByClassID(classID string) ([]*Booking, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return collect(r.byClass[classID]), nil
+}
+
+// Delete removes a booking by its ID
+func (r *BookingRepository) Delete(id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	booking, exists := r.bookings[id]
+	if !exists {
+		return ErrBookingNotFound
 	}
 
-	return bookings
+	r.unindex(booking)
+	delete(r.bookings, id)
+	return nil
 }
 
-// GetByClassID retrieves all bookings for a specific class
-func (r *BookingRepository) GetByClassID(classID string) ([]*Booking, error) {
+// CountByClassAndDate returns the number of active bookings for a class on a specific date
+func (r *BookingRepository) CountByClassAndDate(classID string, date time.Time) (int, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
-	bookings := make([]*Booking, 0)
-	for _, booking := range r.bookings {
-		if booking.ClassID == classID {
-			bookings = append(bookings, booking)
+	return r.countByClassAndDate(classID, date, ""), nil
+}
+
+// CheckDuplicate returns a *DuplicateBookingError if storing booking would
+// give its member a second active booking for the same class and date
+func (r *BookingRepository) CheckDuplicate(booking *Booking) error {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.checkDuplicate(booking)
+}
+
+// checkDuplicate implements CheckDuplicate; callers must hold the lock
+func (r *BookingRepository) checkDuplicate(booking *Booking) error {
+	if !booking.Active() {
+		return nil
+	}
+
+	key := occurrence{classID: booking.ClassID, date: civilDateOf(booking.Date)}
+	for _, existing := range r.byOccurrence[key] {
+		if existing.ID != booking.ID && existing.Active() &&
+			SameMember(existing.MemberID, existing.MemberName, booking.MemberID, booking.MemberName) {
+			return &DuplicateBookingError{ExistingID: existing.ID}
 		}
 	}
 
-	return bookings, nil
+	return nil
+}
+
+// countByClassAndDate counts active bookings for a class on a date, ignoring
+// the booking with excludeID; callers must hold the lock
+func (r *BookingRepository) countByClassAndDate(classID string, date time.Time, excludeID string) int {
+	count := 0
+	for _, booking := range r.byOccurrence[occurrence{classID: classID, date: civilDateOf(date)}] {
+		if booking.ID != excludeID && booking.Active() {
+			count++
+		}
+	}
+
+	return count
+}
+
+// candidates calls fn with a superset of the bookings matching filter,
+// taken from the most selective index the filter allows; callers must hold the lock
+func (r *BookingRepository) candidates(filter BookingFilter, fn func(*Booking)) {
+	visit := func(bookings map[string]*Booking) {
+		for _, booking := range bookings {
+			fn(booking)
+		}
+	}
+
+	switch {
+	case filter.ClassID != "" && !filter.From.IsZero() && filter.From.Equal(filter.To):
+		visit(r.byOccurrence[occurrence{classID: filter.ClassID, date: civilDateOf(filter.From)}])
+	case filter.ClassID != "":
+		visit(r.byClass[filter.ClassID])
+	case filter.MemberID != "":
+		visit(r.byMember["id:"+filter.MemberID])
+	case filter.Member != "":
+		// A booking made under a member ID can also match by name, so it
+		// may be visited twice; record what has been seen
+		seen := make(map[string]bool)
+		for _, key := range []string{"id:" + filter.Member, "name:" + strings.ToLower(filter.Member)} {
+			for id, booking := range r.byMember[key] {
+				if !seen[id] {
+					seen[id] = true
+					fn(booking)
+				}
+			}
+		}
+	case !filter.From.IsZero() && !filter.To.IsZero() && int(filter.To.Sub(filter.From).Hours()/24) < len(r.byDate):
+		// Walking the days of a short range beats scanning every booking
+		for day := filter.From; !day.After(filter.To); day = day.AddDate(0, 0, 1) {
+			visit(r.byDate[civilDateOf(day)])
+		}
+	default:
+		visit(r.bookings)
+	}
+}
+
+// collect returns the bookings of an index entry as a slice
+func collect(bookings map[string]*Booking) []*Booking {
+	result := make([]*Booking, 0, len(bookings))
+	for _, booking := range bookings {
+		result = append(result, booking)
+	}
+	return result
 }
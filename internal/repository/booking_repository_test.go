@@ -1,6 +1,9 @@
 package repository
 
 import (
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -32,11 +35,13 @@ func TestBookingRepository(t *testing.T) {
 	assert.Error(t, err, "Should return error for non-existent booking")
 
 	// Test GetAll
-	allBookings := repo.GetAll()
+	allBookings, err := repo.GetAll()
+	assert.NoError(t, err, "Should retrieve all bookings without error")
 	assert.Len(t, allBookings, 1, "Should return 1 booking")
 
 	// Test GetBookingsByDate
-	bookingsByDate := repo.GetBookingsByDate(booking.Date)
+	bookingsByDate, err := repo.GetBookingsByDate(booking.Date)
+	assert.NoError(t, err, "Should retrieve bookings by date without error")
 	assert.Len(t, bookingsByDate, 1, "Should return 1 booking for date")
 
 	// Test GetByClassID
@@ -55,3 +60,171 @@ func TestBookingRepository(t *testing.T) {
 	err = repo.Create(duplicateBooking)
 	assert.Error(t, err, "Should return error for duplicate booking ID")
 }
+
+func TestBookingRepositoryCreateWithCapacity(t *testing.T) {
+	repo := NewBookingRepository()
+	date := time.Date(2025, 4, 25, 0, 0, 0, 0, time.UTC)
+
+	err := repo.CreateWithCapacity(&Booking{ID: "booking-1", MemberName: "USER A", ClassID: "class-1", Date: date}, 2)
+	assert.NoError(t, err, "Should create first booking without error")
+
+	err = repo.CreateWithCapacity(&Booking{ID: "booking-2", MemberName: "USER B", ClassID: "class-1", Date: date}, 2)
+	assert.NoError(t, err, "Should create second booking without error")
+
+	err = repo.CreateWithCapacity(&Booking{ID: "booking-3", MemberName: "USER C", ClassID: "class-1", Date: date}, 2)
+	assert.ErrorIs(t, err, ErrCapacityExceeded, "Should reject booking once capacity is reached")
+
+	// Capacity is counted per day, so another date is still available
+	err = repo.CreateWithCapacity(&Booking{ID: "booking-4", MemberName: "USER C", ClassID: "class-1", Date: date.AddDate(0, 0, 1)}, 2)
+	assert.NoError(t, err, "Should create booking on a different date without error")
+
+	count, err := repo.CountByClassAndDate("class-1", date)
+	assert.NoError(t, err, "Should count bookings without error")
+	assert.Equal(t, 2, count, "Should count 2 bookings for the full date")
+}
+
+func TestBookingRepositoryCreateWithCapacityConcurrent(t *testing.T) {
+	repo := NewBookingRepository()
+	date := time.Date(2025, 4, 25, 0, 0, 0, 0, time.UTC)
+	capacity := 15
+
+	var wg sync.WaitGroup
+	var created atomic.Int32
+	for i := 0; i < 200; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			booking := &Booking{ID: fmt.Sprintf("booking-%d", i), MemberName: fmt.Sprintf("USER %d", i), ClassID: "class-1", Date: date}
+			if err := repo.CreateWithCapacity(booking, capacity); err == nil {
+				created.Add(1)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(capacity), created.Load(), "Should only create as many bookings as the capacity allows")
+	count, err := repo.CountByClassAndDate("class-1", date)
+	assert.NoError(t, err, "Should count bookings without error")
+	assert.Equal(t, capacity, count, "Should store exactly capacity bookings")
+}
+
+func TestBookingRepositoryIndexes(t *testing.T) {
+	repo := NewBookingRepository()
+	date := time.Date(2025, 4, 25, 0, 0, 0, 0, time.UTC)
+
+	booking := &Booking{ID: "booking-1", MemberID: "member-1", MemberName: "USER A", ClassID: "class-1", Date: date}
+	assert.NoError(t, repo.Create(booking), "Should create booking without error")
+
+	// Move the booking to another class and date
+	moved := *booking
+	moved.ClassID = "class-2"
+	moved.Date = date.AddDate(0, 0, 1)
+	assert.NoError(t, repo.Update(&moved), "Should update booking without error")
+
+	byOldDate, _ := repo.GetBookingsByDate(date)
+	assert.Empty(t, byOldDate, "Should no longer find the booking on its old date")
+	byOldClass, _ := repo.GetByClassID("class-1")
+	assert.Empty(t, byOldClass, "Should no longer find the booking in its old class")
+	count, _ := repo.CountByClassAndDate("class-1", date)
+	assert.Zero(t, count, "Should no longer count the booking for its old occurrence")
+
+	byNewDate, _ := repo.GetBookingsByDate(moved.Date)
+	assert.Len(t, byNewDate, 1, "Should find the booking on its new date")
+	byNewClass, _ := repo.GetByClassID("class-2")
+	assert.Len(t, byNewClass, 1, "Should find the booking in its new class")
+
+	// The same civil date in another location is the same day
+	local := time.Date(2025, 4, 26, 18, 0, 0, 0, time.FixedZone("UTC-8", -8*60*60))
+	byLocalDate, _ := repo.GetBookingsByDate(local)
+	assert.Len(t, byLocalDate, 1, "Should look up bookings by civil date")
+
+	byMember, _, _ := repo.List(BookingFilter{Member: "member-1"}, ListOptions{})
+	assert.Len(t, byMember, 1, "Should find the booking by member ID")
+	byName, _, _ := repo.List(BookingFilter{Member: "user a"}, ListOptions{})
+	assert.Len(t, byName, 1, "Should find the booking by member name")
+
+	assert.NoError(t, repo.Delete("booking-1"), "Should delete booking without error")
+	assert.Empty(t, repo.byDate, "Should remove the date index entry")
+	assert.Empty(t, repo.byClass, "Should remove the class index entry")
+	assert.Empty(t, repo.byOccurrence, "Should remove the occurrence index entry")
+	assert.Empty(t, repo.byMember, "Should remove the member index entries")
+}
+
+// benchmarkSizes are the booking history sizes lookups are measured at;
+// lookup cost should stay roughly flat as the history grows
+var benchmarkSizes = []int{10_000, 100_000, 1_000_000}
+
+// newBenchmarkRepository returns a repository with n bookings: 1,000 a day
+// spread over 100 classes, so a day's or a class occurrence's bookings are
+// the same in number whatever the size of the history
+func newBenchmarkRepository(b *testing.B, n int) *BookingRepository {
+	b.Helper()
+
+	repo := NewBookingRepository()
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < n; i++ {
+		booking := &Booking{
+			ID:         fmt.Sprintf("booking-%d", i),
+			MemberName: fmt.Sprintf("USER %d", i),
+			ClassID:    fmt.Sprintf("class-%d", i%100),
+			Date:       start.AddDate(0, 0, i/1000),
+			Status:     BookingStatusConfirmed,
+		}
+		if err := repo.Create(booking); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return repo
+}
+
+func BenchmarkBookingRepositoryLookups(b *testing.B) {
+	date := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	for _, n := range benchmarkSizes {
+		repo := newBenchmarkRepository(b, n)
+
+		b.Run(fmt.Sprintf("GetBookingsByDate/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := repo.GetBookingsByDate(date); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("CountByClassAndDate/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := repo.CountByClassAndDate("class-7", date); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		// Each booking goes to its own day so the occurrence size stays fixed
+		created := 0
+		b.Run(fmt.Sprintf("CreateWithCapacity/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				created++
+				booking := &Booking{
+					ID:         fmt.Sprintf("new-%d", created),
+					MemberName: "NEW MEMBER",
+					ClassID:    "class-7",
+					Date:       date.AddDate(0, 0, -created),
+					Status:     BookingStatusConfirmed,
+				}
+				if err := repo.CreateWithCapacity(booking, 15); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("ListClassOccurrence/%d", n), func(b *testing.B) {
+			filter := BookingFilter{ClassID: "class-7", From: date, To: date}
+			opts := ListOptions{Sort: []SortField{{Name: "date"}, {Name: "created_at"}}, Limit: 20}
+			for i := 0; i < b.N; i++ {
+				if _, _, err := repo.List(filter, opts); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -1,18 +1,36 @@
 package repository
 
 import (
-	"errors"
 	"sync"
 	"time"
+
+	"github.com/sanjaykishor/Glofox/internal/schedule"
 )
 
-// Class represents a fitness class
+// Class represents a fitness class. Schedule says on which days between
+// StartDate and EndDate the class meets; a class without one meets every day.
+// InstructorID is empty while nobody is assigned to teach it, and RoomID
+// while the class has no room.
 type Class struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	StartDate time.Time `json:"start_date"`
-	EndDate   time.Time `json:"end_date"`
-	Capacity  int       `json:"capacity"`
+	ID           string               `json:"id"`
+	Name         string               `json:"name"`
+	StartDate    time.Time            `json:"start_date"`
+	EndDate      time.Time            `json:"end_date"`
+	Capacity     int                  `json:"capacity"`
+	Schedule     *schedule.Recurrence `json:"schedule,omitempty"`
+	InstructorID string               `json:"instructor_id,omitempty"`
+	RoomID       string               `json:"room_id,omitempty"`
+}
+
+// OccursOn reports whether the class meets on date
+func (c *Class) OccursOn(date time.Time) bool {
+	return c.Schedule.OccursOn(c.StartDate, c.EndDate, date)
+}
+
+// Occurrences returns the meetings of the class between from and to,
+// inclusive, with start times read in the studio's time zone loc
+func (c *Class) Occurrences(from, to time.Time, loc *time.Location) []schedule.Occurrence {
+	return c.Schedule.Expand(c.StartDate, c.EndDate, from, to, loc)
 }
 
 // ClassRepository handles class data storage
@@ -34,7 +52,7 @@ func (r *ClassRepository) Create(class *Class) error {
 	defer r.mutex.Unlock()
 
 	if _, exists := r.classes[class.ID]; exists {
-		return errors.New("class with this ID already exists")
+		return ErrClassExists
 	}
 
 	r.classes[class.ID] = class
@@ -42,7 +60,7 @@ func (r *ClassRepository) Create(class *Class) error {
 }
 
 // GetAll returns all classes
-func (r *ClassRepository) GetAll() []*Class {
+func (r *ClassRepository) GetAll() ([]*Class, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
@@ -50,7 +68,23 @@ func (r *ClassRepository) GetAll() []*Class {
 	for _, class := range r.classes {
 		classes = append(classes, class)
 	}
-	return classes
+	return classes, nil
+}
+
+// List returns the classes matching filter, one page at a time, together
+// with the cursor of the next page or nil on the last page
+func (r *ClassRepository) List(filter ClassFilter, opts ListOptions) ([]*Class, *Cursor, error) {
+	r.mutex.RLock()
+	classes := make([]*Class, 0)
+	for _, class := range r.classes {
+		if filter.Matches(class) {
+			classes = append(classes, class)
+		}
+	}
+	r.mutex.RUnlock()
+
+	page, next := paginate(classes, opts, ClassSortValue, func(class *Class) string { return class.ID })
+	return page, next, nil
 }
 
 // GetByID retrieves a class by its ID
@@ -60,8 +94,34 @@ func (r *ClassRepository) GetByID(id string) (*Class, error) {
 
 	class, exists := r.classes[id]
 	if !exists {
-		return nil, errors.New("class not found")
+		return nil, ErrClassNotFound
 	}
 
 	return class, nil
 }
+
+// Update replaces an existing class
+func (r *ClassRepository) Update(class *Class) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.classes[class.ID]; !exists {
+		return ErrClassNotFound
+	}
+
+	r.classes[class.ID] = class
+	return nil
+}
+
+// Delete removes a class by its ID
+func (r *ClassRepository) Delete(id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.classes[id]; !exists {
+		return ErrClassNotFound
+	}
+
+	delete(r.classes, id)
+	return nil
+}
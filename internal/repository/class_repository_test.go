@@ -34,7 +34,8 @@ func TestClassRepository(t *testing.T) {
 	assert.Error(t, err, "Should return error for non-existent class")
 
 	// Test GetAll
-	allClasses := repo.GetAll()
+	allClasses, err := repo.GetAll()
+	assert.NoError(t, err, "Should retrieve all classes without error")
 	assert.Len(t, allClasses, 1, "Should return 1 class")
 
 	// Test duplicate ID
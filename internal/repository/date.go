@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// dateLayout is how calendar dates are written in JSON
+const dateLayout = "2006-01-02"
+
+// jsonDate is a calendar date, held as midnight UTC, that encodes as
+// YYYY-MM-DD. Rendering it as a timestamp would shift the day for anyone
+// reading it in a zone west of Greenwich.
+type jsonDate time.Time
+
+func (d jsonDate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Time(d).Format(dateLayout))
+}
+
+// UnmarshalJSON accepts a YYYY-MM-DD date or, as written by earlier
+// versions, an RFC 3339 timestamp at midnight
+func (d *jsonDate) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	layout := dateLayout
+	if strings.Contains(value, "T") {
+		layout = time.RFC3339
+	}
+	t, err := time.Parse(layout, value)
+	if err != nil {
+		return fmt.Errorf("invalid date %q: %w", value, err)
+	}
+
+	year, month, day := t.Date()
+	*d = jsonDate(time.Date(year, month, day, 0, 0, 0, 0, time.UTC))
+	return nil
+}
+
+// MarshalJSON writes the class's dates as YYYY-MM-DD
+func (c Class) MarshalJSON() ([]byte, error) {
+	type class Class
+	return json.Marshal(struct {
+		class
+		StartDate jsonDate `json:"start_date"`
+		EndDate   jsonDate `json:"end_date"`
+	}{class(c), jsonDate(c.StartDate), jsonDate(c.EndDate)})
+}
+
+// UnmarshalJSON reads a class written by MarshalJSON
+func (c *Class) UnmarshalJSON(data []byte) error {
+	type class Class
+	aux := struct {
+		*class
+		StartDate jsonDate `json:"start_date"`
+		EndDate   jsonDate `json:"end_date"`
+	}{class: (*class)(c)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	c.StartDate, c.EndDate = time.Time(aux.StartDate), time.Time(aux.EndDate)
+	return nil
+}
+
+// MarshalJSON writes the booking's date as YYYY-MM-DD
+func (b Booking) MarshalJSON() ([]byte, error) {
+	type booking Booking
+	return json.Marshal(struct {
+		booking
+		Date jsonDate `json:"date"`
+	}{booking(b), jsonDate(b.Date)})
+}
+
+// UnmarshalJSON reads a booking written by MarshalJSON
+func (b *Booking) UnmarshalJSON(data []byte) error {
+	type booking Booking
+	aux := struct {
+		*booking
+		Date jsonDate `json:"date"`
+	}{booking: (*booking)(b)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	b.Date = time.Time(aux.Date)
+	return nil
+}
+
+// MarshalJSON writes the entry's date as YYYY-MM-DD
+func (e WaitlistEntry) MarshalJSON() ([]byte, error) {
+	type entry WaitlistEntry
+	return json.Marshal(struct {
+		entry
+		Date jsonDate `json:"date"`
+	}{entry(e), jsonDate(e.Date)})
+}
+
+// UnmarshalJSON reads a waitlist entry written by MarshalJSON
+func (e *WaitlistEntry) UnmarshalJSON(data []byte) error {
+	type entry WaitlistEntry
+	aux := struct {
+		*entry
+		Date jsonDate `json:"date"`
+	}{entry: (*entry)(e)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	e.Date = time.Time(aux.Date)
+	return nil
+}
+
+// MarshalJSON writes the occurrence's date as YYYY-MM-DD
+func (o ClassOccurrence) MarshalJSON() ([]byte, error) {
+	type occurrence ClassOccurrence
+	return json.Marshal(struct {
+		occurrence
+		Date jsonDate `json:"date"`
+	}{occurrence(o), jsonDate(o.Date)})
+}
+
+// UnmarshalJSON reads an occurrence written by MarshalJSON
+func (o *ClassOccurrence) UnmarshalJSON(data []byte) error {
+	type occurrence ClassOccurrence
+	aux := struct {
+		*occurrence
+		Date jsonDate `json:"date"`
+	}{occurrence: (*occurrence)(o)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	o.Date = time.Time(aux.Date)
+	return nil
+}
+
+// MarshalJSON writes the override's date as YYYY-MM-DD
+func (o OccurrenceOverride) MarshalJSON() ([]byte, error) {
+	type override OccurrenceOverride
+	return json.Marshal(struct {
+		override
+		Date jsonDate `json:"date"`
+	}{override(o), jsonDate(o.Date)})
+}
+
+// UnmarshalJSON reads an override written by MarshalJSON
+func (o *OccurrenceOverride) UnmarshalJSON(data []byte) error {
+	type override OccurrenceOverride
+	aux := struct {
+		*override
+		Date jsonDate `json:"date"`
+	}{override: (*override)(o)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	o.Date = time.Time(aux.Date)
+	return nil
+}
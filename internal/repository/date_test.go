@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDateJSON(t *testing.T) {
+	booking := &Booking{
+		ID:         "booking-1",
+		MemberName: "Jane Smith",
+		ClassID:    "class-1",
+		Date:       time.Date(2025, 4, 25, 0, 0, 0, 0, time.UTC),
+		Status:     BookingStatusConfirmed,
+		CreatedAt:  time.Date(2025, 4, 20, 9, 30, 0, 0, time.UTC),
+	}
+
+	data, err := json.Marshal(booking)
+	require.NoError(t, err, "Should marshal booking without error")
+
+	var fields map[string]any
+	require.NoError(t, json.Unmarshal(data, &fields), "Should parse booking JSON without error")
+	assert.Equal(t, "2025-04-25", fields["date"], "Date should be written as a calendar date")
+	assert.Equal(t, "2025-04-20T09:30:00Z", fields["created_at"], "Timestamps should keep their time of day")
+	assert.Equal(t, "Jane Smith", fields["member_name"], "Other fields should be written as before")
+
+	var decoded Booking
+	require.NoError(t, json.Unmarshal(data, &decoded), "Should unmarshal booking without error")
+	assert.Equal(t, booking.Date, decoded.Date, "Date should round-trip")
+	assert.Equal(t, booking.MemberName, decoded.MemberName, "Other fields should round-trip")
+
+	// Earlier versions wrote dates as timestamps at midnight UTC
+	var class Class
+	err = json.Unmarshal([]byte(`{"id":"class-1","start_date":"2025-04-01T00:00:00Z","end_date":"2025-04-30"}`), &class)
+	require.NoError(t, err, "Should unmarshal class without error")
+	assert.Equal(t, time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC), class.StartDate, "Should read a timestamp date")
+	assert.Equal(t, time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC), class.EndDate, "Should read a calendar date")
+
+	err = json.Unmarshal([]byte(`{"id":"class-1","start_date":"April 1st"}`), &class)
+	assert.Error(t, err, "Should return error for an invalid date")
+}
@@ -0,0 +1,78 @@
+package repository
+
+import "errors"
+
+// Error kinds. Every domain error wraps one of these, so callers can
+// handle a whole category with errors.Is without knowing each error.
+var (
+	// ErrNotFound is wrapped by errors reporting a missing record
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is wrapped by errors reporting a request that conflicts
+	// with the current state, such as a duplicate ID or a full class
+	ErrConflict = errors.New("conflict")
+)
+
+// Error is a domain error with a stable, machine-readable code that API
+// clients can rely on even if the message is reworded
+type Error struct {
+	Code    string
+	Message string
+	Kind    error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the kind of the error
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// NewNotFoundError returns a domain error of kind ErrNotFound
+func NewNotFoundError(code, message string) *Error {
+	return &Error{Code: code, Message: message, Kind: ErrNotFound}
+}
+
+// NewConflictError returns a domain error of kind ErrConflict
+func NewConflictError(code, message string) *Error {
+	return &Error{Code: code, Message: message, Kind: ErrConflict}
+}
+
+// Repository errors
+var (
+	ErrClassNotFound         = NewNotFoundError("class_not_found", "class not found")
+	ErrClassExists           = NewConflictError("class_exists", "class with this ID already exists")
+	ErrBookingNotFound       = NewNotFoundError("booking_not_found", "booking not found")
+	ErrBookingExists         = NewConflictError("booking_exists", "booking with this ID already exists")
+	ErrWaitlistEntryNotFound = NewNotFoundError("waitlist_entry_not_found", "waitlist entry not found")
+	ErrWaitlistEntryExists   = NewConflictError("waitlist_entry_exists", "waitlist entry with this ID already exists")
+	ErrMemberNotFound        = NewNotFoundError("member_not_found", "member not found")
+	ErrMemberExists          = NewConflictError("member_exists", "member with this ID already exists")
+	ErrInstructorNotFound    = NewNotFoundError("instructor_not_found", "instructor not found")
+	ErrInstructorExists      = NewConflictError("instructor_exists", "instructor with this ID already exists")
+	ErrLocationNotFound      = NewNotFoundError("location_not_found", "location not found")
+	ErrLocationExists        = NewConflictError("location_exists", "location with this ID already exists")
+	ErrRoomNotFound          = NewNotFoundError("room_not_found", "room not found")
+	ErrRoomExists            = NewConflictError("room_exists", "room with this ID already exists")
+	ErrAPIKeyNotFound        = NewNotFoundError("api_key_not_found", "API key not found")
+	ErrAPIKeyExists          = NewConflictError("api_key_exists", "API key with this ID already exists")
+
+	// ErrOccurrenceOverrideNotFound is returned when an occurrence has no changes of its own
+	ErrOccurrenceOverrideNotFound = NewNotFoundError("occurrence_override_not_found", "occurrence has not been modified")
+
+	// ErrCapacityExceeded is returned when a class has no remaining capacity on the requested date
+	ErrCapacityExceeded = NewConflictError("capacity_exceeded", "class is full for this date")
+
+	// ErrBookingNotConfirmed is returned when changing a booking that is no
+	// longer confirmed, such as one cancelled in the meantime
+	ErrBookingNotConfirmed = NewConflictError("booking_not_confirmed", "only confirmed bookings can be cancelled or rescheduled")
+
+	// ErrBookingChanged is returned when a booking was moved by another
+	// request after it was read
+	ErrBookingChanged = NewConflictError("booking_changed", "booking was changed by another request, fetch it and try again")
+
+	// ErrDuplicateBooking is returned when a member already has an active
+	// booking for the same class on the same date
+	ErrDuplicateBooking = NewConflictError("duplicate_booking", "member already has a booking for this class on this date")
+)
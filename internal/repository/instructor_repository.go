@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"sync"
+	"time"
+)
+
+// Instructor represents a person who teaches classes at the studio
+type Instructor struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email,omitempty"`
+	Phone     string    `json:"phone,omitempty"`
+	Bio       string    `json:"bio,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// InstructorRepository handles instructor data storage
+type InstructorRepository struct {
+	instructors map[string]*Instructor
+	mutex       sync.RWMutex
+}
+
+// NewInstructorRepository creates a new instance of InstructorRepository
+func NewInstructorRepository() *InstructorRepository {
+	return &InstructorRepository{
+		instructors: make(map[string]*Instructor),
+	}
+}
+
+// Create adds a new instructor to the repository
+func (r *InstructorRepository) Create(instructor *Instructor) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.instructors[instructor.ID]; exists {
+		return ErrInstructorExists
+	}
+
+	r.instructors[instructor.ID] = instructor
+	return nil
+}
+
+// GetAll returns all instructors
+func (r *InstructorRepository) GetAll() ([]*Instructor, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	instructors := make([]*Instructor, 0, len(r.instructors))
+	for _, instructor := range r.instructors {
+		instructors = append(instructors, instructor)
+	}
+	return instructors, nil
+}
+
+// GetByID retrieves an instructor by its ID
+func (r *InstructorRepository) GetByID(id string) (*Instructor, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	instructor, exists := r.instructors[id]
+	if !exists {
+		return nil, ErrInstructorNotFound
+	}
+
+	return instructor, nil
+}
+
+// Update replaces an existing instructor
+func (r *InstructorRepository) Update(instructor *Instructor) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.instructors[instructor.ID]; !exists {
+		return ErrInstructorNotFound
+	}
+
+	r.instructors[instructor.ID] = instructor
+	return nil
+}
+
+// Delete removes an instructor by its ID
+func (r *InstructorRepository) Delete(id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.instructors[id]; !exists {
+		return ErrInstructorNotFound
+	}
+
+	delete(r.instructors, id)
+	return nil
+}
@@ -0,0 +1,620 @@
+// Package journal makes the in-memory repositories durable. Every write is
+// appended to a checksummed log file before it is applied in memory, the
+// full state is periodically compacted into a snapshot, and both are
+// replayed when the journal is opened.
+package journal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sanjaykishor/Glofox/internal/repository"
+)
+
+const (
+	logFileName      = "journal.log"
+	snapshotFileName = "snapshot.json"
+
+	// headerSize is the length and CRC32 checksum preceding each record
+	headerSize = 8
+	// maxRecordSize guards against allocating huge buffers for a corrupted length
+	maxRecordSize = 16 << 20
+)
+
+// Record operations
+const (
+	opCreateClass            = "class.create"
+	opUpdateClass            = "class.update"
+	opDeleteClass            = "class.delete"
+	opCreateBooking          = "booking.create"
+	opUpdateBooking          = "booking.update"
+	opDeleteBooking          = "booking.delete"
+	opCreateWaitlist         = "waitlist.create"
+	opDeleteWaitlist         = "waitlist.delete"
+	opDeleteClassWaitlist    = "waitlist.delete_class"
+	opCreateMember           = "member.create"
+	opUpdateMember           = "member.update"
+	opDeleteMember           = "member.delete"
+	opSaveOccurrence         = "occurrence.save"
+	opDeleteOccurrence       = "occurrence.delete"
+	opDeleteClassOccurrences = "occurrence.delete_class"
+	opCreateInstructor       = "instructor.create"
+	opUpdateInstructor       = "instructor.update"
+	opDeleteInstructor       = "instructor.delete"
+	opCreateLocation         = "location.create"
+	opUpdateLocation         = "location.update"
+	opDeleteLocation         = "location.delete"
+	opCreateRoom             = "room.create"
+	opUpdateRoom             = "room.update"
+	opDeleteRoom             = "room.delete"
+	opCreateAPIKey           = "api_key.create"
+	opUpdateAPIKey           = "api_key.update"
+)
+
+// record is a single journaled write. Deletes only carry the ID, except
+// occurrence deletes, which carry the class ID and date of the override.
+type record struct {
+	Op         string                         `json:"op"`
+	ID         string                         `json:"id,omitempty"`
+	Class      *repository.Class              `json:"class,omitempty"`
+	Booking    *repository.Booking            `json:"booking,omitempty"`
+	Waitlist   *repository.WaitlistEntry      `json:"waitlist,omitempty"`
+	Member     *repository.Member             `json:"member,omitempty"`
+	Occurrence *repository.OccurrenceOverride `json:"occurrence,omitempty"`
+	Instructor *repository.Instructor         `json:"instructor,omitempty"`
+	Location   *repository.Location           `json:"location,omitempty"`
+	Room       *repository.Room               `json:"room,omitempty"`
+	APIKey     *repository.APIKey             `json:"api_key,omitempty"`
+}
+
+// snapshot is the compacted state of every repository
+type snapshot struct {
+	CreatedAt   time.Time                        `json:"created_at"`
+	Classes     []*repository.Class              `json:"classes"`
+	Bookings    []*repository.Booking            `json:"bookings"`
+	Waitlist    []*repository.WaitlistEntry      `json:"waitlist"`
+	Members     []*repository.Member             `json:"members"`
+	Occurrences []*repository.OccurrenceOverride `json:"occurrences"`
+	Instructors []*repository.Instructor         `json:"instructors"`
+	Locations   []*repository.Location           `json:"locations"`
+	Rooms       []*repository.Room               `json:"rooms"`
+	APIKeys     []*repository.APIKey             `json:"api_keys"`
+}
+
+// Journal owns the log file and the in-memory repositories it protects
+type Journal struct {
+	dir         string
+	file        *os.File
+	classes     *repository.ClassRepository
+	bookings    *repository.BookingRepository
+	waitlist    *repository.WaitlistRepository
+	members     *repository.MemberRepository
+	occurrences *repository.OccurrenceRepository
+	instructors *repository.InstructorRepository
+	locations   *repository.LocationRepository
+	rooms       *repository.RoomRepository
+	apiKeys     *repository.APIKeyRepository
+
+	// mutex serializes every write so records are appended in the order
+	// they are applied and snapshots see a consistent state
+	mutex sync.Mutex
+}
+
+// Open loads the latest snapshot in dir, replays the log on top of it and
+// prepares the log for appending. A corrupted or partially written tail
+// record is truncated so the journal stays usable.
+func Open(dir string) (*Journal, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	j := &Journal{
+		dir:         dir,
+		classes:     repository.NewClassRepository(),
+		bookings:    repository.NewBookingRepository(),
+		waitlist:    repository.NewWaitlistRepository(),
+		members:     repository.NewMemberRepository(),
+		occurrences: repository.NewOccurrenceRepository(),
+		instructors: repository.NewInstructorRepository(),
+		locations:   repository.NewLocationRepository(),
+		rooms:       repository.NewRoomRepository(),
+		apiKeys:     repository.NewAPIKeyRepository(),
+	}
+
+	if err := j.loadSnapshot(); err != nil {
+		return nil, fmt.Errorf("failed to load snapshot: %w", err)
+	}
+
+	file, err := os.OpenFile(filepath.Join(dir, logFileName), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := j.replay(file); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to replay journal: %w", err)
+	}
+
+	j.file = file
+	return j, nil
+}
+
+// Stores returns the journaled repositories
+func (j *Journal) Stores() *repository.Stores {
+	return &repository.Stores{
+		Classes:     &classStore{ClassRepository: j.classes, journal: j},
+		Bookings:    &bookingStore{BookingRepository: j.bookings, journal: j},
+		Waitlist:    &waitlistStore{WaitlistRepository: j.waitlist, journal: j},
+		Members:     &memberStore{MemberRepository: j.members, journal: j},
+		Occurrences: &occurrenceStore{OccurrenceRepository: j.occurrences, journal: j},
+		Instructors: &instructorStore{InstructorRepository: j.instructors, journal: j},
+		Locations:   &locationStore{LocationRepository: j.locations, journal: j},
+		Rooms:       &roomStore{RoomRepository: j.rooms, journal: j},
+		APIKeys:     &apiKeyStore{APIKeyRepository: j.apiKeys, journal: j},
+	}
+}
+
+// Snapshot writes the current state to the snapshot file and empties the
+// log. The snapshot is written to a temporary file and renamed into place
+// so a crash never leaves a partial snapshot behind.
+func (j *Journal) Snapshot() error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	return j.snapshot()
+}
+
+// StartSnapshots takes a snapshot every interval until the returned stop
+// function is called
+func (j *Journal) StartSnapshots(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := j.Snapshot(); err != nil {
+					slog.Error("Failed to write journal snapshot", "error", err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
+
+// Close takes a final snapshot and closes the log file
+func (j *Journal) Close() error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	snapshotErr := j.snapshot()
+	return errors.Join(snapshotErr, j.file.Close())
+}
+
+// snapshot implements Snapshot; callers must hold the mutex
+func (j *Journal) snapshot() error {
+	classes, err := j.classes.GetAll()
+	if err != nil {
+		return err
+	}
+
+	bookings, err := j.bookings.GetAll()
+	if err != nil {
+		return err
+	}
+
+	waitlist, err := j.waitlist.GetAll()
+	if err != nil {
+		return err
+	}
+
+	members, err := j.members.GetAll()
+	if err != nil {
+		return err
+	}
+
+	occurrences, err := j.occurrences.GetAll()
+	if err != nil {
+		return err
+	}
+
+	instructors, err := j.instructors.GetAll()
+	if err != nil {
+		return err
+	}
+
+	locations, err := j.locations.GetAll()
+	if err != nil {
+		return err
+	}
+
+	rooms, err := j.rooms.GetAll()
+	if err != nil {
+		return err
+	}
+
+	apiKeys, err := j.apiKeys.GetAll()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(snapshot{
+		CreatedAt:   time.Now().UTC(),
+		Classes:     classes,
+		Bookings:    bookings,
+		Waitlist:    waitlist,
+		Members:     members,
+		Occurrences: occurrences,
+		Instructors: instructors,
+		Locations:   locations,
+		Rooms:       rooms,
+		APIKeys:     apiKeys,
+	})
+	if err != nil {
+		return err
+	}
+
+	tmpPath := filepath.Join(j.dir, snapshotFileName+".tmp")
+	if err := writeFileSync(tmpPath, data); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpPath, filepath.Join(j.dir, snapshotFileName)); err != nil {
+		return err
+	}
+
+	// Everything in the log is now part of the snapshot
+	if err := j.file.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := j.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	return j.file.Sync()
+}
+
+// append writes a record to the log and syncs it to disk; callers must hold the mutex
+func (j *Journal) append(rec record) error {
+	payload, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	buf := make([]byte, headerSize+len(payload))
+	binary.BigEndian.PutUint32(buf[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(buf[4:8], crc32.ChecksumIEEE(payload))
+	copy(buf[headerSize:], payload)
+
+	if _, err := j.file.Write(buf); err != nil {
+		return err
+	}
+
+	return j.file.Sync()
+}
+
+// loadSnapshot populates the repositories from the snapshot file, if any
+func (j *Journal) loadSnapshot() error {
+	data, err := os.ReadFile(filepath.Join(j.dir, snapshotFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return err
+	}
+
+	for _, class := range snap.Classes {
+		if err := j.classes.Create(class); err != nil {
+			return err
+		}
+	}
+
+	for _, booking := range snap.Bookings {
+		if err := restoreBooking(booking, j.bookings.Create); err != nil {
+			return err
+		}
+	}
+
+	for _, entry := range snap.Waitlist {
+		if err := j.waitlist.Create(entry); err != nil {
+			return err
+		}
+	}
+
+	for _, member := range snap.Members {
+		if err := j.members.Create(member); err != nil {
+			return err
+		}
+	}
+
+	for _, override := range snap.Occurrences {
+		if err := j.occurrences.Save(override); err != nil {
+			return err
+		}
+	}
+
+	for _, instructor := range snap.Instructors {
+		if err := j.instructors.Create(instructor); err != nil {
+			return err
+		}
+	}
+
+	for _, location := range snap.Locations {
+		if err := j.locations.Create(location); err != nil {
+			return err
+		}
+	}
+
+	for _, room := range snap.Rooms {
+		if err := j.rooms.Create(room); err != nil {
+			return err
+		}
+	}
+
+	for _, key := range snap.APIKeys {
+		if err := j.apiKeys.Create(key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// replay applies every valid record in the log and truncates the file at
+// the first record that is incomplete or fails its checksum
+func (j *Journal) replay(file *os.File) error {
+	reader := bufio.NewReader(file)
+	var offset int64
+	header := make([]byte, headerSize)
+
+	for {
+		rec, size, err := readRecord(reader, header)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			slog.Warn("Truncating corrupted journal", "offset", offset, "error", err)
+			if err := file.Truncate(offset); err != nil {
+				return err
+			}
+			break
+		}
+
+		if err := j.apply(rec); err != nil {
+			return fmt.Errorf("record at offset %d: %w", offset, err)
+		}
+		offset += size
+	}
+
+	_, err := file.Seek(offset, io.SeekStart)
+	return err
+}
+
+// readRecord reads and verifies the next record. It returns io.EOF only
+// when the log ends cleanly on a record boundary.
+func readRecord(reader io.Reader, header []byte) (record, int64, error) {
+	var rec record
+
+	n, err := io.ReadFull(reader, header)
+	if errors.Is(err, io.EOF) && n == 0 {
+		return rec, 0, io.EOF
+	}
+	if err != nil {
+		return rec, 0, errors.New("incomplete record header")
+	}
+
+	length := binary.BigEndian.Uint32(header[0:4])
+	checksum := binary.BigEndian.Uint32(header[4:8])
+	if length > maxRecordSize {
+		return rec, 0, fmt.Errorf("record length %d exceeds maximum", length)
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		return rec, 0, errors.New("incomplete record payload")
+	}
+
+	if crc32.ChecksumIEEE(payload) != checksum {
+		return rec, 0, errors.New("checksum mismatch")
+	}
+
+	if err := json.Unmarshal(payload, &rec); err != nil {
+		return rec, 0, err
+	}
+
+	return rec, int64(headerSize + len(payload)), nil
+}
+
+// apply replays a record against the in-memory repositories. Records that
+// are already reflected in the snapshot (because the process stopped
+// between writing a snapshot and emptying the log) are skipped, which
+// makes replaying the same record twice harmless.
+func (j *Journal) apply(rec record) error {
+	switch rec.Op {
+	case opCreateClass:
+		if _, err := j.classes.GetByID(rec.Class.ID); err == nil {
+			return nil
+		}
+		return j.classes.Create(rec.Class)
+	case opUpdateClass:
+		if _, err := j.classes.GetByID(rec.Class.ID); err != nil {
+			return nil
+		}
+		return j.classes.Update(rec.Class)
+	case opDeleteClass:
+		if _, err := j.classes.GetByID(rec.ID); err != nil {
+			return nil
+		}
+		return j.classes.Delete(rec.ID)
+	case opCreateBooking:
+		if _, err := j.bookings.GetByID(rec.Booking.ID); err == nil {
+			return nil
+		}
+		return restoreBooking(rec.Booking, j.bookings.Create)
+	case opUpdateBooking:
+		if _, err := j.bookings.GetByID(rec.Booking.ID); err != nil {
+			return nil
+		}
+		return restoreBooking(rec.Booking, j.bookings.Update)
+	case opDeleteBooking:
+		if _, err := j.bookings.GetByID(rec.ID); err != nil {
+			return nil
+		}
+		return j.bookings.Delete(rec.ID)
+	case opCreateWaitlist:
+		if _, err := j.waitlist.GetByID(rec.Waitlist.ID); err == nil {
+			return nil
+		}
+		return j.waitlist.Create(rec.Waitlist)
+	case opDeleteWaitlist:
+		if _, err := j.waitlist.GetByID(rec.ID); err != nil {
+			return nil
+		}
+		return j.waitlist.Delete(rec.ID)
+	case opDeleteClassWaitlist:
+		return j.waitlist.DeleteByClass(rec.ID)
+	case opCreateMember:
+		if _, err := j.members.GetByID(rec.Member.ID); err == nil {
+			return nil
+		}
+		return j.members.Create(rec.Member)
+	case opUpdateMember:
+		if _, err := j.members.GetByID(rec.Member.ID); err != nil {
+			return nil
+		}
+		return j.members.Update(rec.Member)
+	case opDeleteMember:
+		if _, err := j.members.GetByID(rec.ID); err != nil {
+			return nil
+		}
+		return j.members.Delete(rec.ID)
+	case opCreateInstructor:
+		if _, err := j.instructors.GetByID(rec.Instructor.ID); err == nil {
+			return nil
+		}
+		return j.instructors.Create(rec.Instructor)
+	case opUpdateInstructor:
+		if _, err := j.instructors.GetByID(rec.Instructor.ID); err != nil {
+			return nil
+		}
+		return j.instructors.Update(rec.Instructor)
+	case opDeleteInstructor:
+		if _, err := j.instructors.GetByID(rec.ID); err != nil {
+			return nil
+		}
+		return j.instructors.Delete(rec.ID)
+	case opCreateLocation:
+		if _, err := j.locations.GetByID(rec.Location.ID); err == nil {
+			return nil
+		}
+		return j.locations.Create(rec.Location)
+	case opUpdateLocation:
+		if _, err := j.locations.GetByID(rec.Location.ID); err != nil {
+			return nil
+		}
+		return j.locations.Update(rec.Location)
+	case opDeleteLocation:
+		if _, err := j.locations.GetByID(rec.ID); err != nil {
+			return nil
+		}
+		return j.locations.Delete(rec.ID)
+	case opCreateRoom:
+		if _, err := j.rooms.GetByID(rec.Room.ID); err == nil {
+			return nil
+		}
+		return j.rooms.Create(rec.Room)
+	case opUpdateRoom:
+		if _, err := j.rooms.GetByID(rec.Room.ID); err != nil {
+			return nil
+		}
+		return j.rooms.Update(rec.Room)
+	case opDeleteRoom:
+		if _, err := j.rooms.GetByID(rec.ID); err != nil {
+			return nil
+		}
+		return j.rooms.Delete(rec.ID)
+	case opCreateAPIKey:
+		if _, err := j.apiKeys.GetByID(rec.APIKey.ID); err == nil {
+			return nil
+		}
+		return j.apiKeys.Create(rec.APIKey)
+	case opUpdateAPIKey:
+		if _, err := j.apiKeys.GetByID(rec.APIKey.ID); err != nil {
+			return nil
+		}
+		return j.apiKeys.Update(rec.APIKey)
+	case opSaveOccurrence:
+		// Saving is idempotent, so a record already in the snapshot is harmless
+		return j.occurrences.Save(rec.Occurrence)
+	case opDeleteOccurrence:
+		if _, err := j.occurrences.Get(rec.Occurrence.ClassID, rec.Occurrence.Date); err != nil {
+			return nil
+		}
+		return j.occurrences.Delete(rec.Occurrence.ClassID, rec.Occurrence.Date)
+	case opDeleteClassOccurrences:
+		return j.occurrences.DeleteByClass(rec.ID)
+	default:
+		return fmt.Errorf("unknown journal operation %q", rec.Op)
+	}
+}
+
+// restoreBooking stores a booking read back from the snapshot or log.
+// Duplicate bookings journaled before they were rejected are kept as
+// cancelled rather than stopping the journal from opening.
+func restoreBooking(booking *repository.Booking, store func(*repository.Booking) error) error {
+	err := store(booking)
+
+	var duplicate *repository.DuplicateBookingError
+	if !errors.As(err, &duplicate) {
+		return err
+	}
+
+	slog.Warn("Cancelling duplicate booking", "booking_id", booking.ID, "existing_booking_id", duplicate.ExistingID)
+	cancelledAt := time.Now().UTC()
+	booking.Status = repository.BookingStatusCancelled
+	booking.CancelledAt = &cancelledAt
+	booking.CancellationReason = "duplicate booking"
+
+	return store(booking)
+}
+
+// writeFileSync writes data to path and flushes it to disk
+func writeFileSync(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
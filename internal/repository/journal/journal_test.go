@@ -0,0 +1,284 @@
+package journal
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sanjaykishor/Glofox/internal/repository"
+	"github.com/sanjaykishor/Glofox/internal/repository/storetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openTestJournal opens a journal in a fresh temporary directory
+func openTestJournal(t *testing.T) *Journal {
+	j, err := Open(t.TempDir())
+	require.NoError(t, err, "Should open journal without error")
+	t.Cleanup(func() { j.Close() })
+	return j
+}
+
+func testClass(id string) *repository.Class {
+	return &repository.Class{
+		ID:        id,
+		Name:      "Yoga",
+		StartDate: time.Date(2025, 4, 25, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC),
+		Capacity:  15,
+	}
+}
+
+func testBooking(id string) *repository.Booking {
+	return &repository.Booking{
+		ID:         id,
+		MemberName: "USER A",
+		ClassID:    "class-1",
+		Date:       time.Date(2025, 4, 25, 0, 0, 0, 0, time.UTC),
+		CreatedAt:  time.Date(2025, 4, 24, 14, 30, 45, 0, time.UTC),
+	}
+}
+
+func TestJournalClassStore(t *testing.T) {
+	storetest.RunClassStoreTests(t, func(t *testing.T) repository.ClassStore {
+		return openTestJournal(t).Stores().Classes
+	})
+}
+
+func TestJournalBookingStore(t *testing.T) {
+	storetest.RunBookingStoreTests(t, func(t *testing.T) repository.BookingStore {
+		return openTestJournal(t).Stores().Bookings
+	})
+}
+
+func TestJournalWaitlistStore(t *testing.T) {
+	storetest.RunWaitlistStoreTests(t, func(t *testing.T) repository.WaitlistStore {
+		return openTestJournal(t).Stores().Waitlist
+	})
+}
+
+func TestJournalMemberStore(t *testing.T) {
+	storetest.RunMemberStoreTests(t, func(t *testing.T) repository.MemberStore {
+		return openTestJournal(t).Stores().Members
+	})
+}
+
+func TestJournalOccurrenceStore(t *testing.T) {
+	storetest.RunOccurrenceStoreTests(t, func(t *testing.T) repository.OccurrenceStore {
+		return openTestJournal(t).Stores().Occurrences
+	})
+}
+
+func TestJournalInstructorStore(t *testing.T) {
+	storetest.RunInstructorStoreTests(t, func(t *testing.T) repository.InstructorStore {
+		return openTestJournal(t).Stores().Instructors
+	})
+}
+
+func TestJournalLocationStore(t *testing.T) {
+	storetest.RunLocationStoreTests(t, func(t *testing.T) repository.LocationStore {
+		return openTestJournal(t).Stores().Locations
+	})
+}
+
+func TestJournalRoomStore(t *testing.T) {
+	storetest.RunRoomStoreTests(t, func(t *testing.T) repository.RoomStore {
+		return openTestJournal(t).Stores().Rooms
+	})
+}
+
+func TestJournalAPIKeyStore(t *testing.T) {
+	storetest.RunAPIKeyStoreTests(t, func(t *testing.T) repository.APIKeyStore {
+		return openTestJournal(t).Stores().APIKeys
+	})
+}
+
+func TestJournalReplay(t *testing.T) {
+	dir := t.TempDir()
+
+	j, err := Open(dir)
+	require.NoError(t, err, "Should open journal without error")
+
+	stores := j.Stores()
+	require.NoError(t, stores.Classes.Create(testClass("class-1")))
+	require.NoError(t, stores.Bookings.CreateWithCapacity(testBooking("booking-1"), 10))
+	overrideDate := testBooking("booking-1").Date
+	require.NoError(t, stores.Occurrences.Save(&repository.OccurrenceOverride{ClassID: "class-1", Date: overrideDate, Capacity: 5}))
+	require.NoError(t, stores.Occurrences.Save(&repository.OccurrenceOverride{ClassID: "class-1", Date: overrideDate.AddDate(0, 0, 1), Capacity: 5}))
+	require.NoError(t, stores.Occurrences.Delete("class-1", overrideDate.AddDate(0, 0, 1)))
+
+	// Simulate a crash: release the file without the final snapshot
+	require.NoError(t, j.file.Close())
+
+	j, err = Open(dir)
+	require.NoError(t, err, "Should reopen journal without error")
+	defer j.Close()
+
+	stores = j.Stores()
+	override, err := stores.Occurrences.Get("class-1", overrideDate)
+	require.NoError(t, err, "Occurrence override should be replayed from the journal")
+	assert.Equal(t, 5, override.Capacity, "Replayed override capacity should match")
+
+	_, err = stores.Occurrences.Get("class-1", overrideDate.AddDate(0, 0, 1))
+	assert.ErrorIs(t, err, repository.ErrOccurrenceOverrideNotFound, "Deleted override should stay deleted")
+
+	class, err := stores.Classes.GetByID("class-1")
+	require.NoError(t, err, "Class should be replayed from the journal")
+	assert.Equal(t, "Yoga", class.Name, "Replayed class name should match")
+
+	booking, err := stores.Bookings.GetByID("booking-1")
+	require.NoError(t, err, "Booking should be replayed from the journal")
+	assert.True(t, booking.Date.Equal(testBooking("booking-1").Date), "Replayed booking date should match")
+}
+
+func TestJournalSnapshot(t *testing.T) {
+	dir := t.TempDir()
+
+	j, err := Open(dir)
+	require.NoError(t, err, "Should open journal without error")
+
+	stores := j.Stores()
+	require.NoError(t, stores.Classes.Create(testClass("class-1")))
+	require.NoError(t, j.Snapshot(), "Should write snapshot without error")
+
+	info, err := os.Stat(filepath.Join(dir, logFileName))
+	require.NoError(t, err)
+	assert.Zero(t, info.Size(), "Snapshot should compact the log")
+
+	require.NoError(t, stores.Classes.Create(testClass("class-2")))
+	require.NoError(t, j.file.Close())
+
+	j, err = Open(dir)
+	require.NoError(t, err, "Should reopen journal without error")
+	defer j.Close()
+
+	classes, err := j.Stores().Classes.GetAll()
+	require.NoError(t, err)
+	assert.Len(t, classes, 2, "Should restore snapshot and replay later records")
+}
+
+func TestJournalSkipsRecordsAlreadyInSnapshot(t *testing.T) {
+	dir := t.TempDir()
+
+	j, err := Open(dir)
+	require.NoError(t, err, "Should open journal without error")
+	require.NoError(t, j.Stores().Classes.Create(testClass("class-1")))
+
+	// Keep the log contents across the snapshot, as if the process stopped
+	// after renaming the snapshot but before emptying the log
+	logData, err := os.ReadFile(filepath.Join(dir, logFileName))
+	require.NoError(t, err)
+	require.NoError(t, j.Close())
+	require.NoError(t, os.WriteFile(filepath.Join(dir, logFileName), logData, 0o644))
+
+	j, err = Open(dir)
+	require.NoError(t, err, "Should reopen journal without error")
+	defer j.Close()
+
+	classes, err := j.Stores().Classes.GetAll()
+	require.NoError(t, err)
+	assert.Len(t, classes, 1, "Should not duplicate records already in the snapshot")
+}
+
+func TestJournalTruncatesCorruptedTail(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func(data []byte) []byte
+		// secondRecordIntact is true when the corruption only follows the second record
+		secondRecordIntact bool
+	}{
+		{
+			name: "Partial Record",
+			corrupt: func(data []byte) []byte {
+				return data[:len(data)-5]
+			},
+		},
+		{
+			name: "Checksum Mismatch",
+			corrupt: func(data []byte) []byte {
+				data[len(data)-2] ^= 0xff
+				return data
+			},
+		},
+		{
+			name: "Partial Header",
+			corrupt: func(data []byte) []byte {
+				return append(data, 0x00, 0x00, 0x01)
+			},
+			secondRecordIntact: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, logFileName)
+
+			j, err := Open(dir)
+			require.NoError(t, err, "Should open journal without error")
+			require.NoError(t, j.Stores().Classes.Create(testClass("class-1")))
+
+			goodSize, err := j.file.Seek(0, io.SeekCurrent)
+			require.NoError(t, err)
+
+			require.NoError(t, j.Stores().Classes.Create(testClass("class-2")))
+			require.NoError(t, j.file.Close())
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			if tt.secondRecordIntact {
+				goodSize = int64(len(data))
+			}
+			require.NoError(t, os.WriteFile(path, tt.corrupt(data), 0o644))
+
+			j, err = Open(dir)
+			require.NoError(t, err, "Should open journal despite corrupted tail")
+
+			_, err = j.Stores().Classes.GetByID("class-1")
+			assert.NoError(t, err, "Records before the corruption should be replayed")
+
+			info, err := os.Stat(path)
+			require.NoError(t, err)
+			assert.Equal(t, goodSize, info.Size(), "Log should be truncated to the last valid record")
+
+			// The journal must stay writable after truncation
+			require.NoError(t, j.Stores().Classes.Create(testClass("class-3")))
+			require.NoError(t, j.file.Close())
+
+			j, err = Open(dir)
+			require.NoError(t, err, "Should reopen journal after truncation")
+			defer j.Close()
+
+			_, err = j.Stores().Classes.GetByID("class-3")
+			assert.NoError(t, err, "Records written after truncation should be replayed")
+		})
+	}
+}
+
+func TestJournalCancelsDuplicateBookingsOnReplay(t *testing.T) {
+	dir := t.TempDir()
+
+	j, err := Open(dir)
+	require.NoError(t, err, "Should open journal without error")
+
+	// Records written before duplicate bookings were rejected
+	j.mutex.Lock()
+	require.NoError(t, j.append(record{Op: opCreateBooking, Booking: testBooking("booking-1")}))
+	require.NoError(t, j.append(record{Op: opCreateBooking, Booking: testBooking("booking-2")}))
+	j.mutex.Unlock()
+	require.NoError(t, j.file.Close())
+
+	j, err = Open(dir)
+	require.NoError(t, err, "Should open journal despite duplicate bookings")
+	defer j.Close()
+
+	kept, err := j.Stores().Bookings.GetByID("booking-1")
+	require.NoError(t, err)
+	assert.True(t, kept.Active(), "First booking should be kept")
+
+	duplicate, err := j.Stores().Bookings.GetByID("booking-2")
+	require.NoError(t, err)
+	assert.Equal(t, repository.BookingStatusCancelled, duplicate.Status, "Later duplicate should be cancelled")
+}
@@ -0,0 +1,579 @@
+package journal
+
+import (
+	"time"
+
+	"github.com/sanjaykishor/Glofox/internal/repository"
+)
+
+// classStore journals class writes before applying them in memory. Reads
+// are served directly by the embedded repository.
+type classStore struct {
+	*repository.ClassRepository
+	journal *Journal
+}
+
+// Create adds a new class to the repository
+func (s *classStore) Create(class *repository.Class) error {
+	s.journal.mutex.Lock()
+	defer s.journal.mutex.Unlock()
+
+	if _, err := s.ClassRepository.GetByID(class.ID); err == nil {
+		return repository.ErrClassExists
+	}
+
+	if err := s.journal.append(record{Op: opCreateClass, Class: class}); err != nil {
+		return err
+	}
+
+	return s.ClassRepository.Create(class)
+}
+
+// Update replaces an existing class
+func (s *classStore) Update(class *repository.Class) error {
+	s.journal.mutex.Lock()
+	defer s.journal.mutex.Unlock()
+
+	if _, err := s.ClassRepository.GetByID(class.ID); err != nil {
+		return err
+	}
+
+	if err := s.journal.append(record{Op: opUpdateClass, Class: class}); err != nil {
+		return err
+	}
+
+	return s.ClassRepository.Update(class)
+}
+
+// Delete removes a class by its ID
+func (s *classStore) Delete(id string) error {
+	s.journal.mutex.Lock()
+	defer s.journal.mutex.Unlock()
+
+	if _, err := s.ClassRepository.GetByID(id); err != nil {
+		return err
+	}
+
+	if err := s.journal.append(record{Op: opDeleteClass, ID: id}); err != nil {
+		return err
+	}
+
+	return s.ClassRepository.Delete(id)
+}
+
+// bookingStore journals booking writes before applying them in memory.
+// Reads are served directly by the embedded repository.
+type bookingStore struct {
+	*repository.BookingRepository
+	journal *Journal
+}
+
+// Create adds a new booking to the repository
+func (s *bookingStore) Create(booking *repository.Booking) error {
+	s.journal.mutex.Lock()
+	defer s.journal.mutex.Unlock()
+
+	return s.create(booking)
+}
+
+// CreateWithCapacity adds a new booking only if the class has fewer than
+// capacity bookings on the booking date. Writes are serialized by the
+// journal, so the count cannot change between the check and the insert.
+func (s *bookingStore) CreateWithCapacity(booking *repository.Booking, capacity int) error {
+	s.journal.mutex.Lock()
+	defer s.journal.mutex.Unlock()
+
+	if err := s.BookingRepository.CheckDuplicate(booking); err != nil {
+		return err
+	}
+
+	count, err := s.BookingRepository.CountByClassAndDate(booking.ClassID, booking.Date)
+	if err != nil {
+		return err
+	}
+	if count >= capacity {
+		return repository.ErrCapacityExceeded
+	}
+
+	return s.create(booking)
+}
+
+// Update replaces an existing booking
+func (s *bookingStore) Update(booking *repository.Booking) error {
+	s.journal.mutex.Lock()
+	defer s.journal.mutex.Unlock()
+
+	return s.update(booking)
+}
+
+// UpdateConfirmed journals and applies a booking update only while the
+// stored booking is still the confirmed booking read as previous. A capacity
+// of zero leaves the class unlimited.
+func (s *bookingStore) UpdateConfirmed(previous, booking *repository.Booking, capacity int) error {
+	s.journal.mutex.Lock()
+	defer s.journal.mutex.Unlock()
+
+	existing, err := s.BookingRepository.GetByID(booking.ID)
+	if err != nil {
+		return err
+	}
+	if err := repository.CheckUnchanged(previous, existing); err != nil {
+		return err
+	}
+
+	if capacity > 0 {
+		if err := s.checkCapacity(existing, booking, capacity); err != nil {
+			return err
+		}
+	}
+
+	return s.update(booking)
+}
+
+// checkCapacity reports whether existing can be replaced by booking without
+// a duplicate or overfilling its class; callers must hold the journal mutex
+func (s *bookingStore) checkCapacity(existing, booking *repository.Booking, capacity int) error {
+	if err := s.BookingRepository.CheckDuplicate(booking); err != nil {
+		return err
+	}
+
+	count, err := s.BookingRepository.CountByClassAndDate(booking.ClassID, booking.Date)
+	if err != nil {
+		return err
+	}
+
+	// The booking being moved does not compete with itself for a place
+	if existing.Active() && existing.ClassID == booking.ClassID &&
+		existing.Date.Format("2006-01-02") == booking.Date.Format("2006-01-02") {
+		count--
+	}
+
+	if count >= capacity {
+		return repository.ErrCapacityExceeded
+	}
+
+	return nil
+}
+
+// Delete removes a booking by its ID
+func (s *bookingStore) Delete(id string) error {
+	s.journal.mutex.Lock()
+	defer s.journal.mutex.Unlock()
+
+	if _, err := s.BookingRepository.GetByID(id); err != nil {
+		return err
+	}
+
+	if err := s.journal.append(record{Op: opDeleteBooking, ID: id}); err != nil {
+		return err
+	}
+
+	return s.BookingRepository.Delete(id)
+}
+
+// update journals and applies a booking update; callers must hold the journal mutex
+func (s *bookingStore) update(booking *repository.Booking) error {
+	if _, err := s.BookingRepository.GetByID(booking.ID); err != nil {
+		return err
+	}
+
+	if err := s.BookingRepository.CheckDuplicate(booking); err != nil {
+		return err
+	}
+
+	if err := s.journal.append(record{Op: opUpdateBooking, Booking: booking}); err != nil {
+		return err
+	}
+
+	return s.BookingRepository.Update(booking)
+}
+
+// create journals and applies a booking; callers must hold the journal mutex
+func (s *bookingStore) create(booking *repository.Booking) error {
+	if _, err := s.BookingRepository.GetByID(booking.ID); err == nil {
+		return repository.ErrBookingExists
+	}
+
+	if err := s.BookingRepository.CheckDuplicate(booking); err != nil {
+		return err
+	}
+
+	if err := s.journal.append(record{Op: opCreateBooking, Booking: booking}); err != nil {
+		return err
+	}
+
+	return s.BookingRepository.Create(booking)
+}
+
+// waitlistStore journals waitlist writes before applying them in memory.
+// Reads are served directly by the embedded repository.
+type waitlistStore struct {
+	*repository.WaitlistRepository
+	journal *Journal
+}
+
+// Create adds a new entry to the waitlist
+func (s *waitlistStore) Create(entry *repository.WaitlistEntry) error {
+	s.journal.mutex.Lock()
+	defer s.journal.mutex.Unlock()
+
+	if _, err := s.WaitlistRepository.GetByID(entry.ID); err == nil {
+		return repository.ErrWaitlistEntryExists
+	}
+
+	if err := s.journal.append(record{Op: opCreateWaitlist, Waitlist: entry}); err != nil {
+		return err
+	}
+
+	return s.WaitlistRepository.Create(entry)
+}
+
+// Delete removes a waitlist entry by its ID
+func (s *waitlistStore) Delete(id string) error {
+	s.journal.mutex.Lock()
+	defer s.journal.mutex.Unlock()
+
+	if _, err := s.WaitlistRepository.GetByID(id); err != nil {
+		return err
+	}
+
+	if err := s.journal.append(record{Op: opDeleteWaitlist, ID: id}); err != nil {
+		return err
+	}
+
+	return s.WaitlistRepository.Delete(id)
+}
+
+// DeleteByClass removes every waitlist entry for a class
+func (s *waitlistStore) DeleteByClass(classID string) error {
+	s.journal.mutex.Lock()
+	defer s.journal.mutex.Unlock()
+
+	if err := s.journal.append(record{Op: opDeleteClassWaitlist, ID: classID}); err != nil {
+		return err
+	}
+
+	return s.WaitlistRepository.DeleteByClass(classID)
+}
+
+// memberStore journals member writes before applying them in memory.
+// Reads are served directly by the embedded repository.
+type memberStore struct {
+	*repository.MemberRepository
+	journal *Journal
+}
+
+// Create adds a new member to the repository
+func (s *memberStore) Create(member *repository.Member) error {
+	s.journal.mutex.Lock()
+	defer s.journal.mutex.Unlock()
+
+	if _, err := s.MemberRepository.GetByID(member.ID); err == nil {
+		return repository.ErrMemberExists
+	}
+
+	if err := s.journal.append(record{Op: opCreateMember, Member: member}); err != nil {
+		return err
+	}
+
+	return s.MemberRepository.Create(member)
+}
+
+// Update replaces an existing member
+func (s *memberStore) Update(member *repository.Member) error {
+	s.journal.mutex.Lock()
+	defer s.journal.mutex.Unlock()
+
+	if _, err := s.MemberRepository.GetByID(member.ID); err != nil {
+		return err
+	}
+
+	if err := s.journal.append(record{Op: opUpdateMember, Member: member}); err != nil {
+		return err
+	}
+
+	return s.MemberRepository.Update(member)
+}
+
+// Delete removes a member by its ID
+func (s *memberStore) Delete(id string) error {
+	s.journal.mutex.Lock()
+	defer s.journal.mutex.Unlock()
+
+	if _, err := s.MemberRepository.GetByID(id); err != nil {
+		return err
+	}
+
+	if err := s.journal.append(record{Op: opDeleteMember, ID: id}); err != nil {
+		return err
+	}
+
+	return s.MemberRepository.Delete(id)
+}
+
+// instructorStore journals instructor writes before applying them in memory.
+// Reads are served directly by the embedded repository.
+type instructorStore struct {
+	*repository.InstructorRepository
+	journal *Journal
+}
+
+// Create adds a new instructor to the repository
+func (s *instructorStore) Create(instructor *repository.Instructor) error {
+	s.journal.mutex.Lock()
+	defer s.journal.mutex.Unlock()
+
+	if _, err := s.InstructorRepository.GetByID(instructor.ID); err == nil {
+		return repository.ErrInstructorExists
+	}
+
+	if err := s.journal.append(record{Op: opCreateInstructor, Instructor: instructor}); err != nil {
+		return err
+	}
+
+	return s.InstructorRepository.Create(instructor)
+}
+
+// Update replaces an existing instructor
+func (s *instructorStore) Update(instructor *repository.Instructor) error {
+	s.journal.mutex.Lock()
+	defer s.journal.mutex.Unlock()
+
+	if _, err := s.InstructorRepository.GetByID(instructor.ID); err != nil {
+		return err
+	}
+
+	if err := s.journal.append(record{Op: opUpdateInstructor, Instructor: instructor}); err != nil {
+		return err
+	}
+
+	return s.InstructorRepository.Update(instructor)
+}
+
+// Delete removes an instructor by its ID
+func (s *instructorStore) Delete(id string) error {
+	s.journal.mutex.Lock()
+	defer s.journal.mutex.Unlock()
+
+	if _, err := s.InstructorRepository.GetByID(id); err != nil {
+		return err
+	}
+
+	if err := s.journal.append(record{Op: opDeleteInstructor, ID: id}); err != nil {
+		return err
+	}
+
+	return s.InstructorRepository.Delete(id)
+}
+
+// locationStore journals location writes before applying them in memory.
+// Reads are served directly by the embedded repository.
+type locationStore struct {
+	*repository.LocationRepository
+	journal *Journal
+}
+
+// Create adds a new location to the repository
+func (s *locationStore) Create(location *repository.Location) error {
+	s.journal.mutex.Lock()
+	defer s.journal.mutex.Unlock()
+
+	if _, err := s.LocationRepository.GetByID(location.ID); err == nil {
+		return repository.ErrLocationExists
+	}
+
+	if err := s.journal.append(record{Op: opCreateLocation, Location: location}); err != nil {
+		return err
+	}
+
+	return s.LocationRepository.Create(location)
+}
+
+// Update replaces an existing location
+func (s *locationStore) Update(location *repository.Location) error {
+	s.journal.mutex.Lock()
+	defer s.journal.mutex.Unlock()
+
+	if _, err := s.LocationRepository.GetByID(location.ID); err != nil {
+		return err
+	}
+
+	if err := s.journal.append(record{Op: opUpdateLocation, Location: location}); err != nil {
+		return err
+	}
+
+	return s.LocationRepository.Update(location)
+}
+
+// Delete removes a location by its ID
+func (s *locationStore) Delete(id string) error {
+	s.journal.mutex.Lock()
+	defer s.journal.mutex.Unlock()
+
+	if _, err := s.LocationRepository.GetByID(id); err != nil {
+		return err
+	}
+
+	if err := s.journal.append(record{Op: opDeleteLocation, ID: id}); err != nil {
+		return err
+	}
+
+	return s.LocationRepository.Delete(id)
+}
+
+// roomStore journals room writes before applying them in memory.
+// Reads are served directly by the embedded repository.
+type roomStore struct {
+	*repository.RoomRepository
+	journal *Journal
+}
+
+// Create adds a new room to the repository
+func (s *roomStore) Create(room *repository.Room) error {
+	s.journal.mutex.Lock()
+	defer s.journal.mutex.Unlock()
+
+	if _, err := s.RoomRepository.GetByID(room.ID); err == nil {
+		return repository.ErrRoomExists
+	}
+
+	if err := s.journal.append(record{Op: opCreateRoom, Room: room}); err != nil {
+		return err
+	}
+
+	return s.RoomRepository.Create(room)
+}
+
+// Update replaces an existing room
+func (s *roomStore) Update(room *repository.Room) error {
+	s.journal.mutex.Lock()
+	defer s.journal.mutex.Unlock()
+
+	if _, err := s.RoomRepository.GetByID(room.ID); err != nil {
+		return err
+	}
+
+	if err := s.journal.append(record{Op: opUpdateRoom, Room: room}); err != nil {
+		return err
+	}
+
+	return s.RoomRepository.Update(room)
+}
+
+// Delete removes a room by its ID
+func (s *roomStore) Delete(id string) error {
+	s.journal.mutex.Lock()
+	defer s.journal.mutex.Unlock()
+
+	if _, err := s.RoomRepository.GetByID(id); err != nil {
+		return err
+	}
+
+	if err := s.journal.append(record{Op: opDeleteRoom, ID: id}); err != nil {
+		return err
+	}
+
+	return s.RoomRepository.Delete(id)
+}
+
+// apiKeyStore journals API key writes before applying them in memory.
+// Reads are served directly by the embedded repository.
+type apiKeyStore struct {
+	*repository.APIKeyRepository
+	journal *Journal
+}
+
+// Create adds a new API key to the repository
+func (s *apiKeyStore) Create(key *repository.APIKey) error {
+	s.journal.mutex.Lock()
+	defer s.journal.mutex.Unlock()
+
+	if _, err := s.APIKeyRepository.GetByID(key.ID); err == nil {
+		return repository.ErrAPIKeyExists
+	}
+	if _, err := s.APIKeyRepository.GetByHash(key.Hash); err == nil {
+		return repository.ErrAPIKeyExists
+	}
+
+	if err := s.journal.append(record{Op: opCreateAPIKey, APIKey: key}); err != nil {
+		return err
+	}
+
+	return s.APIKeyRepository.Create(key)
+}
+
+// Update replaces an existing API key
+func (s *apiKeyStore) Update(key *repository.APIKey) error {
+	s.journal.mutex.Lock()
+	defer s.journal.mutex.Unlock()
+
+	if _, err := s.APIKeyRepository.GetByID(key.ID); err != nil {
+		return err
+	}
+
+	if err := s.journal.append(record{Op: opUpdateAPIKey, APIKey: key}); err != nil {
+		return err
+	}
+
+	return s.APIKeyRepository.Update(key)
+}
+
+// occurrenceStore journals occurrence override writes before applying them
+// in memory. Reads are served directly by the embedded repository.
+type occurrenceStore struct {
+	*repository.OccurrenceRepository
+	journal *Journal
+}
+
+// Save creates or replaces the override for an occurrence
+func (s *occurrenceStore) Save(override *repository.OccurrenceOverride) error {
+	s.journal.mutex.Lock()
+	defer s.journal.mutex.Unlock()
+
+	if err := s.journal.append(record{Op: opSaveOccurrence, Occurrence: override}); err != nil {
+		return err
+	}
+
+	return s.OccurrenceRepository.Save(override)
+}
+
+// Delete removes the override for a class on a date
+func (s *occurrenceStore) Delete(classID string, date time.Time) error {
+	s.journal.mutex.Lock()
+	defer s.journal.mutex.Unlock()
+
+	if _, err := s.OccurrenceRepository.Get(classID, date); err != nil {
+		return err
+	}
+
+	rec := record{Op: opDeleteOccurrence, Occurrence: &repository.OccurrenceOverride{ClassID: classID, Date: date}}
+	if err := s.journal.append(rec); err != nil {
+		return err
+	}
+
+	return s.OccurrenceRepository.Delete(classID, date)
+}
+
+// DeleteByClass removes every override for a class
+func (s *occurrenceStore) DeleteByClass(classID string) error {
+	s.journal.mutex.Lock()
+	defer s.journal.mutex.Unlock()
+
+	if err := s.journal.append(record{Op: opDeleteClassOccurrences, ID: classID}); err != nil {
+		return err
+	}
+
+	return s.OccurrenceRepository.DeleteByClass(classID)
+}
+
+var (
+	_ repository.ClassStore      = (*classStore)(nil)
+	_ repository.BookingStore    = (*bookingStore)(nil)
+	_ repository.WaitlistStore   = (*waitlistStore)(nil)
+	_ repository.MemberStore     = (*memberStore)(nil)
+	_ repository.OccurrenceStore = (*occurrenceStore)(nil)
+	_ repository.InstructorStore = (*instructorStore)(nil)
+	_ repository.LocationStore   = (*locationStore)(nil)
+	_ repository.RoomStore       = (*roomStore)(nil)
+	_ repository.APIKeyStore     = (*apiKeyStore)(nil)
+)
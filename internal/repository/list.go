@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Fields lists can be sorted by. Every sort ends with the ID so the order
+// is total and cursors are stable.
+var (
+	ClassSortFields   = []string{"name", "start_date", "end_date", "capacity"}
+	BookingSortFields = []string{"date", "created_at", "name", "status", "class_id"}
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// timestampLayout formats timestamps in sort keys. Its fixed width keeps
+// the lexical order of formatted timestamps the same as their time order.
+const timestampLayout = "2006-01-02T15:04:05.000000000Z07:00"
+
+// SortField is one key of a list ordering
+type SortField struct {
+	Name string
+	Desc bool
+}
+
+// FormatSort returns the sort in its query string form, e.g. "start_date,-name"
+func FormatSort(sort []SortField) string {
+	names := make([]string, len(sort))
+	for i, field := range sort {
+		names[i] = field.Name
+		if field.Desc {
+			names[i] = "-" + field.Name
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// Cursor identifies the last item of a page; the next page starts after it.
+// Values holds the item's sort key values, each a string or an int64, in
+// the order of the sort fields.
+type Cursor struct {
+	Sort   string `json:"s"`
+	Values []any  `json:"v"`
+	ID     string `json:"id"`
+}
+
+// EncodeCursor returns the opaque form of a cursor handed to clients
+func EncodeCursor(c *Cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a cursor produced by EncodeCursor
+func DecodeCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var c Cursor
+	if err := decoder.Decode(&c); err != nil || c.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+
+	for i, value := range c.Values {
+		switch v := value.(type) {
+		case string:
+		case json.Number:
+			n, err := v.Int64()
+			if err != nil {
+				return nil, ErrInvalidCursor
+			}
+			c.Values[i] = n
+		default:
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	return &c, nil
+}
+
+// ListOptions controls the order and size of list results
+type ListOptions struct {
+	// Sort orders the results; ties are broken by ID
+	Sort []SortField
+	// After skips every item up to and including the one the cursor identifies
+	After *Cursor
+	// Limit caps the number of results; zero means no limit
+	Limit int
+}
+
+// ClassFilter narrows a class listing. Zero fields match every class.
+type ClassFilter struct {
+	// Name matches classes whose name contains it, ignoring case
+	Name string
+	// From matches classes still running on or after this date
+	From time.Time
+	// To matches classes starting on or before this date
+	To time.Time
+	// InstructorID matches classes assigned to this instructor
+	InstructorID string
+	// RoomID matches classes held in this room
+	RoomID string
+}
+
+// Matches reports whether a class passes the filter
+func (f ClassFilter) Matches(class *Class) bool {
+	if f.Name != "" && !strings.Contains(strings.ToLower(class.Name), strings.ToLower(f.Name)) {
+		return false
+	}
+	if !f.From.IsZero() && class.EndDate.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && class.StartDate.After(f.To) {
+		return false
+	}
+	if f.InstructorID != "" && class.InstructorID != f.InstructorID {
+		return false
+	}
+	if f.RoomID != "" && class.RoomID != f.RoomID {
+		return false
+	}
+	return true
+}
+
+// BookingFilter narrows a booking listing. Zero fields match every booking.
+type BookingFilter struct {
+	ClassID string
+	// Member matches bookings by member ID or, ignoring case, member name
+	Member string
+	// MemberID matches only the bookings made for this registered member
+	MemberID string
+	// From and To bound the booking date, inclusive
+	From time.Time
+	To   time.Time
+}
+
+// Matches reports whether a booking passes the filter
+func (f BookingFilter) Matches(booking *Booking) bool {
+	if f.ClassID != "" && booking.ClassID != f.ClassID {
+		return false
+	}
+	if f.Member != "" && booking.MemberID != f.Member && strings.ToLower(booking.MemberName) != strings.ToLower(f.Member) {
+		return false
+	}
+	if f.MemberID != "" && booking.MemberID != f.MemberID {
+		return false
+	}
+	if !f.From.IsZero() && booking.Date.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && booking.Date.After(f.To) {
+		return false
+	}
+	return true
+}
+
+// ClassSortValue returns the value a class is sorted by for a sort field
+func ClassSortValue(class *Class, field string) any {
+	switch field {
+	case "name":
+		return class.Name
+	case "start_date":
+		return class.StartDate.Format("2006-01-02")
+	case "end_date":
+		return class.EndDate.Format("2006-01-02")
+	case "capacity":
+		return int64(class.Capacity)
+	default:
+		return ""
+	}
+}
+
+// BookingSortValue returns the value a booking is sorted by for a sort field
+func BookingSortValue(booking *Booking, field string) any {
+	switch field {
+	case "date":
+		return booking.Date.Format("2006-01-02")
+	case "created_at":
+		return booking.CreatedAt.UTC().Format(timestampLayout)
+	case "name":
+		return booking.MemberName
+	case "status":
+		return string(booking.Status)
+	case "class_id":
+		return booking.ClassID
+	default:
+		return ""
+	}
+}
+
+// compareValues orders two sort key values. Values of different types,
+// which only a tampered cursor produces, are ordered by type.
+func compareValues(a, b any) int {
+	switch a := a.(type) {
+	case string:
+		if b, ok := b.(string); ok {
+			return strings.Compare(a, b)
+		}
+		return 1
+	case int64:
+		if b, ok := b.(int64); ok {
+			return cmp.Compare(a, b)
+		}
+		return -1
+	default:
+		return 0
+	}
+}
+
+// compareKeys orders two items by their sort key values and IDs
+func compareKeys(sort []SortField, valuesA []any, idA string, valuesB []any, idB string) int {
+	for i, field := range sort {
+		c := compareValues(valuesA[i], valuesB[i])
+		if field.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return strings.Compare(idA, idB)
+}
+
+// paginate sorts items and returns the page described by opts, together
+// with the cursor of the next page or nil on the last page
+func paginate[T any](items []T, opts ListOptions, value func(T, string) any, id func(T) string) ([]T, *Cursor) {
+	// Compute every sort key once rather than on each comparison
+	type keyed struct {
+		item   T
+		id     string
+		values []any
+	}
+	entries := make([]keyed, len(items))
+	for i, item := range items {
+		values := make([]any, len(opts.Sort))
+		for j, field := range opts.Sort {
+			values[j] = value(item, field.Name)
+		}
+		entries[i] = keyed{item: item, id: id(item), values: values}
+	}
+
+	slices.SortFunc(entries, func(a, b keyed) int {
+		return compareKeys(opts.Sort, a.values, a.id, b.values, b.id)
+	})
+
+	if opts.After != nil && len(opts.After.Values) == len(opts.Sort) {
+		start, _ := slices.BinarySearchFunc(entries, opts.After, func(entry keyed, after *Cursor) int {
+			c := compareKeys(opts.Sort, entry.values, entry.id, after.Values, after.ID)
+			if c == 0 {
+				// The cursor item itself belongs to the previous page
+				return -1
+			}
+			return c
+		})
+		entries = entries[start:]
+	}
+
+	var next *Cursor
+	if opts.Limit > 0 && len(entries) > opts.Limit {
+		entries = entries[:opts.Limit]
+		last := entries[len(entries)-1]
+		next = &Cursor{Sort: FormatSort(opts.Sort), Values: last.values, ID: last.id}
+	}
+
+	page := make([]T, len(entries))
+	for i, entry := range entries {
+		page[i] = entry.item
+	}
+	return page, next
+}
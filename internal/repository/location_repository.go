@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"sync"
+	"time"
+)
+
+// Location represents a site of the studio, such as a branch or a building
+type Location struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Address   string    `json:"address,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// LocationRepository handles location data storage
+type LocationRepository struct {
+	locations map[string]*Location
+	mutex     sync.RWMutex
+}
+
+// NewLocationRepository creates a new instance of LocationRepository
+func NewLocationRepository() *LocationRepository {
+	return &LocationRepository{
+		locations: make(map[string]*Location),
+	}
+}
+
+// Create adds a new location to the repository
+func (r *LocationRepository) Create(location *Location) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.locations[location.ID]; exists {
+		return ErrLocationExists
+	}
+
+	r.locations[location.ID] = location
+	return nil
+}
+
+// GetAll returns all locations
+func (r *LocationRepository) GetAll() ([]*Location, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	locations := make([]*Location, 0, len(r.locations))
+	for _, location := range r.locations {
+		locations = append(locations, location)
+	}
+	return locations, nil
+}
+
+// GetByID retrieves a location by its ID
+func (r *LocationRepository) GetByID(id string) (*Location, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	location, exists := r.locations[id]
+	if !exists {
+		return nil, ErrLocationNotFound
+	}
+
+	return location, nil
+}
+
+// Update replaces an existing location
+func (r *LocationRepository) Update(location *Location) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.locations[location.ID]; !exists {
+		return ErrLocationNotFound
+	}
+
+	r.locations[location.ID] = location
+	return nil
+}
+
+// Delete removes a location by its ID
+func (r *LocationRepository) Delete(id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.locations[id]; !exists {
+		return ErrLocationNotFound
+	}
+
+	delete(r.locations, id)
+	return nil
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"sync"
+	"time"
+)
+
+// Member represents a registered studio member
+type Member struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email,omitempty"`
+	Phone     string    `json:"phone,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MemberRepository handles member data storage
+type MemberRepository struct {
+	members map[string]*Member
+	mutex   sync.RWMutex
+}
+
+// NewMemberRepository creates a new instance of MemberRepository
+func NewMemberRepository() *MemberRepository {
+	return &MemberRepository{
+		members: make(map[string]*Member),
+	}
+}
+
+// Create adds a new member to the repository
+func (r *MemberRepository) Create(member *Member) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.members[member.ID]; exists {
+		return ErrMemberExists
+	}
+
+	r.members[member.ID] = member
+	return nil
+}
+
+// GetAll returns all members
+func (r *MemberRepository) GetAll() ([]*Member, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	members := make([]*Member, 0, len(r.members))
+	for _, member := range r.members {
+		members = append(members, member)
+	}
+	return members, nil
+}
+
+// GetByID retrieves a member by its ID
+func (r *MemberRepository) GetByID(id string) (*Member, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	member, exists := r.members[id]
+	if !exists {
+		return nil, ErrMemberNotFound
+	}
+
+	return member, nil
+}
+
+// Update replaces an existing member
+func (r *MemberRepository) Update(member *Member) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.members[member.ID]; !exists {
+		return ErrMemberNotFound
+	}
+
+	r.members[member.ID] = member
+	return nil
+}
+
+// Delete removes a member by its ID
+func (r *MemberRepository) Delete(id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.members[id]; !exists {
+		return ErrMemberNotFound
+	}
+
+	delete(r.members, id)
+	return nil
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// OccurrenceStatus is the state of a single class occurrence
+type OccurrenceStatus string
+
+// Occurrence statuses
+const (
+	OccurrenceStatusScheduled OccurrenceStatus = "scheduled"
+	OccurrenceStatusCancelled OccurrenceStatus = "cancelled"
+)
+
+// ClassOccurrence is one scheduled meeting of a class, generated from the
+// class schedule with any override for its date applied. Start and End are
+// nil for classes without a time of day.
+type ClassOccurrence struct {
+	ClassID            string           `json:"class_id"`
+	Date               time.Time        `json:"date"`
+	Start              *time.Time       `json:"start,omitempty"`
+	End                *time.Time       `json:"end,omitempty"`
+	Capacity           int              `json:"capacity"`
+	InstructorID       string           `json:"instructor_id,omitempty"`
+	RoomID             string           `json:"room_id,omitempty"`
+	Status             OccurrenceStatus `json:"status"`
+	CancelledAt        *time.Time       `json:"cancelled_at,omitempty"`
+	CancellationReason string           `json:"cancellation_reason,omitempty"`
+	Modified           bool             `json:"modified"`
+}
+
+// Cancelled reports whether the occurrence has been called off
+func (o *ClassOccurrence) Cancelled() bool {
+	return o.Status == OccurrenceStatusCancelled
+}
+
+// OccurrenceOverride records the changes made to a single occurrence of a
+// class. Zero fields keep the value from the class, so later changes to
+// the series still reach the occurrence.
+type OccurrenceOverride struct {
+	ClassID            string           `json:"class_id"`
+	Date               time.Time        `json:"date"`
+	Capacity           int              `json:"capacity,omitempty"`
+	StartTime          string           `json:"start_time,omitempty"`
+	DurationMinutes    int              `json:"duration_minutes,omitempty"`
+	InstructorID       string           `json:"instructor_id,omitempty"`
+	Status             OccurrenceStatus `json:"status,omitempty"`
+	CancelledAt        *time.Time       `json:"cancelled_at,omitempty"`
+	CancellationReason string           `json:"cancellation_reason,omitempty"`
+	UpdatedAt          time.Time        `json:"updated_at"`
+}
+
+// OccurrenceRepository handles occurrence override storage
+type OccurrenceRepository struct {
+	overrides map[occurrence]*OccurrenceOverride
+	mutex     sync.RWMutex
+}
+
+// NewOccurrenceRepository creates a new instance of OccurrenceRepository
+func NewOccurrenceRepository() *OccurrenceRepository {
+	return &OccurrenceRepository{
+		overrides: make(map[occurrence]*OccurrenceOverride),
+	}
+}
+
+// Save creates or replaces the override for an occurrence
+func (r *OccurrenceRepository) Save(override *OccurrenceOverride) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.overrides[occurrence{classID: override.ClassID, date: civilDateOf(override.Date)}] = override
+	return nil
+}
+
+// Get retrieves the override for a class on a date
+func (r *OccurrenceRepository) Get(classID string, date time.Time) (*OccurrenceOverride, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	override, exists := r.overrides[occurrence{classID: classID, date: civilDateOf(date)}]
+	if !exists {
+		return nil, ErrOccurrenceOverrideNotFound
+	}
+
+	return override, nil
+}
+
+// ListByClass returns the overrides for a class between from and to,
+// inclusive, ordered by date
+func (r *OccurrenceRepository) ListByClass(classID string, from, to time.Time) ([]*OccurrenceOverride, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	overrides := make([]*OccurrenceOverride, 0)
+	for key, override := range r.overrides {
+		if key.classID == classID && !override.Date.Before(from) && !override.Date.After(to) {
+			overrides = append(overrides, override)
+		}
+	}
+
+	slices.SortFunc(overrides, func(a, b *OccurrenceOverride) int {
+		return a.Date.Compare(b.Date)
+	})
+	return overrides, nil
+}
+
+// GetAll returns all overrides
+func (r *OccurrenceRepository) GetAll() ([]*OccurrenceOverride, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	overrides := make([]*OccurrenceOverride, 0, len(r.overrides))
+	for _, override := range r.overrides {
+		overrides = append(overrides, override)
+	}
+	return overrides, nil
+}
+
+// Delete removes the override for a class on a date
+func (r *OccurrenceRepository) Delete(classID string, date time.Time) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	key := occurrence{classID: classID, date: civilDateOf(date)}
+	if _, exists := r.overrides[key]; !exists {
+		return ErrOccurrenceOverrideNotFound
+	}
+
+	delete(r.overrides, key)
+	return nil
+}
+
+// DeleteByClass removes every override for a class
+func (r *OccurrenceRepository) DeleteByClass(classID string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for key := range r.overrides {
+		if key.classID == classID {
+			delete(r.overrides, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"sync"
+	"time"
+)
+
+// Room represents a physical space at a location where classes are held.
+// Capacity is the most people the room can hold.
+type Room struct {
+	ID         string    `json:"id"`
+	LocationID string    `json:"location_id"`
+	Name       string    `json:"name"`
+	Capacity   int       `json:"capacity"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// RoomRepository handles room data storage
+type RoomRepository struct {
+	rooms map[string]*Room
+	mutex sync.RWMutex
+}
+
+// NewRoomRepository creates a new instance of RoomRepository
+func NewRoomRepository() *RoomRepository {
+	return &RoomRepository{
+		rooms: make(map[string]*Room),
+	}
+}
+
+// Create adds a new room to the repository
+func (r *RoomRepository) Create(room *Room) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.rooms[room.ID]; exists {
+		return ErrRoomExists
+	}
+
+	r.rooms[room.ID] = room
+	return nil
+}
+
+// GetAll returns all rooms
+func (r *RoomRepository) GetAll() ([]*Room, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	rooms := make([]*Room, 0, len(r.rooms))
+	for _, room := range r.rooms {
+		rooms = append(rooms, room)
+	}
+	return rooms, nil
+}
+
+// GetByID retrieves a room by its ID
+func (r *RoomRepository) GetByID(id string) (*Room, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	room, exists := r.rooms[id]
+	if !exists {
+		return nil, ErrRoomNotFound
+	}
+
+	return room, nil
+}
+
+// GetByLocationID retrieves all rooms at a specific location
+func (r *RoomRepository) GetByLocationID(locationID string) ([]*Room, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	rooms := make([]*Room, 0)
+	for _, room := range r.rooms {
+		if room.LocationID == locationID {
+			rooms = append(rooms, room)
+		}
+	}
+	return rooms, nil
+}
+
+// Update replaces an existing room
+func (r *RoomRepository) Update(room *Room) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.rooms[room.ID]; !exists {
+		return ErrRoomNotFound
+	}
+
+	r.rooms[room.ID] = room
+	return nil
+}
+
+// Delete removes a room by its ID
+func (r *RoomRepository) Delete(id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.rooms[id]; !exists {
+		return ErrRoomNotFound
+	}
+
+	delete(r.rooms, id)
+	return nil
+}
@@ -0,0 +1,132 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/sanjaykishor/Glofox/internal/repository"
+)
+
+// apiKeyColumns is the column list used by every API key query
+const apiKeyColumns = `id, name, prefix, key_hash, scopes, created_at, last_used_at, revoked_at`
+
+// APIKeyRepository stores API keys in SQLite. Scopes are stored as a
+// space-separated list.
+type APIKeyRepository struct {
+	db *sql.DB
+}
+
+// NewAPIKeyRepository creates a new instance of APIKeyRepository
+func NewAPIKeyRepository(db *DB) *APIKeyRepository {
+	return &APIKeyRepository{
+		db: db.db,
+	}
+}
+
+// Create adds a new API key to the repository
+func (r *APIKeyRepository) Create(key *repository.APIKey) error {
+	_, err := r.db.Exec(`INSERT INTO api_keys (`+apiKeyColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`, apiKeyValues(key)...)
+	if isPrimaryKeyViolation(err) || isUniqueViolation(err) {
+		return repository.ErrAPIKeyExists
+	}
+	return err
+}
+
+// GetAll returns all API keys, including revoked ones
+func (r *APIKeyRepository) GetAll() ([]*repository.APIKey, error) {
+	rows, err := r.db.Query(`SELECT ` + apiKeyColumns + ` FROM api_keys`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]*repository.APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// GetByID retrieves an API key by its ID
+func (r *APIKeyRepository) GetByID(id string) (*repository.APIKey, error) {
+	return r.getOne(`SELECT `+apiKeyColumns+` FROM api_keys WHERE id = ?`, id)
+}
+
+// GetByHash retrieves an API key by the hash of the key
+func (r *APIKeyRepository) GetByHash(hash string) (*repository.APIKey, error) {
+	return r.getOne(`SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = ?`, hash)
+}
+
+// Update replaces an existing API key
+func (r *APIKeyRepository) Update(key *repository.APIKey) error {
+	values := apiKeyValues(key)
+	result, err := r.db.Exec(`UPDATE api_keys SET
+		name = ?, prefix = ?, key_hash = ?, scopes = ?, created_at = ?, last_used_at = ?, revoked_at = ?
+		WHERE id = ?`, append(values[1:], key.ID)...)
+	if isUniqueViolation(err) {
+		return repository.ErrAPIKeyExists
+	}
+	if err != nil {
+		return err
+	}
+
+	return requireRow(result, repository.ErrAPIKeyNotFound)
+}
+
+// getOne runs a query expected to return at most one API key
+func (r *APIKeyRepository) getOne(query string, arg string) (*repository.APIKey, error) {
+	key, err := scanAPIKey(r.db.QueryRow(query, arg))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrAPIKeyNotFound
+	}
+
+	return key, err
+}
+
+// apiKeyValues returns the values of an API key in apiKeyColumns order
+func apiKeyValues(key *repository.APIKey) []any {
+	return []any{
+		key.ID,
+		key.Name,
+		key.Prefix,
+		key.Hash,
+		strings.Join(key.Scopes, " "),
+		formatTimestamp(key.CreatedAt),
+		formatOptionalTimestamp(key.LastUsedAt),
+		formatOptionalTimestamp(key.RevokedAt),
+	}
+}
+
+// scanAPIKey reads an API key from the current row
+func scanAPIKey(row scanner) (*repository.APIKey, error) {
+	var key repository.APIKey
+	var scopes, createdAt string
+	var lastUsedAt, revokedAt sql.NullString
+
+	if err := row.Scan(&key.ID, &key.Name, &key.Prefix, &key.Hash, &scopes, &createdAt, &lastUsedAt, &revokedAt); err != nil {
+		return nil, err
+	}
+
+	key.Scopes = strings.Fields(scopes)
+
+	var err error
+	if key.CreatedAt, err = parseTimestamp(createdAt); err != nil {
+		return nil, err
+	}
+	if key.LastUsedAt, err = parseOptionalTimestamp(lastUsedAt); err != nil {
+		return nil, err
+	}
+	if key.RevokedAt, err = parseOptionalTimestamp(revokedAt); err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+var _ repository.APIKeyStore = (*APIKeyRepository)(nil)
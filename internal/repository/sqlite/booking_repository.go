@@ -0,0 +1,324 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/sanjaykishor/Glofox/internal/repository"
+)
+
+// bookingColumns is the column list used by every booking query
+const bookingColumns = `id, member_id, member_name, class_id, date, status, created_at, cancelled_at, cancellation_reason`
+
+// activeBookingCount counts the active bookings for a class and date, ignoring one booking ID
+const activeBookingCount = `SELECT COUNT(*) FROM bookings
+	WHERE class_id = ? AND date = ? AND status != 'cancelled' AND id != ?`
+
+// duplicateBooking finds another active booking of the same member for a
+// class and date, matching repository.SameMember
+const duplicateBooking = `SELECT id FROM bookings
+	WHERE (CASE WHEN ? = '' THEN member_id = '' AND member_name = ? ELSE member_id = ? END)
+	AND class_id = ? AND date = ? AND status != 'cancelled' AND id != ?
+	LIMIT 1`
+
+// updateBooking replaces every column of a booking; its arguments are
+// bookingValues without the ID, followed by the ID
+const updateBooking = `UPDATE bookings SET
+	member_id = ?, member_name = ?, class_id = ?, date = ?, status = ?, created_at = ?, cancelled_at = ?, cancellation_reason = ?
+	WHERE id = ?`
+
+// BookingRepository stores bookings in SQLite
+type BookingRepository struct {
+	db *sql.DB
+}
+
+// NewBookingRepository creates a new instance of BookingRepository
+func NewBookingRepository(db *DB) *BookingRepository {
+	return &BookingRepository{
+		db: db.db,
+	}
+}
+
+// Create adds a new booking to the repository
+func (r *BookingRepository) Create(booking *repository.Booking) error {
+	_, err := r.db.Exec(`INSERT INTO bookings (`+bookingColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		bookingValues(booking)...)
+	if isPrimaryKeyViolation(err) {
+		return repository.ErrBookingExists
+	}
+	if isUniqueViolation(err) {
+		return duplicateBookingError(r.db, booking, err)
+	}
+	return err
+}
+
+// CreateWithCapacity adds a new booking only if the class has fewer than
+// capacity active bookings on the booking date. The count and the insert
+// are a single statement, which SQLite executes atomically.
+func (r *BookingRepository) CreateWithCapacity(booking *repository.Booking, capacity int) error {
+	args := append(bookingValues(booking), booking.ClassID, formatDate(booking.Date), "", capacity)
+
+	result, err := r.db.Exec(`INSERT INTO bookings (`+bookingColumns+`)
+		SELECT ?, ?, ?, ?, ?, ?, ?, ?, ?
+		WHERE (`+activeBookingCount+`) < ?`, args...)
+	if isPrimaryKeyViolation(err) {
+		return repository.ErrBookingExists
+	}
+	if isUniqueViolation(err) {
+		return duplicateBookingError(r.db, booking, err)
+	}
+	if err != nil {
+		return err
+	}
+
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if inserted == 0 {
+		// A member rebooking a full class is told about their booking first
+		return duplicateBookingError(r.db, booking, repository.ErrCapacityExceeded)
+	}
+
+	return nil
+}
+
+// Update replaces an existing booking
+func (r *BookingRepository) Update(booking *repository.Booking) error {
+	result, err := r.db.Exec(updateBooking, append(bookingValues(booking)[1:], booking.ID)...)
+	if isUniqueViolation(err) {
+		return duplicateBookingError(r.db, booking, err)
+	}
+	if err != nil {
+		return err
+	}
+
+	return requireRow(result, repository.ErrBookingNotFound)
+}
+
+// UpdateConfirmed replaces a booking read as previous only while it is
+// still confirmed for the same class and date, within a transaction so two
+// requests cancelling or moving the same booking cannot both succeed. A
+// capacity of zero leaves the class unlimited.
+func (r *BookingRepository) UpdateConfirmed(previous, booking *repository.Booking, capacity int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	current, err := scanBooking(tx.QueryRow(`SELECT `+bookingColumns+` FROM bookings WHERE id = ?`, booking.ID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return repository.ErrBookingNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if err := repository.CheckUnchanged(previous, current); err != nil {
+		return err
+	}
+
+	if err := updateWithCapacity(tx, booking, capacity); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// updateWithCapacity replaces an existing booking within tx if it is no
+// duplicate and its class has room. A capacity of zero leaves the class
+// unlimited.
+func updateWithCapacity(tx *sql.Tx, booking *repository.Booking, capacity int) error {
+	if err := duplicateBookingError(tx, booking, nil); err != nil {
+		return err
+	}
+
+	if capacity > 0 {
+		var count int
+		if err := tx.QueryRow(activeBookingCount, booking.ClassID, formatDate(booking.Date), booking.ID).Scan(&count); err != nil {
+			return err
+		}
+		if count >= capacity {
+			return repository.ErrCapacityExceeded
+		}
+	}
+
+	_, err := tx.Exec(updateBooking, append(bookingValues(booking)[1:], booking.ID)...)
+	return err
+}
+
+// GetAll returns all bookings
+func (r *BookingRepository) GetAll() ([]*repository.Booking, error) {
+	return r.query(`SELECT ` + bookingColumns + ` FROM bookings`)
+}
+
+// List returns the bookings matching filter, one page at a time, together
+// with the cursor of the next page or nil on the last page
+func (r *BookingRepository) List(filter repository.BookingFilter, opts repository.ListOptions) ([]*repository.Booking, *repository.Cursor, error) {
+	var q listQuery
+	if filter.ClassID != "" {
+		q.where(`class_id = ?`, filter.ClassID)
+	}
+	if filter.Member != "" {
+		q.where(`(member_id = ? OR lower(member_name) = lower(?))`, filter.Member, filter.Member)
+	}
+	if filter.MemberID != "" {
+		q.where(`member_id = ?`, filter.MemberID)
+	}
+	if !filter.From.IsZero() {
+		q.where(`date >= ?`, formatDate(filter.From))
+	}
+	if !filter.To.IsZero() {
+		q.where(`date <= ?`, formatDate(filter.To))
+	}
+
+	clauses, args := q.build(opts, bookingSortColumns)
+	bookings, err := r.query(`SELECT `+bookingColumns+` FROM bookings`+clauses, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	page, next := nextPage(bookings, opts, repository.BookingSortValue, func(booking *repository.Booking) string { return booking.ID })
+	return page, next, nil
+}
+
+// GetByID retrieves a booking by its ID
+func (r *BookingRepository) GetByID(id string) (*repository.Booking, error) {
+	row := r.db.QueryRow(`SELECT `+bookingColumns+` FROM bookings WHERE id = ?`, id)
+
+	booking, err := scanBooking(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrBookingNotFound
+	}
+
+	return booking, err
+}
+
+// GetBookingsByDate retrieves all bookings for a specific date
+func (r *BookingRepository) GetBookingsByDate(date time.Time) ([]*repository.Booking, error) {
+	return r.query(`SELECT `+bookingColumns+` FROM bookings WHERE date = ?`, formatDate(date))
+}
+
+// GetByClassID retrieves all bookings for a specific class
+func (r *BookingRepository) GetByClassID(classID string) ([]*repository.Booking, error) {
+	return r.query(`SELECT `+bookingColumns+` FROM bookings WHERE class_id = ?`, classID)
+}
+
+// CountByClassAndDate returns the number of active bookings for a class on a specific date
+func (r *BookingRepository) CountByClassAndDate(classID string, date time.Time) (int, error) {
+	var count int
+	err := r.db.QueryRow(activeBookingCount, classID, formatDate(date), "").Scan(&count)
+	return count, err
+}
+
+// CheckDuplicate returns a *repository.DuplicateBookingError if storing
+// booking would give its member a second active booking for the same class and date
+func (r *BookingRepository) CheckDuplicate(booking *repository.Booking) error {
+	return duplicateBookingError(r.db, booking, nil)
+}
+
+// Delete removes a booking by its ID
+func (r *BookingRepository) Delete(id string) error {
+	result, err := r.db.Exec(`DELETE FROM bookings WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	return requireRow(result, repository.ErrBookingNotFound)
+}
+
+// duplicateBookingError returns a *repository.DuplicateBookingError if the
+// member already has another active booking for the class and date of
+// booking, and fallback otherwise
+func duplicateBookingError(q querier, booking *repository.Booking, fallback error) error {
+	if !booking.Active() {
+		return fallback
+	}
+
+	var existingID string
+	err := q.QueryRow(duplicateBooking, booking.MemberID, booking.MemberName, booking.MemberID,
+		booking.ClassID, formatDate(booking.Date), booking.ID).Scan(&existingID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fallback
+	}
+	if err != nil {
+		return err
+	}
+
+	return &repository.DuplicateBookingError{ExistingID: existingID}
+}
+
+// query runs a booking query and scans every returned row
+func (r *BookingRepository) query(query string, args ...any) ([]*repository.Booking, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bookings := make([]*repository.Booking, 0)
+	for rows.Next() {
+		booking, err := scanBooking(rows)
+		if err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, booking)
+	}
+
+	return bookings, rows.Err()
+}
+
+// bookingValues returns the values of a booking in bookingColumns order
+func bookingValues(booking *repository.Booking) []any {
+	var cancelledAt *string
+	if booking.CancelledAt != nil {
+		formatted := formatTimestamp(*booking.CancelledAt)
+		cancelledAt = &formatted
+	}
+
+	return []any{
+		booking.ID,
+		booking.MemberID,
+		booking.MemberName,
+		booking.ClassID,
+		formatDate(booking.Date),
+		string(booking.Status),
+		formatTimestamp(booking.CreatedAt),
+		cancelledAt,
+		booking.CancellationReason,
+	}
+}
+
+// scanBooking reads a booking from the current row
+func scanBooking(row scanner) (*repository.Booking, error) {
+	var booking repository.Booking
+	var date, status, createdAt string
+	var cancelledAt sql.NullString
+
+	if err := row.Scan(&booking.ID, &booking.MemberID, &booking.MemberName, &booking.ClassID, &date, &status,
+		&createdAt, &cancelledAt, &booking.CancellationReason); err != nil {
+		return nil, err
+	}
+
+	booking.Status = repository.BookingStatus(status)
+
+	var err error
+	if booking.Date, err = parseDate(date); err != nil {
+		return nil, err
+	}
+	if booking.CreatedAt, err = parseTimestamp(createdAt); err != nil {
+		return nil, err
+	}
+	if cancelledAt.Valid {
+		parsed, err := parseTimestamp(cancelledAt.String)
+		if err != nil {
+			return nil, err
+		}
+		booking.CancelledAt = &parsed
+	}
+
+	return &booking, nil
+}
+
+var _ repository.BookingStore = (*BookingRepository)(nil)
@@ -0,0 +1,185 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/sanjaykishor/Glofox/internal/repository"
+	"github.com/sanjaykishor/Glofox/internal/schedule"
+)
+
+// classColumns is the column list used by every class query
+const classColumns = `id, name, start_date, end_date, capacity, schedule, instructor_id, room_id`
+
+// ClassRepository stores classes in SQLite
+type ClassRepository struct {
+	db *sql.DB
+}
+
+// NewClassRepository creates a new instance of ClassRepository
+func NewClassRepository(db *DB) *ClassRepository {
+	return &ClassRepository{
+		db: db.db,
+	}
+}
+
+// Create adds a new class to the repository
+func (r *ClassRepository) Create(class *repository.Class) error {
+	values, err := classValues(class)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(`INSERT INTO classes (`+classColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`, values...)
+	if isPrimaryKeyViolation(err) {
+		return repository.ErrClassExists
+	}
+	return err
+}
+
+// GetAll returns all classes
+func (r *ClassRepository) GetAll() ([]*repository.Class, error) {
+	return r.query(`SELECT ` + classColumns + ` FROM classes`)
+}
+
+// List returns the classes matching filter, one page at a time, together
+// with the cursor of the next page or nil on the last page
+func (r *ClassRepository) List(filter repository.ClassFilter, opts repository.ListOptions) ([]*repository.Class, *repository.Cursor, error) {
+	var q listQuery
+	if filter.Name != "" {
+		q.where(`instr(lower(name), lower(?)) > 0`, filter.Name)
+	}
+	if !filter.From.IsZero() {
+		q.where(`end_date >= ?`, formatDate(filter.From))
+	}
+	if !filter.To.IsZero() {
+		q.where(`start_date <= ?`, formatDate(filter.To))
+	}
+	if filter.InstructorID != "" {
+		q.where(`instructor_id = ?`, filter.InstructorID)
+	}
+	if filter.RoomID != "" {
+		q.where(`room_id = ?`, filter.RoomID)
+	}
+
+	clauses, args := q.build(opts, classSortColumns)
+	classes, err := r.query(`SELECT `+classColumns+` FROM classes`+clauses, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	page, next := nextPage(classes, opts, repository.ClassSortValue, func(class *repository.Class) string { return class.ID })
+	return page, next, nil
+}
+
+// GetByID retrieves a class by its ID
+func (r *ClassRepository) GetByID(id string) (*repository.Class, error) {
+	row := r.db.QueryRow(`SELECT `+classColumns+` FROM classes WHERE id = ?`, id)
+
+	class, err := scanClass(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrClassNotFound
+	}
+
+	return class, err
+}
+
+// Update replaces an existing class
+func (r *ClassRepository) Update(class *repository.Class) error {
+	values, err := classValues(class)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.Exec(`UPDATE classes SET name = ?, start_date = ?, end_date = ?, capacity = ?, schedule = ?, instructor_id = ?, room_id = ? WHERE id = ?`,
+		append(values[1:], class.ID)...)
+	if err != nil {
+		return err
+	}
+
+	return requireRow(result, repository.ErrClassNotFound)
+}
+
+// Delete removes a class by its ID
+func (r *ClassRepository) Delete(id string) error {
+	result, err := r.db.Exec(`DELETE FROM classes WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	return requireRow(result, repository.ErrClassNotFound)
+}
+
+// query runs a class query and scans every returned row
+func (r *ClassRepository) query(query string, args ...any) ([]*repository.Class, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	classes := make([]*repository.Class, 0)
+	for rows.Next() {
+		class, err := scanClass(rows)
+		if err != nil {
+			return nil, err
+		}
+		classes = append(classes, class)
+	}
+
+	return classes, rows.Err()
+}
+
+// scanner is implemented by both *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// querier is satisfied by *sql.DB and *sql.Tx
+type querier interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// classValues returns the values of a class in classColumns order
+func classValues(class *repository.Class) ([]any, error) {
+	var recurrence string
+	if class.Schedule != nil {
+		data, err := json.Marshal(class.Schedule)
+		if err != nil {
+			return nil, err
+		}
+		recurrence = string(data)
+	}
+
+	return []any{class.ID, class.Name, formatDate(class.StartDate), formatDate(class.EndDate), class.Capacity, recurrence, class.InstructorID, class.RoomID}, nil
+}
+
+// scanClass reads a class from the current row
+func scanClass(row scanner) (*repository.Class, error) {
+	var class repository.Class
+	var startDate, endDate, recurrence string
+
+	if err := row.Scan(&class.ID, &class.Name, &startDate, &endDate, &class.Capacity, &recurrence, &class.InstructorID, &class.RoomID); err != nil {
+		return nil, err
+	}
+
+	if recurrence != "" {
+		class.Schedule = new(schedule.Recurrence)
+		if err := json.Unmarshal([]byte(recurrence), class.Schedule); err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	if class.StartDate, err = parseDate(startDate); err != nil {
+		return nil, err
+	}
+	if class.EndDate, err = parseDate(endDate); err != nil {
+		return nil, err
+	}
+
+	return &class, nil
+}
+
+var _ repository.ClassStore = (*ClassRepository)(nil)
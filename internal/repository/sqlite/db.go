@@ -0,0 +1,227 @@
+// Package sqlite implements the repository stores on top of an embedded
+// SQLite database using a pure-Go driver, so no cgo toolchain is needed.
+package sqlite
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+const (
+	// dateLayout is the format civil dates are stored in
+	dateLayout = "2006-01-02"
+	// timestampLayout is the format timestamps are stored in. Unlike
+	// time.RFC3339Nano it keeps trailing zeros, so stored timestamps sort
+	// correctly as text.
+	timestampLayout = "2006-01-02T15:04:05.000000000Z07:00"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// DB is a handle to the SQLite database backing the repositories
+type DB struct {
+	db *sql.DB
+}
+
+// Open opens (creating if needed) the SQLite database at path and applies
+// any pending schema migrations
+func Open(path string) (*DB, error) {
+	dsn := "file:" + path + "?" + url.Values{
+		"_pragma": {"busy_timeout(5000)", "journal_mode(WAL)", "foreign_keys(1)"},
+		"_txlock": {"immediate"},
+	}.Encode()
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	d := &DB{db: db}
+	if err := d.migrate(migrations); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to apply migrations: %w", err)
+	}
+
+	return d, nil
+}
+
+// Close closes the underlying database
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// migrate applies every migration newer than the recorded schema version.
+// Each migration runs in its own transaction.
+func (d *DB) migrate(migrations []migration) error {
+	if _, err := d.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at TEXT NOT NULL
+	)`); err != nil {
+		return err
+	}
+
+	var current int
+	if err := d.db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+
+		tx, err := d.db.Begin()
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(m.sql); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %s: %w", m.name, err)
+		}
+
+		if _, err := tx.Exec(`INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`,
+			m.version, time.Now().UTC().Format(time.RFC3339)); err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migration is a single versioned schema change
+type migration struct {
+	version int
+	name    string
+	sql     string
+}
+
+// loadMigrations reads the embedded migrations sorted by version. File
+// names must start with the version number, e.g. 0001_create_classes.sql.
+func loadMigrations() ([]migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]migration, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		prefix, _, found := strings.Cut(name, "_")
+		if !found {
+			return nil, fmt.Errorf("migration %s has no version prefix", name)
+		}
+
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s has an invalid version prefix", name)
+		}
+
+		content, err := migrationFiles.ReadFile("migrations/" + name)
+		if err != nil {
+			return nil, err
+		}
+
+		migrations = append(migrations, migration{version: version, name: name, sql: string(content)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+
+	return migrations, nil
+}
+
+// isPrimaryKeyViolation reports whether err is a primary key constraint failure
+func isPrimaryKeyViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+}
+
+// isUniqueViolation reports whether err is a unique index constraint failure
+func isUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE
+}
+
+// requireRow returns notFound if the statement did not affect any row
+func requireRow(result sql.Result, notFound error) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return notFound
+	}
+	return nil
+}
+
+// formatDate converts a date to its stored representation
+func formatDate(t time.Time) string {
+	return t.Format(dateLayout)
+}
+
+// parseDate converts a stored date back to midnight UTC
+func parseDate(s string) (time.Time, error) {
+	return time.Parse(dateLayout, s)
+}
+
+// formatTimestamp converts a timestamp to its stored representation
+func formatTimestamp(t time.Time) string {
+	return t.UTC().Format(timestampLayout)
+}
+
+// parseTimestamp converts a stored timestamp back to a time
+func parseTimestamp(s string) (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, s)
+}
+
+// formatOptionalTimestamp converts a timestamp that may be unset to its
+// stored representation, NULL when unset
+func formatOptionalTimestamp(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := formatTimestamp(*t)
+	return &formatted
+}
+
+// parseOptionalTimestamp converts a stored timestamp that may be NULL back to a time
+func parseOptionalTimestamp(s sql.NullString) (*time.Time, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+	parsed, err := parseTimestamp(s.String)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
@@ -0,0 +1,145 @@
+package sqlite
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sanjaykishor/Glofox/internal/repository"
+	"github.com/sanjaykishor/Glofox/internal/repository/storetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openTestDB opens a fresh database in a temporary directory
+func openTestDB(t *testing.T) *DB {
+	db, err := Open(filepath.Join(t.TempDir(), "glofox.db"))
+	require.NoError(t, err, "Should open database without error")
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestSQLiteClassStore(t *testing.T) {
+	storetest.RunClassStoreTests(t, func(t *testing.T) repository.ClassStore {
+		return NewClassRepository(openTestDB(t))
+	})
+}
+
+func TestSQLiteBookingStore(t *testing.T) {
+	storetest.RunBookingStoreTests(t, func(t *testing.T) repository.BookingStore {
+		return NewBookingRepository(openTestDB(t))
+	})
+}
+
+func TestSQLiteWaitlistStore(t *testing.T) {
+	storetest.RunWaitlistStoreTests(t, func(t *testing.T) repository.WaitlistStore {
+		return NewWaitlistRepository(openTestDB(t))
+	})
+}
+
+func TestSQLiteMemberStore(t *testing.T) {
+	storetest.RunMemberStoreTests(t, func(t *testing.T) repository.MemberStore {
+		return NewMemberRepository(openTestDB(t))
+	})
+}
+
+func TestSQLiteOccurrenceStore(t *testing.T) {
+	storetest.RunOccurrenceStoreTests(t, func(t *testing.T) repository.OccurrenceStore {
+		return NewOccurrenceRepository(openTestDB(t))
+	})
+}
+
+func TestSQLiteInstructorStore(t *testing.T) {
+	storetest.RunInstructorStoreTests(t, func(t *testing.T) repository.InstructorStore {
+		return NewInstructorRepository(openTestDB(t))
+	})
+}
+
+func TestSQLiteLocationStore(t *testing.T) {
+	storetest.RunLocationStoreTests(t, func(t *testing.T) repository.LocationStore {
+		return NewLocationRepository(openTestDB(t))
+	})
+}
+
+func TestSQLiteRoomStore(t *testing.T) {
+	storetest.RunRoomStoreTests(t, func(t *testing.T) repository.RoomStore {
+		return NewRoomRepository(openTestDB(t))
+	})
+}
+
+func TestSQLiteAPIKeyStore(t *testing.T) {
+	storetest.RunAPIKeyStoreTests(t, func(t *testing.T) repository.APIKeyStore {
+		return NewAPIKeyRepository(openTestDB(t))
+	})
+}
+
+func TestOpenPersistsDataAndMigrationsOnce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "glofox.db")
+
+	db, err := Open(path)
+	require.NoError(t, err, "Should open database without error")
+
+	class := &repository.Class{
+		ID:        "class-1",
+		Name:      "Yoga",
+		StartDate: time.Date(2025, 4, 25, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC),
+		Capacity:  15,
+	}
+	require.NoError(t, NewClassRepository(db).Create(class), "Should create class without error")
+	require.NoError(t, db.Close(), "Should close database without error")
+
+	// Reopening runs the migrations again, which must be a no-op
+	db, err = Open(path)
+	require.NoError(t, err, "Should reopen database without error")
+	defer db.Close()
+
+	retrieved, err := NewClassRepository(db).GetByID("class-1")
+	require.NoError(t, err, "Class should survive reopening the database")
+	assert.Equal(t, class.Name, retrieved.Name, "Retrieved class name should match")
+
+	migrations, err := loadMigrations()
+	require.NoError(t, err, "Should load migrations without error")
+
+	var applied int
+	require.NoError(t, db.db.QueryRow(`SELECT COUNT(*) FROM schema_migrations`).Scan(&applied))
+	assert.Equal(t, len(migrations), applied, "Each migration should be recorded exactly once")
+}
+
+func TestMigrationCancelsExistingDuplicateBookings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "glofox.db")
+
+	// Build a database from before duplicate bookings were rejected
+	raw, err := sql.Open("sqlite", "file:"+path)
+	require.NoError(t, err)
+
+	migrations, err := loadMigrations()
+	require.NoError(t, err)
+	require.NoError(t, (&DB{db: raw}).migrate(migrations[:3]), "Should apply the earlier migrations")
+
+	for i, id := range []string{"booking-2", "booking-1", "booking-3"} {
+		createdAt := formatTimestamp(time.Date(2025, 4, 24, 9, i, 0, 0, time.UTC))
+		_, err := raw.Exec(`INSERT INTO bookings (id, member_name, class_id, date, status, created_at)
+			VALUES (?, 'USER A', 'class-1', '2025-04-25', 'confirmed', ?)`, id, createdAt)
+		require.NoError(t, err)
+	}
+	require.NoError(t, raw.Close())
+
+	db, err := Open(path)
+	require.NoError(t, err, "Should apply the remaining migrations despite existing duplicates")
+	defer db.Close()
+
+	bookings := NewBookingRepository(db)
+	kept, err := bookings.GetByID("booking-2")
+	require.NoError(t, err)
+	assert.Equal(t, repository.BookingStatusConfirmed, kept.Status, "Earliest booking should be kept")
+
+	for _, id := range []string{"booking-1", "booking-3"} {
+		duplicate, err := bookings.GetByID(id)
+		require.NoError(t, err)
+		assert.Equal(t, repository.BookingStatusCancelled, duplicate.Status, "Later duplicates should be cancelled")
+		assert.Equal(t, "duplicate booking", duplicate.CancellationReason, "Cancellation reason should explain why")
+		assert.NotNil(t, duplicate.CancelledAt, "Cancellation time should be recorded")
+	}
+}
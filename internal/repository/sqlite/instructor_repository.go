@@ -0,0 +1,105 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/sanjaykishor/Glofox/internal/repository"
+)
+
+// instructorColumns is the column list used by every instructor query
+const instructorColumns = `id, name, email, phone, bio, created_at`
+
+// InstructorRepository stores instructors in SQLite
+type InstructorRepository struct {
+	db *sql.DB
+}
+
+// NewInstructorRepository creates a new instance of InstructorRepository
+func NewInstructorRepository(db *DB) *InstructorRepository {
+	return &InstructorRepository{
+		db: db.db,
+	}
+}
+
+// Create adds a new instructor to the repository
+func (r *InstructorRepository) Create(instructor *repository.Instructor) error {
+	_, err := r.db.Exec(`INSERT INTO instructors (`+instructorColumns+`) VALUES (?, ?, ?, ?, ?, ?)`,
+		instructor.ID, instructor.Name, instructor.Email, instructor.Phone, instructor.Bio, formatTimestamp(instructor.CreatedAt))
+	if isPrimaryKeyViolation(err) {
+		return repository.ErrInstructorExists
+	}
+	return err
+}
+
+// GetAll returns all instructors
+func (r *InstructorRepository) GetAll() ([]*repository.Instructor, error) {
+	rows, err := r.db.Query(`SELECT ` + instructorColumns + ` FROM instructors`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	instructors := make([]*repository.Instructor, 0)
+	for rows.Next() {
+		instructor, err := scanInstructor(rows)
+		if err != nil {
+			return nil, err
+		}
+		instructors = append(instructors, instructor)
+	}
+
+	return instructors, rows.Err()
+}
+
+// GetByID retrieves an instructor by its ID
+func (r *InstructorRepository) GetByID(id string) (*repository.Instructor, error) {
+	row := r.db.QueryRow(`SELECT `+instructorColumns+` FROM instructors WHERE id = ?`, id)
+
+	instructor, err := scanInstructor(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrInstructorNotFound
+	}
+
+	return instructor, err
+}
+
+// Update replaces an existing instructor
+func (r *InstructorRepository) Update(instructor *repository.Instructor) error {
+	result, err := r.db.Exec(`UPDATE instructors SET name = ?, email = ?, phone = ?, bio = ?, created_at = ? WHERE id = ?`,
+		instructor.Name, instructor.Email, instructor.Phone, instructor.Bio, formatTimestamp(instructor.CreatedAt), instructor.ID)
+	if err != nil {
+		return err
+	}
+
+	return requireRow(result, repository.ErrInstructorNotFound)
+}
+
+// Delete removes an instructor by its ID
+func (r *InstructorRepository) Delete(id string) error {
+	result, err := r.db.Exec(`DELETE FROM instructors WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	return requireRow(result, repository.ErrInstructorNotFound)
+}
+
+// scanInstructor reads an instructor from the current row
+func scanInstructor(row scanner) (*repository.Instructor, error) {
+	var instructor repository.Instructor
+	var createdAt string
+
+	if err := row.Scan(&instructor.ID, &instructor.Name, &instructor.Email, &instructor.Phone, &instructor.Bio, &createdAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if instructor.CreatedAt, err = parseTimestamp(createdAt); err != nil {
+		return nil, err
+	}
+
+	return &instructor, nil
+}
+
+var _ repository.InstructorStore = (*InstructorRepository)(nil)
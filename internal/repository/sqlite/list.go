@@ -0,0 +1,118 @@
+package sqlite
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/sanjaykishor/Glofox/internal/repository"
+)
+
+// Columns backing the sort fields of each list
+var (
+	classSortColumns = map[string]string{
+		"name":       "name",
+		"start_date": "start_date",
+		"end_date":   "end_date",
+		"capacity":   "capacity",
+	}
+	bookingSortColumns = map[string]string{
+		"date":       "date",
+		"created_at": "created_at",
+		"name":       "member_name",
+		"status":     "status",
+		"class_id":   "class_id",
+	}
+)
+
+// listQuery collects the filter conditions of a list query and renders the
+// remaining clauses from the list options
+type listQuery struct {
+	conditions []string
+	args       []any
+}
+
+// where adds a filter condition
+func (q *listQuery) where(condition string, args ...any) {
+	q.conditions = append(q.conditions, condition)
+	q.args = append(q.args, args...)
+}
+
+// build returns the WHERE, ORDER BY and LIMIT clauses and their arguments.
+// One row more than the limit is requested, to tell whether a next page exists.
+func (q *listQuery) build(opts repository.ListOptions, columns map[string]string) (string, []any) {
+	conditions := q.conditions
+	args := q.args
+
+	names := make([]string, 0, len(opts.Sort)+1)
+	directions := make([]bool, 0, len(opts.Sort)+1)
+	for _, field := range opts.Sort {
+		names = append(names, columns[field.Name])
+		directions = append(directions, field.Desc)
+	}
+	names = append(names, "id")
+	directions = append(directions, false)
+
+	// Keyset pagination: rows sorting after the cursor row, i.e.
+	// (a > ?) OR (a = ? AND b > ?) OR ... with < for descending keys
+	if opts.After != nil && len(opts.After.Values) == len(opts.Sort) {
+		values := append(append([]any{}, opts.After.Values...), opts.After.ID)
+
+		alternatives := make([]string, len(names))
+		var keysetArgs []any
+		for i := range names {
+			terms := make([]string, 0, i+1)
+			for j := 0; j < i; j++ {
+				terms = append(terms, names[j]+" = ?")
+				keysetArgs = append(keysetArgs, values[j])
+			}
+			op := " > ?"
+			if directions[i] {
+				op = " < ?"
+			}
+			terms = append(terms, names[i]+op)
+			keysetArgs = append(keysetArgs, values[i])
+			alternatives[i] = "(" + strings.Join(terms, " AND ") + ")"
+		}
+
+		conditions = append(conditions, "("+strings.Join(alternatives, " OR ")+")")
+		args = append(args, keysetArgs...)
+	}
+
+	var clauses strings.Builder
+	if len(conditions) > 0 {
+		clauses.WriteString(" WHERE " + strings.Join(conditions, " AND "))
+	}
+
+	order := make([]string, len(names))
+	for i, name := range names {
+		order[i] = name
+		if directions[i] {
+			order[i] += " DESC"
+		}
+	}
+	clauses.WriteString(" ORDER BY " + strings.Join(order, ", "))
+
+	if opts.Limit > 0 {
+		clauses.WriteString(" LIMIT " + strconv.Itoa(opts.Limit+1))
+	}
+
+	return clauses.String(), args
+}
+
+// nextPage trims items fetched by a listQuery to the limit and returns the
+// cursor of the next page, or nil when there is none
+func nextPage[T any](items []T, opts repository.ListOptions, value func(T, string) any, id func(T) string) ([]T, *repository.Cursor) {
+	if opts.Limit <= 0 || len(items) <= opts.Limit {
+		return items, nil
+	}
+
+	items = items[:opts.Limit]
+	last := items[len(items)-1]
+
+	values := make([]any, len(opts.Sort))
+	for i, field := range opts.Sort {
+		values[i] = value(last, field.Name)
+	}
+
+	return items, &repository.Cursor{Sort: repository.FormatSort(opts.Sort), Values: values, ID: id(last)}
+}
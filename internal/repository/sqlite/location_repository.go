@@ -0,0 +1,105 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/sanjaykishor/Glofox/internal/repository"
+)
+
+// locationColumns is the column list used by every location query
+const locationColumns = `id, name, address, created_at`
+
+// LocationRepository stores locations in SQLite
+type LocationRepository struct {
+	db *sql.DB
+}
+
+// NewLocationRepository creates a new instance of LocationRepository
+func NewLocationRepository(db *DB) *LocationRepository {
+	return &LocationRepository{
+		db: db.db,
+	}
+}
+
+// Create adds a new location to the repository
+func (r *LocationRepository) Create(location *repository.Location) error {
+	_, err := r.db.Exec(`INSERT INTO locations (`+locationColumns+`) VALUES (?, ?, ?, ?)`,
+		location.ID, location.Name, location.Address, formatTimestamp(location.CreatedAt))
+	if isPrimaryKeyViolation(err) {
+		return repository.ErrLocationExists
+	}
+	return err
+}
+
+// GetAll returns all locations
+func (r *LocationRepository) GetAll() ([]*repository.Location, error) {
+	rows, err := r.db.Query(`SELECT ` + locationColumns + ` FROM locations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	locations := make([]*repository.Location, 0)
+	for rows.Next() {
+		location, err := scanLocation(rows)
+		if err != nil {
+			return nil, err
+		}
+		locations = append(locations, location)
+	}
+
+	return locations, rows.Err()
+}
+
+// GetByID retrieves a location by its ID
+func (r *LocationRepository) GetByID(id string) (*repository.Location, error) {
+	row := r.db.QueryRow(`SELECT `+locationColumns+` FROM locations WHERE id = ?`, id)
+
+	location, err := scanLocation(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrLocationNotFound
+	}
+
+	return location, err
+}
+
+// Update replaces an existing location
+func (r *LocationRepository) Update(location *repository.Location) error {
+	result, err := r.db.Exec(`UPDATE locations SET name = ?, address = ?, created_at = ? WHERE id = ?`,
+		location.Name, location.Address, formatTimestamp(location.CreatedAt), location.ID)
+	if err != nil {
+		return err
+	}
+
+	return requireRow(result, repository.ErrLocationNotFound)
+}
+
+// Delete removes a location by its ID
+func (r *LocationRepository) Delete(id string) error {
+	result, err := r.db.Exec(`DELETE FROM locations WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	return requireRow(result, repository.ErrLocationNotFound)
+}
+
+// scanLocation reads a location from the current row
+func scanLocation(row scanner) (*repository.Location, error) {
+	var location repository.Location
+	var createdAt string
+
+	if err := row.Scan(&location.ID, &location.Name, &location.Address, &createdAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if location.CreatedAt, err = parseTimestamp(createdAt); err != nil {
+		return nil, err
+	}
+
+	return &location, nil
+}
+
+var _ repository.LocationStore = (*LocationRepository)(nil)
@@ -0,0 +1,105 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/sanjaykishor/Glofox/internal/repository"
+)
+
+// memberColumns is the column list used by every member query
+const memberColumns = `id, name, email, phone, created_at`
+
+// MemberRepository stores members in SQLite
+type MemberRepository struct {
+	db *sql.DB
+}
+
+// NewMemberRepository creates a new instance of MemberRepository
+func NewMemberRepository(db *DB) *MemberRepository {
+	return &MemberRepository{
+		db: db.db,
+	}
+}
+
+// Create adds a new member to the repository
+func (r *MemberRepository) Create(member *repository.Member) error {
+	_, err := r.db.Exec(`INSERT INTO members (`+memberColumns+`) VALUES (?, ?, ?, ?, ?)`,
+		member.ID, member.Name, member.Email, member.Phone, formatTimestamp(member.CreatedAt))
+	if isPrimaryKeyViolation(err) {
+		return repository.ErrMemberExists
+	}
+	return err
+}
+
+// GetAll returns all members
+func (r *MemberRepository) GetAll() ([]*repository.Member, error) {
+	rows, err := r.db.Query(`SELECT ` + memberColumns + ` FROM members`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := make([]*repository.Member, 0)
+	for rows.Next() {
+		member, err := scanMember(rows)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	return members, rows.Err()
+}
+
+// GetByID retrieves a member by its ID
+func (r *MemberRepository) GetByID(id string) (*repository.Member, error) {
+	row := r.db.QueryRow(`SELECT `+memberColumns+` FROM members WHERE id = ?`, id)
+
+	member, err := scanMember(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrMemberNotFound
+	}
+
+	return member, err
+}
+
+// Update replaces an existing member
+func (r *MemberRepository) Update(member *repository.Member) error {
+	result, err := r.db.Exec(`UPDATE members SET name = ?, email = ?, phone = ?, created_at = ? WHERE id = ?`,
+		member.Name, member.Email, member.Phone, formatTimestamp(member.CreatedAt), member.ID)
+	if err != nil {
+		return err
+	}
+
+	return requireRow(result, repository.ErrMemberNotFound)
+}
+
+// Delete removes a member by its ID
+func (r *MemberRepository) Delete(id string) error {
+	result, err := r.db.Exec(`DELETE FROM members WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	return requireRow(result, repository.ErrMemberNotFound)
+}
+
+// scanMember reads a member from the current row
+func scanMember(row scanner) (*repository.Member, error) {
+	var member repository.Member
+	var createdAt string
+
+	if err := row.Scan(&member.ID, &member.Name, &member.Email, &member.Phone, &createdAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if member.CreatedAt, err = parseTimestamp(createdAt); err != nil {
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+var _ repository.MemberStore = (*MemberRepository)(nil)
@@ -0,0 +1,18 @@
+CREATE TABLE classes (
+    id         TEXT PRIMARY KEY,
+    name       TEXT NOT NULL,
+    start_date TEXT NOT NULL,
+    end_date   TEXT NOT NULL,
+    capacity   INTEGER NOT NULL
+);
+
+CREATE TABLE bookings (
+    id          TEXT PRIMARY KEY,
+    member_name TEXT NOT NULL,
+    class_id    TEXT NOT NULL DEFAULT '',
+    date        TEXT NOT NULL,
+    created_at  TEXT NOT NULL
+);
+
+CREATE INDEX idx_bookings_class_date ON bookings (class_id, date);
+CREATE INDEX idx_bookings_date ON bookings (date);
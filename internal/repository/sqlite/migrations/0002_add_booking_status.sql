@@ -0,0 +1,3 @@
+ALTER TABLE bookings ADD COLUMN status TEXT NOT NULL DEFAULT 'confirmed';
+ALTER TABLE bookings ADD COLUMN cancelled_at TEXT;
+ALTER TABLE bookings ADD COLUMN cancellation_reason TEXT NOT NULL DEFAULT '';
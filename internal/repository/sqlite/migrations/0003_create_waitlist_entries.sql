@@ -0,0 +1,9 @@
+CREATE TABLE waitlist_entries (
+    id          TEXT PRIMARY KEY,
+    member_name TEXT NOT NULL,
+    class_id    TEXT NOT NULL,
+    date        TEXT NOT NULL,
+    created_at  TEXT NOT NULL
+);
+
+CREATE INDEX idx_waitlist_entries_occurrence ON waitlist_entries (class_id, date, created_at);
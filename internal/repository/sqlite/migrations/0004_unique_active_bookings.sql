@@ -0,0 +1,21 @@
+-- Bookings made before duplicates were rejected would block the index
+-- below. Keep the earliest active booking of each member, class and date
+-- and cancel the rest.
+UPDATE bookings
+SET status = 'cancelled',
+    cancelled_at = strftime('%Y-%m-%dT%H:%M:%fZ', 'now'),
+    cancellation_reason = 'duplicate booking'
+WHERE status != 'cancelled'
+  AND EXISTS (
+    SELECT 1 FROM bookings AS earlier
+    WHERE earlier.member_name = bookings.member_name
+      AND earlier.class_id = bookings.class_id
+      AND earlier.date = bookings.date
+      AND earlier.status != 'cancelled'
+      AND (earlier.created_at < bookings.created_at
+           OR (earlier.created_at = bookings.created_at AND earlier.id < bookings.id))
+  );
+
+CREATE UNIQUE INDEX idx_bookings_active_member_class_date
+    ON bookings (member_name, class_id, date)
+    WHERE status != 'cancelled';
@@ -0,0 +1,17 @@
+CREATE TABLE members (
+    id         TEXT PRIMARY KEY,
+    name       TEXT NOT NULL,
+    email      TEXT NOT NULL DEFAULT '',
+    phone      TEXT NOT NULL DEFAULT '',
+    created_at TEXT NOT NULL
+);
+
+ALTER TABLE bookings ADD COLUMN member_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE waitlist_entries ADD COLUMN member_id TEXT NOT NULL DEFAULT '';
+
+-- Bookings by registered members are unique per member ID; bookings made
+-- by name only are still unique per name
+DROP INDEX idx_bookings_active_member_class_date;
+CREATE UNIQUE INDEX idx_bookings_active_member_class_date
+    ON bookings ((CASE WHEN member_id = '' THEN 'name:' || member_name ELSE 'id:' || member_id END), class_id, date)
+    WHERE status != 'cancelled';
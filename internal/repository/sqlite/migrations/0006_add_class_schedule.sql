@@ -0,0 +1,2 @@
+-- The recurrence of a class as JSON; empty for classes meeting every day
+ALTER TABLE classes ADD COLUMN schedule TEXT NOT NULL DEFAULT '';
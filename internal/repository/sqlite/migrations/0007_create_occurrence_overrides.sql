@@ -0,0 +1,12 @@
+CREATE TABLE occurrence_overrides (
+    class_id            TEXT NOT NULL,
+    date                TEXT NOT NULL,
+    capacity            INTEGER NOT NULL DEFAULT 0,
+    start_time          TEXT NOT NULL DEFAULT '',
+    duration_minutes    INTEGER NOT NULL DEFAULT 0,
+    status              TEXT NOT NULL DEFAULT '',
+    cancelled_at        TEXT,
+    cancellation_reason TEXT NOT NULL DEFAULT '',
+    updated_at          TEXT NOT NULL,
+    PRIMARY KEY (class_id, date)
+);
@@ -0,0 +1,13 @@
+CREATE TABLE instructors (
+    id         TEXT PRIMARY KEY,
+    name       TEXT NOT NULL,
+    email      TEXT NOT NULL DEFAULT '',
+    phone      TEXT NOT NULL DEFAULT '',
+    bio        TEXT NOT NULL DEFAULT '',
+    created_at TEXT NOT NULL
+);
+
+ALTER TABLE classes ADD COLUMN instructor_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE occurrence_overrides ADD COLUMN instructor_id TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX idx_classes_instructor ON classes (instructor_id);
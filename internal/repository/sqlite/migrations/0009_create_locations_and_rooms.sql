@@ -0,0 +1,20 @@
+CREATE TABLE locations (
+    id         TEXT PRIMARY KEY,
+    name       TEXT NOT NULL,
+    address    TEXT NOT NULL DEFAULT '',
+    created_at TEXT NOT NULL
+);
+
+CREATE TABLE rooms (
+    id          TEXT PRIMARY KEY,
+    location_id TEXT NOT NULL,
+    name        TEXT NOT NULL,
+    capacity    INTEGER NOT NULL,
+    created_at  TEXT NOT NULL
+);
+
+CREATE INDEX idx_rooms_location ON rooms (location_id);
+
+ALTER TABLE classes ADD COLUMN room_id TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX idx_classes_room ON classes (room_id);
@@ -0,0 +1,148 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/sanjaykishor/Glofox/internal/repository"
+)
+
+// occurrenceColumns is the column list used by every occurrence override query
+const occurrenceColumns = `class_id, date, capacity, start_time, duration_minutes, status, cancelled_at, cancellation_reason, updated_at, instructor_id`
+
+// OccurrenceRepository stores occurrence overrides in SQLite
+type OccurrenceRepository struct {
+	db *sql.DB
+}
+
+// NewOccurrenceRepository creates a new instance of OccurrenceRepository
+func NewOccurrenceRepository(db *DB) *OccurrenceRepository {
+	return &OccurrenceRepository{
+		db: db.db,
+	}
+}
+
+// Save creates or replaces the override for an occurrence
+func (r *OccurrenceRepository) Save(override *repository.OccurrenceOverride) error {
+	_, err := r.db.Exec(`INSERT OR REPLACE INTO occurrence_overrides (`+occurrenceColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		occurrenceValues(override)...)
+	return err
+}
+
+// Get retrieves the override for a class on a date
+func (r *OccurrenceRepository) Get(classID string, date time.Time) (*repository.OccurrenceOverride, error) {
+	row := r.db.QueryRow(`SELECT `+occurrenceColumns+` FROM occurrence_overrides WHERE class_id = ? AND date = ?`,
+		classID, formatDate(date))
+
+	override, err := scanOccurrence(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrOccurrenceOverrideNotFound
+	}
+
+	return override, err
+}
+
+// ListByClass returns the overrides for a class between from and to,
+// inclusive, ordered by date
+func (r *OccurrenceRepository) ListByClass(classID string, from, to time.Time) ([]*repository.OccurrenceOverride, error) {
+	return r.query(`SELECT `+occurrenceColumns+` FROM occurrence_overrides
+		WHERE class_id = ? AND date >= ? AND date <= ? ORDER BY date`,
+		classID, formatDate(from), formatDate(to))
+}
+
+// GetAll returns all overrides
+func (r *OccurrenceRepository) GetAll() ([]*repository.OccurrenceOverride, error) {
+	return r.query(`SELECT ` + occurrenceColumns + ` FROM occurrence_overrides`)
+}
+
+// Delete removes the override for a class on a date
+func (r *OccurrenceRepository) Delete(classID string, date time.Time) error {
+	result, err := r.db.Exec(`DELETE FROM occurrence_overrides WHERE class_id = ? AND date = ?`, classID, formatDate(date))
+	if err != nil {
+		return err
+	}
+
+	return requireRow(result, repository.ErrOccurrenceOverrideNotFound)
+}
+
+// DeleteByClass removes every override for a class
+func (r *OccurrenceRepository) DeleteByClass(classID string) error {
+	_, err := r.db.Exec(`DELETE FROM occurrence_overrides WHERE class_id = ?`, classID)
+	return err
+}
+
+// query runs an occurrence override query and scans every returned row
+func (r *OccurrenceRepository) query(query string, args ...any) ([]*repository.OccurrenceOverride, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	overrides := make([]*repository.OccurrenceOverride, 0)
+	for rows.Next() {
+		override, err := scanOccurrence(rows)
+		if err != nil {
+			return nil, err
+		}
+		overrides = append(overrides, override)
+	}
+
+	return overrides, rows.Err()
+}
+
+// occurrenceValues returns the values of an override in occurrenceColumns order
+func occurrenceValues(override *repository.OccurrenceOverride) []any {
+	var cancelledAt *string
+	if override.CancelledAt != nil {
+		formatted := formatTimestamp(*override.CancelledAt)
+		cancelledAt = &formatted
+	}
+
+	return []any{
+		override.ClassID,
+		formatDate(override.Date),
+		override.Capacity,
+		override.StartTime,
+		override.DurationMinutes,
+		string(override.Status),
+		cancelledAt,
+		override.CancellationReason,
+		formatTimestamp(override.UpdatedAt),
+		override.InstructorID,
+	}
+}
+
+// scanOccurrence reads an occurrence override from the current row
+func scanOccurrence(row scanner) (*repository.OccurrenceOverride, error) {
+	var override repository.OccurrenceOverride
+	var date, status, updatedAt string
+	var cancelledAt sql.NullString
+
+	if err := row.Scan(&override.ClassID, &date, &override.Capacity, &override.StartTime, &override.DurationMinutes,
+		&status, &cancelledAt, &override.CancellationReason, &updatedAt, &override.InstructorID); err != nil {
+		return nil, err
+	}
+
+	override.Status = repository.OccurrenceStatus(status)
+
+	var err error
+	if override.Date, err = parseDate(date); err != nil {
+		return nil, err
+	}
+	if override.UpdatedAt, err = parseTimestamp(updatedAt); err != nil {
+		return nil, err
+	}
+	if cancelledAt.Valid {
+		parsed, err := parseTimestamp(cancelledAt.String)
+		if err != nil {
+			return nil, err
+		}
+		override.CancelledAt = &parsed
+	}
+
+	return &override, nil
+}
+
+var _ repository.OccurrenceStore = (*OccurrenceRepository)(nil)
@@ -0,0 +1,115 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/sanjaykishor/Glofox/internal/repository"
+)
+
+// roomColumns is the column list used by every room query
+const roomColumns = `id, location_id, name, capacity, created_at`
+
+// RoomRepository stores rooms in SQLite
+type RoomRepository struct {
+	db *sql.DB
+}
+
+// NewRoomRepository creates a new instance of RoomRepository
+func NewRoomRepository(db *DB) *RoomRepository {
+	return &RoomRepository{
+		db: db.db,
+	}
+}
+
+// Create adds a new room to the repository
+func (r *RoomRepository) Create(room *repository.Room) error {
+	_, err := r.db.Exec(`INSERT INTO rooms (`+roomColumns+`) VALUES (?, ?, ?, ?, ?)`,
+		room.ID, room.LocationID, room.Name, room.Capacity, formatTimestamp(room.CreatedAt))
+	if isPrimaryKeyViolation(err) {
+		return repository.ErrRoomExists
+	}
+	return err
+}
+
+// GetAll returns all rooms
+func (r *RoomRepository) GetAll() ([]*repository.Room, error) {
+	return r.query(`SELECT ` + roomColumns + ` FROM rooms`)
+}
+
+// GetByID retrieves a room by its ID
+func (r *RoomRepository) GetByID(id string) (*repository.Room, error) {
+	row := r.db.QueryRow(`SELECT `+roomColumns+` FROM rooms WHERE id = ?`, id)
+
+	room, err := scanRoom(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrRoomNotFound
+	}
+
+	return room, err
+}
+
+// GetByLocationID retrieves all rooms at a specific location
+func (r *RoomRepository) GetByLocationID(locationID string) ([]*repository.Room, error) {
+	return r.query(`SELECT `+roomColumns+` FROM rooms WHERE location_id = ?`, locationID)
+}
+
+// Update replaces an existing room
+func (r *RoomRepository) Update(room *repository.Room) error {
+	result, err := r.db.Exec(`UPDATE rooms SET location_id = ?, name = ?, capacity = ?, created_at = ? WHERE id = ?`,
+		room.LocationID, room.Name, room.Capacity, formatTimestamp(room.CreatedAt), room.ID)
+	if err != nil {
+		return err
+	}
+
+	return requireRow(result, repository.ErrRoomNotFound)
+}
+
+// Delete removes a room by its ID
+func (r *RoomRepository) Delete(id string) error {
+	result, err := r.db.Exec(`DELETE FROM rooms WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	return requireRow(result, repository.ErrRoomNotFound)
+}
+
+// query runs a room query and scans every returned row
+func (r *RoomRepository) query(query string, args ...any) ([]*repository.Room, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rooms := make([]*repository.Room, 0)
+	for rows.Next() {
+		room, err := scanRoom(rows)
+		if err != nil {
+			return nil, err
+		}
+		rooms = append(rooms, room)
+	}
+
+	return rooms, rows.Err()
+}
+
+// scanRoom reads a room from the current row
+func scanRoom(row scanner) (*repository.Room, error) {
+	var room repository.Room
+	var createdAt string
+
+	if err := row.Scan(&room.ID, &room.LocationID, &room.Name, &room.Capacity, &createdAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if room.CreatedAt, err = parseTimestamp(createdAt); err != nil {
+		return nil, err
+	}
+
+	return &room, nil
+}
+
+var _ repository.RoomStore = (*RoomRepository)(nil)
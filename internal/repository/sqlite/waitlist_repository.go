@@ -0,0 +1,117 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/sanjaykishor/Glofox/internal/repository"
+)
+
+// waitlistColumns is the column list used by every waitlist query
+const waitlistColumns = `id, member_id, member_name, class_id, date, created_at`
+
+// WaitlistRepository stores waitlist entries in SQLite
+type WaitlistRepository struct {
+	db *sql.DB
+}
+
+// NewWaitlistRepository creates a new instance of WaitlistRepository
+func NewWaitlistRepository(db *DB) *WaitlistRepository {
+	return &WaitlistRepository{
+		db: db.db,
+	}
+}
+
+// Create adds a new entry to the waitlist
+func (r *WaitlistRepository) Create(entry *repository.WaitlistEntry) error {
+	_, err := r.db.Exec(`INSERT INTO waitlist_entries (`+waitlistColumns+`) VALUES (?, ?, ?, ?, ?, ?)`,
+		entry.ID, entry.MemberID, entry.MemberName, entry.ClassID, formatDate(entry.Date), formatTimestamp(entry.CreatedAt))
+	if isPrimaryKeyViolation(err) {
+		return repository.ErrWaitlistEntryExists
+	}
+	return err
+}
+
+// GetByID retrieves a waitlist entry by its ID
+func (r *WaitlistRepository) GetByID(id string) (*repository.WaitlistEntry, error) {
+	row := r.db.QueryRow(`SELECT `+waitlistColumns+` FROM waitlist_entries WHERE id = ?`, id)
+
+	entry, err := scanWaitlistEntry(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrWaitlistEntryNotFound
+	}
+
+	return entry, err
+}
+
+// GetByOccurrence retrieves the waitlist for a class on a specific date in
+// the order members joined it
+func (r *WaitlistRepository) GetByOccurrence(classID string, date time.Time) ([]*repository.WaitlistEntry, error) {
+	return r.query(`SELECT `+waitlistColumns+` FROM waitlist_entries
+		WHERE class_id = ? AND date = ?
+		ORDER BY created_at, id`, classID, formatDate(date))
+}
+
+// GetAll returns all waitlist entries
+func (r *WaitlistRepository) GetAll() ([]*repository.WaitlistEntry, error) {
+	return r.query(`SELECT ` + waitlistColumns + ` FROM waitlist_entries`)
+}
+
+// Delete removes a waitlist entry by its ID
+func (r *WaitlistRepository) Delete(id string) error {
+	result, err := r.db.Exec(`DELETE FROM waitlist_entries WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	return requireRow(result, repository.ErrWaitlistEntryNotFound)
+}
+
+// DeleteByClass removes every waitlist entry for a class
+func (r *WaitlistRepository) DeleteByClass(classID string) error {
+	_, err := r.db.Exec(`DELETE FROM waitlist_entries WHERE class_id = ?`, classID)
+	return err
+}
+
+// query runs a waitlist query and scans every returned row
+func (r *WaitlistRepository) query(query string, args ...any) ([]*repository.WaitlistEntry, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]*repository.WaitlistEntry, 0)
+	for rows.Next() {
+		entry, err := scanWaitlistEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// scanWaitlistEntry reads a waitlist entry from the current row
+func scanWaitlistEntry(row scanner) (*repository.WaitlistEntry, error) {
+	var entry repository.WaitlistEntry
+	var date, createdAt string
+
+	if err := row.Scan(&entry.ID, &entry.MemberID, &entry.MemberName, &entry.ClassID, &date, &createdAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if entry.Date, err = parseDate(date); err != nil {
+		return nil, err
+	}
+	if entry.CreatedAt, err = parseTimestamp(createdAt); err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+var _ repository.WaitlistStore = (*WaitlistRepository)(nil)
@@ -0,0 +1,213 @@
+package repository
+
+import "time"
+
+// ClassStore is the storage contract every class backend must satisfy
+type ClassStore interface {
+	// Create adds a new class, failing if a class with the same ID exists
+	Create(class *Class) error
+	// GetAll returns all classes
+	GetAll() ([]*Class, error)
+	// List returns a page of the classes matching filter, ordered by
+	// opts.Sort and then ID, and the cursor of the next page, or nil on
+	// the last page
+	List(filter ClassFilter, opts ListOptions) ([]*Class, *Cursor, error)
+	// GetByID retrieves a class by its ID
+	GetByID(id string) (*Class, error)
+	// Update replaces an existing class
+	Update(class *Class) error
+	// Delete removes a class by its ID
+	Delete(id string) error
+}
+
+// BookingStore is the storage contract every booking backend must satisfy.
+// Every write rejects a booking that would give a member a second active
+// booking for the same class and date with a *DuplicateBookingError.
+// Bookings are matched to members by MemberID, or by MemberName for
+// bookings made without a registered member.
+type BookingStore interface {
+	// Create adds a new booking, failing if a booking with the same ID exists
+	Create(booking *Booking) error
+	// CreateWithCapacity atomically adds a booking unless the class already
+	// has capacity active bookings on the booking date, in which case it
+	// returns ErrCapacityExceeded. A duplicate booking is reported before a full class.
+	CreateWithCapacity(booking *Booking, capacity int) error
+	// Update replaces an existing booking
+	Update(booking *Booking) error
+	// UpdateConfirmed atomically replaces a booking read as previous, but
+	// only while the stored booking is still confirmed, returning
+	// ErrBookingNotConfirmed otherwise, and still for the class and date of
+	// previous, returning ErrBookingChanged otherwise. With a positive
+	// capacity it returns ErrCapacityExceeded if the class already has
+	// capacity other active bookings on the booking date; zero leaves the
+	// class unlimited.
+	UpdateConfirmed(previous, booking *Booking, capacity int) error
+	// GetAll returns all bookings
+	GetAll() ([]*Booking, error)
+	// List returns a page of the bookings matching filter, ordered by
+	// opts.Sort and then ID, and the cursor of the next page, or nil on
+	// the last page
+	List(filter BookingFilter, opts ListOptions) ([]*Booking, *Cursor, error)
+	// GetByID retrieves a booking by its ID
+	GetByID(id string) (*Booking, error)
+	// GetBookingsByDate retrieves all bookings for a specific date
+	GetBookingsByDate(date time.Time) ([]*Booking, error)
+	// GetByClassID retrieves all bookings for a specific class
+	GetByClassID(classID string) ([]*Booking, error)
+	// CountByClassAndDate returns the number of active bookings for a class on a specific date
+	CountByClassAndDate(classID string, date time.Time) (int, error)
+	// CheckDuplicate returns a *DuplicateBookingError if storing booking
+	// would give its member a second active booking for the same class and date
+	CheckDuplicate(booking *Booking) error
+	// Delete removes a booking by its ID
+	Delete(id string) error
+}
+
+// WaitlistStore is the storage contract every waitlist backend must satisfy
+type WaitlistStore interface {
+	// Create adds a new entry, failing if an entry with the same ID exists
+	Create(entry *WaitlistEntry) error
+	// GetByID retrieves a waitlist entry by its ID
+	GetByID(id string) (*WaitlistEntry, error)
+	// GetByOccurrence retrieves the waitlist for a class on a specific date,
+	// ordered by CreatedAt and then ID
+	GetByOccurrence(classID string, date time.Time) ([]*WaitlistEntry, error)
+	// GetAll returns all waitlist entries
+	GetAll() ([]*WaitlistEntry, error)
+	// Delete removes a waitlist entry by its ID
+	Delete(id string) error
+	// DeleteByClass removes every waitlist entry for a class
+	DeleteByClass(classID string) error
+}
+
+// MemberStore is the storage contract every member backend must satisfy
+type MemberStore interface {
+	// Create adds a new member, failing if a member with the same ID exists
+	Create(member *Member) error
+	// GetAll returns all members
+	GetAll() ([]*Member, error)
+	// GetByID retrieves a member by its ID
+	GetByID(id string) (*Member, error)
+	// Update replaces an existing member
+	Update(member *Member) error
+	// Delete removes a member by its ID
+	Delete(id string) error
+}
+
+// InstructorStore is the storage contract every instructor backend must satisfy
+type InstructorStore interface {
+	// Create adds a new instructor, failing if an instructor with the same ID exists
+	Create(instructor *Instructor) error
+	// GetAll returns all instructors
+	GetAll() ([]*Instructor, error)
+	// GetByID retrieves an instructor by its ID
+	GetByID(id string) (*Instructor, error)
+	// Update replaces an existing instructor
+	Update(instructor *Instructor) error
+	// Delete removes an instructor by its ID
+	Delete(id string) error
+}
+
+// LocationStore is the storage contract every location backend must satisfy
+type LocationStore interface {
+	// Create adds a new location, failing if a location with the same ID exists
+	Create(location *Location) error
+	// GetAll returns all locations
+	GetAll() ([]*Location, error)
+	// GetByID retrieves a location by its ID
+	GetByID(id string) (*Location, error)
+	// Update replaces an existing location
+	Update(location *Location) error
+	// Delete removes a location by its ID
+	Delete(id string) error
+}
+
+// RoomStore is the storage contract every room backend must satisfy
+type RoomStore interface {
+	// Create adds a new room, failing if a room with the same ID exists
+	Create(room *Room) error
+	// GetAll returns all rooms
+	GetAll() ([]*Room, error)
+	// GetByID retrieves a room by its ID
+	GetByID(id string) (*Room, error)
+	// GetByLocationID retrieves all rooms at a specific location
+	GetByLocationID(locationID string) ([]*Room, error)
+	// Update replaces an existing room
+	Update(room *Room) error
+	// Delete removes a room by its ID
+	Delete(id string) error
+}
+
+// APIKeyStore is the storage contract every API key backend must satisfy.
+// Keys are never deleted, so revoked keys stay on record.
+type APIKeyStore interface {
+	// Create adds a new API key, failing if a key with the same ID or hash exists
+	Create(key *APIKey) error
+	// GetAll returns all API keys, including revoked ones
+	GetAll() ([]*APIKey, error)
+	// GetByID retrieves an API key by its ID
+	GetByID(id string) (*APIKey, error)
+	// GetByHash retrieves an API key by the hash of the key
+	GetByHash(hash string) (*APIKey, error)
+	// Update replaces an existing API key
+	Update(key *APIKey) error
+}
+
+// OccurrenceStore is the storage contract every occurrence override backend
+// must satisfy. Overrides are keyed by class ID and date.
+type OccurrenceStore interface {
+	// Save creates or replaces the override for an occurrence
+	Save(override *OccurrenceOverride) error
+	// Get retrieves the override for a class on a date
+	Get(classID string, date time.Time) (*OccurrenceOverride, error)
+	// ListByClass returns the overrides for a class between from and to,
+	// inclusive, ordered by date
+	ListByClass(classID string, from, to time.Time) ([]*OccurrenceOverride, error)
+	// GetAll returns all overrides
+	GetAll() ([]*OccurrenceOverride, error)
+	// Delete removes the override for a class on a date
+	Delete(classID string, date time.Time) error
+	// DeleteByClass removes every override for a class
+	DeleteByClass(classID string) error
+}
+
+// Stores groups the storage backends used by the application
+type Stores struct {
+	Classes     ClassStore
+	Bookings    BookingStore
+	Waitlist    WaitlistStore
+	Members     MemberStore
+	Occurrences OccurrenceStore
+	Instructors InstructorStore
+	Locations   LocationStore
+	Rooms       RoomStore
+	APIKeys     APIKeyStore
+}
+
+// NewMemoryStores returns stores backed by in-process maps. Data is lost
+// when the process exits.
+func NewMemoryStores() *Stores {
+	return &Stores{
+		Classes:     NewClassRepository(),
+		Bookings:    NewBookingRepository(),
+		Waitlist:    NewWaitlistRepository(),
+		Members:     NewMemberRepository(),
+		Occurrences: NewOccurrenceRepository(),
+		Instructors: NewInstructorRepository(),
+		Locations:   NewLocationRepository(),
+		Rooms:       NewRoomRepository(),
+		APIKeys:     NewAPIKeyRepository(),
+	}
+}
+
+var (
+	_ ClassStore      = (*ClassRepository)(nil)
+	_ BookingStore    = (*BookingRepository)(nil)
+	_ WaitlistStore   = (*WaitlistRepository)(nil)
+	_ MemberStore     = (*MemberRepository)(nil)
+	_ OccurrenceStore = (*OccurrenceRepository)(nil)
+	_ InstructorStore = (*InstructorRepository)(nil)
+	_ LocationStore   = (*LocationRepository)(nil)
+	_ RoomStore       = (*RoomRepository)(nil)
+	_ APIKeyStore     = (*APIKeyRepository)(nil)
+)
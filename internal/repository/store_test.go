@@ -0,0 +1,62 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/sanjaykishor/Glofox/internal/repository"
+	"github.com/sanjaykishor/Glofox/internal/repository/storetest"
+)
+
+func TestMemoryClassStore(t *testing.T) {
+	storetest.RunClassStoreTests(t, func(t *testing.T) repository.ClassStore {
+		return repository.NewClassRepository()
+	})
+}
+
+func TestMemoryBookingStore(t *testing.T) {
+	storetest.RunBookingStoreTests(t, func(t *testing.T) repository.BookingStore {
+		return repository.NewBookingRepository()
+	})
+}
+
+func TestMemoryWaitlistStore(t *testing.T) {
+	storetest.RunWaitlistStoreTests(t, func(t *testing.T) repository.WaitlistStore {
+		return repository.NewWaitlistRepository()
+	})
+}
+
+func TestMemoryMemberStore(t *testing.T) {
+	storetest.RunMemberStoreTests(t, func(t *testing.T) repository.MemberStore {
+		return repository.NewMemberRepository()
+	})
+}
+
+func TestMemoryOccurrenceStore(t *testing.T) {
+	storetest.RunOccurrenceStoreTests(t, func(t *testing.T) repository.OccurrenceStore {
+		return repository.NewOccurrenceRepository()
+	})
+}
+
+func TestMemoryInstructorStore(t *testing.T) {
+	storetest.RunInstructorStoreTests(t, func(t *testing.T) repository.InstructorStore {
+		return repository.NewInstructorRepository()
+	})
+}
+
+func TestMemoryLocationStore(t *testing.T) {
+	storetest.RunLocationStoreTests(t, func(t *testing.T) repository.LocationStore {
+		return repository.NewLocationRepository()
+	})
+}
+
+func TestMemoryRoomStore(t *testing.T) {
+	storetest.RunRoomStoreTests(t, func(t *testing.T) repository.RoomStore {
+		return repository.NewRoomRepository()
+	})
+}
+
+func TestMemoryAPIKeyStore(t *testing.T) {
+	storetest.RunAPIKeyStoreTests(t, func(t *testing.T) repository.APIKeyStore {
+		return repository.NewAPIKeyRepository()
+	})
+}
@@ -0,0 +1,1233 @@
+// Package storetest provides the contract test suite every repository
+// backend must pass. Backends call the Run*StoreTests functions from their
+// own tests with a factory that returns a fresh, empty store.
+package storetest
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sanjaykishor/Glofox/internal/repository"
+	"github.com/sanjaykishor/Glofox/internal/schedule"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ClassStoreFactory returns a new, empty ClassStore
+type ClassStoreFactory func(t *testing.T) repository.ClassStore
+
+// BookingStoreFactory returns a new, empty BookingStore
+type BookingStoreFactory func(t *testing.T) repository.BookingStore
+
+// WaitlistStoreFactory returns a new, empty WaitlistStore
+type WaitlistStoreFactory func(t *testing.T) repository.WaitlistStore
+
+// MemberStoreFactory returns a new, empty MemberStore
+type MemberStoreFactory func(t *testing.T) repository.MemberStore
+
+// OccurrenceStoreFactory returns a new, empty OccurrenceStore
+type OccurrenceStoreFactory func(t *testing.T) repository.OccurrenceStore
+
+// InstructorStoreFactory returns a new, empty InstructorStore
+type InstructorStoreFactory func(t *testing.T) repository.InstructorStore
+
+// LocationStoreFactory returns a new, empty LocationStore
+type LocationStoreFactory func(t *testing.T) repository.LocationStore
+
+// RoomStoreFactory returns a new, empty RoomStore
+type RoomStoreFactory func(t *testing.T) repository.RoomStore
+
+// APIKeyStoreFactory returns a new, empty APIKeyStore
+type APIKeyStoreFactory func(t *testing.T) repository.APIKeyStore
+
+// date returns midnight UTC for the given day, the form in which the
+// services hand dates to the stores
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// RunClassStoreTests runs the ClassStore contract against stores built by newStore
+func RunClassStoreTests(t *testing.T, newStore ClassStoreFactory) {
+	t.Run("CreateAndGetByID", func(t *testing.T) {
+		store := newStore(t)
+
+		class := &repository.Class{
+			ID:           "class-1",
+			Name:         "Yoga",
+			StartDate:    date(2025, 4, 25),
+			EndDate:      date(2025, 4, 30),
+			Capacity:     15,
+			InstructorID: "instructor-1",
+			RoomID:       "room-1",
+		}
+		require.NoError(t, store.Create(class), "Should create class without error")
+
+		retrieved, err := store.GetByID("class-1")
+		require.NoError(t, err, "Should retrieve class without error")
+		assert.Equal(t, class.ID, retrieved.ID, "Retrieved class ID should match")
+		assert.Equal(t, class.Name, retrieved.Name, "Retrieved class name should match")
+		assert.True(t, class.StartDate.Equal(retrieved.StartDate), "Retrieved class start date should match")
+		assert.True(t, class.EndDate.Equal(retrieved.EndDate), "Retrieved class end date should match")
+		assert.Equal(t, class.Capacity, retrieved.Capacity, "Retrieved class capacity should match")
+		assert.Equal(t, class.InstructorID, retrieved.InstructorID, "Retrieved class instructor should match")
+		assert.Equal(t, class.RoomID, retrieved.RoomID, "Retrieved class room should match")
+	})
+
+	t.Run("Schedule", func(t *testing.T) {
+		store := newStore(t)
+
+		class := &repository.Class{
+			ID:        "class-1",
+			Name:      "Yoga",
+			StartDate: date(2025, 4, 1),
+			EndDate:   date(2025, 4, 30),
+			Capacity:  15,
+			Schedule: &schedule.Recurrence{
+				Weekdays:        []string{"monday", "wednesday"},
+				StartTime:       "18:30",
+				DurationMinutes: 60,
+				ExDates:         []string{"2025-04-21"},
+			},
+		}
+		require.NoError(t, store.Create(class), "Should create class without error")
+
+		retrieved, err := store.GetByID("class-1")
+		require.NoError(t, err, "Should retrieve class without error")
+		assert.Equal(t, class.Schedule, retrieved.Schedule, "Retrieved class schedule should match")
+
+		updated := *retrieved
+		updated.Schedule = nil
+		require.NoError(t, store.Update(&updated), "Should update class without error")
+
+		retrieved, err = store.GetByID("class-1")
+		require.NoError(t, err, "Should retrieve class without error")
+		assert.Nil(t, retrieved.Schedule, "Schedule should be cleared")
+	})
+
+	t.Run("GetByIDNotFound", func(t *testing.T) {
+		store := newStore(t)
+
+		_, err := store.GetByID("non-existent-id")
+		require.Error(t, err, "Should return error for non-existent class")
+		assert.Contains(t, err.Error(), "not found", "Error should report the class was not found")
+	})
+
+	t.Run("DuplicateID", func(t *testing.T) {
+		store := newStore(t)
+
+		class := &repository.Class{ID: "class-1", Name: "Yoga", StartDate: date(2025, 4, 25), EndDate: date(2025, 4, 30), Capacity: 15}
+		require.NoError(t, store.Create(class), "Should create class without error")
+
+		duplicate := &repository.Class{ID: "class-1", Name: "Pilates", StartDate: date(2025, 5, 1), EndDate: date(2025, 5, 2), Capacity: 10}
+		err := store.Create(duplicate)
+		require.Error(t, err, "Should return error for duplicate class ID")
+		assert.Contains(t, err.Error(), "already exists", "Error should report the class already exists")
+	})
+
+	t.Run("GetAll", func(t *testing.T) {
+		store := newStore(t)
+
+		classes, err := store.GetAll()
+		require.NoError(t, err, "Should retrieve all classes without error")
+		assert.Empty(t, classes, "New store should have no classes")
+
+		for i := 0; i < 3; i++ {
+			class := &repository.Class{ID: fmt.Sprintf("class-%d", i), Name: "Yoga", StartDate: date(2025, 4, 25), EndDate: date(2025, 4, 30), Capacity: 15}
+			require.NoError(t, store.Create(class), "Should create class without error")
+		}
+
+		classes, err = store.GetAll()
+		require.NoError(t, err, "Should retrieve all classes without error")
+		assert.Len(t, classes, 3, "Should return 3 classes")
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		store := newStore(t)
+
+		class := &repository.Class{ID: "class-1", Name: "Yoga", StartDate: date(2025, 4, 25), EndDate: date(2025, 4, 30), Capacity: 15}
+		require.NoError(t, store.Create(class), "Should create class without error")
+
+		updated := &repository.Class{ID: "class-1", Name: "Power Yoga", StartDate: date(2025, 4, 26), EndDate: date(2025, 5, 10), Capacity: 20}
+		require.NoError(t, store.Update(updated), "Should update class without error")
+
+		retrieved, err := store.GetByID("class-1")
+		require.NoError(t, err, "Should retrieve class without error")
+		assert.Equal(t, "Power Yoga", retrieved.Name, "Class name should be updated")
+		assert.True(t, date(2025, 4, 26).Equal(retrieved.StartDate), "Class start date should be updated")
+		assert.True(t, date(2025, 5, 10).Equal(retrieved.EndDate), "Class end date should be updated")
+		assert.Equal(t, 20, retrieved.Capacity, "Class capacity should be updated")
+
+		err = store.Update(&repository.Class{ID: "non-existent-id", Name: "Yoga", StartDate: date(2025, 4, 25), EndDate: date(2025, 4, 30), Capacity: 15})
+		require.Error(t, err, "Should return error when updating a non-existent class")
+		assert.Contains(t, err.Error(), "not found", "Error should report the class was not found")
+	})
+
+	t.Run("List", func(t *testing.T) {
+		store := newStore(t)
+
+		classes := []*repository.Class{
+			{ID: "class-1", Name: "Morning Yoga", StartDate: date(2025, 4, 1), EndDate: date(2025, 4, 10), Capacity: 10},
+			{ID: "class-2", Name: "Pilates", StartDate: date(2025, 4, 5), EndDate: date(2025, 4, 20), Capacity: 20, InstructorID: "instructor-1"},
+			{ID: "class-3", Name: "Evening Yoga", StartDate: date(2025, 4, 5), EndDate: date(2025, 4, 30), Capacity: 15, RoomID: "room-1"},
+			{ID: "class-4", Name: "Spin", StartDate: date(2025, 5, 1), EndDate: date(2025, 5, 31), Capacity: 15},
+		}
+		for _, class := range classes {
+			require.NoError(t, store.Create(class), "Should create class without error")
+		}
+
+		sort := []repository.SortField{{Name: "start_date"}, {Name: "name", Desc: true}}
+		all, next, err := store.List(repository.ClassFilter{}, repository.ListOptions{Sort: sort})
+		require.NoError(t, err, "Should list classes without error")
+		assert.Nil(t, next, "Unlimited list should have no next page")
+		assert.Equal(t, []string{"class-1", "class-2", "class-3", "class-4"}, classIDs(all), "Should order by start date, then name descending")
+
+		byName, _, err := store.List(repository.ClassFilter{Name: "yoga"}, repository.ListOptions{Sort: sort})
+		require.NoError(t, err, "Should list classes without error")
+		assert.Equal(t, []string{"class-1", "class-3"}, classIDs(byName), "Should match names ignoring case")
+
+		byRange, _, err := store.List(repository.ClassFilter{From: date(2025, 4, 15), To: date(2025, 4, 30)}, repository.ListOptions{Sort: sort})
+		require.NoError(t, err, "Should list classes without error")
+		assert.Equal(t, []string{"class-2", "class-3"}, classIDs(byRange), "Should return classes running within the range")
+
+		byInstructor, _, err := store.List(repository.ClassFilter{InstructorID: "instructor-1"}, repository.ListOptions{Sort: sort})
+		require.NoError(t, err, "Should list classes without error")
+		assert.Equal(t, []string{"class-2"}, classIDs(byInstructor), "Should return the instructor's classes")
+
+		byRoom, _, err := store.List(repository.ClassFilter{RoomID: "room-1"}, repository.ListOptions{Sort: sort})
+		require.NoError(t, err, "Should list classes without error")
+		assert.Equal(t, []string{"class-3"}, classIDs(byRoom), "Should return the classes held in the room")
+
+		// Walk the pages; capacity ties between class-3 and class-4 are broken by ID
+		bySize := []repository.SortField{{Name: "capacity", Desc: true}}
+		var pages [][]string
+		var after *repository.Cursor
+		for {
+			page, next, err := store.List(repository.ClassFilter{}, repository.ListOptions{Sort: bySize, After: after, Limit: 2})
+			require.NoError(t, err, "Should list classes without error")
+			pages = append(pages, classIDs(page))
+			if next == nil {
+				break
+			}
+			// Cursors reach clients in encoded form
+			after, err = repository.DecodeCursor(repository.EncodeCursor(next))
+			require.NoError(t, err, "Should decode cursor without error")
+		}
+		assert.Equal(t, [][]string{{"class-2", "class-3"}, {"class-4", "class-1"}}, pages, "Should page through classes in order")
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		store := newStore(t)
+
+		class := &repository.Class{ID: "class-1", Name: "Yoga", StartDate: date(2025, 4, 25), EndDate: date(2025, 4, 30), Capacity: 15}
+		require.NoError(t, store.Create(class), "Should create class without error")
+		require.NoError(t, store.Delete("class-1"), "Should delete class without error")
+
+		_, err := store.GetByID("class-1")
+		assert.Error(t, err, "Deleted class should not be found")
+
+		err = store.Delete("class-1")
+		require.Error(t, err, "Should return error when deleting a non-existent class")
+		assert.Contains(t, err.Error(), "not found", "Error should report the class was not found")
+	})
+}
+
+// RunBookingStoreTests runs the BookingStore contract against stores built by newStore
+func RunBookingStoreTests(t *testing.T, newStore BookingStoreFactory) {
+	t.Run("CreateAndGetByID", func(t *testing.T) {
+		store := newStore(t)
+
+		booking := &repository.Booking{
+			ID:         "booking-1",
+			MemberName: "USER A",
+			ClassID:    "class-1",
+			Date:       date(2025, 4, 25),
+			CreatedAt:  time.Date(2025, 4, 24, 14, 30, 45, 0, time.UTC),
+		}
+		require.NoError(t, store.Create(booking), "Should create booking without error")
+
+		retrieved, err := store.GetByID("booking-1")
+		require.NoError(t, err, "Should retrieve booking without error")
+		assert.Equal(t, booking.ID, retrieved.ID, "Retrieved booking ID should match")
+		assert.Equal(t, booking.MemberName, retrieved.MemberName, "Retrieved booking member name should match")
+		assert.Equal(t, booking.ClassID, retrieved.ClassID, "Retrieved booking class ID should match")
+		assert.True(t, booking.Date.Equal(retrieved.Date), "Retrieved booking date should match")
+		assert.True(t, booking.CreatedAt.Equal(retrieved.CreatedAt), "Retrieved booking creation time should match")
+	})
+
+	t.Run("GetByIDNotFound", func(t *testing.T) {
+		store := newStore(t)
+
+		_, err := store.GetByID("non-existent-id")
+		require.Error(t, err, "Should return error for non-existent booking")
+		assert.Contains(t, err.Error(), "not found", "Error should report the booking was not found")
+	})
+
+	t.Run("DuplicateID", func(t *testing.T) {
+		store := newStore(t)
+
+		booking := &repository.Booking{ID: "booking-1", MemberName: "USER A", ClassID: "class-1", Date: date(2025, 4, 25)}
+		require.NoError(t, store.Create(booking), "Should create booking without error")
+
+		duplicate := &repository.Booking{ID: "booking-1", MemberName: "USER B", ClassID: "class-2", Date: date(2025, 4, 26)}
+		err := store.Create(duplicate)
+		require.Error(t, err, "Should return error for duplicate booking ID")
+		assert.Contains(t, err.Error(), "already exists", "Error should report the booking already exists")
+	})
+
+	t.Run("Queries", func(t *testing.T) {
+		store := newStore(t)
+
+		bookings := []*repository.Booking{
+			{ID: "booking-1", MemberName: "USER A", ClassID: "class-1", Date: date(2025, 4, 25)},
+			{ID: "booking-2", MemberName: "USER B", ClassID: "class-1", Date: date(2025, 4, 26)},
+			{ID: "booking-3", MemberName: "USER C", ClassID: "class-2", Date: date(2025, 4, 25)},
+			{ID: "booking-4", MemberName: "USER D", Date: date(2025, 4, 27)},
+		}
+		for _, booking := range bookings {
+			require.NoError(t, store.Create(booking), "Should create booking without error")
+		}
+
+		all, err := store.GetAll()
+		require.NoError(t, err, "Should retrieve all bookings without error")
+		assert.Len(t, all, 4, "Should return 4 bookings")
+
+		byDate, err := store.GetBookingsByDate(date(2025, 4, 25))
+		require.NoError(t, err, "Should retrieve bookings by date without error")
+		assert.ElementsMatch(t, []string{"booking-1", "booking-3"}, bookingIDs(byDate), "Should return bookings for the date")
+
+		byClass, err := store.GetByClassID("class-1")
+		require.NoError(t, err, "Should retrieve bookings by class ID without error")
+		assert.ElementsMatch(t, []string{"booking-1", "booking-2"}, bookingIDs(byClass), "Should return bookings for the class")
+
+		count, err := store.CountByClassAndDate("class-1", date(2025, 4, 25))
+		require.NoError(t, err, "Should count bookings without error")
+		assert.Equal(t, 1, count, "Should count 1 booking for the class and date")
+	})
+
+	t.Run("List", func(t *testing.T) {
+		store := newStore(t)
+
+		created := time.Date(2025, 4, 20, 9, 0, 0, 0, time.UTC)
+		bookings := []*repository.Booking{
+			{ID: "booking-1", MemberID: "member-1", MemberName: "Ann", ClassID: "class-1", Date: date(2025, 4, 25), CreatedAt: created},
+			{ID: "booking-2", MemberName: "Bob", ClassID: "class-1", Date: date(2025, 4, 26), CreatedAt: created.Add(time.Hour)},
+			{ID: "booking-3", MemberName: "ann", ClassID: "class-2", Date: date(2025, 4, 25), CreatedAt: created.Add(time.Millisecond)},
+			{ID: "booking-4", MemberName: "Cid", ClassID: "class-2", Date: date(2025, 4, 27), CreatedAt: created.Add(time.Minute)},
+			{ID: "booking-5", MemberName: "Dee", ClassID: "class-1", Date: date(2025, 4, 28), CreatedAt: created.Add(time.Minute)},
+		}
+		for _, booking := range bookings {
+			require.NoError(t, store.Create(booking), "Should create booking without error")
+		}
+
+		sort := []repository.SortField{{Name: "date"}, {Name: "created_at"}}
+		all, next, err := store.List(repository.BookingFilter{}, repository.ListOptions{Sort: sort})
+		require.NoError(t, err, "Should list bookings without error")
+		assert.Nil(t, next, "Unlimited list should have no next page")
+		assert.Equal(t, []string{"booking-1", "booking-3", "booking-2", "booking-4", "booking-5"}, bookingIDs(all), "Should order by date, then creation time")
+
+		byClass, _, err := store.List(repository.BookingFilter{ClassID: "class-1", From: date(2025, 4, 26)}, repository.ListOptions{Sort: sort})
+		require.NoError(t, err, "Should list bookings without error")
+		assert.Equal(t, []string{"booking-2", "booking-5"}, bookingIDs(byClass), "Should filter by class and start date")
+
+		byMember, _, err := store.List(repository.BookingFilter{Member: "ANN", To: date(2025, 4, 25)}, repository.ListOptions{Sort: sort})
+		require.NoError(t, err, "Should list bookings without error")
+		assert.Equal(t, []string{"booking-1", "booking-3"}, bookingIDs(byMember), "Should match member names ignoring case")
+
+		byMemberID, _, err := store.List(repository.BookingFilter{Member: "member-1"}, repository.ListOptions{Sort: sort})
+		require.NoError(t, err, "Should list bookings without error")
+		assert.Equal(t, []string{"booking-1"}, bookingIDs(byMemberID), "Should match member IDs")
+
+		// A name-only booking whose name happens to be the member ID is not the member's
+		require.NoError(t, store.Create(&repository.Booking{ID: "booking-6", MemberName: "member-1", ClassID: "class-1", Date: date(2025, 4, 29), CreatedAt: created}))
+		onlyMemberID, _, err := store.List(repository.BookingFilter{MemberID: "member-1"}, repository.ListOptions{Sort: sort})
+		require.NoError(t, err, "Should list bookings without error")
+		assert.Equal(t, []string{"booking-1"}, bookingIDs(onlyMemberID), "Should match only bookings made for the member")
+		require.NoError(t, store.Delete("booking-6"))
+
+		newestFirst := []repository.SortField{{Name: "created_at", Desc: true}}
+		var pages [][]string
+		var after *repository.Cursor
+		for {
+			page, next, err := store.List(repository.BookingFilter{}, repository.ListOptions{Sort: newestFirst, After: after, Limit: 2})
+			require.NoError(t, err, "Should list bookings without error")
+			pages = append(pages, bookingIDs(page))
+			if next == nil {
+				break
+			}
+			after, err = repository.DecodeCursor(repository.EncodeCursor(next))
+			require.NoError(t, err, "Should decode cursor without error")
+		}
+		assert.Equal(t, [][]string{{"booking-2", "booking-4"}, {"booking-5", "booking-3"}, {"booking-1"}}, pages, "Should page through bookings in order")
+	})
+
+	t.Run("CreateWithCapacity", func(t *testing.T) {
+		store := newStore(t)
+
+		for i := 0; i < 2; i++ {
+			booking := &repository.Booking{ID: fmt.Sprintf("booking-%d", i), MemberName: fmt.Sprintf("USER %d", i), ClassID: "class-1", Date: date(2025, 4, 25)}
+			require.NoError(t, store.CreateWithCapacity(booking, 2), "Should create booking while capacity remains")
+		}
+
+		full := &repository.Booking{ID: "booking-full", MemberName: "USER FULL", ClassID: "class-1", Date: date(2025, 4, 25)}
+		assert.ErrorIs(t, store.CreateWithCapacity(full, 2), repository.ErrCapacityExceeded, "Should reject booking once capacity is reached")
+
+		_, err := store.GetByID("booking-full")
+		assert.Error(t, err, "Rejected booking should not be stored")
+
+		nextDay := &repository.Booking{ID: "booking-next-day", MemberName: "USER 0", ClassID: "class-1", Date: date(2025, 4, 26)}
+		assert.NoError(t, store.CreateWithCapacity(nextDay, 2), "Capacity should be counted per date")
+	})
+
+	t.Run("CreateWithCapacityConcurrent", func(t *testing.T) {
+		store := newStore(t)
+		capacity := 15
+
+		var wg sync.WaitGroup
+		var created atomic.Int32
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				booking := &repository.Booking{ID: fmt.Sprintf("booking-%d", i), MemberName: fmt.Sprintf("USER %d", i), ClassID: "class-1", Date: date(2025, 4, 25)}
+				if err := store.CreateWithCapacity(booking, capacity); err == nil {
+					created.Add(1)
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(capacity), created.Load(), "Should only create as many bookings as the capacity allows")
+
+		count, err := store.CountByClassAndDate("class-1", date(2025, 4, 25))
+		require.NoError(t, err, "Should count bookings without error")
+		assert.Equal(t, capacity, count, "Should store exactly capacity bookings")
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		store := newStore(t)
+
+		booking := &repository.Booking{ID: "booking-1", MemberName: "USER A", ClassID: "class-1", Date: date(2025, 4, 25), Status: repository.BookingStatusConfirmed}
+		require.NoError(t, store.Create(booking), "Should create booking without error")
+
+		cancelledAt := time.Date(2025, 4, 24, 9, 15, 0, 0, time.UTC)
+		cancelled := &repository.Booking{
+			ID:                 "booking-1",
+			MemberName:         "USER A",
+			ClassID:            "class-1",
+			Date:               date(2025, 4, 25),
+			Status:             repository.BookingStatusCancelled,
+			CancelledAt:        &cancelledAt,
+			CancellationReason: "Feeling unwell",
+		}
+		require.NoError(t, store.Update(cancelled), "Should update booking without error")
+
+		retrieved, err := store.GetByID("booking-1")
+		require.NoError(t, err, "Should retrieve booking without error")
+		assert.Equal(t, repository.BookingStatusCancelled, retrieved.Status, "Booking status should be updated")
+		require.NotNil(t, retrieved.CancelledAt, "Cancellation time should be stored")
+		assert.True(t, cancelledAt.Equal(*retrieved.CancelledAt), "Cancellation time should match")
+		assert.Equal(t, "Feeling unwell", retrieved.CancellationReason, "Cancellation reason should match")
+
+		count, err := store.CountByClassAndDate("class-1", date(2025, 4, 25))
+		require.NoError(t, err, "Should count bookings without error")
+		assert.Zero(t, count, "Cancelled bookings should not count towards capacity")
+
+		err = store.Update(&repository.Booking{ID: "non-existent-id", MemberName: "USER A", Date: date(2025, 4, 25)})
+		require.Error(t, err, "Should return error when updating a non-existent booking")
+		assert.Contains(t, err.Error(), "not found", "Error should report the booking was not found")
+	})
+
+	t.Run("CancelledBookingsFreeCapacity", func(t *testing.T) {
+		store := newStore(t)
+
+		cancelled := &repository.Booking{ID: "booking-1", MemberName: "USER A", ClassID: "class-1", Date: date(2025, 4, 25), Status: repository.BookingStatusCancelled}
+		require.NoError(t, store.Create(cancelled), "Should create booking without error")
+
+		booking := &repository.Booking{ID: "booking-2", MemberName: "USER B", ClassID: "class-1", Date: date(2025, 4, 25), Status: repository.BookingStatusConfirmed}
+		assert.NoError(t, store.CreateWithCapacity(booking, 1), "Cancelled booking should not take up a place")
+	})
+
+	t.Run("DuplicateMemberBooking", func(t *testing.T) {
+		store := newStore(t)
+
+		booking := &repository.Booking{ID: "booking-1", MemberName: "USER A", ClassID: "class-1", Date: date(2025, 4, 25), Status: repository.BookingStatusConfirmed}
+		require.NoError(t, store.Create(booking), "Should create booking without error")
+
+		assertDuplicate := func(err error, msg string) {
+			t.Helper()
+			require.ErrorIs(t, err, repository.ErrDuplicateBooking, msg)
+			var duplicate *repository.DuplicateBookingError
+			require.ErrorAs(t, err, &duplicate, msg)
+			assert.Equal(t, "booking-1", duplicate.ExistingID, "Error should identify the existing booking")
+		}
+
+		again := &repository.Booking{ID: "booking-2", MemberName: "USER A", ClassID: "class-1", Date: date(2025, 4, 25), Status: repository.BookingStatusConfirmed}
+		assertDuplicate(store.CheckDuplicate(again), "Should report the member's existing booking")
+		assert.NoError(t, store.CheckDuplicate(booking), "A booking should not duplicate itself")
+		assertDuplicate(store.Create(again), "Should reject a second booking for the same class and date")
+		assertDuplicate(store.CreateWithCapacity(again, 10), "Should reject a duplicate booking while capacity remains")
+		assertDuplicate(store.CreateWithCapacity(again, 1), "Should report a duplicate booking before a full class")
+
+		_, err := store.GetByID("booking-2")
+		assert.Error(t, err, "Rejected booking should not be stored")
+
+		otherDay := &repository.Booking{ID: "booking-3", MemberName: "USER A", ClassID: "class-1", Date: date(2025, 4, 26), Status: repository.BookingStatusConfirmed}
+		require.NoError(t, store.CreateWithCapacity(otherDay, 10), "Should allow the same member on another date")
+
+		otherMember := &repository.Booking{ID: "booking-4", MemberName: "USER B", ClassID: "class-1", Date: date(2025, 4, 25), Status: repository.BookingStatusConfirmed}
+		require.NoError(t, store.CreateWithCapacity(otherMember, 10), "Should allow another member in the same class")
+
+		// Moving a booking onto a date the member is already booked for is rejected
+		moved := *otherDay
+		moved.Date = date(2025, 4, 25)
+		assertDuplicate(store.UpdateConfirmed(otherDay, &moved, 10), "Should reject moving onto a date the member is booked for")
+		assertDuplicate(store.Update(&moved), "Should reject updating into a duplicate booking")
+
+		// Cancelled bookings do not block a new booking
+		cancelled := *booking
+		cancelled.Status = repository.BookingStatusCancelled
+		require.NoError(t, store.Update(&cancelled), "Should cancel booking without error")
+		require.NoError(t, store.CreateWithCapacity(again, 10), "Should allow rebooking after a cancellation")
+	})
+
+	t.Run("DuplicateRegisteredMemberBooking", func(t *testing.T) {
+		store := newStore(t)
+
+		booking := &repository.Booking{ID: "booking-1", MemberID: "member-1", MemberName: "Jane Doe", ClassID: "class-1", Date: date(2025, 4, 25), Status: repository.BookingStatusConfirmed}
+		require.NoError(t, store.Create(booking), "Should create booking without error")
+
+		retrieved, err := store.GetByID("booking-1")
+		require.NoError(t, err, "Should retrieve booking without error")
+		assert.Equal(t, "member-1", retrieved.MemberID, "Retrieved member ID should match")
+
+		// The same member is matched by ID even if their name has changed
+		renamed := &repository.Booking{ID: "booking-2", MemberID: "member-1", MemberName: "Jane Smith", ClassID: "class-1", Date: date(2025, 4, 25), Status: repository.BookingStatusConfirmed}
+		assert.ErrorIs(t, store.CreateWithCapacity(renamed, 10), repository.ErrDuplicateBooking, "Should reject a second booking by the same member")
+
+		// Another member, or a name-only booking, with the same name is a different person
+		namesake := &repository.Booking{ID: "booking-3", MemberID: "member-2", MemberName: "Jane Doe", ClassID: "class-1", Date: date(2025, 4, 25), Status: repository.BookingStatusConfirmed}
+		assert.NoError(t, store.CreateWithCapacity(namesake, 10), "Should allow another member with the same name")
+
+		nameOnly := &repository.Booking{ID: "booking-4", MemberName: "Jane Doe", ClassID: "class-1", Date: date(2025, 4, 25), Status: repository.BookingStatusConfirmed}
+		assert.NoError(t, store.CreateWithCapacity(nameOnly, 10), "Should allow a name-only booking with the same name")
+	})
+
+	t.Run("DuplicateMemberBookingConcurrent", func(t *testing.T) {
+		store := newStore(t)
+
+		var wg sync.WaitGroup
+		var created, duplicates atomic.Int32
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				booking := &repository.Booking{ID: fmt.Sprintf("booking-%d", i), MemberName: "USER A", ClassID: "class-1", Date: date(2025, 4, 25)}
+				err := store.CreateWithCapacity(booking, 100)
+				if err == nil {
+					created.Add(1)
+				} else if errors.Is(err, repository.ErrDuplicateBooking) {
+					duplicates.Add(1)
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(1), created.Load(), "Should create exactly one booking for the member")
+		assert.Equal(t, int32(49), duplicates.Load(), "Every other attempt should be reported as a duplicate")
+	})
+
+	t.Run("UpdateConfirmed Capacity", func(t *testing.T) {
+		store := newStore(t)
+
+		bookings := []*repository.Booking{
+			{ID: "booking-1", MemberName: "USER A", ClassID: "class-1", Date: date(2025, 4, 25), Status: repository.BookingStatusConfirmed},
+			{ID: "booking-2", MemberName: "USER B", ClassID: "class-1", Date: date(2025, 4, 26), Status: repository.BookingStatusConfirmed},
+		}
+		for _, booking := range bookings {
+			require.NoError(t, store.Create(booking), "Should create booking without error")
+		}
+		previous := *bookings[0]
+
+		// Moving onto a full date is rejected
+		moved := previous
+		moved.Date = date(2025, 4, 26)
+		assert.ErrorIs(t, store.UpdateConfirmed(&previous, &moved, 1), repository.ErrCapacityExceeded, "Should reject moving onto a full date")
+
+		retrieved, err := store.GetByID("booking-1")
+		require.NoError(t, err, "Should retrieve booking without error")
+		assert.True(t, date(2025, 4, 25).Equal(retrieved.Date), "Rejected move should leave the booking unchanged")
+
+		// A booking does not compete with itself for a place
+		same := *bookings[1]
+		same.MemberName = "USER B2"
+		assert.NoError(t, store.UpdateConfirmed(bookings[1], &same, 1), "Should allow updating a booking in a full class")
+
+		moved.Date = date(2025, 4, 27)
+		require.NoError(t, store.UpdateConfirmed(&previous, &moved, 1), "Should move booking to a date with capacity")
+
+		retrieved, err = store.GetByID("booking-1")
+		require.NoError(t, err, "Should retrieve booking without error")
+		assert.True(t, date(2025, 4, 27).Equal(retrieved.Date), "Booking should be moved")
+	})
+
+	t.Run("UpdateConfirmed", func(t *testing.T) {
+		store := newStore(t)
+
+		bookings := []*repository.Booking{
+			{ID: "booking-1", MemberName: "USER A", ClassID: "class-1", Date: date(2025, 4, 25), Status: repository.BookingStatusConfirmed},
+			{ID: "booking-2", MemberName: "USER B", ClassID: "class-1", Date: date(2025, 4, 26), Status: repository.BookingStatusConfirmed},
+		}
+		for _, booking := range bookings {
+			require.NoError(t, store.Create(booking), "Should create booking without error")
+		}
+		previous := *bookings[0]
+
+		// Moving onto a full date is rejected
+		moved := previous
+		moved.Date = date(2025, 4, 26)
+		assert.ErrorIs(t, store.UpdateConfirmed(&previous, &moved, 1), repository.ErrCapacityExceeded, "Should reject moving onto a full date")
+
+		// Without a capacity the class is unlimited
+		require.NoError(t, store.UpdateConfirmed(&previous, &moved, 0), "Should move booking without a capacity check")
+
+		// A move made by another request is not overwritten
+		cancelled := previous
+		cancelled.Status = repository.BookingStatusCancelled
+		assert.ErrorIs(t, store.UpdateConfirmed(&previous, &cancelled, 0), repository.ErrBookingChanged, "Should reject updating a booking moved since it was read")
+
+		retrieved, err := store.GetByID("booking-1")
+		require.NoError(t, err, "Should retrieve booking without error")
+		assert.True(t, date(2025, 4, 26).Equal(retrieved.Date), "Rejected update should leave the move in place")
+		assert.Equal(t, repository.BookingStatusConfirmed, retrieved.Status, "Rejected update should leave the booking confirmed")
+
+		cancelled = moved
+		cancelled.Status = repository.BookingStatusCancelled
+		require.NoError(t, store.UpdateConfirmed(&moved, &cancelled, 0), "Should cancel a booking that is still confirmed")
+
+		again := cancelled
+		again.CancellationReason = "twice"
+		assert.ErrorIs(t, store.UpdateConfirmed(&moved, &again, 0), repository.ErrBookingNotConfirmed, "Should reject updating a booking that is no longer confirmed")
+
+		err = store.UpdateConfirmed(&previous, &repository.Booking{ID: "non-existent-id", ClassID: "class-1", Date: date(2025, 4, 28)}, 0)
+		assert.ErrorIs(t, err, repository.ErrBookingNotFound, "Should return error when updating a non-existent booking")
+	})
+
+	t.Run("Concurrent UpdateConfirmed", func(t *testing.T) {
+		store := newStore(t)
+
+		booking := &repository.Booking{ID: "booking-1", MemberName: "USER A", ClassID: "class-1", Date: date(2025, 4, 25), Status: repository.BookingStatusConfirmed}
+		require.NoError(t, store.Create(booking), "Should create booking without error")
+		previous := *booking
+
+		var wg sync.WaitGroup
+		var updated, rejected atomic.Int32
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+
+				cancelled := previous
+				cancelled.Status = repository.BookingStatusCancelled
+				cancelled.CancellationReason = fmt.Sprintf("attempt %d", i)
+
+				err := store.UpdateConfirmed(&previous, &cancelled, 0)
+				if err == nil {
+					updated.Add(1)
+				} else if errors.Is(err, repository.ErrBookingNotConfirmed) {
+					rejected.Add(1)
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(1), updated.Load(), "Should cancel the booking exactly once")
+		assert.Equal(t, int32(19), rejected.Load(), "Every other attempt should find the booking no longer confirmed")
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		store := newStore(t)
+
+		booking := &repository.Booking{ID: "booking-1", MemberName: "USER A", ClassID: "class-1", Date: date(2025, 4, 25)}
+		require.NoError(t, store.Create(booking), "Should create booking without error")
+		require.NoError(t, store.Delete("booking-1"), "Should delete booking without error")
+
+		_, err := store.GetByID("booking-1")
+		assert.Error(t, err, "Deleted booking should not be found")
+
+		count, err := store.CountByClassAndDate("class-1", date(2025, 4, 25))
+		require.NoError(t, err, "Should count bookings without error")
+		assert.Zero(t, count, "Deleted booking should not count towards capacity")
+
+		err = store.Delete("booking-1")
+		require.Error(t, err, "Should return error when deleting a non-existent booking")
+		assert.Contains(t, err.Error(), "not found", "Error should report the booking was not found")
+	})
+}
+
+// RunWaitlistStoreTests runs the WaitlistStore contract against stores built by newStore
+func RunWaitlistStoreTests(t *testing.T, newStore WaitlistStoreFactory) {
+	joined := time.Date(2025, 4, 24, 9, 0, 0, 0, time.UTC)
+
+	t.Run("CreateAndGetByID", func(t *testing.T) {
+		store := newStore(t)
+
+		entry := &repository.WaitlistEntry{ID: "entry-1", MemberName: "USER A", ClassID: "class-1", Date: date(2025, 4, 25), CreatedAt: joined}
+		require.NoError(t, store.Create(entry), "Should create waitlist entry without error")
+
+		retrieved, err := store.GetByID("entry-1")
+		require.NoError(t, err, "Should retrieve waitlist entry without error")
+		assert.Equal(t, entry.MemberName, retrieved.MemberName, "Retrieved member name should match")
+		assert.Equal(t, entry.ClassID, retrieved.ClassID, "Retrieved class ID should match")
+		assert.True(t, entry.Date.Equal(retrieved.Date), "Retrieved date should match")
+		assert.True(t, entry.CreatedAt.Equal(retrieved.CreatedAt), "Retrieved join time should match")
+
+		err = store.Create(entry)
+		require.Error(t, err, "Should return error for duplicate waitlist entry ID")
+		assert.Contains(t, err.Error(), "already exists", "Error should report the entry already exists")
+
+		_, err = store.GetByID("non-existent-id")
+		require.Error(t, err, "Should return error for non-existent waitlist entry")
+		assert.Contains(t, err.Error(), "not found", "Error should report the entry was not found")
+	})
+
+	t.Run("GetByOccurrenceIsFIFO", func(t *testing.T) {
+		store := newStore(t)
+
+		entries := []*repository.WaitlistEntry{
+			{ID: "entry-c", MemberName: "USER C", ClassID: "class-1", Date: date(2025, 4, 25), CreatedAt: joined.Add(2 * time.Second)},
+			{ID: "entry-a", MemberName: "USER A", ClassID: "class-1", Date: date(2025, 4, 25), CreatedAt: joined},
+			{ID: "entry-b", MemberName: "USER B", ClassID: "class-1", Date: date(2025, 4, 25), CreatedAt: joined.Add(150 * time.Millisecond)},
+			{ID: "entry-other-day", MemberName: "USER D", ClassID: "class-1", Date: date(2025, 4, 26), CreatedAt: joined},
+			{ID: "entry-other-class", MemberName: "USER E", ClassID: "class-2", Date: date(2025, 4, 25), CreatedAt: joined},
+		}
+		for _, entry := range entries {
+			require.NoError(t, store.Create(entry), "Should create waitlist entry without error")
+		}
+
+		waitlist, err := store.GetByOccurrence("class-1", date(2025, 4, 25))
+		require.NoError(t, err, "Should retrieve waitlist without error")
+		assert.Equal(t, []string{"entry-a", "entry-b", "entry-c"}, waitlistIDs(waitlist), "Waitlist should be ordered by join time")
+
+		all, err := store.GetAll()
+		require.NoError(t, err, "Should retrieve all waitlist entries without error")
+		assert.Len(t, all, 5, "Should return 5 waitlist entries")
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		store := newStore(t)
+
+		entry := &repository.WaitlistEntry{ID: "entry-1", MemberName: "USER A", ClassID: "class-1", Date: date(2025, 4, 25), CreatedAt: joined}
+		require.NoError(t, store.Create(entry), "Should create waitlist entry without error")
+		require.NoError(t, store.Delete("entry-1"), "Should delete waitlist entry without error")
+
+		waitlist, err := store.GetByOccurrence("class-1", date(2025, 4, 25))
+		require.NoError(t, err, "Should retrieve waitlist without error")
+		assert.Empty(t, waitlist, "Deleted entry should leave the waitlist")
+
+		err = store.Delete("entry-1")
+		require.Error(t, err, "Should return error when deleting a non-existent entry")
+		assert.Contains(t, err.Error(), "not found", "Error should report the entry was not found")
+	})
+
+	t.Run("DeleteByClass", func(t *testing.T) {
+		store := newStore(t)
+
+		entries := []*repository.WaitlistEntry{
+			{ID: "entry-a", MemberName: "USER A", ClassID: "class-1", Date: date(2025, 4, 25), CreatedAt: joined},
+			{ID: "entry-b", MemberName: "USER B", ClassID: "class-1", Date: date(2025, 4, 26), CreatedAt: joined},
+			{ID: "entry-other-class", MemberName: "USER C", ClassID: "class-2", Date: date(2025, 4, 25), CreatedAt: joined},
+		}
+		for _, entry := range entries {
+			require.NoError(t, store.Create(entry), "Should create waitlist entry without error")
+		}
+
+		require.NoError(t, store.DeleteByClass("class-1"), "Should delete class waitlist entries without error")
+		all, err := store.GetAll()
+		require.NoError(t, err, "Should retrieve all waitlist entries without error")
+		assert.Equal(t, []string{"entry-other-class"}, waitlistIDs(all), "Only the other class's entry should remain")
+	})
+}
+
+// RunMemberStoreTests runs the MemberStore contract against stores built by newStore
+func RunMemberStoreTests(t *testing.T, newStore MemberStoreFactory) {
+	t.Run("CreateAndGetByID", func(t *testing.T) {
+		store := newStore(t)
+
+		member := &repository.Member{
+			ID:        "member-1",
+			Name:      "Jane Doe",
+			Email:     "jane@example.com",
+			Phone:     "+353 1 234 5678",
+			CreatedAt: time.Date(2025, 4, 24, 14, 30, 45, 0, time.UTC),
+		}
+		require.NoError(t, store.Create(member), "Should create member without error")
+
+		retrieved, err := store.GetByID("member-1")
+		require.NoError(t, err, "Should retrieve member without error")
+		assert.Equal(t, member.Name, retrieved.Name, "Retrieved member name should match")
+		assert.Equal(t, member.Email, retrieved.Email, "Retrieved member email should match")
+		assert.Equal(t, member.Phone, retrieved.Phone, "Retrieved member phone should match")
+		assert.True(t, member.CreatedAt.Equal(retrieved.CreatedAt), "Retrieved creation time should match")
+
+		err = store.Create(member)
+		require.Error(t, err, "Should return error for duplicate member ID")
+		assert.Contains(t, err.Error(), "already exists", "Error should report the member already exists")
+
+		_, err = store.GetByID("non-existent-id")
+		require.Error(t, err, "Should return error for non-existent member")
+		assert.Contains(t, err.Error(), "not found", "Error should report the member was not found")
+	})
+
+	t.Run("GetAllUpdateAndDelete", func(t *testing.T) {
+		store := newStore(t)
+
+		for _, id := range []string{"member-1", "member-2"} {
+			require.NoError(t, store.Create(&repository.Member{ID: id, Name: "Jane Doe"}), "Should create member without error")
+		}
+
+		members, err := store.GetAll()
+		require.NoError(t, err, "Should retrieve all members without error")
+		assert.Len(t, members, 2, "Should return 2 members")
+
+		require.NoError(t, store.Update(&repository.Member{ID: "member-1", Name: "Jane Smith", Email: "jane@example.com"}), "Should update member without error")
+
+		retrieved, err := store.GetByID("member-1")
+		require.NoError(t, err, "Should retrieve member without error")
+		assert.Equal(t, "Jane Smith", retrieved.Name, "Member name should be updated")
+		assert.Equal(t, "jane@example.com", retrieved.Email, "Member email should be updated")
+
+		err = store.Update(&repository.Member{ID: "non-existent-id", Name: "Nobody"})
+		require.Error(t, err, "Should return error when updating a non-existent member")
+		assert.Contains(t, err.Error(), "not found", "Error should report the member was not found")
+
+		require.NoError(t, store.Delete("member-1"), "Should delete member without error")
+		_, err = store.GetByID("member-1")
+		assert.Error(t, err, "Deleted member should not be found")
+
+		err = store.Delete("member-1")
+		require.Error(t, err, "Should return error when deleting a non-existent member")
+		assert.Contains(t, err.Error(), "not found", "Error should report the member was not found")
+	})
+}
+
+// RunInstructorStoreTests runs the InstructorStore contract against stores built by newStore
+func RunInstructorStoreTests(t *testing.T, newStore InstructorStoreFactory) {
+	t.Run("CreateAndGetByID", func(t *testing.T) {
+		store := newStore(t)
+
+		instructor := &repository.Instructor{
+			ID:        "instructor-1",
+			Name:      "Alex Morgan",
+			Email:     "alex@example.com",
+			Phone:     "+353 1 234 5678",
+			Bio:       "Yoga and pilates teacher",
+			CreatedAt: time.Date(2025, 4, 24, 14, 30, 45, 0, time.UTC),
+		}
+		require.NoError(t, store.Create(instructor), "Should create instructor without error")
+
+		retrieved, err := store.GetByID("instructor-1")
+		require.NoError(t, err, "Should retrieve instructor without error")
+		assert.Equal(t, instructor.Name, retrieved.Name, "Retrieved instructor name should match")
+		assert.Equal(t, instructor.Email, retrieved.Email, "Retrieved instructor email should match")
+		assert.Equal(t, instructor.Phone, retrieved.Phone, "Retrieved instructor phone should match")
+		assert.Equal(t, instructor.Bio, retrieved.Bio, "Retrieved instructor bio should match")
+		assert.True(t, instructor.CreatedAt.Equal(retrieved.CreatedAt), "Retrieved creation time should match")
+
+		err = store.Create(instructor)
+		require.Error(t, err, "Should return error for duplicate instructor ID")
+		assert.Contains(t, err.Error(), "already exists", "Error should report the instructor already exists")
+
+		_, err = store.GetByID("non-existent-id")
+		require.Error(t, err, "Should return error for non-existent instructor")
+		assert.Contains(t, err.Error(), "not found", "Error should report the instructor was not found")
+	})
+
+	t.Run("GetAllUpdateAndDelete", func(t *testing.T) {
+		store := newStore(t)
+
+		for _, id := range []string{"instructor-1", "instructor-2"} {
+			require.NoError(t, store.Create(&repository.Instructor{ID: id, Name: "Alex Morgan"}), "Should create instructor without error")
+		}
+
+		instructors, err := store.GetAll()
+		require.NoError(t, err, "Should retrieve all instructors without error")
+		assert.Len(t, instructors, 2, "Should return 2 instructors")
+
+		require.NoError(t, store.Update(&repository.Instructor{ID: "instructor-1", Name: "Sam Morgan", Bio: "Spin"}), "Should update instructor without error")
+
+		retrieved, err := store.GetByID("instructor-1")
+		require.NoError(t, err, "Should retrieve instructor without error")
+		assert.Equal(t, "Sam Morgan", retrieved.Name, "Instructor name should be updated")
+		assert.Equal(t, "Spin", retrieved.Bio, "Instructor bio should be updated")
+
+		err = store.Update(&repository.Instructor{ID: "non-existent-id", Name: "Nobody"})
+		require.Error(t, err, "Should return error when updating a non-existent instructor")
+		assert.Contains(t, err.Error(), "not found", "Error should report the instructor was not found")
+
+		require.NoError(t, store.Delete("instructor-1"), "Should delete instructor without error")
+		_, err = store.GetByID("instructor-1")
+		assert.Error(t, err, "Deleted instructor should not be found")
+
+		err = store.Delete("instructor-1")
+		require.Error(t, err, "Should return error when deleting a non-existent instructor")
+		assert.Contains(t, err.Error(), "not found", "Error should report the instructor was not found")
+	})
+}
+
+// RunLocationStoreTests runs the LocationStore contract against stores built by newStore
+func RunLocationStoreTests(t *testing.T, newStore LocationStoreFactory) {
+	t.Run("CreateAndGetByID", func(t *testing.T) {
+		store := newStore(t)
+
+		location := &repository.Location{
+			ID:        "location-1",
+			Name:      "City Centre",
+			Address:   "1 Main Street, Dublin",
+			CreatedAt: time.Date(2025, 4, 24, 14, 30, 45, 0, time.UTC),
+		}
+		require.NoError(t, store.Create(location), "Should create location without error")
+
+		retrieved, err := store.GetByID("location-1")
+		require.NoError(t, err, "Should retrieve location without error")
+		assert.Equal(t, location.Name, retrieved.Name, "Retrieved location name should match")
+		assert.Equal(t, location.Address, retrieved.Address, "Retrieved location address should match")
+		assert.True(t, location.CreatedAt.Equal(retrieved.CreatedAt), "Retrieved creation time should match")
+
+		err = store.Create(location)
+		require.Error(t, err, "Should return error for duplicate location ID")
+		assert.Contains(t, err.Error(), "already exists", "Error should report the location already exists")
+
+		_, err = store.GetByID("non-existent-id")
+		require.Error(t, err, "Should return error for non-existent location")
+		assert.Contains(t, err.Error(), "not found", "Error should report the location was not found")
+	})
+
+	t.Run("GetAllUpdateAndDelete", func(t *testing.T) {
+		store := newStore(t)
+
+		for _, id := range []string{"location-1", "location-2"} {
+			require.NoError(t, store.Create(&repository.Location{ID: id, Name: "City Centre"}), "Should create location without error")
+		}
+
+		locations, err := store.GetAll()
+		require.NoError(t, err, "Should retrieve all locations without error")
+		assert.Len(t, locations, 2, "Should return 2 locations")
+
+		require.NoError(t, store.Update(&repository.Location{ID: "location-1", Name: "Docklands", Address: "2 Quay Street"}), "Should update location without error")
+
+		retrieved, err := store.GetByID("location-1")
+		require.NoError(t, err, "Should retrieve location without error")
+		assert.Equal(t, "Docklands", retrieved.Name, "Location name should be updated")
+		assert.Equal(t, "2 Quay Street", retrieved.Address, "Location address should be updated")
+
+		err = store.Update(&repository.Location{ID: "non-existent-id", Name: "Nowhere"})
+		require.Error(t, err, "Should return error when updating a non-existent location")
+		assert.Contains(t, err.Error(), "not found", "Error should report the location was not found")
+
+		require.NoError(t, store.Delete("location-1"), "Should delete location without error")
+		_, err = store.GetByID("location-1")
+		assert.Error(t, err, "Deleted location should not be found")
+
+		err = store.Delete("location-1")
+		require.Error(t, err, "Should return error when deleting a non-existent location")
+		assert.Contains(t, err.Error(), "not found", "Error should report the location was not found")
+	})
+}
+
+// RunRoomStoreTests runs the RoomStore contract against stores built by newStore
+func RunRoomStoreTests(t *testing.T, newStore RoomStoreFactory) {
+	t.Run("CreateAndGetByID", func(t *testing.T) {
+		store := newStore(t)
+
+		room := &repository.Room{
+			ID:         "room-1",
+			LocationID: "location-1",
+			Name:       "Studio A",
+			Capacity:   20,
+			CreatedAt:  time.Date(2025, 4, 24, 14, 30, 45, 0, time.UTC),
+		}
+		require.NoError(t, store.Create(room), "Should create room without error")
+
+		retrieved, err := store.GetByID("room-1")
+		require.NoError(t, err, "Should retrieve room without error")
+		assert.Equal(t, room.LocationID, retrieved.LocationID, "Retrieved room location should match")
+		assert.Equal(t, room.Name, retrieved.Name, "Retrieved room name should match")
+		assert.Equal(t, room.Capacity, retrieved.Capacity, "Retrieved room capacity should match")
+		assert.True(t, room.CreatedAt.Equal(retrieved.CreatedAt), "Retrieved creation time should match")
+
+		err = store.Create(room)
+		require.Error(t, err, "Should return error for duplicate room ID")
+		assert.Contains(t, err.Error(), "already exists", "Error should report the room already exists")
+
+		_, err = store.GetByID("non-existent-id")
+		require.Error(t, err, "Should return error for non-existent room")
+		assert.Contains(t, err.Error(), "not found", "Error should report the room was not found")
+	})
+
+	t.Run("GetByLocationID", func(t *testing.T) {
+		store := newStore(t)
+
+		rooms := []*repository.Room{
+			{ID: "room-1", LocationID: "location-1", Name: "Studio A", Capacity: 20},
+			{ID: "room-2", LocationID: "location-2", Name: "Studio B", Capacity: 10},
+			{ID: "room-3", LocationID: "location-1", Name: "Studio C", Capacity: 15},
+		}
+		for _, room := range rooms {
+			require.NoError(t, store.Create(room), "Should create room without error")
+		}
+
+		atLocation, err := store.GetByLocationID("location-1")
+		require.NoError(t, err, "Should retrieve rooms by location without error")
+		assert.ElementsMatch(t, []string{"room-1", "room-3"}, roomIDs(atLocation), "Should return the rooms at the location")
+
+		none, err := store.GetByLocationID("location-3")
+		require.NoError(t, err, "Should retrieve rooms by location without error")
+		assert.Empty(t, none, "Should return no rooms for a location without any")
+	})
+
+	t.Run("GetAllUpdateAndDelete", func(t *testing.T) {
+		store := newStore(t)
+
+		for _, id := range []string{"room-1", "room-2"} {
+			require.NoError(t, store.Create(&repository.Room{ID: id, LocationID: "location-1", Name: "Studio A", Capacity: 20}), "Should create room without error")
+		}
+
+		rooms, err := store.GetAll()
+		require.NoError(t, err, "Should retrieve all rooms without error")
+		assert.Len(t, rooms, 2, "Should return 2 rooms")
+
+		require.NoError(t, store.Update(&repository.Room{ID: "room-1", LocationID: "location-2", Name: "Studio B", Capacity: 12}), "Should update room without error")
+
+		retrieved, err := store.GetByID("room-1")
+		require.NoError(t, err, "Should retrieve room without error")
+		assert.Equal(t, "location-2", retrieved.LocationID, "Room location should be updated")
+		assert.Equal(t, "Studio B", retrieved.Name, "Room name should be updated")
+		assert.Equal(t, 12, retrieved.Capacity, "Room capacity should be updated")
+
+		err = store.Update(&repository.Room{ID: "non-existent-id", Name: "Nowhere", Capacity: 1})
+		require.Error(t, err, "Should return error when updating a non-existent room")
+		assert.Contains(t, err.Error(), "not found", "Error should report the room was not found")
+
+		require.NoError(t, store.Delete("room-1"), "Should delete room without error")
+		_, err = store.GetByID("room-1")
+		assert.Error(t, err, "Deleted room should not be found")
+
+		err = store.Delete("room-1")
+		require.Error(t, err, "Should return error when deleting a non-existent room")
+		assert.Contains(t, err.Error(), "not found", "Error should report the room was not found")
+	})
+}
+
+// RunAPIKeyStoreTests runs the APIKeyStore contract against stores built by newStore
+func RunAPIKeyStoreTests(t *testing.T, newStore APIKeyStoreFactory) {
+	t.Run("CreateAndGet", func(t *testing.T) {
+		store := newStore(t)
+
+		key := &repository.APIKey{
+			ID:        "key-1",
+			Name:      "Booking widget",
+			Prefix:    "gfx_abcd1234",
+			Hash:      "hash-1",
+			Scopes:    []string{"classes:read", "bookings:create"},
+			CreatedAt: time.Date(2025, 4, 24, 14, 30, 45, 0, time.UTC),
+		}
+		require.NoError(t, store.Create(key), "Should create API key without error")
+
+		retrieved, err := store.GetByID("key-1")
+		require.NoError(t, err, "Should retrieve API key without error")
+		assert.Equal(t, key.Name, retrieved.Name, "Retrieved API key name should match")
+		assert.Equal(t, key.Prefix, retrieved.Prefix, "Retrieved API key prefix should match")
+		assert.Equal(t, key.Scopes, retrieved.Scopes, "Retrieved API key scopes should match")
+		assert.True(t, key.CreatedAt.Equal(retrieved.CreatedAt), "Retrieved creation time should match")
+		assert.Nil(t, retrieved.LastUsedAt, "New API key should not have been used")
+		assert.False(t, retrieved.Revoked(), "New API key should not be revoked")
+
+		byHash, err := store.GetByHash("hash-1")
+		require.NoError(t, err, "Should retrieve API key by hash without error")
+		assert.Equal(t, "key-1", byHash.ID, "Should return the key with the hash")
+
+		err = store.Create(key)
+		require.Error(t, err, "Should return error for duplicate API key ID")
+		assert.Contains(t, err.Error(), "already exists", "Error should report the API key already exists")
+
+		err = store.Create(&repository.APIKey{ID: "key-2", Name: "Kiosk", Hash: "hash-1", Scopes: []string{"classes:read"}})
+		require.Error(t, err, "Should return error for duplicate API key hash")
+
+		_, err = store.GetByID("non-existent-id")
+		require.Error(t, err, "Should return error for non-existent API key")
+		assert.Contains(t, err.Error(), "not found", "Error should report the API key was not found")
+
+		_, err = store.GetByHash("non-existent-hash")
+		require.Error(t, err, "Should return error for an unknown hash")
+		assert.Contains(t, err.Error(), "not found", "Error should report the API key was not found")
+	})
+
+	t.Run("GetAllAndUpdate", func(t *testing.T) {
+		store := newStore(t)
+
+		for _, id := range []string{"key-1", "key-2"} {
+			require.NoError(t, store.Create(&repository.APIKey{ID: id, Name: "Kiosk", Hash: "hash-" + id, Scopes: []string{"classes:read"}}), "Should create API key without error")
+		}
+
+		keys, err := store.GetAll()
+		require.NoError(t, err, "Should retrieve all API keys without error")
+		assert.Len(t, keys, 2, "Should return 2 API keys")
+
+		lastUsedAt := time.Date(2025, 4, 25, 9, 0, 0, 0, time.UTC)
+		revokedAt := time.Date(2025, 4, 26, 9, 0, 0, 0, time.UTC)
+		require.NoError(t, store.Update(&repository.APIKey{
+			ID:         "key-1",
+			Name:       "Front desk kiosk",
+			Hash:       "hash-key-1",
+			Scopes:     []string{"classes:read"},
+			LastUsedAt: &lastUsedAt,
+			RevokedAt:  &revokedAt,
+		}), "Should update API key without error")
+
+		retrieved, err := store.GetByHash("hash-key-1")
+		require.NoError(t, err, "Should retrieve API key by hash without error")
+		assert.Equal(t, "Front desk kiosk", retrieved.Name, "API key name should be updated")
+		require.NotNil(t, retrieved.LastUsedAt, "Last used time should be recorded")
+		assert.True(t, lastUsedAt.Equal(*retrieved.LastUsedAt), "Last used time should match")
+		assert.True(t, retrieved.Revoked(), "API key should be revoked")
+
+		err = store.Update(&repository.APIKey{ID: "non-existent-id", Hash: "hash-3"})
+		require.Error(t, err, "Should return error when updating a non-existent API key")
+		assert.Contains(t, err.Error(), "not found", "Error should report the API key was not found")
+	})
+}
+
+func roomIDs(rooms []*repository.Room) []string {
+	ids := make([]string, 0, len(rooms))
+	for _, room := range rooms {
+		ids = append(ids, room.ID)
+	}
+	return ids
+}
+
+func waitlistIDs(entries []*repository.WaitlistEntry) []string {
+	ids := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, entry.ID)
+	}
+	return ids
+}
+
+// RunOccurrenceStoreTests runs the OccurrenceStore contract against stores built by newStore
+func RunOccurrenceStoreTests(t *testing.T, newStore OccurrenceStoreFactory) {
+	t.Run("SaveAndGet", func(t *testing.T) {
+		store := newStore(t)
+
+		cancelledAt := time.Date(2025, 4, 20, 9, 0, 0, 0, time.UTC)
+		override := &repository.OccurrenceOverride{
+			ClassID:            "class-1",
+			Date:               date(2025, 4, 25),
+			Capacity:           8,
+			StartTime:          "19:00",
+			DurationMinutes:    45,
+			Status:             repository.OccurrenceStatusCancelled,
+			CancelledAt:        &cancelledAt,
+			CancellationReason: "instructor unavailable",
+			InstructorID:       "instructor-2",
+			UpdatedAt:          cancelledAt,
+		}
+		require.NoError(t, store.Save(override), "Should save override without error")
+
+		retrieved, err := store.Get("class-1", date(2025, 4, 25))
+		require.NoError(t, err, "Should retrieve override without error")
+		assert.Equal(t, override.Capacity, retrieved.Capacity, "Retrieved capacity should match")
+		assert.Equal(t, override.StartTime, retrieved.StartTime, "Retrieved start time should match")
+		assert.Equal(t, override.DurationMinutes, retrieved.DurationMinutes, "Retrieved duration should match")
+		assert.Equal(t, override.InstructorID, retrieved.InstructorID, "Retrieved instructor should match")
+		assert.Equal(t, override.Status, retrieved.Status, "Retrieved status should match")
+		assert.Equal(t, override.CancellationReason, retrieved.CancellationReason, "Retrieved cancellation reason should match")
+		require.NotNil(t, retrieved.CancelledAt, "Cancellation time should be stored")
+		assert.True(t, cancelledAt.Equal(*retrieved.CancelledAt), "Retrieved cancellation time should match")
+
+		_, err = store.Get("class-1", date(2025, 4, 26))
+		assert.ErrorIs(t, err, repository.ErrOccurrenceOverrideNotFound, "Should report a missing override")
+	})
+
+	t.Run("SaveReplaces", func(t *testing.T) {
+		store := newStore(t)
+
+		require.NoError(t, store.Save(&repository.OccurrenceOverride{ClassID: "class-1", Date: date(2025, 4, 25), Capacity: 8}),
+			"Should save override without error")
+		require.NoError(t, store.Save(&repository.OccurrenceOverride{ClassID: "class-1", Date: date(2025, 4, 25), Capacity: 12}),
+			"Should replace override without error")
+
+		retrieved, err := store.Get("class-1", date(2025, 4, 25))
+		require.NoError(t, err, "Should retrieve override without error")
+		assert.Equal(t, 12, retrieved.Capacity, "Override should be replaced")
+
+		all, err := store.GetAll()
+		require.NoError(t, err, "Should retrieve all overrides without error")
+		assert.Len(t, all, 1, "Replacing should not add an override")
+	})
+
+	t.Run("ListByClass", func(t *testing.T) {
+		store := newStore(t)
+
+		for _, override := range []*repository.OccurrenceOverride{
+			{ClassID: "class-1", Date: date(2025, 4, 27), Capacity: 5},
+			{ClassID: "class-1", Date: date(2025, 4, 21), Capacity: 5},
+			{ClassID: "class-1", Date: date(2025, 4, 24), Capacity: 5},
+			{ClassID: "class-1", Date: date(2025, 5, 1), Capacity: 5},
+			{ClassID: "class-2", Date: date(2025, 4, 24), Capacity: 5},
+		} {
+			require.NoError(t, store.Save(override), "Should save override without error")
+		}
+
+		overrides, err := store.ListByClass("class-1", date(2025, 4, 21), date(2025, 4, 30))
+		require.NoError(t, err, "Should list overrides without error")
+		dates := make([]string, len(overrides))
+		for i, override := range overrides {
+			dates[i] = override.Date.Format("2006-01-02")
+		}
+		assert.Equal(t, []string{"2025-04-21", "2025-04-24", "2025-04-27"}, dates, "Should list the class overrides in range by date")
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		store := newStore(t)
+
+		require.NoError(t, store.Save(&repository.OccurrenceOverride{ClassID: "class-1", Date: date(2025, 4, 25), Capacity: 8}),
+			"Should save override without error")
+		require.NoError(t, store.Save(&repository.OccurrenceOverride{ClassID: "class-1", Date: date(2025, 4, 26), Capacity: 8}),
+			"Should save override without error")
+		require.NoError(t, store.Save(&repository.OccurrenceOverride{ClassID: "class-2", Date: date(2025, 4, 25), Capacity: 8}),
+			"Should save override without error")
+
+		require.NoError(t, store.Delete("class-1", date(2025, 4, 25)), "Should delete override without error")
+		_, err := store.Get("class-1", date(2025, 4, 25))
+		assert.ErrorIs(t, err, repository.ErrOccurrenceOverrideNotFound, "Deleted override should be gone")
+
+		err = store.Delete("class-1", date(2025, 4, 25))
+		assert.ErrorIs(t, err, repository.ErrOccurrenceOverrideNotFound, "Should report a missing override")
+
+		require.NoError(t, store.DeleteByClass("class-1"), "Should delete class overrides without error")
+		all, err := store.GetAll()
+		require.NoError(t, err, "Should retrieve all overrides without error")
+		require.Len(t, all, 1, "Only the other class's override should remain")
+		assert.Equal(t, "class-2", all[0].ClassID, "Other class's override should be kept")
+	})
+}
+
+func classIDs(classes []*repository.Class) []string {
+	ids := make([]string, 0, len(classes))
+	for _, class := range classes {
+		ids = append(ids, class.ID)
+	}
+	return ids
+}
+
+func bookingIDs(bookings []*repository.Booking) []string {
+	ids := make([]string, 0, len(bookings))
+	for _, booking := range bookings {
+		ids = append(ids, booking.ID)
+	}
+	return ids
+}
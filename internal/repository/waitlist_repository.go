@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// WaitlistEntry represents a member waiting for a place in a full class occurrence
+type WaitlistEntry struct {
+	ID         string    `json:"id"`
+	MemberID   string    `json:"member_id,omitempty"`
+	MemberName string    `json:"member_name"`
+	ClassID    string    `json:"class_id"`
+	Date       time.Time `json:"date"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// WaitlistRepository handles waitlist data storage
+type WaitlistRepository struct {
+	entries map[string]*WaitlistEntry
+	mutex   sync.RWMutex
+}
+
+// NewWaitlistRepository creates a new instance of WaitlistRepository
+func NewWaitlistRepository() *WaitlistRepository {
+	return &WaitlistRepository{
+		entries: make(map[string]*WaitlistEntry),
+	}
+}
+
+// Create adds a new entry to the waitlist
+func (r *WaitlistRepository) Create(entry *WaitlistEntry) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.entries[entry.ID]; exists {
+		return ErrWaitlistEntryExists
+	}
+
+	r.entries[entry.ID] = entry
+	return nil
+}
+
+// GetByID retrieves a waitlist entry by its ID
+func (r *WaitlistRepository) GetByID(id string) (*WaitlistEntry, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	entry, exists := r.entries[id]
+	if !exists {
+		return nil, ErrWaitlistEntryNotFound
+	}
+
+	return entry, nil
+}
+
+// GetByOccurrence retrieves the waitlist for a class on a specific date in
+// the order members joined it
+func (r *WaitlistRepository) GetByOccurrence(classID string, date time.Time) ([]*WaitlistEntry, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	targetDate := date.Format("2006-01-02")
+
+	entries := make([]*WaitlistEntry, 0)
+	for _, entry := range r.entries {
+		if entry.ClassID == classID && entry.Date.Format("2006-01-02") == targetDate {
+			entries = append(entries, entry)
+		}
+	}
+
+	SortWaitlist(entries)
+	return entries, nil
+}
+
+// GetAll returns all waitlist entries
+func (r *WaitlistRepository) GetAll() ([]*WaitlistEntry, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	entries := make([]*WaitlistEntry, 0, len(r.entries))
+	for _, entry := range r.entries {
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// Delete removes a waitlist entry by its ID
+func (r *WaitlistRepository) Delete(id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.entries[id]; !exists {
+		return ErrWaitlistEntryNotFound
+	}
+
+	delete(r.entries, id)
+	return nil
+}
+
+// DeleteByClass removes every waitlist entry for a class
+func (r *WaitlistRepository) DeleteByClass(classID string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for id, entry := range r.entries {
+		if entry.ClassID == classID {
+			delete(r.entries, id)
+		}
+	}
+	return nil
+}
+
+// SortWaitlist orders entries first come, first served, breaking ties on
+// the ID so the order is stable
+func SortWaitlist(entries []*WaitlistEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].CreatedAt.Equal(entries[j].CreatedAt) {
+			return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+		}
+		return entries[i].ID < entries[j].ID
+	})
+}
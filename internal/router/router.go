@@ -2,35 +2,98 @@ package router
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/sanjaykishor/Glofox/internal/config"
 	"github.com/sanjaykishor/Glofox/internal/handler"
 	"github.com/sanjaykishor/Glofox/internal/middleware"
+	"github.com/sanjaykishor/Glofox/internal/service"
 )
 
+// Setup builds the router, applying the global middleware configured in
+// cfg. Only the configured proxies are trusted to report the client IP.
+// Every API route is authenticated with an API key from apiKeys or a
+// bearer token checked by auth, or left open when auth is nil, and each
+// route group is rate limited by limiter unless it is nil.
 func Setup(
+	cfg *config.Config,
+	auth *middleware.Authenticator,
+	apiKeys *service.APIKeyService,
+	limiter *middleware.RateLimiter,
 	classHandler *handler.ClassHandler,
 	bookingHandler *handler.BookingHandler,
-) *gin.Engine {
+	waitlistHandler *handler.WaitlistHandler,
+	memberHandler *handler.MemberHandler,
+	occurrenceHandler *handler.OccurrenceHandler,
+	instructorHandler *handler.InstructorHandler,
+	locationHandler *handler.LocationHandler,
+	roomHandler *handler.RoomHandler,
+	apiKeyHandler *handler.APIKeyHandler,
+) (*gin.Engine, error) {
 
-	router := gin.Default()
-	middleware.Setup(router)
-	setupAPIRoutes(router, classHandler, bookingHandler)
+	// Request logging and panic recovery come from middleware.Setup
+	router := gin.New()
 
-	return router
+	// Rate limits and logs rely on the client IP, which anyone can claim
+	// in X-Forwarded-For unless the header is only read from known proxies
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		return nil, err
+	}
+
+	middleware.Setup(router, cfg)
+	setupAPIRoutes(router, auth, apiKeys, limiter, classHandler, bookingHandler, waitlistHandler, memberHandler, occurrenceHandler, instructorHandler, locationHandler, roomHandler, apiKeyHandler)
+
+	return router, nil
 }
 
 // setupAPIRoutes configures all the API routes for the application
 func setupAPIRoutes(
 	router *gin.Engine,
+	auth *middleware.Authenticator,
+	apiKeys *service.APIKeyService,
+	limiter *middleware.RateLimiter,
 	classHandler *handler.ClassHandler,
 	bookingHandler *handler.BookingHandler,
+	waitlistHandler *handler.WaitlistHandler,
+	memberHandler *handler.MemberHandler,
+	occurrenceHandler *handler.OccurrenceHandler,
+	instructorHandler *handler.InstructorHandler,
+	locationHandler *handler.LocationHandler,
+	roomHandler *handler.RoomHandler,
+	apiKeyHandler *handler.APIKeyHandler,
 ) {
-	api := router.Group("/api/v1")
+	// Requests are limited by IP before their credentials are checked, so
+	// rejected credentials count against the client too
+	api := router.Group("/api/v1", middleware.RateLimitIP(limiter, config.RouteGroupAuth), middleware.APIKeyAuth(apiKeys), middleware.Authenticate(auth))
 	{
+		// Each route group counts requests against its own rate limit
+		catalogRoutes := api.Group("", middleware.RateLimit(limiter, config.RouteGroupCatalog))
+		bookingRoutes := api.Group("", middleware.RateLimit(limiter, config.RouteGroupBookings))
+		memberRoutes := api.Group("", middleware.RateLimit(limiter, config.RouteGroupMembers))
+		apiKeyRoutes := api.Group("", middleware.RateLimit(limiter, config.RouteGroupAPIKeys))
+
 		// Register class routes
-		classHandler.RegisterRoutes(api)
+		classHandler.RegisterRoutes(catalogRoutes)
 
 		// Register booking routes
-		bookingHandler.RegisterRoutes(api)
+		bookingHandler.RegisterRoutes(bookingRoutes)
+
+		// Register waitlist routes
+		waitlistHandler.RegisterRoutes(bookingRoutes)
+
+		// Register member routes
+		memberHandler.RegisterRoutes(memberRoutes)
+
+		// Register class occurrence routes
+		occurrenceHandler.RegisterRoutes(catalogRoutes)
+
+		// Register instructor routes
+		instructorHandler.RegisterRoutes(catalogRoutes)
+
+		// Register location and room routes
+		locationHandler.RegisterRoutes(catalogRoutes)
+		roomHandler.RegisterRoutes(catalogRoutes)
+
+		// Register API key routes
+		apiKeyHandler.RegisterRoutes(apiKeyRoutes)
 	}
 
 }
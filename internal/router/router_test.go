@@ -1,28 +1,63 @@
 package router
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sanjaykishor/Glofox/internal/config"
 	"github.com/sanjaykishor/Glofox/internal/handler"
+	"github.com/sanjaykishor/Glofox/internal/middleware"
 	"github.com/sanjaykishor/Glofox/internal/repository"
 	"github.com/sanjaykishor/Glofox/internal/service"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-func TestRouterSetup(t *testing.T) {
+// setupTestRouter builds the full router over in-memory stores
+func setupTestRouter(t *testing.T, cfg *config.Config, limiter *middleware.RateLimiter) *gin.Engine {
+	t.Helper()
+
 	classRepo := repository.NewClassRepository()
 	bookingRepo := repository.NewBookingRepository()
-
-	classService := service.NewClassService(classRepo)
-	bookingService := service.NewBookingService(bookingRepo, classRepo)
+	waitlistRepo := repository.NewWaitlistRepository()
+	memberRepo := repository.NewMemberRepository()
+	occurrenceRepo := repository.NewOccurrenceRepository()
+	instructorRepo := repository.NewInstructorRepository()
+	locationRepo := repository.NewLocationRepository()
+	roomRepo := repository.NewRoomRepository()
+
+	classService := service.NewClassService(classRepo, bookingRepo, waitlistRepo, occurrenceRepo, instructorRepo, roomRepo)
+	bookingService := service.NewBookingService(bookingRepo, classRepo, waitlistRepo, memberRepo, occurrenceRepo)
+	waitlistService := service.NewWaitlistService(waitlistRepo, bookingRepo, classRepo, memberRepo, occurrenceRepo)
+	memberService := service.NewMemberService(memberRepo)
+	instructorService := service.NewInstructorService(instructorRepo, classRepo, occurrenceRepo)
+	locationService := service.NewLocationService(locationRepo, roomRepo)
+	roomService := service.NewRoomService(roomRepo, locationRepo, classRepo, occurrenceRepo)
+	occurrenceService := service.NewOccurrenceService(occurrenceRepo, classRepo, bookingRepo, waitlistRepo, instructorRepo, roomRepo)
 
 	classHandler := handler.NewClassHandler(classService)
 	bookingHandler := handler.NewBookingHandler(bookingService)
+	waitlistHandler := handler.NewWaitlistHandler(waitlistService)
+	memberHandler := handler.NewMemberHandler(memberService)
+	occurrenceHandler := handler.NewOccurrenceHandler(occurrenceService)
+	instructorHandler := handler.NewInstructorHandler(instructorService)
+	locationHandler := handler.NewLocationHandler(locationService)
+	roomHandler := handler.NewRoomHandler(roomService)
+	apiKeyService := service.NewAPIKeyService(repository.NewAPIKeyRepository(), middleware.IntegrationScopes)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
 
 	gin.SetMode(gin.TestMode)
 
-	router := Setup(classHandler, bookingHandler)
+	router, err := Setup(cfg, nil, apiKeyService, limiter, classHandler, bookingHandler, waitlistHandler, memberHandler, occurrenceHandler, instructorHandler, locationHandler, roomHandler, apiKeyHandler)
+	require.NoError(t, err, "Should set up router without error")
+	return router
+}
+
+func TestRouterSetup(t *testing.T) {
+	router := setupTestRouter(t, &config.Config{}, nil)
 
 	assert.NotNil(t, router, "Router should not be nil")
 
@@ -30,21 +65,106 @@ func TestRouterSetup(t *testing.T) {
 	assert.NotEmpty(t, routes, "Router should have routes registered")
 }
 
+func TestRouterClientIP(t *testing.T) {
+	// doRequest lists classes from remoteIP, claiming to forward forwardedFor
+	doRequest := func(router *gin.Engine, remoteIP, forwardedFor string) int {
+		req, _ := http.NewRequest("GET", "/api/v1/classes", nil)
+		req.RemoteAddr = remoteIP + ":12345"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	rateLimit := config.RateLimitConfig{
+		Enabled: true,
+		Default: config.RateLimit{Requests: 2, Period: time.Minute},
+	}
+
+	t.Run("Untrusted Client", func(t *testing.T) {
+		cfg := &config.Config{RateLimit: rateLimit}
+		router := setupTestRouter(t, cfg, middleware.NewRateLimiter(cfg.RateLimit))
+
+		assert.Equal(t, http.StatusOK, doRequest(router, "203.0.113.7", "198.51.100.1"))
+		assert.Equal(t, http.StatusOK, doRequest(router, "203.0.113.7", "198.51.100.2"))
+		assert.Equal(t, http.StatusTooManyRequests, doRequest(router, "203.0.113.7", "198.51.100.3"), "Should count a spoofed X-Forwarded-For against the client's own IP")
+	})
+
+	t.Run("Trusted Proxy", func(t *testing.T) {
+		cfg := &config.Config{RateLimit: rateLimit, TrustedProxies: []string{"10.0.0.0/8"}}
+		router := setupTestRouter(t, cfg, middleware.NewRateLimiter(cfg.RateLimit))
+
+		assert.Equal(t, http.StatusOK, doRequest(router, "10.0.0.1", "198.51.100.1"))
+		assert.Equal(t, http.StatusOK, doRequest(router, "10.0.0.1", "198.51.100.1"))
+		assert.Equal(t, http.StatusOK, doRequest(router, "10.0.0.1", "198.51.100.2"), "Should count clients behind a trusted proxy by their forwarded IP")
+		assert.Equal(t, http.StatusTooManyRequests, doRequest(router, "10.0.0.1", "198.51.100.1"))
+	})
+
+	t.Run("Invalid Proxy", func(t *testing.T) {
+		_, err := Setup(&config.Config{TrustedProxies: []string{"not-an-ip"}}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+		assert.Error(t, err, "Should return error for an invalid trusted proxy")
+	})
+}
+
+func TestRouterRateLimitsRejectedCredentials(t *testing.T) {
+	cfg := &config.Config{RateLimit: config.RateLimitConfig{
+		Enabled: true,
+		Default: config.RateLimit{Requests: 100, Period: time.Minute},
+		Groups:  map[string]config.RateLimit{config.RouteGroupAuth: {Requests: 2, Period: time.Minute}},
+	}}
+	router := setupTestRouter(t, cfg, middleware.NewRateLimiter(cfg.RateLimit))
+
+	doRequest := func() int {
+		req, _ := http.NewRequest("GET", "/api/v1/classes", nil)
+		req.RemoteAddr = "203.0.113.7:12345"
+		req.Header.Set(middleware.APIKeyHeader, "glofox_guessed-key")
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, doRequest(), "Should reject an unknown API key")
+	assert.Equal(t, http.StatusUnauthorized, doRequest(), "Should reject an unknown API key")
+	assert.Equal(t, http.StatusTooManyRequests, doRequest(), "Should limit clients guessing credentials")
+}
+
 func TestSetupAPIRoutes(t *testing.T) {
 	classRepo := repository.NewClassRepository()
 	bookingRepo := repository.NewBookingRepository()
-
-	classService := service.NewClassService(classRepo)
-	bookingService := service.NewBookingService(bookingRepo, classRepo)
+	waitlistRepo := repository.NewWaitlistRepository()
+	memberRepo := repository.NewMemberRepository()
+	occurrenceRepo := repository.NewOccurrenceRepository()
+	instructorRepo := repository.NewInstructorRepository()
+	locationRepo := repository.NewLocationRepository()
+	roomRepo := repository.NewRoomRepository()
+
+	classService := service.NewClassService(classRepo, bookingRepo, waitlistRepo, occurrenceRepo, instructorRepo, roomRepo)
+	bookingService := service.NewBookingService(bookingRepo, classRepo, waitlistRepo, memberRepo, occurrenceRepo)
+	waitlistService := service.NewWaitlistService(waitlistRepo, bookingRepo, classRepo, memberRepo, occurrenceRepo)
+	memberService := service.NewMemberService(memberRepo)
+	instructorService := service.NewInstructorService(instructorRepo, classRepo, occurrenceRepo)
+	locationService := service.NewLocationService(locationRepo, roomRepo)
+	roomService := service.NewRoomService(roomRepo, locationRepo, classRepo, occurrenceRepo)
+	occurrenceService := service.NewOccurrenceService(occurrenceRepo, classRepo, bookingRepo, waitlistRepo, instructorRepo, roomRepo)
 
 	classHandler := handler.NewClassHandler(classService)
 	bookingHandler := handler.NewBookingHandler(bookingService)
+	waitlistHandler := handler.NewWaitlistHandler(waitlistService)
+	memberHandler := handler.NewMemberHandler(memberService)
+	occurrenceHandler := handler.NewOccurrenceHandler(occurrenceService)
+	instructorHandler := handler.NewInstructorHandler(instructorService)
+	locationHandler := handler.NewLocationHandler(locationService)
+	roomHandler := handler.NewRoomHandler(roomService)
+	apiKeyService := service.NewAPIKeyService(repository.NewAPIKeyRepository(), middleware.IntegrationScopes)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
 
 	gin.SetMode(gin.TestMode)
 
 	router := gin.New()
 
-	setupAPIRoutes(router, classHandler, bookingHandler)
+	setupAPIRoutes(router, nil, apiKeyService, nil, classHandler, bookingHandler, waitlistHandler, memberHandler, occurrenceHandler, instructorHandler, locationHandler, roomHandler, apiKeyHandler)
 
 	routes := router.Routes()
 	assert.NotEmpty(t, routes, "Router should have routes registered")
@@ -0,0 +1,406 @@
+// Package schedule models when a class meets: a recurrence within the
+// class's date range, and its expansion into concrete occurrences.
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// Recurrence describes the days, and optionally the time of day, a class
+// meets within its date range. The days are picked by Weekdays or by an
+// RRule, not both; with neither the class meets every day. ExDates lists
+// days, as YYYY-MM-DD, on which the class does not meet.
+//
+// RRule accepts the subset of RFC 5545 recurrence rules that fits a daily
+// timetable: FREQ=DAILY or WEEKLY, INTERVAL, BYDAY without ordinals,
+// COUNT or UNTIL, and WKST. The series starts on the class start date and
+// never runs past the class end date.
+type Recurrence struct {
+	Weekdays        []string `json:"weekdays,omitempty"`
+	StartTime       string   `json:"start_time,omitempty"`
+	DurationMinutes int      `json:"duration_minutes,omitempty"`
+	RRule           string   `json:"rrule,omitempty"`
+	ExDates         []string `json:"exdates,omitempty"`
+}
+
+// Error reports an invalid recurrence field. Field is the JSON name of the
+// field within the recurrence.
+type Error struct {
+	Field   string
+	Rule    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Occurrence is one meeting of a class. Start and End are nil for classes
+// without a time of day; otherwise they are instants in the studio's time
+// zone, so a class keeps its wall-clock time across daylight saving changes.
+type Occurrence struct {
+	Date  time.Time  `json:"date"`
+	Start *time.Time `json:"start,omitempty"`
+	End   *time.Time `json:"end,omitempty"`
+}
+
+// Validate reports the first problem with the recurrence, as an *Error
+func (r *Recurrence) Validate() error {
+	_, err := r.compile()
+	return err
+}
+
+// OccursOn reports whether a class running from start to end, inclusive,
+// with this recurrence meets on date. A nil recurrence meets every day.
+func (r *Recurrence) OccursOn(start, end, date time.Time) bool {
+	day := civil(date)
+	found := false
+	r.each(start, end, day, day, func(time.Time) bool {
+		found = true
+		return false
+	})
+	return found
+}
+
+// Expand returns the occurrences of a class running from start to end,
+// inclusive, with this recurrence that fall between from and to, inclusive.
+// Start times are read as wall-clock times in loc.
+func (r *Recurrence) Expand(start, end, from, to time.Time, loc *time.Location) []Occurrence {
+	compiled, err := r.compile()
+	if err != nil {
+		return nil
+	}
+
+	occurrences := make([]Occurrence, 0)
+	compiled.each(start, end, from, to, func(day time.Time) bool {
+		occurrences = append(occurrences, compiled.occurrence(day, loc))
+		return true
+	})
+	return occurrences
+}
+
+// On returns the occurrence on date at the recurrence's time of day in loc,
+// whether or not the class meets on date
+func (r *Recurrence) On(date time.Time, loc *time.Location) Occurrence {
+	compiled, err := r.compile()
+	if err != nil {
+		return Occurrence{Date: civil(date)}
+	}
+	return compiled.occurrence(civil(date), loc)
+}
+
+// each calls fn for every day the class meets between from and to until fn
+// returns false. A recurrence that does not compile has no days.
+func (r *Recurrence) each(start, end, from, to time.Time, fn func(time.Time) bool) {
+	compiled, err := r.compile()
+	if err != nil {
+		return
+	}
+	compiled.each(start, end, from, to, fn)
+}
+
+// rule is a validated recurrence in a form that is quick to evaluate
+type rule struct {
+	weekly   bool
+	interval int
+	// byDay is nil when every weekday matches
+	byDay     map[time.Weekday]bool
+	weekStart time.Weekday
+	count     int
+	until     time.Time
+	exDates   map[time.Time]bool
+	// startTime is the offset from midnight; duration is zero without a time of day
+	startTime time.Duration
+	duration  time.Duration
+}
+
+// compile validates the recurrence and converts it into a rule
+func (r *Recurrence) compile() (*rule, error) {
+	compiled := &rule{interval: 1, weekStart: time.Monday}
+	if r == nil {
+		return compiled, nil
+	}
+
+	if len(r.Weekdays) > 0 && r.RRule != "" {
+		return nil, &Error{Field: "weekdays", Rule: "excluded_with", Message: "weekdays and rrule cannot both be set"}
+	}
+
+	if len(r.Weekdays) > 0 {
+		compiled.weekly = true
+		compiled.byDay = make(map[time.Weekday]bool)
+		for _, name := range r.Weekdays {
+			weekday, ok := parseWeekday(name)
+			if !ok {
+				return nil, &Error{Field: "weekdays", Rule: "weekday", Message: fmt.Sprintf("%q is not a weekday", name)}
+			}
+			compiled.byDay[weekday] = true
+		}
+	}
+
+	if r.RRule != "" {
+		if err := compiled.parseRRule(r.RRule); err != nil {
+			return nil, err
+		}
+	}
+
+	if r.StartTime != "" || r.DurationMinutes != 0 {
+		startTime, err := time.Parse("15:04", r.StartTime)
+		if err != nil {
+			return nil, &Error{Field: "start_time", Rule: "time_format", Message: "invalid start time format, use HH:MM"}
+		}
+		if r.DurationMinutes <= 0 || r.DurationMinutes > 24*60 {
+			return nil, &Error{Field: "duration_minutes", Rule: "range", Message: "duration_minutes must be between 1 and 1440"}
+		}
+		compiled.startTime = time.Duration(startTime.Hour())*time.Hour + time.Duration(startTime.Minute())*time.Minute
+		compiled.duration = time.Duration(r.DurationMinutes) * time.Minute
+	}
+
+	if len(r.ExDates) > 0 {
+		compiled.exDates = make(map[time.Time]bool)
+		for _, value := range r.ExDates {
+			date, err := time.Parse(dateLayout, value)
+			if err != nil {
+				return nil, &Error{Field: "exdates", Rule: "date_format", Message: "invalid exdate format, use YYYY-MM-DD"}
+			}
+			compiled.exDates[date] = true
+		}
+	}
+
+	return compiled, nil
+}
+
+// parseRRule reads the supported subset of an RFC 5545 RRULE
+func (r *rule) parseRRule(value string) error {
+	invalid := func(message string) error {
+		return &Error{Field: "rrule", Rule: "rrule", Message: "invalid rrule: " + message}
+	}
+
+	value = strings.TrimPrefix(strings.TrimSpace(value), "RRULE:")
+	parts := make(map[string]string)
+	for _, part := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok || val == "" {
+			return invalid(fmt.Sprintf("%q is not a KEY=VALUE pair", part))
+		}
+		key = strings.ToUpper(key)
+		if _, seen := parts[key]; seen {
+			return invalid(key + " is given more than once")
+		}
+		parts[key] = strings.ToUpper(val)
+	}
+
+	for key, val := range parts {
+		switch key {
+		case "FREQ":
+			switch val {
+			case "DAILY":
+			case "WEEKLY":
+				r.weekly = true
+			default:
+				return invalid("only FREQ=DAILY and FREQ=WEEKLY are supported")
+			}
+		case "INTERVAL":
+			interval, err := strconv.Atoi(val)
+			if err != nil || interval < 1 {
+				return invalid("INTERVAL must be a positive number")
+			}
+			r.interval = interval
+		case "BYDAY":
+			r.byDay = make(map[time.Weekday]bool)
+			for _, code := range strings.Split(val, ",") {
+				weekday, ok := weekdayCodes[code]
+				if !ok {
+					return invalid(fmt.Sprintf("BYDAY value %q is not supported, use MO to SU", code))
+				}
+				r.byDay[weekday] = true
+			}
+		case "COUNT":
+			count, err := strconv.Atoi(val)
+			if err != nil || count < 1 {
+				return invalid("COUNT must be a positive number")
+			}
+			r.count = count
+		case "UNTIL":
+			until, err := parseUntil(val)
+			if err != nil {
+				return invalid("UNTIL must be a date such as 20250630 or 20250630T235959Z")
+			}
+			r.until = until
+		case "WKST":
+			weekStart, ok := weekdayCodes[val]
+			if !ok {
+				return invalid("WKST must be one of MO to SU")
+			}
+			r.weekStart = weekStart
+		default:
+			return invalid(key + " is not supported")
+		}
+	}
+
+	if _, ok := parts["FREQ"]; !ok {
+		return invalid("FREQ is required")
+	}
+	if r.count > 0 && !r.until.IsZero() {
+		return invalid("COUNT and UNTIL cannot both be set")
+	}
+
+	return nil
+}
+
+// each calls fn for every day the rule meets between from and to, within
+// start and end, until fn returns false
+func (r *rule) each(start, end, from, to time.Time, fn func(time.Time) bool) {
+	start, end, from, to = civil(start), civil(end), civil(from), civil(to)
+	if !r.until.IsZero() && r.until.Before(end) {
+		end = r.until
+	}
+	if to.After(end) {
+		to = end
+	}
+
+	day := start
+	// Without COUNT, days before from never matter
+	if r.count == 0 && from.After(start) {
+		day = from
+	}
+
+	matched := 0
+	for ; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if !r.matches(start, day) {
+			continue
+		}
+		// COUNT limits the series before EXDATE removes days from it
+		matched++
+		if r.count > 0 && matched > r.count {
+			return
+		}
+		if day.Before(from) || r.exDates[day] {
+			continue
+		}
+		if !fn(day) {
+			return
+		}
+	}
+}
+
+// matches reports whether the rule's pattern, started on start, includes day
+func (r *rule) matches(start, day time.Time) bool {
+	if r.weekly {
+		byDay := r.byDay
+		if byDay == nil {
+			byDay = map[time.Weekday]bool{start.Weekday(): true}
+		}
+		if !byDay[day.Weekday()] {
+			return false
+		}
+		weeks := daysBetween(r.startOfWeek(start), r.startOfWeek(day)) / 7
+		return weeks%r.interval == 0
+	}
+
+	if r.byDay != nil && !r.byDay[day.Weekday()] {
+		return false
+	}
+	return daysBetween(start, day)%r.interval == 0
+}
+
+// startOfWeek returns the first day of the week containing day
+func (r *rule) startOfWeek(day time.Time) time.Time {
+	offset := (int(day.Weekday()) - int(r.weekStart) + 7) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// occurrence returns the occurrence on a day, with its times in loc
+func (r *rule) occurrence(day time.Time, loc *time.Location) Occurrence {
+	occurrence := Occurrence{Date: day}
+	if r.duration > 0 {
+		start := localTime(day, r.startTime, loc)
+		end := start.Add(r.duration)
+		occurrence.Start = &start
+		occurrence.End = &end
+	}
+	return occurrence
+}
+
+// weekdayCodes maps RFC 5545 weekday codes to weekdays
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// parseWeekday accepts an English weekday name or an RFC 5545 code, in any case
+func parseWeekday(name string) (time.Weekday, bool) {
+	if weekday, ok := weekdayCodes[strings.ToUpper(name)]; ok {
+		return weekday, true
+	}
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		if strings.EqualFold(weekday.String(), name) {
+			return weekday, true
+		}
+	}
+	return 0, false
+}
+
+// parseUntil reads an RRULE UNTIL value, keeping only its date
+func parseUntil(value string) (time.Time, error) {
+	if len(value) > 8 {
+		until, err := time.Parse("20060102T150405Z", value)
+		return civil(until), err
+	}
+	return time.Parse("20060102", value)
+}
+
+// localTime returns the instant offset past midnight, by the wall clock, on
+// the calendar day of day in loc; a nil loc means UTC. Following RFC 5545, a
+// time skipped when clocks go forward is moved later by the length of the
+// gap, and a time that happens twice when clocks go back is the first one.
+func localTime(day time.Time, offset time.Duration, loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	// wall holds the wanted wall-clock reading as if it were UTC
+	wall := civil(day).Add(offset)
+	zoneOffset := func(t time.Time) int {
+		_, seconds := t.In(loc).Zone()
+		return seconds
+	}
+
+	// A day sees at most one transition, so the offsets in force well before
+	// and well after the wall time are the only candidates
+	before, after := zoneOffset(wall.Add(-36*time.Hour)), zoneOffset(wall.Add(36*time.Hour))
+
+	var resolved time.Time
+	for _, seconds := range []int{before, after} {
+		t := wall.Add(-time.Duration(seconds) * time.Second).In(loc)
+		if zoneOffset(t) == seconds && (resolved.IsZero() || t.Before(resolved)) {
+			resolved = t
+		}
+	}
+	if resolved.IsZero() {
+		// The wall time falls in a gap; reading it with the offset from before
+		// the gap moves it forward
+		resolved = wall.Add(-time.Duration(before) * time.Second).In(loc)
+	}
+	return resolved
+}
+
+// civil returns midnight UTC on the calendar day of t
+func civil(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// daysBetween returns the number of days from a to b, both civil dates
+func daysBetween(a, b time.Time) int {
+	return int(b.Sub(a).Hours() / 24)
+}
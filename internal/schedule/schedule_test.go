@@ -0,0 +1,154 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// days expands a recurrence over April 2025 and returns the days of the month
+func days(t *testing.T, r *Recurrence) []int {
+	t.Helper()
+	require.NoError(t, r.Validate(), "Should validate recurrence without error")
+
+	result := make([]int, 0)
+	for _, occurrence := range r.Expand(date(2025, 4, 1), date(2025, 4, 30), date(2025, 4, 1), date(2025, 4, 30), time.UTC) {
+		result = append(result, occurrence.Date.Day())
+	}
+	return result
+}
+
+func TestExpand(t *testing.T) {
+	// April 1, 2025 is a Tuesday
+	tests := []struct {
+		name       string
+		recurrence *Recurrence
+		want       []int
+	}{
+		{"NilMeetsEveryDay", nil, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30}},
+		{"Weekdays", &Recurrence{Weekdays: []string{"Monday", "TH"}}, []int{3, 7, 10, 14, 17, 21, 24, 28}},
+		{"ExDates", &Recurrence{Weekdays: []string{"monday"}, ExDates: []string{"2025-04-14"}}, []int{7, 21, 28}},
+		{"DailyInterval", &Recurrence{RRule: "FREQ=DAILY;INTERVAL=10"}, []int{1, 11, 21}},
+		{"WeeklyDefaultsToStartDay", &Recurrence{RRule: "FREQ=WEEKLY"}, []int{1, 8, 15, 22, 29}},
+		{"WeeklyIntervalByDay", &Recurrence{RRule: "RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,FR"}, []int{1, 4, 15, 18, 29}},
+		{"WeekStart", &Recurrence{RRule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=SU,TU;WKST=SU"}, []int{1, 13, 15, 27, 29}},
+		{"Count", &Recurrence{RRule: "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=3"}, []int{2, 7, 9}},
+		{"CountBeforeExDates", &Recurrence{RRule: "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=3", ExDates: []string{"2025-04-07"}}, []int{2, 9}},
+		{"Until", &Recurrence{RRule: "FREQ=DAILY;UNTIL=20250403T235959Z"}, []int{1, 2, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, days(t, tt.recurrence), "Should expand to the expected days")
+		})
+	}
+}
+
+func TestExpandWindow(t *testing.T) {
+	r := &Recurrence{RRule: "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4"}
+
+	occurrences := r.Expand(date(2025, 4, 1), date(2025, 4, 30), date(2025, 4, 8), date(2025, 4, 30), time.UTC)
+	require.Len(t, occurrences, 2, "Should only count occurrences from the start of the series")
+	assert.Equal(t, date(2025, 4, 9), occurrences[0].Date, "First occurrence in the window should match")
+	assert.Equal(t, date(2025, 4, 14), occurrences[1].Date, "Last occurrence of the series should match")
+
+	assert.Empty(t, r.Expand(date(2025, 4, 1), date(2025, 4, 30), date(2025, 5, 1), date(2025, 5, 31), time.UTC), "Should have no occurrences after the class ends")
+}
+
+func TestOccursOn(t *testing.T) {
+	r := &Recurrence{Weekdays: []string{"saturday"}}
+	start, end := date(2025, 4, 1), date(2025, 4, 30)
+
+	assert.True(t, r.OccursOn(start, end, date(2025, 4, 5)), "Should occur on a scheduled day")
+	assert.True(t, r.OccursOn(start, end, time.Date(2025, 4, 5, 18, 0, 0, 0, time.UTC)), "Should ignore the time of day")
+	assert.False(t, r.OccursOn(start, end, date(2025, 4, 6)), "Should not occur on an unscheduled day")
+	assert.False(t, r.OccursOn(start, end, date(2025, 5, 3)), "Should not occur after the class ends")
+
+	var none *Recurrence
+	assert.True(t, none.OccursOn(start, end, date(2025, 4, 6)), "Nil recurrence should occur every day")
+	assert.False(t, none.OccursOn(start, end, date(2025, 3, 31)), "Nil recurrence should not occur before the class starts")
+}
+
+func TestOccurrenceTimes(t *testing.T) {
+	r := &Recurrence{Weekdays: []string{"friday"}, StartTime: "18:30", DurationMinutes: 45}
+
+	occurrences := r.Expand(date(2025, 4, 1), date(2025, 4, 30), date(2025, 4, 4), date(2025, 4, 4), time.UTC)
+	require.Len(t, occurrences, 1, "Should return one occurrence")
+	require.NotNil(t, occurrences[0].Start, "Occurrence should have a start time")
+	assert.Equal(t, time.Date(2025, 4, 4, 18, 30, 0, 0, time.UTC), *occurrences[0].Start, "Start time should match")
+	assert.Equal(t, time.Date(2025, 4, 4, 19, 15, 0, 0, time.UTC), *occurrences[0].End, "End time should match")
+
+	occurrence := r.On(date(2025, 4, 5), time.UTC)
+	require.NotNil(t, occurrence.Start, "Occurrence on an unscheduled day should still have a start time")
+	assert.Equal(t, time.Date(2025, 4, 5, 18, 30, 0, 0, time.UTC), *occurrence.Start, "Start time should match")
+
+	occurrences = (&Recurrence{Weekdays: []string{"friday"}}).Expand(date(2025, 4, 1), date(2025, 4, 30), date(2025, 4, 4), date(2025, 4, 4), time.UTC)
+	require.Len(t, occurrences, 1, "Should return one occurrence")
+	assert.Nil(t, occurrences[0].Start, "Occurrence without a time of day should have no start")
+}
+
+func TestDaylightSaving(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err, "Should load time zone without error")
+
+	// Clocks in New York went forward on March 9, 2025 and back on November 2, 2025
+	r := &Recurrence{RRule: "FREQ=WEEKLY", StartTime: "09:00", DurationMinutes: 60}
+	occurrences := r.Expand(date(2025, 3, 2), date(2025, 3, 16), date(2025, 3, 2), date(2025, 3, 16), loc)
+	require.Len(t, occurrences, 3, "Should return one occurrence a week")
+	for _, occurrence := range occurrences {
+		start := occurrence.Start.In(loc)
+		assert.Equal(t, 9, start.Hour(), "Class should keep its local start time across the change")
+		assert.Equal(t, occurrence.Date.Day(), start.Day(), "Start should fall on the occurrence date")
+		assert.Equal(t, time.Hour, occurrence.End.Sub(*occurrence.Start), "Class should last its full duration")
+	}
+	assert.Equal(t, 14, occurrences[0].Start.UTC().Hour(), "Start should be in standard time before the change")
+	assert.Equal(t, 13, occurrences[1].Start.UTC().Hour(), "Start should be in daylight time after the change")
+
+	skipped := (&Recurrence{StartTime: "02:30", DurationMinutes: 30}).On(date(2025, 3, 9), loc)
+	assert.Equal(t, time.Date(2025, 3, 9, 3, 30, 0, 0, loc), *skipped.Start, "Skipped time should move forward by the gap")
+
+	repeated := (&Recurrence{StartTime: "01:30", DurationMinutes: 30}).On(date(2025, 11, 2), loc)
+	assert.Equal(t, time.Date(2025, 11, 2, 5, 30, 0, 0, time.UTC), repeated.Start.UTC(), "Repeated time should resolve to the first one")
+	assert.Equal(t, time.Date(2025, 11, 2, 6, 0, 0, 0, time.UTC), repeated.End.UTC(), "End should be the elapsed duration after the start")
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		recurrence *Recurrence
+		field      string
+		rule       string
+	}{
+		{"WeekdaysAndRRule", &Recurrence{Weekdays: []string{"monday"}, RRule: "FREQ=DAILY"}, "weekdays", "excluded_with"},
+		{"UnknownWeekday", &Recurrence{Weekdays: []string{"funday"}}, "weekdays", "weekday"},
+		{"MissingFreq", &Recurrence{RRule: "INTERVAL=2"}, "rrule", "rrule"},
+		{"UnsupportedFreq", &Recurrence{RRule: "FREQ=MONTHLY"}, "rrule", "rrule"},
+		{"UnsupportedPart", &Recurrence{RRule: "FREQ=WEEKLY;BYMONTH=4"}, "rrule", "rrule"},
+		{"OrdinalByDay", &Recurrence{RRule: "FREQ=WEEKLY;BYDAY=1MO"}, "rrule", "rrule"},
+		{"CountAndUntil", &Recurrence{RRule: "FREQ=DAILY;COUNT=2;UNTIL=20250430"}, "rrule", "rrule"},
+		{"BadStartTime", &Recurrence{StartTime: "25:00", DurationMinutes: 60}, "start_time", "time_format"},
+		{"DurationWithoutStart", &Recurrence{DurationMinutes: 60}, "start_time", "time_format"},
+		{"StartWithoutDuration", &Recurrence{StartTime: "09:00"}, "duration_minutes", "range"},
+		{"BadExDate", &Recurrence{ExDates: []string{"14/04/2025"}}, "exdates", "date_format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.recurrence.Validate()
+			require.Error(t, err, "Should reject invalid recurrence")
+
+			var scheduleErr *Error
+			require.ErrorAs(t, err, &scheduleErr, "Error should be a schedule error")
+			assert.Equal(t, tt.field, scheduleErr.Field, "Error field should match")
+			assert.Equal(t, tt.rule, scheduleErr.Rule, "Error rule should match")
+		})
+	}
+
+	assert.NoError(t, (*Recurrence)(nil).Validate(), "Nil recurrence should be valid")
+}
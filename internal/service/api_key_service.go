@@ -0,0 +1,241 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sanjaykishor/Glofox/internal/repository"
+)
+
+// ErrInvalidAPIKey is returned when a presented API key is unknown or revoked
+var ErrInvalidAPIKey = errors.New("invalid or revoked API key")
+
+// ErrAPIKeyRevoked is returned when revoking a key that is already revoked
+var ErrAPIKeyRevoked = repository.NewConflictError("api_key_revoked", "API key has already been revoked")
+
+const (
+	// apiKeyPrefix starts every key so leaked keys are easy to recognise
+	apiKeyPrefix = "gfx_"
+	// apiKeyBytes is the amount of randomness in a key
+	apiKeyBytes = 32
+	// apiKeyDisplayLength is how much of a key is kept to tell keys apart
+	apiKeyDisplayLength = len(apiKeyPrefix) + 8
+	// apiKeyUsageResolution limits how often the last use of a key is
+	// written, so a busy integration does not cause a write per request
+	apiKeyUsageResolution = time.Minute
+)
+
+// APIKeyService handles business logic for API keys
+type APIKeyService struct {
+	repo          repository.APIKeyStore
+	allowedScopes []string
+	now           func() time.Time
+
+	// mutex serializes key updates so recording a use cannot undo a
+	// concurrent revocation
+	mutex sync.Mutex
+}
+
+// NewAPIKeyService creates a new instance of APIKeyService. Keys can only
+// be granted the allowed scopes.
+func NewAPIKeyService(repo repository.APIKeyStore, allowedScopes []string) *APIKeyService {
+	return &APIKeyService{
+		repo:          repo,
+		allowedScopes: allowedScopes,
+		now:           time.Now,
+	}
+}
+
+// SetClock overrides the clock used to record creation, use and revocation
+func (s *APIKeyService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// CreateAPIKeyRequest represents the data needed to create an API key
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name" binding:"required"`
+	Scopes []string `json:"scopes" binding:"required,min=1"`
+}
+
+// APIKeyDetails is an API key as shown to clients, without its hash. Key
+// holds the full key and is only set in response to creating it.
+type APIKeyDetails struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	Key        string     `json:"key,omitempty"`
+}
+
+// newAPIKeyDetails returns the client view of a stored key
+func newAPIKeyDetails(key *repository.APIKey) *APIKeyDetails {
+	return &APIKeyDetails{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		CreatedAt:  key.CreatedAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+	}
+}
+
+// CreateAPIKey issues a new key with the requested scopes. The returned
+// details are the only place the full key appears; only its hash is stored.
+func (s *APIKeyService) CreateAPIKey(req *CreateAPIKeyRequest) (*APIKeyDetails, error) {
+	for _, scope := range req.Scopes {
+		if !slices.Contains(s.allowedScopes, scope) {
+			return nil, &ValidationError{
+				Field:   "scopes",
+				Rule:    "oneof",
+				Param:   strings.Join(s.allowedScopes, " "),
+				Message: "scopes must only contain " + strings.Join(s.allowedScopes, ", "),
+			}
+		}
+	}
+
+	secret := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	plaintext := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	scopes := slices.Clone(req.Scopes)
+	slices.Sort(scopes)
+
+	key := &repository.APIKey{
+		ID:        uuid.New().String(),
+		Name:      req.Name,
+		Prefix:    plaintext[:apiKeyDisplayLength],
+		Hash:      hashAPIKey(plaintext),
+		Scopes:    slices.Compact(scopes),
+		CreatedAt: s.now(),
+	}
+
+	if err := s.repo.Create(key); err != nil {
+		return nil, err
+	}
+
+	details := newAPIKeyDetails(key)
+	details.Key = plaintext
+	return details, nil
+}
+
+// GetAllAPIKeys returns every key, including revoked ones, oldest first
+func (s *APIKeyService) GetAllAPIKeys() ([]*APIKeyDetails, error) {
+	keys, err := s.repo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(keys, func(a, b *repository.APIKey) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	details := make([]*APIKeyDetails, len(keys))
+	for i, key := range keys {
+		details[i] = newAPIKeyDetails(key)
+	}
+	return details, nil
+}
+
+// GetAPIKeyByID retrieves a key by its ID
+func (s *APIKeyService) GetAPIKeyByID(id string) (*APIKeyDetails, error) {
+	key, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return newAPIKeyDetails(key), nil
+}
+
+// RevokeAPIKey stops a key from being used. The key stays on record so
+// its last use can still be seen.
+func (s *APIKeyService) RevokeAPIKey(id string) (*APIKeyDetails, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	existing, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if existing.Revoked() {
+		return nil, ErrAPIKeyRevoked
+	}
+
+	key := *existing
+	revokedAt := s.now()
+	key.RevokedAt = &revokedAt
+
+	if err := s.repo.Update(&key); err != nil {
+		return nil, err
+	}
+
+	return newAPIKeyDetails(&key), nil
+}
+
+// VerifyAPIKey returns the key matching a presented plaintext key and
+// records that it was used. It returns ErrInvalidAPIKey for unknown and
+// revoked keys.
+func (s *APIKeyService) VerifyAPIKey(plaintext string) (*repository.APIKey, error) {
+	if !strings.HasPrefix(plaintext, apiKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	existing, err := s.repo.GetByHash(hashAPIKey(plaintext))
+	if errors.Is(err, repository.ErrAPIKeyNotFound) {
+		return nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	if existing.Revoked() {
+		return nil, ErrInvalidAPIKey
+	}
+
+	now := s.now()
+	if existing.LastUsedAt != nil && now.Sub(*existing.LastUsedAt) < apiKeyUsageResolution {
+		return existing, nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// Re-read the key now that no revocation can be in progress
+	current, err := s.repo.GetByID(existing.ID)
+	if err != nil {
+		return nil, err
+	}
+	if current.Revoked() {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key := *current
+	key.LastUsedAt = &now
+	if err := s.repo.Update(&key); err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+// hashAPIKey returns the stored form of a key. Keys carry 256 random bits,
+// so a fast hash is enough to make a leaked hash useless.
+func hashAPIKey(plaintext string) string {
+	sum := sha256.Sum256([]byte(plaintext))
+	return hex.EncodeToString(sum[:])
+}
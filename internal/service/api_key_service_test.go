@@ -0,0 +1,95 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sanjaykishor/Glofox/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testScopes = []string{"classes:read", "bookings:create"}
+
+func TestAPIKeyService(t *testing.T) {
+	repo := repository.NewAPIKeyRepository()
+	service := NewAPIKeyService(repo, testScopes)
+
+	now := time.Date(2025, 4, 1, 9, 0, 0, 0, time.UTC)
+	service.SetClock(func() time.Time { return now })
+
+	created, err := service.CreateAPIKey(&CreateAPIKeyRequest{Name: "Booking widget", Scopes: []string{"bookings:create", "classes:read", "classes:read"}})
+	require.NoError(t, err, "Should create API key without error")
+	assert.NotEmpty(t, created.ID, "API key should be given an ID")
+	assert.True(t, strings.HasPrefix(created.Key, "gfx_"), "Key should carry the glofox prefix")
+	assert.True(t, strings.HasPrefix(created.Key, created.Prefix), "Prefix should be the start of the key")
+	assert.Equal(t, []string{"bookings:create", "classes:read"}, created.Scopes, "Scopes should be sorted without duplicates")
+
+	stored, err := repo.GetByID(created.ID)
+	require.NoError(t, err, "Should retrieve stored API key without error")
+	assert.NotContains(t, stored.Hash, created.Key, "Only a hash of the key should be stored")
+
+	_, err = service.CreateAPIKey(&CreateAPIKeyRequest{Name: "Kiosk", Scopes: []string{"members:write"}})
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr, "Should reject a scope keys cannot be granted")
+	assert.Equal(t, "scopes", validationErr.Field, "Error should name the scopes field")
+
+	keys, err := service.GetAllAPIKeys()
+	require.NoError(t, err, "Should retrieve all API keys without error")
+	require.Len(t, keys, 1, "Should return 1 API key")
+	assert.Empty(t, keys[0].Key, "Listing should never return the full key")
+
+	t.Run("Verify", func(t *testing.T) {
+		key, err := service.VerifyAPIKey(created.Key)
+		require.NoError(t, err, "Should verify the key without error")
+		assert.Equal(t, created.ID, key.ID, "Should return the matching key")
+
+		details, err := service.GetAPIKeyByID(created.ID)
+		require.NoError(t, err, "Should retrieve API key without error")
+		require.NotNil(t, details.LastUsedAt, "Last use should be recorded")
+		assert.True(t, now.Equal(*details.LastUsedAt), "Last use should be the current time")
+
+		_, err = service.VerifyAPIKey(created.Key + "x")
+		assert.ErrorIs(t, err, ErrInvalidAPIKey, "Should reject an unknown key")
+
+		_, err = service.VerifyAPIKey("not-a-key")
+		assert.ErrorIs(t, err, ErrInvalidAPIKey, "Should reject a key without the glofox prefix")
+	})
+
+	t.Run("LastUsedResolution", func(t *testing.T) {
+		firstUse := now
+		now = now.Add(30 * time.Second)
+		_, err := service.VerifyAPIKey(created.Key)
+		require.NoError(t, err, "Should verify the key without error")
+
+		details, _ := service.GetAPIKeyByID(created.ID)
+		assert.True(t, firstUse.Equal(*details.LastUsedAt), "Uses within a minute should not be written again")
+
+		now = now.Add(time.Minute)
+		_, err = service.VerifyAPIKey(created.Key)
+		require.NoError(t, err, "Should verify the key without error")
+
+		details, _ = service.GetAPIKeyByID(created.ID)
+		assert.True(t, now.Equal(*details.LastUsedAt), "A later use should be recorded")
+	})
+
+	t.Run("Revoke", func(t *testing.T) {
+		revoked, err := service.RevokeAPIKey(created.ID)
+		require.NoError(t, err, "Should revoke API key without error")
+		require.NotNil(t, revoked.RevokedAt, "Revocation time should be recorded")
+
+		_, err = service.VerifyAPIKey(created.Key)
+		assert.ErrorIs(t, err, ErrInvalidAPIKey, "Should reject a revoked key")
+
+		_, err = service.RevokeAPIKey(created.ID)
+		assert.ErrorIs(t, err, ErrAPIKeyRevoked, "Should not revoke a key twice")
+
+		_, err = service.RevokeAPIKey("non-existent-key")
+		assert.ErrorIs(t, err, repository.ErrAPIKeyNotFound, "Should return error for non-existent key")
+
+		keys, err := service.GetAllAPIKeys()
+		require.NoError(t, err, "Should retrieve all API keys without error")
+		assert.Len(t, keys, 1, "Revoked keys should stay on record")
+	})
+}
@@ -1,57 +1,114 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"log/slog"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/sanjaykishor/Glofox/internal/repository"
 )
 
+// ErrBookingNotConfirmed is returned when cancelling or rescheduling a booking that is no longer confirmed
+var ErrBookingNotConfirmed = repository.ErrBookingNotConfirmed
+
 // BookingService handles business logic for bookings
 type BookingService struct {
-	bookingRepo *repository.BookingRepository
-	classRepo   *repository.ClassRepository
+	bookingRepo    repository.BookingStore
+	classRepo      repository.ClassStore
+	waitlistRepo   repository.WaitlistStore
+	memberRepo     repository.MemberStore
+	occurrenceRepo repository.OccurrenceStore
+	now            func() time.Time
+	location       *time.Location
 }
 
 // NewBookingService creates a new instance of BookingService
-func NewBookingService(bookingRepo *repository.BookingRepository, classRepo *repository.ClassRepository) *BookingService {
+func NewBookingService(
+	bookingRepo repository.BookingStore,
+	classRepo repository.ClassStore,
+	waitlistRepo repository.WaitlistStore,
+	memberRepo repository.MemberStore,
+	occurrenceRepo repository.OccurrenceStore,
+) *BookingService {
 	return &BookingService{
-		bookingRepo: bookingRepo,
-		classRepo:   classRepo,
+		bookingRepo:    bookingRepo,
+		classRepo:      classRepo,
+		waitlistRepo:   waitlistRepo,
+		memberRepo:     memberRepo,
+		occurrenceRepo: occurrenceRepo,
+		now:            time.Now,
+		location:       time.UTC,
 	}
 }
 
-// CreateBookingRequest represents the data needed to create a booking
+// SetClock overrides the clock used to decide which dates are in the past
+func (s *BookingService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// SetLocation sets the studio's time zone, which decides which day is today
+func (s *BookingService) SetLocation(loc *time.Location) {
+	s.location = loc
+}
+
+// localNow returns the current time in the studio's time zone
+func (s *BookingService) localNow() time.Time {
+	return s.now().In(s.location)
+}
+
+// CreateBookingRequest represents the data needed to create a booking. A
+// booking is made for a registered member by MemberID, or by name only.
 type CreateBookingRequest struct {
-	MemberName string `json:"name" binding:"required"`
+	MemberID   string `json:"member_id"`
+	MemberName string `json:"name" binding:"required_without=MemberID"`
 	Date       string `json:"date" binding:"required"`
 	ClassID    string `json:"class_id"`
 }
 
+// CancelBookingRequest represents the optional details of a cancellation
+type CancelBookingRequest struct {
+	Reason string `json:"reason"`
+}
+
+// RescheduleBookingRequest represents the new date, and optionally the new class, of a booking
+type RescheduleBookingRequest struct {
+	Date    string `json:"date" binding:"required"`
+	ClassID string `json:"class_id"`
+}
+
 // CreateBooking creates a new booking
 func (s *BookingService) CreateBooking(req *CreateBookingRequest) (*repository.Booking, error) {
-	bookingDate, err := time.Parse("2006-01-02", req.Date)
+	memberID, memberName, err := resolveMember(s.memberRepo, req.MemberID, req.MemberName)
 	if err != nil {
-		return nil, errors.New("invalid date format, use YYYY-MM-DD")
+		return nil, err
 	}
 
-	if req.ClassID != "" {
-		_, err := s.classRepo.GetByID(req.ClassID)
-		if err != nil {
-			return nil, errors.New("class not found")
-		}
+	// Hold the class so its capacity cannot change between reading and booking
+	defer lockClass(req.ClassID)()
+
+	bookingDate, occurrence, err := validateBookingDate(s.classRepo, s.occurrenceRepo, s.localNow(), req.Date, req.ClassID)
+	if err != nil {
+		return nil, err
 	}
 
 	booking := &repository.Booking{
 		ID:         uuid.New().String(),
-		MemberName: req.MemberName,
+		MemberID:   memberID,
+		MemberName: memberName,
 		ClassID:    req.ClassID,
 		Date:       bookingDate,
-		CreatedAt:  time.Now(),
+		Status:     repository.BookingStatusConfirmed,
+		CreatedAt:  s.now(),
 	}
 
-	if err := s.bookingRepo.Create(booking); err != nil {
+	if occurrence != nil {
+		err = s.bookingRepo.CreateWithCapacity(booking, occurrence.Capacity)
+	} else {
+		err = s.bookingRepo.Create(booking)
+	}
+	if err != nil {
 		return nil, err
 	}
 
@@ -59,10 +116,55 @@ func (s *BookingService) CreateBooking(req *CreateBookingRequest) (*repository.B
 }
 
 // GetAllBookings returns all bookings
-func (s *BookingService) GetAllBookings() []*repository.Booking {
+func (s *BookingService) GetAllBookings() ([]*repository.Booking, error) {
 	return s.bookingRepo.GetAll()
 }
 
+// ListBookings returns a page of the bookings matching the request
+// filters, ordered by date and creation time unless another sort is requested
+func (s *BookingService) ListBookings(req *ListBookingsRequest) (*BookingPage, error) {
+	opts, err := listOptions(&req.ListRequest, repository.BookingSortFields, "date,created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	from, to, err := parseDateRange(req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+	if req.Date != "" {
+		if from.IsZero() && to.IsZero() {
+			from, err = time.Parse("2006-01-02", req.Date)
+			if err != nil {
+				return nil, newValidationError("date", "date_format", "invalid date format, use YYYY-MM-DD")
+			}
+			to = from
+		} else {
+			return nil, &ValidationError{Field: "date", Rule: "excluded_with", Param: "from to", Message: "date cannot be combined with from or to"}
+		}
+	}
+
+	filter := repository.BookingFilter{ClassID: req.ClassID, Member: req.Member, MemberID: req.MemberID, From: from, To: to}
+	bookings, next, err := s.bookingRepo.List(filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BookingPage{Bookings: bookings, PageInfo: pageInfo(opts, next)}, nil
+}
+
+// ListClassBookings returns a page of the bookings for a class, filtered
+// like ListBookings
+func (s *BookingService) ListClassBookings(classID string, req *ListBookingsRequest) (*BookingPage, error) {
+	if _, err := s.classRepo.GetByID(classID); err != nil {
+		return nil, err
+	}
+
+	classReq := *req
+	classReq.ClassID = classID
+	return s.ListBookings(&classReq)
+}
+
 // GetBookingByID retrieves a booking by its ID
 func (s *BookingService) GetBookingByID(id string) (*repository.Booking, error) {
 	return s.bookingRepo.GetByID(id)
@@ -72,8 +174,152 @@ func (s *BookingService) GetBookingByID(id string) (*repository.Booking, error)
 func (s *BookingService) GetBookingsByDate(dateStr string) ([]*repository.Booking, error) {
 	date, err := time.Parse("2006-01-02", dateStr)
 	if err != nil {
-		return nil, errors.New("invalid date format, use YYYY-MM-DD")
+		return nil, newValidationError("date", "date_format", "invalid date format, use YYYY-MM-DD")
+	}
+
+	return s.bookingRepo.GetBookingsByDate(date)
+}
+
+// CancelBooking marks a confirmed booking as cancelled, recording when and
+// why. The booking is kept so the member's history stays complete, and the
+// freed place goes to the first member on the waitlist.
+func (s *BookingService) CancelBooking(ctx context.Context, id string, req *CancelBookingRequest) (*repository.Booking, error) {
+	existing, err := s.bookingRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing.Status != repository.BookingStatusConfirmed {
+		return nil, ErrBookingNotConfirmed
+	}
+
+	// Work on a copy so a failed update never leaks into the stored booking
+	booking := *existing
+	cancelledAt := s.now()
+	booking.Status = repository.BookingStatusCancelled
+	booking.CancelledAt = &cancelledAt
+	booking.CancellationReason = req.Reason
+
+	// The booking may have been cancelled or moved since it was read, in
+	// which case the place it held has already been given away
+	if err := s.bookingRepo.UpdateConfirmed(existing, &booking, 0); err != nil {
+		return nil, err
+	}
+
+	s.promoteWaitlist(ctx, existing.ClassID, existing.Date)
+
+	return &booking, nil
+}
+
+// RescheduleBooking moves a confirmed booking to another date and
+// optionally another class. The capacity of the new class and date is
+// checked atomically with the move, and the place left behind goes to the
+// first member on the waitlist.
+func (s *BookingService) RescheduleBooking(ctx context.Context, id string, req *RescheduleBookingRequest) (*repository.Booking, error) {
+	existing, err := s.bookingRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing.Status != repository.BookingStatusConfirmed {
+		return nil, ErrBookingNotConfirmed
+	}
+
+	classID := req.ClassID
+	if classID == "" {
+		classID = existing.ClassID
+	}
+
+	// The new class is released before promoting, which locks the old one
+	unlock := lockClass(classID)
+	bookingDate, occurrence, err := validateBookingDate(s.classRepo, s.occurrenceRepo, s.localNow(), req.Date, classID)
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+
+	booking := *existing
+	booking.ClassID = classID
+	booking.Date = bookingDate
+
+	// A booking without a class has no capacity to check
+	capacity := 0
+	if occurrence != nil {
+		capacity = occurrence.Capacity
+	}
+	err = s.bookingRepo.UpdateConfirmed(existing, &booking, capacity)
+	unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	if existing.ClassID != booking.ClassID || !existing.Date.Equal(booking.Date) {
+		s.promoteWaitlist(ctx, existing.ClassID, existing.Date)
 	}
 
-	return s.bookingRepo.GetBookingsByDate(date), nil
+	return &booking, nil
+}
+
+// promoteWaitlist fills a place freed in a class occurrence from its
+// waitlist. A failed promotion is logged rather than returned because the
+// change that freed the place has already been applied, with ctx so the
+// log lines carry the ID of the request that freed it.
+func (s *BookingService) promoteWaitlist(ctx context.Context, classID string, date time.Time) {
+	if classID == "" {
+		return
+	}
+
+	promoted, err := promoteWaitlist(s.waitlistRepo, s.bookingRepo, s.classRepo, s.occurrenceRepo, s.localNow, classID, date)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to promote waitlist", "class_id", classID, "date", date.Format("2006-01-02"), "error", err)
+	}
+	for entryID, booking := range promoted {
+		slog.InfoContext(ctx, "Promoted waitlist entry", "entry_id", entryID, "booking_id", booking.ID)
+	}
+}
+
+// validateBookingDate parses a booking date and checks that it is not in
+// the past and, when a class is given, that the class runs on that date.
+// It returns the class occurrence being booked, if any. now must be in the
+// studio's time zone, which decides both today and the occurrence times.
+func validateBookingDate(
+	classRepo repository.ClassStore,
+	occurrenceRepo repository.OccurrenceStore,
+	now time.Time,
+	dateStr, classID string,
+) (time.Time, *repository.ClassOccurrence, error) {
+	bookingDate, err := time.Parse("2006-01-02", dateStr)
+	if err != nil {
+		return time.Time{}, nil, newValidationError("date", "date_format", "invalid date format, use YYYY-MM-DD")
+	}
+
+	if bookingDate.Before(startOfDay(now)) {
+		return time.Time{}, nil, newValidationError("date", "not_past", "booking date cannot be in the past")
+	}
+
+	if classID == "" {
+		return bookingDate, nil, nil
+	}
+
+	_, occurrence, err := loadOccurrence(classRepo, occurrenceRepo, now.Location(), classID, bookingDate)
+	if errors.Is(err, ErrOccurrenceNotFound) {
+		return time.Time{}, nil, newValidationError("date", "class_schedule", "booking date is outside the class schedule")
+	}
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+
+	if occurrence.Cancelled() {
+		return time.Time{}, nil, ErrOccurrenceCancelled
+	}
+
+	return bookingDate, occurrence, nil
+}
+
+// startOfDay returns midnight UTC of the calendar day t falls on in t's
+// location, matching the dates produced by time.Parse("2006-01-02", ...).
+// Applied to the current time in the studio's time zone it gives today.
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
 }
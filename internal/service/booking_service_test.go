@@ -1,11 +1,17 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/sanjaykishor/Glofox/internal/repository"
+	"github.com/sanjaykishor/Glofox/internal/schedule"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestBookingService(t *testing.T) {
@@ -15,19 +21,22 @@ func TestBookingService(t *testing.T) {
 	class := &repository.Class{
 		ID:        "test-class-1",
 		Name:      "Yoga",
-		StartDate: time.Now().Add(24 * time.Hour), // Future date
-		EndDate:   time.Now().Add(25 * time.Hour),
+		StartDate: time.Date(2025, 4, 22, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2025, 4, 23, 0, 0, 0, 0, time.UTC),
 		Capacity:  20,
 	}
 
 	err := classRepo.Create(class)
 	assert.NoError(t, err, "Should create test class without error")
 
-	service := NewBookingService(bookingRepo, classRepo)
+	service := NewBookingService(bookingRepo, classRepo, repository.NewWaitlistRepository(), repository.NewMemberRepository(), repository.NewOccurrenceRepository())
+	service.SetClock(func() time.Time {
+		return time.Date(2025, 4, 22, 9, 0, 0, 0, time.UTC)
+	})
 
 	createReq := &CreateBookingRequest{
 		MemberName: "John Doe",
-		Date:       time.Now().Format("2006-01-02"),
+		Date:       "2025-04-22",
 		ClassID:    "test-class-1",
 	}
 
@@ -35,9 +44,11 @@ func TestBookingService(t *testing.T) {
 	assert.NoError(t, err, "Should create booking without error")
 	assert.Equal(t, createReq.MemberName, booking.MemberName, "Booking member name should match request")
 	assert.Equal(t, createReq.ClassID, booking.ClassID, "Booking class ID should match request")
+	assert.True(t, time.Date(2025, 4, 22, 9, 0, 0, 0, time.UTC).Equal(booking.CreatedAt), "Booking should be stamped with the service clock")
 
 	// Test getting all bookings
-	allBookings := service.GetAllBookings()
+	allBookings, err := service.GetAllBookings()
+	assert.NoError(t, err, "Should retrieve all bookings without error")
 	assert.Len(t, allBookings, 1, "Should return 1 booking")
 
 	// Test getting booking by ID
@@ -46,7 +57,7 @@ func TestBookingService(t *testing.T) {
 	assert.Equal(t, booking.ID, retrievedBooking.ID, "Retrieved booking ID should match")
 
 	// Test getting bookings by date
-	bookingsByDate, err := service.GetBookingsByDate(time.Now().Format("2006-01-02"))
+	bookingsByDate, err := service.GetBookingsByDate("2025-04-22")
 	assert.NoError(t, err, "Should retrieve bookings by date without error")
 	assert.Len(t, bookingsByDate, 1, "Should return 1 booking for date")
 
@@ -63,7 +74,7 @@ func TestBookingService(t *testing.T) {
 	// Non-existent class
 	_, err = service.CreateBooking(&CreateBookingRequest{
 		MemberName: "USER A",
-		Date:       time.Now().Format("2006-01-02"),
+		Date:       "2025-04-22",
 		ClassID:    "non-existent-class",
 	})
 	assert.Error(t, err, "Should return error for non-existent class")
@@ -76,3 +87,297 @@ func TestBookingService(t *testing.T) {
 	_, err = service.GetBookingsByDate("invalid-date")
 	assert.Error(t, err, "Should return error for invalid date format in GetBookingsByDate")
 }
+
+func TestBookingServiceCapacity(t *testing.T) {
+	bookingRepo := repository.NewBookingRepository()
+	classRepo := repository.NewClassRepository()
+
+	class := &repository.Class{
+		ID:        "test-class-1",
+		Name:      "Yoga",
+		StartDate: time.Date(2025, 4, 22, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2025, 4, 24, 0, 0, 0, 0, time.UTC),
+		Capacity:  1,
+	}
+	err := classRepo.Create(class)
+	assert.NoError(t, err, "Should create test class without error")
+
+	service := NewBookingService(bookingRepo, classRepo, repository.NewWaitlistRepository(), repository.NewMemberRepository(), repository.NewOccurrenceRepository())
+	service.SetClock(func() time.Time {
+		return time.Date(2025, 4, 22, 9, 0, 0, 0, time.UTC)
+	})
+	date := "2025-04-22"
+
+	_, err = service.CreateBooking(&CreateBookingRequest{MemberName: "USER A", Date: date, ClassID: class.ID})
+	assert.NoError(t, err, "Should create booking while capacity remains")
+
+	_, err = service.CreateBooking(&CreateBookingRequest{MemberName: "USER B", Date: date, ClassID: class.ID})
+	assert.ErrorIs(t, err, repository.ErrCapacityExceeded, "Should reject booking when class is full")
+}
+
+func TestBookingServiceDateValidation(t *testing.T) {
+	bookingRepo := repository.NewBookingRepository()
+	classRepo := repository.NewClassRepository()
+
+	class := &repository.Class{
+		ID:        "test-class-1",
+		Name:      "Yoga",
+		StartDate: time.Date(2025, 4, 20, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC),
+		Capacity:  20,
+	}
+	err := classRepo.Create(class)
+	assert.NoError(t, err, "Should create test class without error")
+
+	service := NewBookingService(bookingRepo, classRepo, repository.NewWaitlistRepository(), repository.NewMemberRepository(), repository.NewOccurrenceRepository())
+	service.SetClock(func() time.Time {
+		return time.Date(2025, 4, 22, 15, 30, 0, 0, time.UTC)
+	})
+
+	// Today and the last day of the class are both bookable
+	_, err = service.CreateBooking(&CreateBookingRequest{MemberName: "USER A", Date: "2025-04-22", ClassID: class.ID})
+	assert.NoError(t, err, "Should create booking for today without error")
+
+	_, err = service.CreateBooking(&CreateBookingRequest{MemberName: "USER A", Date: "2025-04-30", ClassID: class.ID})
+	assert.NoError(t, err, "Should create booking on the class end date without error")
+
+	// Date in the past
+	_, err = service.CreateBooking(&CreateBookingRequest{MemberName: "USER A", Date: "2025-04-21", ClassID: class.ID})
+	assert.EqualError(t, err, "booking date cannot be in the past")
+
+	// Date after the class has ended
+	_, err = service.CreateBooking(&CreateBookingRequest{MemberName: "USER A", Date: "2025-05-01", ClassID: class.ID})
+	assert.EqualError(t, err, "booking date is outside the class schedule")
+
+	// Date before the class starts
+	service.SetClock(func() time.Time {
+		return time.Date(2025, 4, 1, 9, 0, 0, 0, time.UTC)
+	})
+	_, err = service.CreateBooking(&CreateBookingRequest{MemberName: "USER A", Date: "2025-04-19", ClassID: class.ID})
+	assert.EqualError(t, err, "booking date is outside the class schedule")
+
+	// Date within the range on which a recurring class does not meet
+	scheduled := *class
+	scheduled.Schedule = &schedule.Recurrence{Weekdays: []string{"tuesday", "thursday"}}
+	assert.NoError(t, classRepo.Update(&scheduled), "Should update test class without error")
+
+	_, err = service.CreateBooking(&CreateBookingRequest{MemberName: "USER B", Date: "2025-04-24", ClassID: class.ID})
+	assert.NoError(t, err, "Should create booking on a scheduled day without error")
+
+	_, err = service.CreateBooking(&CreateBookingRequest{MemberName: "USER B", Date: "2025-04-23", ClassID: class.ID})
+	assert.EqualError(t, err, "booking date is outside the class schedule")
+}
+
+func TestBookingServiceTimeZone(t *testing.T) {
+	classRepo := repository.NewClassRepository()
+	err := classRepo.Create(&repository.Class{
+		ID:        "test-class-1",
+		Name:      "Yoga",
+		StartDate: time.Date(2025, 4, 20, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC),
+		Capacity:  20,
+	})
+	assert.NoError(t, err, "Should create test class without error")
+
+	service := NewBookingService(repository.NewBookingRepository(), classRepo, repository.NewWaitlistRepository(), repository.NewMemberRepository(), repository.NewOccurrenceRepository())
+	// 02:00 UTC on April 22 is still the evening of April 21 in Los Angeles
+	service.SetClock(func() time.Time { return time.Date(2025, 4, 22, 2, 0, 0, 0, time.UTC) })
+
+	_, err = service.CreateBooking(&CreateBookingRequest{MemberName: "USER A", Date: "2025-04-21", ClassID: "test-class-1"})
+	assert.EqualError(t, err, "booking date cannot be in the past", "UTC studio should already be on April 22")
+
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	require.NoError(t, err, "Should load time zone without error")
+	service.SetLocation(loc)
+
+	booking, err := service.CreateBooking(&CreateBookingRequest{MemberName: "USER A", Date: "2025-04-21", ClassID: "test-class-1"})
+	require.NoError(t, err, "Should book today in the studio's time zone without error")
+	assert.Equal(t, time.Date(2025, 4, 21, 0, 0, 0, 0, time.UTC), booking.Date, "Booking should keep its calendar date")
+}
+
+func TestBookingServiceCancelAndReschedule(t *testing.T) {
+	bookingRepo := repository.NewBookingRepository()
+	classRepo := repository.NewClassRepository()
+
+	classes := []*repository.Class{
+		{ID: "yoga", Name: "Yoga", StartDate: time.Date(2025, 4, 20, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC), Capacity: 1},
+		{ID: "pilates", Name: "Pilates", StartDate: time.Date(2025, 4, 20, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC), Capacity: 1},
+	}
+	for _, class := range classes {
+		assert.NoError(t, classRepo.Create(class), "Should create test class without error")
+	}
+
+	now := time.Date(2025, 4, 22, 15, 30, 0, 0, time.UTC)
+	service := NewBookingService(bookingRepo, classRepo, repository.NewWaitlistRepository(), repository.NewMemberRepository(), repository.NewOccurrenceRepository())
+	service.SetClock(func() time.Time { return now })
+
+	first, err := service.CreateBooking(&CreateBookingRequest{MemberName: "USER A", Date: "2025-04-25", ClassID: "yoga"})
+	assert.NoError(t, err, "Should create booking without error")
+	assert.Equal(t, repository.BookingStatusConfirmed, first.Status, "New booking should be confirmed")
+
+	second, err := service.CreateBooking(&CreateBookingRequest{MemberName: "USER B", Date: "2025-04-26", ClassID: "yoga"})
+	assert.NoError(t, err, "Should create booking without error")
+
+	// Rescheduling onto a full date is rejected
+	_, err = service.RescheduleBooking(context.Background(), second.ID, &RescheduleBookingRequest{Date: "2025-04-25"})
+	assert.ErrorIs(t, err, repository.ErrCapacityExceeded, "Should reject rescheduling onto a full date")
+
+	// Rescheduling to another class and date
+	moved, err := service.RescheduleBooking(context.Background(), second.ID, &RescheduleBookingRequest{Date: "2025-04-27", ClassID: "pilates"})
+	assert.NoError(t, err, "Should reschedule booking without error")
+	assert.Equal(t, "pilates", moved.ClassID, "Booking should move to the new class")
+	assert.Equal(t, "2025-04-27", moved.Date.Format("2006-01-02"), "Booking should move to the new date")
+
+	// Rescheduling re-checks the class schedule and the clock
+	_, err = service.RescheduleBooking(context.Background(), first.ID, &RescheduleBookingRequest{Date: "2025-05-01"})
+	assert.EqualError(t, err, "booking date is outside the class schedule")
+
+	_, err = service.RescheduleBooking(context.Background(), first.ID, &RescheduleBookingRequest{Date: "2025-04-21"})
+	assert.EqualError(t, err, "booking date cannot be in the past")
+
+	// Cancelling keeps the booking and frees its place
+	cancelled, err := service.CancelBooking(context.Background(), first.ID, &CancelBookingRequest{Reason: "Feeling unwell"})
+	assert.NoError(t, err, "Should cancel booking without error")
+	assert.Equal(t, repository.BookingStatusCancelled, cancelled.Status, "Booking should be cancelled")
+	assert.Equal(t, "Feeling unwell", cancelled.CancellationReason, "Cancellation reason should be recorded")
+	assert.Equal(t, now, *cancelled.CancelledAt, "Cancellation time should come from the clock")
+
+	stored, err := service.GetBookingByID(first.ID)
+	assert.NoError(t, err, "Cancelled booking should still exist")
+	assert.Equal(t, repository.BookingStatusCancelled, stored.Status, "Stored booking should be cancelled")
+
+	_, err = service.CreateBooking(&CreateBookingRequest{MemberName: "USER C", Date: "2025-04-25", ClassID: "yoga"})
+	assert.NoError(t, err, "Cancelled booking should free its place")
+
+	// Cancelled bookings cannot be cancelled or rescheduled again
+	_, err = service.CancelBooking(context.Background(), first.ID, &CancelBookingRequest{})
+	assert.ErrorIs(t, err, ErrBookingNotConfirmed, "Should reject cancelling a cancelled booking")
+
+	_, err = service.RescheduleBooking(context.Background(), first.ID, &RescheduleBookingRequest{Date: "2025-04-28"})
+	assert.ErrorIs(t, err, ErrBookingNotConfirmed, "Should reject rescheduling a cancelled booking")
+
+	// Non-existent booking
+	_, err = service.CancelBooking(context.Background(), "non-existent-booking", &CancelBookingRequest{})
+	assert.Error(t, err, "Should return error for non-existent booking")
+}
+
+func TestBookingServiceConcurrentChanges(t *testing.T) {
+	setup := func(t *testing.T) (*BookingService, *repository.WaitlistRepository, *repository.Booking) {
+		classRepo := repository.NewClassRepository()
+		require.NoError(t, classRepo.Create(&repository.Class{ID: "yoga", Name: "Yoga", StartDate: time.Date(2025, 4, 20, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC), Capacity: 1}), "Should create test class without error")
+
+		waitlistRepo := repository.NewWaitlistRepository()
+		service := NewBookingService(repository.NewBookingRepository(), classRepo, waitlistRepo, repository.NewMemberRepository(), repository.NewOccurrenceRepository())
+		service.SetClock(func() time.Time { return time.Date(2025, 4, 22, 9, 0, 0, 0, time.UTC) })
+
+		booking, err := service.CreateBooking(&CreateBookingRequest{MemberName: "USER A", Date: "2025-04-25", ClassID: "yoga"})
+		require.NoError(t, err, "Should create booking without error")
+
+		for i, name := range []string{"USER B", "USER C"} {
+			entry := &repository.WaitlistEntry{ID: name, MemberName: name, ClassID: "yoga", Date: booking.Date, CreatedAt: time.Date(2025, 4, 22, 8, i, 0, 0, time.UTC)}
+			require.NoError(t, waitlistRepo.Create(entry), "Should join waitlist without error")
+		}
+
+		return service, waitlistRepo, booking
+	}
+
+	t.Run("Cancel Twice", func(t *testing.T) {
+		service, waitlistRepo, booking := setup(t)
+
+		var wg sync.WaitGroup
+		var cancelled, rejected atomic.Int32
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				_, err := service.CancelBooking(context.Background(), booking.ID, &CancelBookingRequest{})
+				if err == nil {
+					cancelled.Add(1)
+				} else if errors.Is(err, ErrBookingNotConfirmed) {
+					rejected.Add(1)
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(1), cancelled.Load(), "Should cancel the booking exactly once")
+		assert.Equal(t, int32(9), rejected.Load(), "Every other attempt should find the booking already cancelled")
+
+		waitlist, err := waitlistRepo.GetByOccurrence("yoga", booking.Date)
+		require.NoError(t, err, "Should retrieve waitlist without error")
+		assert.Len(t, waitlist, 1, "Should promote exactly one member into the freed place")
+	})
+
+	t.Run("Cancel And Reschedule", func(t *testing.T) {
+		service, _, booking := setup(t)
+
+		var wg sync.WaitGroup
+		errs := make([]error, 2)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, errs[0] = service.CancelBooking(context.Background(), booking.ID, &CancelBookingRequest{})
+		}()
+		go func() {
+			defer wg.Done()
+			_, errs[1] = service.RescheduleBooking(context.Background(), booking.ID, &RescheduleBookingRequest{Date: "2025-04-27"})
+		}()
+		wg.Wait()
+
+		require.False(t, errs[0] != nil && errs[1] != nil, "One of the changes should succeed: %v, %v", errs[0], errs[1])
+
+		// Whichever ran second saw the first, so neither undid the other
+		stored, err := service.GetBookingByID(booking.ID)
+		require.NoError(t, err, "Should retrieve booking without error")
+		if errs[0] == nil {
+			assert.Equal(t, repository.BookingStatusCancelled, stored.Status, "Stored booking should be cancelled")
+		} else {
+			assert.Equal(t, repository.BookingStatusConfirmed, stored.Status, "Stored booking should stay confirmed")
+		}
+		if errs[1] == nil {
+			assert.Equal(t, "2025-04-27", stored.Date.Format("2006-01-02"), "Stored booking should keep its move")
+		}
+
+		count, err := service.bookingRepo.CountByClassAndDate("yoga", booking.Date)
+		require.NoError(t, err, "Should count bookings without error")
+		assert.Equal(t, 1, count, "Should fill the freed place exactly once")
+	})
+}
+
+func TestBookingServiceDuplicateBooking(t *testing.T) {
+	bookingRepo := repository.NewBookingRepository()
+	classRepo := repository.NewClassRepository()
+
+	class := &repository.Class{
+		ID:        "yoga",
+		Name:      "Yoga",
+		StartDate: time.Date(2025, 4, 20, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC),
+		Capacity:  10,
+	}
+	assert.NoError(t, classRepo.Create(class), "Should create test class without error")
+
+	service := NewBookingService(bookingRepo, classRepo, repository.NewWaitlistRepository(), repository.NewMemberRepository(), repository.NewOccurrenceRepository())
+	service.SetClock(func() time.Time { return time.Date(2025, 4, 22, 9, 0, 0, 0, time.UTC) })
+
+	first, err := service.CreateBooking(&CreateBookingRequest{MemberName: "USER A", Date: "2025-04-25", ClassID: "yoga"})
+	assert.NoError(t, err, "Should create booking without error")
+
+	_, err = service.CreateBooking(&CreateBookingRequest{MemberName: "USER A", Date: "2025-04-25", ClassID: "yoga"})
+	var duplicate *repository.DuplicateBookingError
+	assert.ErrorAs(t, err, &duplicate, "Should reject a second booking for the same class and date")
+	assert.Equal(t, first.ID, duplicate.ExistingID, "Error should identify the existing booking")
+
+	other, err := service.CreateBooking(&CreateBookingRequest{MemberName: "USER A", Date: "2025-04-26", ClassID: "yoga"})
+	assert.NoError(t, err, "Should allow booking the same class on another date")
+
+	_, err = service.RescheduleBooking(context.Background(), other.ID, &RescheduleBookingRequest{Date: "2025-04-25"})
+	assert.ErrorIs(t, err, repository.ErrDuplicateBooking, "Should reject rescheduling onto a date the member is booked for")
+
+	_, err = service.CancelBooking(context.Background(), first.ID, &CancelBookingRequest{})
+	assert.NoError(t, err, "Should cancel booking without error")
+
+	_, err = service.CreateBooking(&CreateBookingRequest{MemberName: "USER A", Date: "2025-04-25", ClassID: "yoga"})
+	assert.NoError(t, err, "Should allow rebooking after a cancellation")
+}
@@ -0,0 +1,73 @@
+package service
+
+import "sync"
+
+// classLocks serializes the changes that decide how many places a class
+// occurrence has with the bookings that take them. Classes, overrides and
+// bookings live in separate stores, so a booking checked against a capacity
+// read before a concurrent update would otherwise overbook the class.
+var classLocks = newKeyedMutex()
+
+// lockClass locks a class against capacity changes and returns the function
+// that releases it. A booking without a class has nothing to lock.
+func lockClass(classID string) (unlock func()) {
+	if classID == "" {
+		return func() {}
+	}
+	return classLocks.lock(classID)
+}
+
+// assignmentLock serializes the instructor and room double-booking checks
+// with the class and occurrence changes they guard. A clash can involve any
+// two classes, so a single lock covers them all. It is always taken after
+// the class lock, never before.
+var assignmentLock sync.Mutex
+
+// lockAssignments locks instructor and room assignments and returns the
+// function that releases them
+func lockAssignments() (unlock func()) {
+	assignmentLock.Lock()
+	return assignmentLock.Unlock
+}
+
+// keyedMutex hands out a mutex per key, dropping it once nobody holds it
+type keyedMutex struct {
+	mutex sync.Mutex
+	locks map[string]*refMutex
+}
+
+// refMutex is a mutex counting the callers holding or waiting for it
+type refMutex struct {
+	sync.Mutex
+	refs int
+}
+
+func newKeyedMutex() *keyedMutex {
+	return &keyedMutex{locks: make(map[string]*refMutex)}
+}
+
+// lock blocks until the mutex for key is held and returns the function that
+// releases it. The mutex is not reentrant, so the caller must release it
+// before calling anything else that locks the same key.
+func (k *keyedMutex) lock(key string) (unlock func()) {
+	k.mutex.Lock()
+	m, ok := k.locks[key]
+	if !ok {
+		m = &refMutex{}
+		k.locks[key] = m
+	}
+	m.refs++
+	k.mutex.Unlock()
+
+	m.Lock()
+	return func() {
+		m.Unlock()
+
+		k.mutex.Lock()
+		m.refs--
+		if m.refs == 0 {
+			delete(k.locks, key)
+		}
+		k.mutex.Unlock()
+	}
+}
@@ -1,52 +1,121 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"log/slog"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/sanjaykishor/Glofox/internal/repository"
+	"github.com/sanjaykishor/Glofox/internal/schedule"
+)
+
+var (
+	// ErrCapacityBelowBookings is returned when an update would leave a day with more bookings than capacity
+	ErrCapacityBelowBookings = repository.NewConflictError("capacity_below_bookings", "capacity cannot be lower than the number of bookings already made for a day")
+	// ErrBookingsOutsideRange is returned when an update would leave upcoming bookings on days the class no longer meets
+	ErrBookingsOutsideRange = repository.NewConflictError("bookings_outside_range", "class has upcoming bookings outside the new date range or schedule")
+	// ErrClassHasBookings is returned when deleting a class with upcoming bookings without forcing it
+	ErrClassHasBookings = repository.NewConflictError("class_has_bookings", "class has upcoming bookings, use force=true to cancel them and delete the class")
 )
 
 type ClassService struct {
-	repo *repository.ClassRepository
+	repo           repository.ClassStore
+	bookingRepo    repository.BookingStore
+	waitlistRepo   repository.WaitlistStore
+	occurrenceRepo repository.OccurrenceStore
+	instructorRepo repository.InstructorStore
+	roomRepo       repository.RoomStore
+	now            func() time.Time
+	location       *time.Location
 }
 
-func NewClassService(repo *repository.ClassRepository) *ClassService {
+func NewClassService(
+	repo repository.ClassStore,
+	bookingRepo repository.BookingStore,
+	waitlistRepo repository.WaitlistStore,
+	occurrenceRepo repository.OccurrenceStore,
+	instructorRepo repository.InstructorStore,
+	roomRepo repository.RoomStore,
+) *ClassService {
 	return &ClassService{
-		repo: repo,
+		repo:           repo,
+		bookingRepo:    bookingRepo,
+		waitlistRepo:   waitlistRepo,
+		occurrenceRepo: occurrenceRepo,
+		instructorRepo: instructorRepo,
+		roomRepo:       roomRepo,
+		now:            time.Now,
+		location:       time.UTC,
 	}
 }
 
-// CreateClassRequest represents the data needed to create a class
+// SetClock overrides the clock used to decide which bookings are upcoming
+func (s *ClassService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// SetLocation sets the studio's time zone, which decides which day is today
+func (s *ClassService) SetLocation(loc *time.Location) {
+	s.location = loc
+}
+
+// localNow returns the current time in the studio's time zone
+func (s *ClassService) localNow() time.Time {
+	return s.now().In(s.location)
+}
+
+// CreateClassRequest represents the data needed to create a class. Without
+// a schedule the class meets every day from StartDate to EndDate.
 type CreateClassRequest struct {
-	Name      string `json:"name" binding:"required"`
-	StartDate string `json:"start_date" binding:"required"`
-	EndDate   string `json:"end_date" binding:"required"`
-	Capacity  int    `json:"capacity" binding:"required,min=1"`
+	Name         string               `json:"name" binding:"required"`
+	StartDate    string               `json:"start_date" binding:"required"`
+	EndDate      string               `json:"end_date" binding:"required"`
+	Capacity     int                  `json:"capacity" binding:"required,min=1"`
+	Schedule     *schedule.Recurrence `json:"schedule"`
+	InstructorID string               `json:"instructor_id"`
+	RoomID       string               `json:"room_id"`
+}
+
+// UpdateClassRequest represents a partial update to a class. Omitted fields
+// are left unchanged; a schedule replaces the whole existing schedule, and
+// an empty instructor or room ID unassigns the instructor or room.
+type UpdateClassRequest struct {
+	Name         *string              `json:"name" binding:"omitempty,min=1"`
+	StartDate    *string              `json:"start_date"`
+	EndDate      *string              `json:"end_date"`
+	Capacity     *int                 `json:"capacity" binding:"omitempty,min=1"`
+	Schedule     *schedule.Recurrence `json:"schedule"`
+	InstructorID *string              `json:"instructor_id"`
+	RoomID       *string              `json:"room_id"`
 }
 
 func (s *ClassService) CreateClass(req *CreateClassRequest) (*repository.Class, error) {
-	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	startDate, endDate, err := parseClassDates(req.StartDate, req.EndDate)
 	if err != nil {
-		return nil, errors.New("invalid start date format, use YYYY-MM-DD")
+		return nil, err
 	}
 
-	endDate, err := time.Parse("2006-01-02", req.EndDate)
-	if err != nil {
-		return nil, errors.New("invalid end date format, use YYYY-MM-DD")
+	class := &repository.Class{
+		ID:           uuid.New().String(),
+		Name:         req.Name,
+		StartDate:    startDate,
+		EndDate:      endDate,
+		Capacity:     req.Capacity,
+		Schedule:     req.Schedule,
+		InstructorID: req.InstructorID,
+		RoomID:       req.RoomID,
 	}
 
-	if endDate.Before(startDate) {
-		return nil, errors.New("end date cannot be before start date")
+	if err := validateSchedule(class); err != nil {
+		return nil, err
 	}
 
-	class := &repository.Class{
-		ID:        uuid.New().String(),
-		Name:      req.Name,
-		StartDate: startDate,
-		EndDate:   endDate,
-		Capacity:  req.Capacity,
+	defer lockAssignments()()
+
+	if err := s.checkAssignments(class); err != nil {
+		return nil, err
 	}
 
 	if err := s.repo.Create(class); err != nil {
@@ -57,11 +126,325 @@ func (s *ClassService) CreateClass(req *CreateClassRequest) (*repository.Class,
 }
 
 // GetAllClasses returns all classes
-func (s *ClassService) GetAllClasses() []*repository.Class {
+func (s *ClassService) GetAllClasses() ([]*repository.Class, error) {
 	return s.repo.GetAll()
 }
 
+// ListClasses returns a page of the classes matching the request filters,
+// ordered by start date unless another sort is requested
+func (s *ClassService) ListClasses(req *ListClassesRequest) (*ClassPage, error) {
+	opts, err := listOptions(&req.ListRequest, repository.ClassSortFields, "start_date")
+	if err != nil {
+		return nil, err
+	}
+
+	from, to, err := parseDateRange(req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+
+	classes, next, err := s.repo.List(repository.ClassFilter{Name: req.Name, From: from, To: to, InstructorID: req.InstructorID, RoomID: req.RoomID}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ClassPage{Classes: classes, PageInfo: pageInfo(opts, next)}, nil
+}
+
 // GetClassByID retrieves a class by its ID
 func (s *ClassService) GetClassByID(id string) (*repository.Class, error) {
 	return s.repo.GetByID(id)
 }
+
+// ReplaceClass replaces every field of an existing class
+func (s *ClassService) ReplaceClass(ctx context.Context, id string, req *CreateClassRequest) (*repository.Class, error) {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return nil, err
+	}
+
+	startDate, endDate, err := parseClassDates(req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	class := &repository.Class{
+		ID:           id,
+		Name:         req.Name,
+		StartDate:    startDate,
+		EndDate:      endDate,
+		Capacity:     req.Capacity,
+		Schedule:     req.Schedule,
+		InstructorID: req.InstructorID,
+		RoomID:       req.RoomID,
+	}
+
+	if err := s.update(class); err != nil {
+		return nil, err
+	}
+
+	s.promoteWaitlists(ctx, class.ID)
+
+	return class, nil
+}
+
+// PatchClass updates the fields of an existing class that are set in the request
+func (s *ClassService) PatchClass(ctx context.Context, id string, req *UpdateClassRequest) (*repository.Class, error) {
+	existing, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Work on a copy so a rejected update never leaks into the stored class
+	class := *existing
+
+	if req.Name != nil {
+		class.Name = *req.Name
+	}
+	if req.Capacity != nil {
+		class.Capacity = *req.Capacity
+	}
+	if req.Schedule != nil {
+		class.Schedule = req.Schedule
+	}
+	if req.InstructorID != nil {
+		class.InstructorID = *req.InstructorID
+	}
+	if req.RoomID != nil {
+		class.RoomID = *req.RoomID
+	}
+
+	startDate := class.StartDate.Format("2006-01-02")
+	if req.StartDate != nil {
+		startDate = *req.StartDate
+	}
+	endDate := class.EndDate.Format("2006-01-02")
+	if req.EndDate != nil {
+		endDate = *req.EndDate
+	}
+
+	class.StartDate, class.EndDate, err = parseClassDates(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.update(&class); err != nil {
+		return nil, err
+	}
+
+	s.promoteWaitlists(ctx, class.ID)
+
+	return &class, nil
+}
+
+// promoteWaitlists fills the places a class change may have opened, such as
+// a raised capacity, from the class's waitlists. It runs once the class is
+// unlocked, since promoting locks it again, and failures are logged rather
+// than returned because the change has already been applied.
+func (s *ClassService) promoteWaitlists(ctx context.Context, classID string) {
+	entries, err := s.waitlistRepo.GetAll()
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to load waitlists", "class_id", classID, "error", err)
+		return
+	}
+
+	dates := make(map[time.Time]bool)
+	for _, entry := range entries {
+		if entry.ClassID == classID {
+			dates[startOfDay(entry.Date)] = true
+		}
+	}
+
+	for date := range dates {
+		promoted, err := promoteWaitlist(s.waitlistRepo, s.bookingRepo, s.repo, s.occurrenceRepo, s.localNow, classID, date)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to promote waitlist", "class_id", classID, "date", date.Format("2006-01-02"), "error", err)
+		}
+		for entryID, booking := range promoted {
+			slog.InfoContext(ctx, "Promoted waitlist entry", "entry_id", entryID, "booking_id", booking.ID)
+		}
+	}
+}
+
+// DeleteClass removes a class. Classes with upcoming active bookings are
+// only deleted when force is set, in which case those bookings are
+// cancelled. Past bookings are kept as history; the class's waitlists are
+// removed, since there is nothing left to wait for. The class is locked
+// throughout so no booking is taken after its bookings are cancelled.
+func (s *ClassService) DeleteClass(id string, force bool) error {
+	defer lockClass(id)()
+
+	if _, err := s.repo.GetByID(id); err != nil {
+		return err
+	}
+
+	bookings, err := s.bookingRepo.GetByClassID(id)
+	if err != nil {
+		return err
+	}
+
+	today := startOfDay(s.localNow())
+	upcoming := make([]*repository.Booking, 0)
+	for _, booking := range bookings {
+		if booking.Active() && !booking.Date.Before(today) {
+			upcoming = append(upcoming, booking)
+		}
+	}
+
+	if len(upcoming) > 0 && !force {
+		return ErrClassHasBookings
+	}
+
+	cancelledAt := s.now()
+	for _, existing := range upcoming {
+		booking := *existing
+		booking.Status = repository.BookingStatusCancelled
+		booking.CancelledAt = &cancelledAt
+		booking.CancellationReason = "class deleted"
+
+		if err := s.bookingRepo.Update(&booking); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+
+	if err := s.occurrenceRepo.DeleteByClass(id); err != nil {
+		return err
+	}
+
+	return s.waitlistRepo.DeleteByClass(id)
+}
+
+// update stores the class after checking that its active bookings still
+// fit: no day may have more bookings than its capacity, which is the new
+// class capacity unless the occurrence overrides it, and upcoming bookings
+// must stay on days the class still meets. The class is locked so no
+// booking is taken against the capacity or schedule being replaced.
+func (s *ClassService) update(class *repository.Class) error {
+	defer lockClass(class.ID)()
+
+	if err := validateSchedule(class); err != nil {
+		return err
+	}
+
+	bookings, err := s.bookingRepo.GetByClassID(class.ID)
+	if err != nil {
+		return err
+	}
+
+	today := startOfDay(s.localNow())
+	perDay := make(map[time.Time]int)
+	for _, booking := range bookings {
+		if !booking.Active() {
+			continue
+		}
+
+		day := startOfDay(booking.Date)
+		perDay[day]++
+
+		if perDay[day] > class.Capacity {
+			// An occurrence with its own capacity is not limited by the class capacity
+			override, err := s.occurrenceRepo.Get(class.ID, day)
+			if err != nil && !errors.Is(err, repository.ErrOccurrenceOverrideNotFound) {
+				return err
+			}
+			if override == nil || override.Capacity == 0 || perDay[day] > override.Capacity {
+				return ErrCapacityBelowBookings
+			}
+		}
+
+		if !day.Before(today) && !class.OccursOn(day) {
+			return ErrBookingsOutsideRange
+		}
+	}
+
+	defer lockAssignments()()
+
+	if err := s.checkAssignments(class); err != nil {
+		return err
+	}
+
+	return s.repo.Update(class)
+}
+
+// checkAssignments checks that the class instructor and room exist, that
+// the class and its upcoming occurrences fit in the room, and that no
+// upcoming occurrence clashes with another class taught by the same
+// instructor or held in the same room. Callers must hold the assignment
+// lock until the class is stored.
+func (s *ClassService) checkAssignments(class *repository.Class) error {
+	if err := validateInstructor(s.instructorRepo, class.InstructorID); err != nil {
+		return err
+	}
+
+	room, err := validateRoom(s.roomRepo, class.RoomID)
+	if err != nil {
+		return err
+	}
+	if room != nil && class.Capacity > room.Capacity {
+		return roomCapacityError(room)
+	}
+
+	from := maxDate(class.StartDate, startOfDay(s.localNow()))
+	occurrences, err := resolveOccurrences(s.occurrenceRepo, class, from, class.EndDate, s.location)
+	if err != nil {
+		return err
+	}
+
+	if room != nil {
+		for _, occurrence := range occurrences {
+			if !occurrence.Cancelled() && occurrence.Capacity > room.Capacity {
+				return roomCapacityError(room)
+			}
+		}
+	}
+
+	if err := checkInstructorConflicts(s.repo, s.occurrenceRepo, s.location, class.ID, occurrences); err != nil {
+		return err
+	}
+
+	return checkRoomConflicts(s.repo, s.occurrenceRepo, s.location, class.ID, occurrences)
+}
+
+// validateSchedule checks the schedule of a class and that the class meets
+// at least once between its start and end dates
+func validateSchedule(class *repository.Class) error {
+	if class.Schedule == nil {
+		return nil
+	}
+
+	if err := class.Schedule.Validate(); err != nil {
+		var scheduleErr *schedule.Error
+		if errors.As(err, &scheduleErr) {
+			return &ValidationError{Field: "schedule." + scheduleErr.Field, Rule: scheduleErr.Rule, Message: scheduleErr.Message}
+		}
+		return err
+	}
+
+	if len(class.Occurrences(class.StartDate, class.EndDate, time.UTC)) == 0 {
+		return newValidationError("schedule", "occurrences", "schedule has no occurrences between start_date and end_date")
+	}
+
+	return nil
+}
+
+// parseClassDates parses the start and end dates of a class and checks their order
+func parseClassDates(start, end string) (time.Time, time.Time, error) {
+	startDate, err := time.Parse("2006-01-02", start)
+	if err != nil {
+		return time.Time{}, time.Time{}, newValidationError("start_date", "date_format", "invalid start date format, use YYYY-MM-DD")
+	}
+
+	endDate, err := time.Parse("2006-01-02", end)
+	if err != nil {
+		return time.Time{}, time.Time{}, newValidationError("end_date", "date_format", "invalid end date format, use YYYY-MM-DD")
+	}
+
+	if endDate.Before(startDate) {
+		return time.Time{}, time.Time{}, &ValidationError{Field: "end_date", Rule: "gtefield", Param: "start_date", Message: "end date cannot be before start date"}
+	}
+
+	return startDate, endDate, nil
+}
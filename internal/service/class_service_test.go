@@ -1,17 +1,23 @@
 package service
 
 import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/sanjaykishor/Glofox/internal/repository"
+	"github.com/sanjaykishor/Glofox/internal/schedule"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestClassService(t *testing.T) {
 	repo := repository.NewClassRepository()
 
-	service := NewClassService(repo)
+	service := NewClassService(repo, repository.NewBookingRepository(), repository.NewWaitlistRepository(), repository.NewOccurrenceRepository(), repository.NewInstructorRepository(), repository.NewRoomRepository())
 
 	createReq := &CreateClassRequest{
 		Name:      "Yoga",
@@ -25,7 +31,8 @@ func TestClassService(t *testing.T) {
 	assert.Equal(t, createReq.Name, class.Name, "Class name should match request")
 	assert.Equal(t, createReq.Capacity, class.Capacity, "Class capacity should match request")
 
-	allClasses := service.GetAllClasses()
+	allClasses, err := service.GetAllClasses()
+	assert.NoError(t, err, "Should retrieve all classes without error")
 	assert.Len(t, allClasses, 1, "Should return 1 class")
 
 	retrievedClass, err := service.GetClassByID(class.ID)
@@ -65,3 +72,280 @@ func TestClassService(t *testing.T) {
 	_, err = service.GetClassByID("non-existent-class")
 	assert.Error(t, err, "Should return error for non-existent class")
 }
+
+// setupClassWithBookings creates a class from 2025-04-20 to 2025-04-30 with
+// two bookings on 2025-04-25 and one on 2025-04-26, with the clock set to 2025-04-22
+func setupClassWithBookings(t *testing.T) (*ClassService, *repository.ClassRepository, *repository.BookingRepository) {
+	classRepo := repository.NewClassRepository()
+	bookingRepo := repository.NewBookingRepository()
+
+	class := &repository.Class{
+		ID:        "test-class-1",
+		Name:      "Yoga",
+		StartDate: time.Date(2025, 4, 20, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC),
+		Capacity:  10,
+	}
+	assert.NoError(t, classRepo.Create(class), "Should create test class without error")
+
+	bookings := []*repository.Booking{
+		{ID: "booking-1", MemberName: "USER A", ClassID: class.ID, Date: time.Date(2025, 4, 25, 0, 0, 0, 0, time.UTC)},
+		{ID: "booking-2", MemberName: "USER B", ClassID: class.ID, Date: time.Date(2025, 4, 25, 0, 0, 0, 0, time.UTC)},
+		{ID: "booking-3", MemberName: "USER C", ClassID: class.ID, Date: time.Date(2025, 4, 26, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, booking := range bookings {
+		assert.NoError(t, bookingRepo.Create(booking), "Should create test booking without error")
+	}
+
+	service := NewClassService(classRepo, bookingRepo, repository.NewWaitlistRepository(), repository.NewOccurrenceRepository(), repository.NewInstructorRepository(), repository.NewRoomRepository())
+	service.SetClock(func() time.Time {
+		return time.Date(2025, 4, 22, 10, 0, 0, 0, time.UTC)
+	})
+
+	return service, classRepo, bookingRepo
+}
+
+func TestClassServiceUpdate(t *testing.T) {
+	service, classRepo, _ := setupClassWithBookings(t)
+
+	// Replace every field
+	class, err := service.ReplaceClass(context.Background(), "test-class-1", &CreateClassRequest{
+		Name:      "Power Yoga",
+		StartDate: "2025-04-20",
+		EndDate:   "2025-05-15",
+		Capacity:  12,
+	})
+	assert.NoError(t, err, "Should replace class without error")
+	assert.Equal(t, "Power Yoga", class.Name, "Class name should be replaced")
+	assert.Equal(t, "2025-05-15", class.EndDate.Format("2006-01-02"), "Class end date should be extended")
+
+	// Patch a single field
+	name := "Morning Yoga"
+	class, err = service.PatchClass(context.Background(), "test-class-1", &UpdateClassRequest{Name: &name})
+	assert.NoError(t, err, "Should patch class without error")
+	assert.Equal(t, "Morning Yoga", class.Name, "Class name should be patched")
+	assert.Equal(t, 12, class.Capacity, "Capacity should be unchanged")
+
+	// Capacity can shrink down to the busiest day
+	capacity := 2
+	_, err = service.PatchClass(context.Background(), "test-class-1", &UpdateClassRequest{Capacity: &capacity})
+	assert.NoError(t, err, "Should allow capacity equal to the busiest day")
+
+	capacity = 1
+	_, err = service.PatchClass(context.Background(), "test-class-1", &UpdateClassRequest{Capacity: &capacity})
+	assert.ErrorIs(t, err, ErrCapacityBelowBookings, "Should reject capacity below the busiest day")
+
+	stored, _ := classRepo.GetByID("test-class-1")
+	assert.Equal(t, 2, stored.Capacity, "Rejected update should not change the stored class")
+
+	// Upcoming bookings must stay inside the date range
+	endDate := "2025-04-25"
+	_, err = service.PatchClass(context.Background(), "test-class-1", &UpdateClassRequest{EndDate: &endDate})
+	assert.ErrorIs(t, err, ErrBookingsOutsideRange, "Should reject a range that excludes upcoming bookings")
+
+	startDate := "2025-05-01"
+	endDate = "2025-04-30"
+	_, err = service.PatchClass(context.Background(), "test-class-1", &UpdateClassRequest{StartDate: &startDate, EndDate: &endDate})
+	assert.EqualError(t, err, "end date cannot be before start date")
+
+	// Upcoming bookings must stay on scheduled days; the bookings fall on a Friday and a Saturday
+	_, err = service.PatchClass(context.Background(), "test-class-1", &UpdateClassRequest{Schedule: &schedule.Recurrence{Weekdays: []string{"friday"}}})
+	assert.ErrorIs(t, err, ErrBookingsOutsideRange, "Should reject a schedule that excludes upcoming bookings")
+
+	class, err = service.PatchClass(context.Background(), "test-class-1", &UpdateClassRequest{Schedule: &schedule.Recurrence{Weekdays: []string{"friday", "saturday"}}})
+	assert.NoError(t, err, "Should patch schedule without error")
+	assert.Equal(t, []string{"friday", "saturday"}, class.Schedule.Weekdays, "Class schedule should be patched")
+
+	// Non-existent class
+	_, err = service.PatchClass(context.Background(), "non-existent-class", &UpdateClassRequest{Name: &name})
+	assert.Error(t, err, "Should return error for non-existent class")
+}
+
+// pausedBookingStore holds up a class update between reading the class's
+// bookings and storing the class, signalling blocked once it is held and
+// waiting for the test to close release
+type pausedBookingStore struct {
+	repository.BookingStore
+	blocked chan struct{}
+	release chan struct{}
+}
+
+func (s *pausedBookingStore) GetByClassID(classID string) ([]*repository.Booking, error) {
+	bookings, err := s.BookingStore.GetByClassID(classID)
+	close(s.blocked)
+	<-s.release
+	return bookings, err
+}
+
+// holders returns how many callers hold or wait for the lock on key
+func (k *keyedMutex) holders(key string) int {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	if m, ok := k.locks[key]; ok {
+		return m.refs
+	}
+	return 0
+}
+
+func TestClassServiceConcurrentCapacity(t *testing.T) {
+	service, classRepo, bookingRepo := setupClassWithBookings(t)
+	paused := &pausedBookingStore{BookingStore: bookingRepo, blocked: make(chan struct{}), release: make(chan struct{})}
+	service.bookingRepo = paused
+
+	bookingService := NewBookingService(bookingRepo, classRepo, repository.NewWaitlistRepository(), repository.NewMemberRepository(), repository.NewOccurrenceRepository())
+	bookingService.SetClock(func() time.Time { return time.Date(2025, 4, 22, 10, 0, 0, 0, time.UTC) })
+
+	patched := make(chan error, 1)
+	go func() {
+		capacity := 3
+		_, err := service.PatchClass(context.Background(), "test-class-1", &UpdateClassRequest{Capacity: &capacity})
+		patched <- err
+	}()
+
+	// Book while the update has checked the bookings but not yet stored the smaller capacity
+	<-paused.blocked
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, _ = bookingService.CreateBooking(&CreateBookingRequest{MemberName: fmt.Sprintf("MEMBER %d", i), Date: "2025-04-25", ClassID: "test-class-1"})
+		}(i)
+	}
+	booked := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(booked)
+	}()
+
+	// Release the update once every booking is waiting for the class, or
+	// has already been made
+	for waiting := false; !waiting; {
+		select {
+		case <-booked:
+			waiting = true
+		default:
+			waiting = classLocks.holders("test-class-1") == 5
+			runtime.Gosched()
+		}
+	}
+	close(paused.release)
+	require.NoError(t, <-patched, "Should patch capacity without error")
+	<-booked
+
+	count, err := bookingRepo.CountByClassAndDate("test-class-1", time.Date(2025, 4, 25, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err, "Should count bookings without error")
+	assert.Equal(t, 3, count, "Bookings made during the update should be held to the new capacity")
+}
+
+func TestClassServiceCapacityPromotesWaitlist(t *testing.T) {
+	service, _, bookingRepo := setupClassWithBookings(t)
+	waitlistRepo := repository.NewWaitlistRepository()
+	service.waitlistRepo = waitlistRepo
+
+	// The busiest day is full at a capacity of two
+	capacity := 2
+	_, err := service.PatchClass(context.Background(), "test-class-1", &UpdateClassRequest{Capacity: &capacity})
+	require.NoError(t, err, "Should patch capacity without error")
+
+	day := time.Date(2025, 4, 25, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, waitlistRepo.Create(&repository.WaitlistEntry{ID: "entry-1", MemberName: "USER D", ClassID: "test-class-1", Date: day}),
+		"Should create waitlist entry without error")
+	require.NoError(t, waitlistRepo.Create(&repository.WaitlistEntry{ID: "entry-2", MemberName: "USER E", ClassID: "test-class-1", Date: day, CreatedAt: time.Date(2025, 4, 22, 9, 0, 0, 0, time.UTC)}),
+		"Should create waitlist entry without error")
+
+	// Raising the capacity by one promotes the first member waiting
+	capacity = 3
+	_, err = service.PatchClass(context.Background(), "test-class-1", &UpdateClassRequest{Capacity: &capacity})
+	require.NoError(t, err, "Should patch capacity without error")
+
+	_, err = waitlistRepo.GetByID("entry-1")
+	assert.ErrorIs(t, err, repository.ErrWaitlistEntryNotFound, "First member waiting should be promoted")
+	_, err = waitlistRepo.GetByID("entry-2")
+	assert.NoError(t, err, "Second member should keep waiting")
+
+	count, err := bookingRepo.CountByClassAndDate("test-class-1", day)
+	require.NoError(t, err, "Should count bookings without error")
+	assert.Equal(t, 3, count, "Promotion should fill the new place")
+}
+
+func TestClassServiceSchedule(t *testing.T) {
+	service := NewClassService(repository.NewClassRepository(), repository.NewBookingRepository(), repository.NewWaitlistRepository(), repository.NewOccurrenceRepository(), repository.NewInstructorRepository(), repository.NewRoomRepository())
+
+	req := &CreateClassRequest{
+		Name:      "Yoga",
+		StartDate: "2025-04-01",
+		EndDate:   "2025-04-30",
+		Capacity:  10,
+		Schedule:  &schedule.Recurrence{RRule: "FREQ=WEEKLY;BYDAY=MO,WE", StartTime: "07:00", DurationMinutes: 60},
+	}
+	class, err := service.CreateClass(req)
+	require.NoError(t, err, "Should create scheduled class without error")
+	assert.True(t, class.OccursOn(time.Date(2025, 4, 7, 0, 0, 0, 0, time.UTC)), "Class should meet on a Monday")
+	assert.False(t, class.OccursOn(time.Date(2025, 4, 8, 0, 0, 0, 0, time.UTC)), "Class should not meet on a Tuesday")
+
+	// Invalid schedules are reported against the schedule field
+	req.Schedule = &schedule.Recurrence{Weekdays: []string{"funday"}}
+	_, err = service.CreateClass(req)
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr, "Should reject an invalid schedule")
+	assert.Equal(t, "schedule.weekdays", validationErr.Field, "Error field should match")
+
+	// A schedule must meet at least once within the class dates
+	req.Schedule = &schedule.Recurrence{Weekdays: []string{"monday"}, ExDates: []string{"2025-04-07"}}
+	req.StartDate, req.EndDate = "2025-04-06", "2025-04-12"
+	_, err = service.CreateClass(req)
+	require.ErrorAs(t, err, &validationErr, "Should reject a schedule without occurrences")
+	assert.Equal(t, "schedule", validationErr.Field, "Error field should match")
+}
+
+func TestClassServiceDelete(t *testing.T) {
+	service, classRepo, bookingRepo := setupClassWithBookings(t)
+	waitlistRepo := repository.NewWaitlistRepository()
+	service.waitlistRepo = waitlistRepo
+
+	waitlist := []*repository.WaitlistEntry{
+		{ID: "entry-1", MemberName: "USER E", ClassID: "test-class-1", Date: time.Date(2025, 4, 25, 0, 0, 0, 0, time.UTC)},
+		{ID: "entry-other", MemberName: "USER F", ClassID: "other-class", Date: time.Date(2025, 4, 25, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, entry := range waitlist {
+		require.NoError(t, waitlistRepo.Create(entry), "Should create waitlist entry without error")
+	}
+
+	// A past booking is kept as history when the class is deleted
+	past := &repository.Booking{ID: "booking-past", MemberName: "USER D", ClassID: "test-class-1", Date: time.Date(2025, 4, 21, 0, 0, 0, 0, time.UTC)}
+	assert.NoError(t, bookingRepo.Create(past))
+
+	err := service.DeleteClass("test-class-1", false)
+	assert.ErrorIs(t, err, ErrClassHasBookings, "Should refuse to delete a class with upcoming bookings")
+
+	_, err = classRepo.GetByID("test-class-1")
+	assert.NoError(t, err, "Class should still exist after a refused delete")
+
+	err = service.DeleteClass("test-class-1", true)
+	assert.NoError(t, err, "Should delete class and cancel its upcoming bookings when forced")
+
+	_, err = classRepo.GetByID("test-class-1")
+	assert.Error(t, err, "Class should be deleted")
+
+	remaining, err := bookingRepo.GetByClassID("test-class-1")
+	assert.NoError(t, err)
+	assert.Len(t, remaining, 4, "Bookings should be kept as history")
+	for _, booking := range remaining {
+		if booking.ID == "booking-past" {
+			assert.NotEqual(t, repository.BookingStatusCancelled, booking.Status, "Past booking should not be cancelled")
+		} else {
+			assert.Equal(t, repository.BookingStatusCancelled, booking.Status, "Upcoming booking should be cancelled")
+			assert.Equal(t, "class deleted", booking.CancellationReason, "Cancellation reason should be recorded")
+		}
+	}
+
+	_, err = waitlistRepo.GetByID("entry-1")
+	assert.ErrorIs(t, err, repository.ErrWaitlistEntryNotFound, "Class waitlist should be removed")
+	_, err = waitlistRepo.GetByID("entry-other")
+	assert.NoError(t, err, "Other classes' waitlists should be kept")
+
+	err = service.DeleteClass("test-class-1", false)
+	assert.Error(t, err, "Should return error for non-existent class")
+}
@@ -0,0 +1,30 @@
+package service
+
+import "errors"
+
+// ErrValidation is wrapped by every ValidationError
+var ErrValidation = errors.New("validation failed")
+
+// ValidationError reports a request field that failed a business rule,
+// such as a booking date in the past. Field is the JSON name of the field
+// and Rule a short identifier of the check, in the style of validator tags.
+type ValidationError struct {
+	Field   string
+	Rule    string
+	Param   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// Unwrap returns ErrValidation
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
+
+// newValidationError returns a ValidationError for the given JSON field
+func newValidationError(field, rule, message string) *ValidationError {
+	return &ValidationError{Field: field, Rule: rule, Message: message}
+}
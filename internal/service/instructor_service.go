@@ -0,0 +1,150 @@
+package service
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sanjaykishor/Glofox/internal/repository"
+)
+
+var (
+	// ErrInstructorDoubleBooked is returned when an assignment would have an instructor teach two classes at once
+	ErrInstructorDoubleBooked = repository.NewConflictError("instructor_double_booked", "instructor already teaches another class at this time")
+	// ErrInstructorAssigned is returned when deleting an instructor who still teaches classes
+	ErrInstructorAssigned = repository.NewConflictError("instructor_assigned", "instructor is assigned to classes, reassign them first")
+)
+
+// InstructorService handles business logic for instructors
+type InstructorService struct {
+	repo           repository.InstructorStore
+	classRepo      repository.ClassStore
+	occurrenceRepo repository.OccurrenceStore
+}
+
+// NewInstructorService creates a new instance of InstructorService
+func NewInstructorService(repo repository.InstructorStore, classRepo repository.ClassStore, occurrenceRepo repository.OccurrenceStore) *InstructorService {
+	return &InstructorService{
+		repo:           repo,
+		classRepo:      classRepo,
+		occurrenceRepo: occurrenceRepo,
+	}
+}
+
+// InstructorRequest represents the data needed to create or replace an instructor
+type InstructorRequest struct {
+	Name  string `json:"name" binding:"required"`
+	Email string `json:"email" binding:"omitempty,email"`
+	Phone string `json:"phone"`
+	Bio   string `json:"bio"`
+}
+
+// CreateInstructor registers a new instructor
+func (s *InstructorService) CreateInstructor(req *InstructorRequest) (*repository.Instructor, error) {
+	instructor := &repository.Instructor{
+		ID:        uuid.New().String(),
+		Name:      req.Name,
+		Email:     req.Email,
+		Phone:     req.Phone,
+		Bio:       req.Bio,
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.repo.Create(instructor); err != nil {
+		return nil, err
+	}
+
+	return instructor, nil
+}
+
+// GetAllInstructors returns all instructors
+func (s *InstructorService) GetAllInstructors() ([]*repository.Instructor, error) {
+	return s.repo.GetAll()
+}
+
+// GetInstructorByID retrieves an instructor by its ID
+func (s *InstructorService) GetInstructorByID(id string) (*repository.Instructor, error) {
+	return s.repo.GetByID(id)
+}
+
+// ReplaceInstructor replaces the details of an existing instructor
+func (s *InstructorService) ReplaceInstructor(id string, req *InstructorRequest) (*repository.Instructor, error) {
+	existing, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	instructor := &repository.Instructor{
+		ID:        id,
+		Name:      req.Name,
+		Email:     req.Email,
+		Phone:     req.Phone,
+		Bio:       req.Bio,
+		CreatedAt: existing.CreatedAt,
+	}
+
+	if err := s.repo.Update(instructor); err != nil {
+		return nil, err
+	}
+
+	return instructor, nil
+}
+
+// DeleteInstructor removes an instructor who is not assigned to any class
+// or occurrence
+func (s *InstructorService) DeleteInstructor(id string) error {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return err
+	}
+
+	classes, err := s.classRepo.GetAll()
+	if err != nil {
+		return err
+	}
+	for _, class := range classes {
+		if class.InstructorID == id {
+			return ErrInstructorAssigned
+		}
+	}
+
+	overrides, err := s.occurrenceRepo.GetAll()
+	if err != nil {
+		return err
+	}
+	for _, override := range overrides {
+		if override.InstructorID == id {
+			return ErrInstructorAssigned
+		}
+	}
+
+	return s.repo.Delete(id)
+}
+
+// validateInstructor checks that an instructor being assigned exists. An
+// empty ID leaves the class or occurrence without one.
+func validateInstructor(instructorRepo repository.InstructorStore, id string) error {
+	if id == "" {
+		return nil
+	}
+	_, err := instructorRepo.GetByID(id)
+	return err
+}
+
+// checkInstructorConflicts returns ErrInstructorDoubleBooked when one of
+// occurrences, all belonging to the class classID, has the same instructor
+// as an overlapping occurrence of another class
+func checkInstructorConflicts(
+	classRepo repository.ClassStore,
+	occurrenceRepo repository.OccurrenceStore,
+	loc *time.Location,
+	classID string,
+	occurrences []*repository.ClassOccurrence,
+) error {
+	clash, err := findOverlap(classRepo, occurrenceRepo, loc, classID, occurrences, func(o *repository.ClassOccurrence) string { return o.InstructorID })
+	if err != nil {
+		return err
+	}
+	if clash {
+		return ErrInstructorDoubleBooked
+	}
+	return nil
+}
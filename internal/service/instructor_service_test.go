@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sanjaykishor/Glofox/internal/repository"
+	"github.com/sanjaykishor/Glofox/internal/schedule"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstructorService(t *testing.T) {
+	service := NewInstructorService(repository.NewInstructorRepository(), repository.NewClassRepository(), repository.NewOccurrenceRepository())
+
+	instructor, err := service.CreateInstructor(&InstructorRequest{Name: "Alex Morgan", Email: "alex@example.com", Bio: "Yoga teacher"})
+	require.NoError(t, err, "Should create instructor without error")
+	assert.NotEmpty(t, instructor.ID, "Instructor should be given an ID")
+	assert.False(t, instructor.CreatedAt.IsZero(), "Instructor creation time should be set")
+
+	instructors, err := service.GetAllInstructors()
+	assert.NoError(t, err, "Should retrieve all instructors without error")
+	assert.Len(t, instructors, 1, "Should return 1 instructor")
+
+	updated, err := service.ReplaceInstructor(instructor.ID, &InstructorRequest{Name: "Sam Morgan"})
+	require.NoError(t, err, "Should update instructor without error")
+	assert.Equal(t, "Sam Morgan", updated.Name, "Instructor name should be updated")
+	assert.Empty(t, updated.Bio, "Replacing an instructor should clear omitted details")
+	assert.Equal(t, instructor.CreatedAt, updated.CreatedAt, "Creation time should be kept")
+
+	_, err = service.ReplaceInstructor("non-existent-instructor", &InstructorRequest{Name: "Nobody"})
+	assert.ErrorIs(t, err, repository.ErrInstructorNotFound, "Should return error for non-existent instructor")
+
+	assert.NoError(t, service.DeleteInstructor(instructor.ID), "Should delete instructor without error")
+	_, err = service.GetInstructorByID(instructor.ID)
+	assert.ErrorIs(t, err, repository.ErrInstructorNotFound, "Deleted instructor should not be found")
+}
+
+// setupInstructors creates two instructors and a class taught by the first
+// on Mondays at 18:00 for an hour in April 2025, with the clock on April 1
+func setupInstructors(t *testing.T) (*ClassService, *OccurrenceService, *InstructorService) {
+	classRepo := repository.NewClassRepository()
+	occurrenceRepo := repository.NewOccurrenceRepository()
+	instructorRepo := repository.NewInstructorRepository()
+	bookingRepo := repository.NewBookingRepository()
+
+	for _, id := range []string{"alex", "sam"} {
+		require.NoError(t, instructorRepo.Create(&repository.Instructor{ID: id, Name: id}), "Should create test instructor without error")
+	}
+
+	require.NoError(t, classRepo.Create(&repository.Class{
+		ID:           "monday-yoga",
+		Name:         "Yoga",
+		StartDate:    time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:      time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC),
+		Capacity:     10,
+		Schedule:     &schedule.Recurrence{Weekdays: []string{"monday"}, StartTime: "18:00", DurationMinutes: 60},
+		InstructorID: "alex",
+	}), "Should create test class without error")
+
+	now := func() time.Time { return time.Date(2025, 4, 1, 9, 0, 0, 0, time.UTC) }
+
+	classService := NewClassService(classRepo, bookingRepo, repository.NewWaitlistRepository(), occurrenceRepo, instructorRepo, repository.NewRoomRepository())
+	classService.SetClock(now)
+	occurrenceService := NewOccurrenceService(occurrenceRepo, classRepo, bookingRepo, repository.NewWaitlistRepository(), instructorRepo, repository.NewRoomRepository())
+	occurrenceService.SetClock(now)
+	instructorService := NewInstructorService(instructorRepo, classRepo, occurrenceRepo)
+
+	return classService, occurrenceService, instructorService
+}
+
+func TestClassInstructorAssignment(t *testing.T) {
+	classService, _, _ := setupInstructors(t)
+
+	request := func(instructorID, startTime string) *CreateClassRequest {
+		return &CreateClassRequest{
+			Name:         "Pilates",
+			StartDate:    "2025-04-01",
+			EndDate:      "2025-04-30",
+			Capacity:     10,
+			Schedule:     &schedule.Recurrence{Weekdays: []string{"monday", "thursday"}, StartTime: startTime, DurationMinutes: 45},
+			InstructorID: instructorID,
+		}
+	}
+
+	_, err := classService.CreateClass(request("nobody", "18:30"))
+	assert.ErrorIs(t, err, repository.ErrInstructorNotFound, "Should reject an unknown instructor")
+
+	_, err = classService.CreateClass(request("alex", "18:30"))
+	assert.ErrorIs(t, err, ErrInstructorDoubleBooked, "Should reject a class overlapping the instructor's Monday class")
+
+	class, err := classService.CreateClass(request("alex", "19:00"))
+	require.NoError(t, err, "Should create a class starting when the other ends without error")
+	assert.Equal(t, "alex", class.InstructorID, "Class should be assigned to the instructor")
+
+	other, err := classService.CreateClass(request("sam", "18:30"))
+	require.NoError(t, err, "Should create an overlapping class for another instructor without error")
+
+	alex := "alex"
+	_, err = classService.PatchClass(context.Background(), other.ID, &UpdateClassRequest{InstructorID: &alex})
+	assert.ErrorIs(t, err, ErrInstructorDoubleBooked, "Should reject reassigning an overlapping class")
+
+	_, err = classService.PatchClass(context.Background(), class.ID, &UpdateClassRequest{Schedule: &schedule.Recurrence{Weekdays: []string{"monday"}, StartTime: "17:30", DurationMinutes: 45}})
+	assert.ErrorIs(t, err, ErrInstructorDoubleBooked, "Should reject moving a class onto the instructor's other class")
+
+	unassigned := ""
+	patched, err := classService.PatchClass(context.Background(), class.ID, &UpdateClassRequest{InstructorID: &unassigned})
+	require.NoError(t, err, "Should unassign the instructor without error")
+	assert.Empty(t, patched.InstructorID, "Class should have no instructor")
+
+	page, err := classService.ListClasses(&ListClassesRequest{InstructorID: "sam"})
+	require.NoError(t, err, "Should list classes without error")
+	require.Len(t, page.Classes, 1, "Should list the instructor's classes")
+	assert.Equal(t, other.ID, page.Classes[0].ID, "Should return the class taught by the instructor")
+}
+
+func TestOccurrenceInstructorOverride(t *testing.T) {
+	classService, occurrenceService, instructorService := setupInstructors(t)
+
+	thursday, err := classService.CreateClass(&CreateClassRequest{
+		Name:         "Spin",
+		StartDate:    "2025-04-01",
+		EndDate:      "2025-04-30",
+		Capacity:     10,
+		Schedule:     &schedule.Recurrence{Weekdays: []string{"thursday"}, StartTime: "18:00", DurationMinutes: 60},
+		InstructorID: "sam",
+	})
+	require.NoError(t, err, "Should create class without error")
+
+	// Sam covers one Monday; Sam's own class is on Thursdays, so nothing clashes
+	sam := "sam"
+	occurrence, err := occurrenceService.UpdateOccurrence(context.Background(), "monday-yoga", "2025-04-14", &UpdateOccurrenceRequest{InstructorID: &sam})
+	require.NoError(t, err, "Should assign a substitute instructor without error")
+	assert.Equal(t, "sam", occurrence.InstructorID, "Occurrence should be taught by the substitute")
+
+	other, err := occurrenceService.GetOccurrence("monday-yoga", "2025-04-21")
+	require.NoError(t, err, "Should get occurrence without error")
+	assert.Equal(t, "alex", other.InstructorID, "Other occurrences should keep the class instructor")
+
+	alex := "alex"
+	_, err = occurrenceService.UpdateOccurrence(context.Background(), thursday.ID, "2025-04-17", &UpdateOccurrenceRequest{InstructorID: &alex})
+	require.NoError(t, err, "Should assign an instructor who is free without error")
+
+	// Alex now teaches the Thursday class on April 17, so Alex's class cannot also meet that evening
+	_, err = classService.PatchClass(context.Background(), "monday-yoga", &UpdateClassRequest{Schedule: &schedule.Recurrence{Weekdays: []string{"monday", "thursday"}, StartTime: "18:30", DurationMinutes: 60}})
+	assert.ErrorIs(t, err, ErrInstructorDoubleBooked, "Should reject a class change clashing with an occurrence override")
+
+	nobody := "nobody"
+	_, err = occurrenceService.UpdateOccurrence(context.Background(), "monday-yoga", "2025-04-21", &UpdateOccurrenceRequest{InstructorID: &nobody})
+	assert.ErrorIs(t, err, repository.ErrInstructorNotFound, "Should reject an unknown instructor")
+
+	// A cancelled occurrence frees its instructor
+	_, err = occurrenceService.CancelOccurrence(thursday.ID, "2025-04-17", &CancelOccurrenceRequest{})
+	require.NoError(t, err, "Should cancel occurrence without error")
+	_, err = classService.PatchClass(context.Background(), "monday-yoga", &UpdateClassRequest{Schedule: &schedule.Recurrence{Weekdays: []string{"monday", "thursday"}, StartTime: "18:30", DurationMinutes: 60}})
+	require.NoError(t, err, "Should update class once the clashing occurrence is cancelled without error")
+
+	assert.ErrorIs(t, instructorService.DeleteInstructor("sam"), ErrInstructorAssigned, "Should not delete an instructor who teaches classes")
+}
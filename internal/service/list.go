@@ -0,0 +1,146 @@
+package service
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sanjaykishor/Glofox/internal/repository"
+)
+
+const (
+	// DefaultPageSize is the number of items a list returns when no limit is given
+	DefaultPageSize = 20
+	// MaxPageSize is the largest limit a list accepts
+	MaxPageSize = 100
+)
+
+// ListRequest holds the pagination and sorting query parameters shared by
+// every list endpoint
+type ListRequest struct {
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Cursor string `form:"cursor"`
+	Sort   string `form:"sort"`
+}
+
+// PageInfo describes where a page sits in a listing. NextCursor is empty on
+// the last page.
+type PageInfo struct {
+	Limit      int
+	NextCursor string
+}
+
+// ListClassesRequest represents the query parameters of a class listing
+type ListClassesRequest struct {
+	ListRequest
+	Name         string `form:"name"`
+	From         string `form:"from"`
+	To           string `form:"to"`
+	InstructorID string `form:"instructor_id"`
+	RoomID       string `form:"room_id"`
+}
+
+// ClassPage is one page of a class listing
+type ClassPage struct {
+	Classes []*repository.Class
+	PageInfo
+}
+
+// ListBookingsRequest represents the query parameters of a booking
+// listing. Date is shorthand for a range of a single day.
+type ListBookingsRequest struct {
+	ListRequest
+	ClassID string `form:"class_id"`
+	Member  string `form:"member"`
+	Date    string `form:"date"`
+	From    string `form:"from"`
+	To      string `form:"to"`
+	// MemberID restricts the listing to the bookings of one registered
+	// member. It is not read from the query; handlers set it for members,
+	// who may only see their own bookings.
+	MemberID string `form:"-"`
+}
+
+// BookingPage is one page of a booking listing
+type BookingPage struct {
+	Bookings []*repository.Booking
+	PageInfo
+}
+
+// listOptions validates the pagination and sorting parameters of a list
+// request against the sortable fields, applying the default sort and limit
+func listOptions(req *ListRequest, allowed []string, defaultSort string) (repository.ListOptions, error) {
+	opts := repository.ListOptions{Limit: req.Limit}
+	if opts.Limit == 0 {
+		opts.Limit = DefaultPageSize
+	}
+	if opts.Limit < 0 || opts.Limit > MaxPageSize {
+		return opts, &ValidationError{Field: "limit", Rule: "max", Param: "100", Message: "limit must be between 1 and 100"}
+	}
+
+	sortParam := req.Sort
+	if sortParam == "" {
+		sortParam = defaultSort
+	}
+	for _, name := range strings.Split(sortParam, ",") {
+		field := repository.SortField{Name: strings.TrimSpace(name)}
+		if strings.HasPrefix(field.Name, "-") {
+			field.Name = field.Name[1:]
+			field.Desc = true
+		}
+		if !slices.Contains(allowed, field.Name) {
+			return opts, &ValidationError{
+				Field:   "sort",
+				Rule:    "oneof",
+				Param:   strings.Join(allowed, " "),
+				Message: "sort must be a comma-separated list of " + strings.Join(allowed, ", ") + ", each optionally prefixed with -",
+			}
+		}
+		opts.Sort = append(opts.Sort, field)
+	}
+
+	if req.Cursor != "" {
+		cursor, err := repository.DecodeCursor(req.Cursor)
+		if err != nil {
+			return opts, newValidationError("cursor", "cursor", "cursor is invalid")
+		}
+		// A cursor only marks a position within the order it was issued for
+		if cursor.Sort != repository.FormatSort(opts.Sort) || len(cursor.Values) != len(opts.Sort) {
+			return opts, newValidationError("cursor", "cursor", "cursor does not match the requested sort")
+		}
+		opts.After = cursor
+	}
+
+	return opts, nil
+}
+
+// parseDateRange parses the optional from and to date filters of a list request
+func parseDateRange(fromStr, toStr string) (time.Time, time.Time, error) {
+	var from, to time.Time
+	var err error
+
+	if fromStr != "" {
+		if from, err = time.Parse("2006-01-02", fromStr); err != nil {
+			return from, to, newValidationError("from", "date_format", "invalid from date format, use YYYY-MM-DD")
+		}
+	}
+	if toStr != "" {
+		if to, err = time.Parse("2006-01-02", toStr); err != nil {
+			return from, to, newValidationError("to", "date_format", "invalid to date format, use YYYY-MM-DD")
+		}
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return from, to, &ValidationError{Field: "to", Rule: "gtefield", Param: "from", Message: "to date cannot be before from date"}
+	}
+
+	return from, to, nil
+}
+
+// pageInfo returns the page description for a listing's next cursor
+func pageInfo(opts repository.ListOptions, next *repository.Cursor) PageInfo {
+	info := PageInfo{Limit: opts.Limit}
+	if next != nil {
+		info.NextCursor = repository.EncodeCursor(next)
+	}
+	return info
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sanjaykishor/Glofox/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListClasses(t *testing.T) {
+	repo := repository.NewClassRepository()
+	service := NewClassService(repo, repository.NewBookingRepository(), repository.NewWaitlistRepository(), repository.NewOccurrenceRepository(), repository.NewInstructorRepository(), repository.NewRoomRepository())
+
+	start := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 25; i++ {
+		require.NoError(t, repo.Create(&repository.Class{
+			ID:        fmt.Sprintf("class-%02d", i),
+			Name:      fmt.Sprintf("Class %02d", i),
+			StartDate: start.AddDate(0, 0, i),
+			EndDate:   start.AddDate(0, 0, i+1),
+			Capacity:  10,
+		}))
+	}
+
+	page, err := service.ListClasses(&ListClassesRequest{})
+	require.NoError(t, err, "Should list classes without error")
+	assert.Len(t, page.Classes, DefaultPageSize, "Should apply the default page size")
+	assert.Equal(t, "class-00", page.Classes[0].ID, "Should sort by start date by default")
+	require.NotEmpty(t, page.NextCursor, "Should return a cursor for the next page")
+
+	page, err = service.ListClasses(&ListClassesRequest{ListRequest: ListRequest{Cursor: page.NextCursor}})
+	require.NoError(t, err, "Should list the next page without error")
+	assert.Len(t, page.Classes, 5, "Should return the remaining classes")
+	assert.Empty(t, page.NextCursor, "Last page should have no cursor")
+
+	page, err = service.ListClasses(&ListClassesRequest{ListRequest: ListRequest{Limit: 3, Sort: "-start_date"}, From: "2025-04-10", To: "2025-04-20"})
+	require.NoError(t, err, "Should list filtered classes without error")
+	assert.Equal(t, "class-19", page.Classes[0].ID, "Should sort by start date descending")
+	assert.Equal(t, 3, page.Limit, "Should report the requested limit")
+
+	tests := []struct {
+		name  string
+		req   *ListClassesRequest
+		field string
+	}{
+		{"Unknown Sort Field", &ListClassesRequest{ListRequest: ListRequest{Sort: "start_date,colour"}}, "sort"},
+		{"Limit Too Large", &ListClassesRequest{ListRequest: ListRequest{Limit: MaxPageSize + 1}}, "limit"},
+		{"Malformed Cursor", &ListClassesRequest{ListRequest: ListRequest{Cursor: "not a cursor"}}, "cursor"},
+		{"Cursor For Another Sort", &ListClassesRequest{ListRequest: ListRequest{Cursor: page.NextCursor}}, "cursor"},
+		{"Invalid From Date", &ListClassesRequest{From: "April"}, "from"},
+		{"Reversed Range", &ListClassesRequest{From: "2025-04-20", To: "2025-04-10"}, "to"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.ListClasses(tt.req)
+
+			var validationErr *ValidationError
+			require.ErrorAs(t, err, &validationErr, "Should return a validation error")
+			assert.Equal(t, tt.field, validationErr.Field, "Should identify the invalid parameter")
+		})
+	}
+}
+
+func TestListBookings(t *testing.T) {
+	bookingRepo := repository.NewBookingRepository()
+	service := NewBookingService(bookingRepo, repository.NewClassRepository(), repository.NewWaitlistRepository(), repository.NewMemberRepository(), repository.NewOccurrenceRepository())
+
+	date := time.Date(2025, 4, 25, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, bookingRepo.Create(&repository.Booking{ID: "booking-1", MemberName: "Ann", ClassID: "class-1", Date: date}))
+	require.NoError(t, bookingRepo.Create(&repository.Booking{ID: "booking-2", MemberName: "Bob", ClassID: "class-1", Date: date.AddDate(0, 0, 1)}))
+	require.NoError(t, bookingRepo.Create(&repository.Booking{ID: "booking-3", MemberName: "Ann", ClassID: "class-2", Date: date.AddDate(0, 0, 2)}))
+
+	page, err := service.ListBookings(&ListBookingsRequest{Member: "ann"})
+	require.NoError(t, err, "Should list bookings without error")
+	assert.Len(t, page.Bookings, 2, "Should return the member's bookings")
+
+	page, err = service.ListBookings(&ListBookingsRequest{ClassID: "class-1", From: "2025-04-26"})
+	require.NoError(t, err, "Should list bookings without error")
+	require.Len(t, page.Bookings, 1, "Should filter by class and date")
+	assert.Equal(t, "booking-2", page.Bookings[0].ID, "Should return the matching booking")
+
+	_, err = service.ListBookings(&ListBookingsRequest{ListRequest: ListRequest{Sort: "capacity"}})
+	assert.ErrorIs(t, err, ErrValidation, "Should reject sorting bookings by a class field")
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sanjaykishor/Glofox/internal/repository"
+)
+
+// ErrLocationHasRooms is returned when deleting a location that still has rooms
+var ErrLocationHasRooms = repository.NewConflictError("location_has_rooms", "location has rooms, delete or move them first")
+
+// LocationService handles business logic for studio locations
+type LocationService struct {
+	repo     repository.LocationStore
+	roomRepo repository.RoomStore
+}
+
+// NewLocationService creates a new instance of LocationService
+func NewLocationService(repo repository.LocationStore, roomRepo repository.RoomStore) *LocationService {
+	return &LocationService{
+		repo:     repo,
+		roomRepo: roomRepo,
+	}
+}
+
+// LocationRequest represents the data needed to create or replace a location
+type LocationRequest struct {
+	Name    string `json:"name" binding:"required"`
+	Address string `json:"address"`
+}
+
+// CreateLocation registers a new location
+func (s *LocationService) CreateLocation(req *LocationRequest) (*repository.Location, error) {
+	location := &repository.Location{
+		ID:        uuid.New().String(),
+		Name:      req.Name,
+		Address:   req.Address,
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.repo.Create(location); err != nil {
+		return nil, err
+	}
+
+	return location, nil
+}
+
+// GetAllLocations returns all locations
+func (s *LocationService) GetAllLocations() ([]*repository.Location, error) {
+	return s.repo.GetAll()
+}
+
+// GetLocationByID retrieves a location by its ID
+func (s *LocationService) GetLocationByID(id string) (*repository.Location, error) {
+	return s.repo.GetByID(id)
+}
+
+// ReplaceLocation replaces the details of an existing location
+func (s *LocationService) ReplaceLocation(id string, req *LocationRequest) (*repository.Location, error) {
+	existing, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	location := &repository.Location{
+		ID:        id,
+		Name:      req.Name,
+		Address:   req.Address,
+		CreatedAt: existing.CreatedAt,
+	}
+
+	if err := s.repo.Update(location); err != nil {
+		return nil, err
+	}
+
+	return location, nil
+}
+
+// DeleteLocation removes a location that has no rooms
+func (s *LocationService) DeleteLocation(id string) error {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return err
+	}
+
+	rooms, err := s.roomRepo.GetByLocationID(id)
+	if err != nil {
+		return err
+	}
+	if len(rooms) > 0 {
+		return ErrLocationHasRooms
+	}
+
+	return s.repo.Delete(id)
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/sanjaykishor/Glofox/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocationService(t *testing.T) {
+	roomRepo := repository.NewRoomRepository()
+	service := NewLocationService(repository.NewLocationRepository(), roomRepo)
+
+	location, err := service.CreateLocation(&LocationRequest{Name: "City Centre", Address: "1 Main Street"})
+	require.NoError(t, err, "Should create location without error")
+	assert.NotEmpty(t, location.ID, "Location should be given an ID")
+
+	locations, err := service.GetAllLocations()
+	require.NoError(t, err, "Should retrieve all locations without error")
+	assert.Len(t, locations, 1, "Should return 1 location")
+
+	retrieved, err := service.GetLocationByID(location.ID)
+	require.NoError(t, err, "Should retrieve location by ID without error")
+	assert.Equal(t, "1 Main Street", retrieved.Address, "Retrieved address should match")
+
+	updated, err := service.ReplaceLocation(location.ID, &LocationRequest{Name: "Docklands"})
+	require.NoError(t, err, "Should update location without error")
+	assert.Equal(t, "Docklands", updated.Name, "Location name should be updated")
+	assert.Empty(t, updated.Address, "Replacing a location should clear omitted details")
+	assert.Equal(t, location.CreatedAt, updated.CreatedAt, "Creation time should be kept")
+
+	_, err = service.ReplaceLocation("non-existent-location", &LocationRequest{Name: "Nowhere"})
+	assert.ErrorIs(t, err, repository.ErrLocationNotFound, "Should return error for non-existent location")
+
+	require.NoError(t, roomRepo.Create(&repository.Room{ID: "studio-a", LocationID: location.ID, Name: "Studio A", Capacity: 20}))
+	assert.ErrorIs(t, service.DeleteLocation(location.ID), ErrLocationHasRooms, "Should not delete a location with rooms")
+
+	require.NoError(t, roomRepo.Delete("studio-a"))
+	assert.NoError(t, service.DeleteLocation(location.ID), "Should delete location without error")
+	_, err = service.GetLocationByID(location.ID)
+	assert.ErrorIs(t, err, repository.ErrLocationNotFound, "Deleted location should not be found")
+	assert.ErrorIs(t, service.DeleteLocation(location.ID), repository.ErrLocationNotFound, "Should return error when deleting twice")
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sanjaykishor/Glofox/internal/repository"
+)
+
+// MemberService handles business logic for members
+type MemberService struct {
+	repo repository.MemberStore
+}
+
+// NewMemberService creates a new instance of MemberService
+func NewMemberService(repo repository.MemberStore) *MemberService {
+	return &MemberService{
+		repo: repo,
+	}
+}
+
+// MemberRequest represents the data needed to create or replace a member
+type MemberRequest struct {
+	Name  string `json:"name" binding:"required"`
+	Email string `json:"email" binding:"omitempty,email"`
+	Phone string `json:"phone"`
+}
+
+// CreateMember registers a new member
+func (s *MemberService) CreateMember(req *MemberRequest) (*repository.Member, error) {
+	member := &repository.Member{
+		ID:        uuid.New().String(),
+		Name:      req.Name,
+		Email:     req.Email,
+		Phone:     req.Phone,
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.repo.Create(member); err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
+
+// GetAllMembers returns all members
+func (s *MemberService) GetAllMembers() ([]*repository.Member, error) {
+	return s.repo.GetAll()
+}
+
+// GetMemberByID retrieves a member by its ID
+func (s *MemberService) GetMemberByID(id string) (*repository.Member, error) {
+	return s.repo.GetByID(id)
+}
+
+// ReplaceMember replaces the details of an existing member. Existing
+// bookings keep the name they were made under.
+func (s *MemberService) ReplaceMember(id string, req *MemberRequest) (*repository.Member, error) {
+	existing, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	member := &repository.Member{
+		ID:        id,
+		Name:      req.Name,
+		Email:     req.Email,
+		Phone:     req.Phone,
+		CreatedAt: existing.CreatedAt,
+	}
+
+	if err := s.repo.Update(member); err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
+
+// DeleteMember removes a member. Their bookings are kept for the studio's records.
+func (s *MemberService) DeleteMember(id string) error {
+	return s.repo.Delete(id)
+}
+
+// resolveMember returns the member ID and name a booking or waitlist entry
+// is made under. A registered member takes precedence over a free-text
+// name, which is still accepted for bookings made without one.
+func resolveMember(memberRepo repository.MemberStore, memberID, name string) (string, string, error) {
+	if memberID == "" {
+		if name == "" {
+			return "", "", &ValidationError{Field: "name", Rule: "required_without", Param: "member_id", Message: "name or member_id is required"}
+		}
+		return "", name, nil
+	}
+
+	member, err := memberRepo.GetByID(memberID)
+	if err != nil {
+		return "", "", err
+	}
+
+	return member.ID, member.Name, nil
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sanjaykishor/Glofox/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemberService(t *testing.T) {
+	service := NewMemberService(repository.NewMemberRepository())
+
+	member, err := service.CreateMember(&MemberRequest{Name: "Jane Doe", Email: "jane@example.com"})
+	require.NoError(t, err, "Should create member without error")
+	assert.NotEmpty(t, member.ID, "Member should be given an ID")
+	assert.False(t, member.CreatedAt.IsZero(), "Member creation time should be set")
+
+	members, err := service.GetAllMembers()
+	assert.NoError(t, err, "Should retrieve all members without error")
+	assert.Len(t, members, 1, "Should return 1 member")
+
+	updated, err := service.ReplaceMember(member.ID, &MemberRequest{Name: "Jane Smith", Phone: "+353 1 234 5678"})
+	require.NoError(t, err, "Should update member without error")
+	assert.Equal(t, "Jane Smith", updated.Name, "Member name should be updated")
+	assert.Empty(t, updated.Email, "Replacing a member should clear omitted details")
+	assert.Equal(t, member.CreatedAt, updated.CreatedAt, "Creation time should be kept")
+
+	_, err = service.ReplaceMember("non-existent-member", &MemberRequest{Name: "Nobody"})
+	assert.Error(t, err, "Should return error for non-existent member")
+
+	assert.NoError(t, service.DeleteMember(member.ID), "Should delete member without error")
+	_, err = service.GetMemberByID(member.ID)
+	assert.Error(t, err, "Deleted member should not be found")
+}
+
+func TestBookingServiceWithMembers(t *testing.T) {
+	bookingRepo := repository.NewBookingRepository()
+	classRepo := repository.NewClassRepository()
+	memberRepo := repository.NewMemberRepository()
+
+	class := &repository.Class{
+		ID:        "yoga",
+		Name:      "Yoga",
+		StartDate: time.Date(2025, 4, 20, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC),
+		Capacity:  10,
+	}
+	require.NoError(t, classRepo.Create(class), "Should create test class without error")
+
+	memberService := NewMemberService(memberRepo)
+	jane, err := memberService.CreateMember(&MemberRequest{Name: "Jane Doe"})
+	require.NoError(t, err, "Should create member without error")
+	namesake, err := memberService.CreateMember(&MemberRequest{Name: "Jane Doe"})
+	require.NoError(t, err, "Should create member without error")
+
+	service := NewBookingService(bookingRepo, classRepo, repository.NewWaitlistRepository(), memberRepo, repository.NewOccurrenceRepository())
+	service.SetClock(func() time.Time { return time.Date(2025, 4, 22, 9, 0, 0, 0, time.UTC) })
+
+	// The member's registered name is used, whatever name is sent
+	booking, err := service.CreateBooking(&CreateBookingRequest{MemberID: jane.ID, MemberName: "jane doe", Date: "2025-04-25", ClassID: "yoga"})
+	require.NoError(t, err, "Should create booking for a member without error")
+	assert.Equal(t, jane.ID, booking.MemberID, "Booking should reference the member")
+	assert.Equal(t, "Jane Doe", booking.MemberName, "Booking should use the member's name")
+
+	_, err = service.CreateBooking(&CreateBookingRequest{MemberID: namesake.ID, Date: "2025-04-25", ClassID: "yoga"})
+	assert.NoError(t, err, "Members with the same name should be told apart")
+
+	_, err = service.CreateBooking(&CreateBookingRequest{MemberID: jane.ID, Date: "2025-04-25", ClassID: "yoga"})
+	assert.ErrorIs(t, err, repository.ErrDuplicateBooking, "Should reject a second booking by the same member")
+
+	_, err = service.CreateBooking(&CreateBookingRequest{MemberID: "non-existent-member", Date: "2025-04-25", ClassID: "yoga"})
+	assert.EqualError(t, err, "member not found")
+
+	// Name-only bookings keep working
+	byName, err := service.CreateBooking(&CreateBookingRequest{MemberName: "USER A", Date: "2025-04-25", ClassID: "yoga"})
+	require.NoError(t, err, "Should create a name-only booking without error")
+	assert.Empty(t, byName.MemberID, "Name-only booking should not reference a member")
+
+	_, err = service.CreateBooking(&CreateBookingRequest{Date: "2025-04-25", ClassID: "yoga"})
+	assert.EqualError(t, err, "name or member_id is required")
+}
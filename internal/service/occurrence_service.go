@@ -0,0 +1,586 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/sanjaykishor/Glofox/internal/repository"
+	"github.com/sanjaykishor/Glofox/internal/schedule"
+)
+
+var (
+	// ErrOccurrenceNotFound is returned when a class does not meet on the requested date
+	ErrOccurrenceNotFound = repository.NewNotFoundError("occurrence_not_found", "class does not meet on this date")
+
+	// ErrOccurrenceCancelled is returned when booking or changing an occurrence that has been cancelled
+	ErrOccurrenceCancelled = repository.NewConflictError("occurrence_cancelled", "class is cancelled on this date")
+)
+
+// OccurrenceService handles business logic for individual class occurrences
+type OccurrenceService struct {
+	occurrenceRepo repository.OccurrenceStore
+	classRepo      repository.ClassStore
+	bookingRepo    repository.BookingStore
+	waitlistRepo   repository.WaitlistStore
+	instructorRepo repository.InstructorStore
+	roomRepo       repository.RoomStore
+	now            func() time.Time
+	location       *time.Location
+}
+
+// NewOccurrenceService creates a new instance of OccurrenceService
+func NewOccurrenceService(
+	occurrenceRepo repository.OccurrenceStore,
+	classRepo repository.ClassStore,
+	bookingRepo repository.BookingStore,
+	waitlistRepo repository.WaitlistStore,
+	instructorRepo repository.InstructorStore,
+	roomRepo repository.RoomStore,
+) *OccurrenceService {
+	return &OccurrenceService{
+		occurrenceRepo: occurrenceRepo,
+		classRepo:      classRepo,
+		bookingRepo:    bookingRepo,
+		waitlistRepo:   waitlistRepo,
+		instructorRepo: instructorRepo,
+		roomRepo:       roomRepo,
+		now:            time.Now,
+		location:       time.UTC,
+	}
+}
+
+// SetClock overrides the clock used to decide which dates are in the past
+func (s *OccurrenceService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// SetLocation sets the studio's time zone, which decides which day is today
+func (s *OccurrenceService) SetLocation(loc *time.Location) {
+	s.location = loc
+}
+
+// localNow returns the current time in the studio's time zone
+func (s *OccurrenceService) localNow() time.Time {
+	return s.now().In(s.location)
+}
+
+// ListOccurrencesRequest represents the query parameters of an occurrence
+// listing. From and To default to the class start and end dates.
+type ListOccurrencesRequest struct {
+	ListRequest
+	From string `form:"from"`
+	To   string `form:"to"`
+}
+
+// OccurrencePage is one page of an occurrence listing
+type OccurrencePage struct {
+	Occurrences []*repository.ClassOccurrence
+	PageInfo
+}
+
+// UpdateOccurrenceRequest represents a change to a single occurrence.
+// Omitted fields keep their current value; an empty instructor ID hands the
+// occurrence back to the class instructor.
+type UpdateOccurrenceRequest struct {
+	Capacity        *int    `json:"capacity" binding:"omitempty,min=1"`
+	StartTime       *string `json:"start_time"`
+	DurationMinutes *int    `json:"duration_minutes"`
+	InstructorID    *string `json:"instructor_id"`
+}
+
+// CancelOccurrenceRequest represents the optional details of an occurrence cancellation
+type CancelOccurrenceRequest struct {
+	Reason string `json:"reason"`
+}
+
+// ListOccurrences returns a page of the occurrences of a class, ordered by
+// date unless sorted by -date
+func (s *OccurrenceService) ListOccurrences(classID string, req *ListOccurrencesRequest) (*OccurrencePage, error) {
+	opts, err := listOptions(&req.ListRequest, []string{"date"}, "date")
+	if err != nil {
+		return nil, err
+	}
+
+	from, to, err := parseDateRange(req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+
+	class, err := s.classRepo.GetByID(classID)
+	if err != nil {
+		return nil, err
+	}
+
+	if from.IsZero() || from.Before(class.StartDate) {
+		from = class.StartDate
+	}
+	if to.IsZero() || to.After(class.EndDate) {
+		to = class.EndDate
+	}
+
+	desc := opts.Sort[0].Desc
+	if opts.After != nil {
+		after, err := cursorDate(opts.After)
+		if err != nil {
+			return nil, err
+		}
+		if desc {
+			to = minDate(to, after.AddDate(0, 0, -1))
+		} else {
+			from = maxDate(from, after.AddDate(0, 0, 1))
+		}
+	}
+
+	days := class.Occurrences(from, to, s.location)
+	if desc {
+		slices.Reverse(days)
+	}
+
+	var next *repository.Cursor
+	if len(days) > opts.Limit {
+		days = days[:opts.Limit]
+		last := days[len(days)-1].Date.Format("2006-01-02")
+		next = &repository.Cursor{Sort: repository.FormatSort(opts.Sort), Values: []any{last}, ID: last}
+	}
+
+	if len(days) == 0 {
+		return &OccurrencePage{Occurrences: []*repository.ClassOccurrence{}, PageInfo: pageInfo(opts, next)}, nil
+	}
+
+	first, last := days[0].Date, days[len(days)-1].Date
+	if desc {
+		first, last = last, first
+	}
+	occurrences, err := resolveOccurrences(s.occurrenceRepo, class, first, last, s.location)
+	if err != nil {
+		return nil, err
+	}
+	if desc {
+		slices.Reverse(occurrences)
+	}
+
+	return &OccurrencePage{Occurrences: occurrences, PageInfo: pageInfo(opts, next)}, nil
+}
+
+// GetOccurrence retrieves the occurrence of a class on a date
+func (s *OccurrenceService) GetOccurrence(classID, dateStr string) (*repository.ClassOccurrence, error) {
+	date, err := parseOccurrenceDate(dateStr)
+	if err != nil {
+		return nil, err
+	}
+
+	_, occurrence, err := loadOccurrence(s.classRepo, s.occurrenceRepo, s.location, classID, date)
+	return occurrence, err
+}
+
+// UpdateOccurrence changes the capacity or time of a single upcoming
+// occurrence without touching the rest of the series. Raising the capacity
+// promotes members from the occurrence's waitlist.
+func (s *OccurrenceService) UpdateOccurrence(ctx context.Context, classID, dateStr string, req *UpdateOccurrenceRequest) (*repository.ClassOccurrence, error) {
+	occurrence, err := s.saveOverride(classID, dateStr, req)
+	if err != nil {
+		return nil, err
+	}
+
+	date := occurrence.Date
+	promoted, err := promoteWaitlist(s.waitlistRepo, s.bookingRepo, s.classRepo, s.occurrenceRepo, s.localNow, classID, date)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to promote waitlist", "class_id", classID, "date", date.Format("2006-01-02"), "error", err)
+	}
+	for entryID, booking := range promoted {
+		slog.InfoContext(ctx, "Promoted waitlist entry", "entry_id", entryID, "booking_id", booking.ID)
+	}
+
+	return occurrence, nil
+}
+
+// saveOverride applies an occurrence update and stores the override. The
+// class is locked so no booking is taken against the capacity being replaced.
+func (s *OccurrenceService) saveOverride(classID, dateStr string, req *UpdateOccurrenceRequest) (*repository.ClassOccurrence, error) {
+	defer lockClass(classID)()
+
+	date, class, existing, err := s.loadUpcoming(classID, dateStr)
+	if err != nil {
+		return nil, err
+	}
+
+	override := *existing
+	if req.Capacity != nil {
+		count, err := s.bookingRepo.CountByClassAndDate(classID, date)
+		if err != nil {
+			return nil, err
+		}
+		if *req.Capacity < count {
+			return nil, ErrCapacityBelowBookings
+		}
+		room, err := validateRoom(s.roomRepo, class.RoomID)
+		if err != nil {
+			return nil, err
+		}
+		if room != nil && *req.Capacity > room.Capacity {
+			return nil, roomCapacityError(room)
+		}
+		override.Capacity = *req.Capacity
+	}
+	if req.StartTime != nil {
+		override.StartTime = *req.StartTime
+	}
+	if req.DurationMinutes != nil {
+		// Zero in an override means the class duration, so it cannot be set explicitly
+		if *req.DurationMinutes <= 0 {
+			return nil, newValidationError("duration_minutes", "range", "duration_minutes must be between 1 and 1440")
+		}
+		override.DurationMinutes = *req.DurationMinutes
+	}
+	if req.InstructorID != nil {
+		if err := validateInstructor(s.instructorRepo, *req.InstructorID); err != nil {
+			return nil, err
+		}
+		override.InstructorID = *req.InstructorID
+	}
+
+	// The time of day is checked as a whole, with any part not overridden taken from the class
+	startTime, duration := occurrenceTime(class, &override)
+	timing := &schedule.Recurrence{StartTime: startTime, DurationMinutes: duration}
+	if err := timing.Validate(); err != nil {
+		var scheduleErr *schedule.Error
+		if errors.As(err, &scheduleErr) {
+			return nil, &ValidationError{Field: scheduleErr.Field, Rule: scheduleErr.Rule, Message: scheduleErr.Message}
+		}
+		return nil, err
+	}
+
+	// A new instructor or time must not clash with the instructor's other
+	// classes or with other classes in the same room, so the check holds
+	// the assignment lock until the override is stored
+	occurrence := resolveOccurrence(class, date, &override, s.location)
+	defer lockAssignments()()
+	if err := checkInstructorConflicts(s.classRepo, s.occurrenceRepo, s.location, classID, []*repository.ClassOccurrence{occurrence}); err != nil {
+		return nil, err
+	}
+	if err := checkRoomConflicts(s.classRepo, s.occurrenceRepo, s.location, classID, []*repository.ClassOccurrence{occurrence}); err != nil {
+		return nil, err
+	}
+
+	override.UpdatedAt = s.now()
+	if err := s.occurrenceRepo.Save(&override); err != nil {
+		return nil, err
+	}
+
+	return occurrence, nil
+}
+
+// CancelOccurrence calls off a single upcoming occurrence. Its confirmed
+// bookings are cancelled with the same reason and its waitlist is cleared;
+// the rest of the series is unaffected.
+func (s *OccurrenceService) CancelOccurrence(classID, dateStr string, req *CancelOccurrenceRequest) (*repository.ClassOccurrence, error) {
+	// Bookings check the occurrence under the class lock, so holding it
+	// throughout keeps any booking from landing after the cancellation
+	defer lockClass(classID)()
+
+	date, class, existing, err := s.loadUpcoming(classID, dateStr)
+	if err != nil {
+		return nil, err
+	}
+
+	reason := req.Reason
+	if reason == "" {
+		reason = "class cancelled"
+	}
+
+	cancelledAt := s.now()
+	override := *existing
+	override.Status = repository.OccurrenceStatusCancelled
+	override.CancelledAt = &cancelledAt
+	override.CancellationReason = reason
+	override.UpdatedAt = cancelledAt
+
+	if err := s.occurrenceRepo.Save(&override); err != nil {
+		return nil, err
+	}
+
+	bookings, err := s.bookingRepo.GetByClassID(classID)
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range bookings {
+		if !existing.Active() || !startOfDay(existing.Date).Equal(date) {
+			continue
+		}
+
+		booking := *existing
+		booking.Status = repository.BookingStatusCancelled
+		booking.CancelledAt = &cancelledAt
+		booking.CancellationReason = reason
+		if err := s.bookingRepo.Update(&booking); err != nil {
+			return nil, err
+		}
+	}
+
+	waitlist, err := s.waitlistRepo.GetByOccurrence(classID, date)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range waitlist {
+		if err := s.waitlistRepo.Delete(entry.ID); err != nil && !errors.Is(err, repository.ErrWaitlistEntryNotFound) {
+			return nil, err
+		}
+	}
+
+	return resolveOccurrence(class, date, &override, s.location), nil
+}
+
+// loadUpcoming loads an occurrence that can still be changed: it is not in
+// the past and has not been cancelled. It returns the occurrence's current
+// override, or a new empty one.
+func (s *OccurrenceService) loadUpcoming(classID, dateStr string) (time.Time, *repository.Class, *repository.OccurrenceOverride, error) {
+	date, err := parseOccurrenceDate(dateStr)
+	if err != nil {
+		return time.Time{}, nil, nil, err
+	}
+
+	if date.Before(startOfDay(s.localNow())) {
+		return time.Time{}, nil, nil, newValidationError("date", "not_past", "past occurrences cannot be changed")
+	}
+
+	class, occurrence, err := loadOccurrence(s.classRepo, s.occurrenceRepo, s.location, classID, date)
+	if err != nil {
+		return time.Time{}, nil, nil, err
+	}
+	if occurrence.Cancelled() {
+		return time.Time{}, nil, nil, ErrOccurrenceCancelled
+	}
+
+	override, err := s.occurrenceRepo.Get(classID, date)
+	if errors.Is(err, repository.ErrOccurrenceOverrideNotFound) {
+		return date, class, &repository.OccurrenceOverride{ClassID: classID, Date: date}, nil
+	}
+	if err != nil {
+		return time.Time{}, nil, nil, err
+	}
+
+	return date, class, override, nil
+}
+
+// loadOccurrence retrieves a class and its occurrence on date, which must
+// be a day the class meets
+func loadOccurrence(
+	classRepo repository.ClassStore,
+	occurrenceRepo repository.OccurrenceStore,
+	loc *time.Location,
+	classID string,
+	date time.Time,
+) (*repository.Class, *repository.ClassOccurrence, error) {
+	class, err := classRepo.GetByID(classID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !class.OccursOn(date) {
+		return nil, nil, ErrOccurrenceNotFound
+	}
+
+	override, err := occurrenceRepo.Get(classID, date)
+	if errors.Is(err, repository.ErrOccurrenceOverrideNotFound) {
+		override = nil
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	return class, resolveOccurrence(class, startOfDay(date), override, loc), nil
+}
+
+// resolveOccurrences builds the occurrences of a class between from and to,
+// inclusive, applying the overrides stored for them
+func resolveOccurrences(
+	occurrenceRepo repository.OccurrenceStore,
+	class *repository.Class,
+	from, to time.Time,
+	loc *time.Location,
+) ([]*repository.ClassOccurrence, error) {
+	days := class.Occurrences(from, to, loc)
+	occurrences := make([]*repository.ClassOccurrence, 0, len(days))
+	if len(days) == 0 {
+		return occurrences, nil
+	}
+
+	overrides, err := occurrenceRepo.ListByClass(class.ID, days[0].Date, days[len(days)-1].Date)
+	if err != nil {
+		return nil, err
+	}
+
+	byDate := make(map[time.Time]*repository.OccurrenceOverride, len(overrides))
+	for _, override := range overrides {
+		byDate[startOfDay(override.Date)] = override
+	}
+	for _, day := range days {
+		occurrences = append(occurrences, resolveOccurrence(class, day.Date, byDate[day.Date], loc))
+	}
+
+	return occurrences, nil
+}
+
+// resolveOccurrence builds the occurrence of a class on day, applying the
+// override for that day if there is one, with its times in loc
+func resolveOccurrence(class *repository.Class, day time.Time, override *repository.OccurrenceOverride, loc *time.Location) *repository.ClassOccurrence {
+	occurrence := &repository.ClassOccurrence{
+		ClassID:      class.ID,
+		Date:         day,
+		Capacity:     class.Capacity,
+		InstructorID: class.InstructorID,
+		RoomID:       class.RoomID,
+		Status:       repository.OccurrenceStatusScheduled,
+	}
+
+	if override != nil {
+		occurrence.Modified = true
+		if override.Capacity > 0 {
+			occurrence.Capacity = override.Capacity
+		}
+		if override.InstructorID != "" {
+			occurrence.InstructorID = override.InstructorID
+		}
+		if override.Status == repository.OccurrenceStatusCancelled {
+			occurrence.Status = repository.OccurrenceStatusCancelled
+			occurrence.CancelledAt = override.CancelledAt
+			occurrence.CancellationReason = override.CancellationReason
+		}
+	}
+
+	startTime, duration := occurrenceTime(class, override)
+	times := (&schedule.Recurrence{StartTime: startTime, DurationMinutes: duration}).On(day, loc)
+	occurrence.Start, occurrence.End = times.Start, times.End
+
+	return occurrence
+}
+
+// occurrenceTime returns the start time and duration of an occurrence,
+// taking each from the override when it sets one and from the class otherwise
+func occurrenceTime(class *repository.Class, override *repository.OccurrenceOverride) (string, int) {
+	var startTime string
+	var duration int
+	if class.Schedule != nil {
+		startTime, duration = class.Schedule.StartTime, class.Schedule.DurationMinutes
+	}
+
+	if override != nil {
+		if override.StartTime != "" {
+			startTime = override.StartTime
+		}
+		if override.DurationMinutes > 0 {
+			duration = override.DurationMinutes
+		}
+	}
+
+	return startTime, duration
+}
+
+// parseOccurrenceDate parses the date identifying an occurrence
+func parseOccurrenceDate(dateStr string) (time.Time, error) {
+	date, err := time.Parse("2006-01-02", dateStr)
+	if err != nil {
+		return time.Time{}, newValidationError("date", "date_format", "invalid date format, use YYYY-MM-DD")
+	}
+	return date, nil
+}
+
+// cursorDate returns the date of the last occurrence on the previous page
+func cursorDate(cursor *repository.Cursor) (time.Time, error) {
+	value, _ := cursor.Values[0].(string)
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, newValidationError("cursor", "cursor", "cursor is invalid")
+	}
+	return date, nil
+}
+
+func minDate(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
+
+func maxDate(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// findOverlap reports whether one of occurrences, all belonging to the class
+// classID, shares a resource, such as an instructor or a room, with an
+// overlapping occurrence of another class. Occurrences without the resource
+// and cancelled occurrences never overlap.
+func findOverlap(
+	classRepo repository.ClassStore,
+	occurrenceRepo repository.OccurrenceStore,
+	loc *time.Location,
+	classID string,
+	occurrences []*repository.ClassOccurrence,
+	resource func(*repository.ClassOccurrence) string,
+) (bool, error) {
+	byDay := make(map[time.Time][]*repository.ClassOccurrence)
+	var from, to time.Time
+	for _, occurrence := range occurrences {
+		if resource(occurrence) == "" || occurrence.Cancelled() {
+			continue
+		}
+		byDay[occurrence.Date] = append(byDay[occurrence.Date], occurrence)
+		if from.IsZero() || occurrence.Date.Before(from) {
+			from = occurrence.Date
+		}
+		if to.IsZero() || occurrence.Date.After(to) {
+			to = occurrence.Date
+		}
+	}
+	if len(byDay) == 0 {
+		return false, nil
+	}
+
+	// A late class can run past midnight into an early class the next day
+	from, to = from.AddDate(0, 0, -1), to.AddDate(0, 0, 1)
+
+	classes, err := classRepo.GetAll()
+	if err != nil {
+		return false, err
+	}
+
+	for _, other := range classes {
+		if other.ID == classID || other.EndDate.Before(from) || other.StartDate.After(to) {
+			continue
+		}
+
+		otherOccurrences, err := resolveOccurrences(occurrenceRepo, other, from, to, loc)
+		if err != nil {
+			return false, err
+		}
+
+		for _, existing := range otherOccurrences {
+			if resource(existing) == "" || existing.Cancelled() {
+				continue
+			}
+			for _, day := range []time.Time{existing.Date.AddDate(0, 0, -1), existing.Date, existing.Date.AddDate(0, 0, 1)} {
+				for _, occurrence := range byDay[day] {
+					if resource(occurrence) == resource(existing) && occurrencesOverlap(occurrence, existing) {
+						return true, nil
+					}
+				}
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// occurrencesOverlap reports whether two occurrences take place at the same
+// time. An occurrence without a time of day takes up its whole day.
+func occurrencesOverlap(a, b *repository.ClassOccurrence) bool {
+	if a.Start == nil || b.Start == nil {
+		return a.Date.Equal(b.Date)
+	}
+	return a.Start.Before(*b.End) && b.Start.Before(*a.End)
+}
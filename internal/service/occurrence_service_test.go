@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sanjaykishor/Glofox/internal/logging"
+	"github.com/sanjaykishor/Glofox/internal/repository"
+	"github.com/sanjaykishor/Glofox/internal/schedule"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupOccurrences creates a class meeting on Mondays and Wednesdays at
+// 18:00 in April 2025, with the clock on Tuesday April 8
+func setupOccurrences(t *testing.T) (*OccurrenceService, *BookingService, *WaitlistService, repository.BookingStore) {
+	classRepo := repository.NewClassRepository()
+	bookingRepo := repository.NewBookingRepository()
+	waitlistRepo := repository.NewWaitlistRepository()
+	memberRepo := repository.NewMemberRepository()
+	occurrenceRepo := repository.NewOccurrenceRepository()
+
+	class := &repository.Class{
+		ID:        "test-class-1",
+		Name:      "Yoga",
+		StartDate: time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC),
+		Capacity:  2,
+		Schedule:  &schedule.Recurrence{Weekdays: []string{"monday", "wednesday"}, StartTime: "18:00", DurationMinutes: 60},
+	}
+	require.NoError(t, classRepo.Create(class), "Should create test class without error")
+
+	now := func() time.Time { return time.Date(2025, 4, 8, 9, 0, 0, 0, time.UTC) }
+
+	occurrenceService := NewOccurrenceService(occurrenceRepo, classRepo, bookingRepo, waitlistRepo, repository.NewInstructorRepository(), repository.NewRoomRepository())
+	occurrenceService.SetClock(now)
+	bookingService := NewBookingService(bookingRepo, classRepo, waitlistRepo, memberRepo, occurrenceRepo)
+	bookingService.SetClock(now)
+	waitlistService := NewWaitlistService(waitlistRepo, bookingRepo, classRepo, memberRepo, occurrenceRepo)
+	waitlistService.SetClock(now)
+
+	return occurrenceService, bookingService, waitlistService, bookingRepo
+}
+
+func occurrenceDates(occurrences []*repository.ClassOccurrence) []string {
+	dates := make([]string, len(occurrences))
+	for i, occurrence := range occurrences {
+		dates[i] = occurrence.Date.Format("2006-01-02")
+	}
+	return dates
+}
+
+func TestListOccurrences(t *testing.T) {
+	service, _, _, _ := setupOccurrences(t)
+
+	page, err := service.ListOccurrences("test-class-1", &ListOccurrencesRequest{})
+	require.NoError(t, err, "Should list occurrences without error")
+	assert.Equal(t, []string{"2025-04-02", "2025-04-07", "2025-04-09", "2025-04-14", "2025-04-16", "2025-04-21", "2025-04-23", "2025-04-28", "2025-04-30"},
+		occurrenceDates(page.Occurrences), "Should list every scheduled day")
+	assert.Empty(t, page.NextCursor, "Single page should have no next cursor")
+
+	first := page.Occurrences[0]
+	assert.Equal(t, 2, first.Capacity, "Occurrence should inherit the class capacity")
+	assert.Equal(t, repository.OccurrenceStatusScheduled, first.Status, "Occurrence should be scheduled")
+	require.NotNil(t, first.Start, "Occurrence should have a start time")
+	assert.Equal(t, time.Date(2025, 4, 2, 18, 0, 0, 0, time.UTC), *first.Start, "Start time should match")
+	assert.Equal(t, time.Date(2025, 4, 2, 19, 0, 0, 0, time.UTC), *first.End, "End time should match")
+
+	// Paginate within a date range
+	req := &ListOccurrencesRequest{ListRequest: ListRequest{Limit: 2}, From: "2025-04-08", To: "2025-04-25"}
+	page, err = service.ListOccurrences("test-class-1", req)
+	require.NoError(t, err, "Should list occurrences without error")
+	assert.Equal(t, []string{"2025-04-09", "2025-04-14"}, occurrenceDates(page.Occurrences), "First page should match")
+	require.NotEmpty(t, page.NextCursor, "First page should have a next cursor")
+
+	req.Cursor = page.NextCursor
+	page, err = service.ListOccurrences("test-class-1", req)
+	require.NoError(t, err, "Should list occurrences without error")
+	assert.Equal(t, []string{"2025-04-16", "2025-04-21"}, occurrenceDates(page.Occurrences), "Second page should match")
+
+	req.Cursor = page.NextCursor
+	page, err = service.ListOccurrences("test-class-1", req)
+	require.NoError(t, err, "Should list occurrences without error")
+	assert.Equal(t, []string{"2025-04-23"}, occurrenceDates(page.Occurrences), "Last page should match")
+	assert.Empty(t, page.NextCursor, "Last page should have no next cursor")
+
+	// Newest first
+	page, err = service.ListOccurrences("test-class-1", &ListOccurrencesRequest{ListRequest: ListRequest{Limit: 2, Sort: "-date"}})
+	require.NoError(t, err, "Should list occurrences without error")
+	assert.Equal(t, []string{"2025-04-30", "2025-04-28"}, occurrenceDates(page.Occurrences), "Should list newest first")
+
+	page, err = service.ListOccurrences("test-class-1", &ListOccurrencesRequest{ListRequest: ListRequest{Limit: 2, Sort: "-date", Cursor: page.NextCursor}})
+	require.NoError(t, err, "Should list occurrences without error")
+	assert.Equal(t, []string{"2025-04-23", "2025-04-21"}, occurrenceDates(page.Occurrences), "Second page should continue newest first")
+
+	_, err = service.ListOccurrences("non-existent-class", &ListOccurrencesRequest{})
+	assert.ErrorIs(t, err, repository.ErrClassNotFound, "Should return error for non-existent class")
+}
+
+func TestGetOccurrence(t *testing.T) {
+	service, _, _, _ := setupOccurrences(t)
+
+	occurrence, err := service.GetOccurrence("test-class-1", "2025-04-14")
+	require.NoError(t, err, "Should get occurrence without error")
+	assert.False(t, occurrence.Modified, "Occurrence should not be modified")
+
+	_, err = service.GetOccurrence("test-class-1", "2025-04-15")
+	assert.ErrorIs(t, err, ErrOccurrenceNotFound, "Should report a day the class does not meet")
+
+	_, err = service.GetOccurrence("test-class-1", "15/04/2025")
+	assert.ErrorIs(t, err, ErrValidation, "Should reject an invalid date")
+}
+
+func TestOccurrenceTimeZone(t *testing.T) {
+	service, _, _, _ := setupOccurrences(t)
+
+	loc, err := time.LoadLocation("Europe/London")
+	require.NoError(t, err, "Should load time zone without error")
+	service.SetLocation(loc)
+
+	// London moved to summer time on March 30, so 18:00 there is 17:00 UTC
+	occurrence, err := service.GetOccurrence("test-class-1", "2025-04-14")
+	require.NoError(t, err, "Should get occurrence without error")
+	require.NotNil(t, occurrence.Start, "Occurrence should have a start time")
+	assert.Equal(t, time.Date(2025, 4, 14, 17, 0, 0, 0, time.UTC), occurrence.Start.UTC(), "Start should be the studio's wall-clock time")
+	assert.Equal(t, "2025-04-14T18:00:00+01:00", occurrence.Start.Format(time.RFC3339), "Start should be shown in the studio's time zone")
+	assert.Equal(t, time.Date(2025, 4, 14, 0, 0, 0, 0, time.UTC), occurrence.Date, "Date should stay the calendar day")
+}
+
+func TestUpdateOccurrence(t *testing.T) {
+	service, bookingService, waitlistService, _ := setupOccurrences(t)
+
+	for _, name := range []string{"USER A", "USER B"} {
+		_, err := bookingService.CreateBooking(&CreateBookingRequest{MemberName: name, Date: "2025-04-14", ClassID: "test-class-1"})
+		require.NoError(t, err, "Should create booking without error")
+	}
+	position, err := waitlistService.JoinWaitlist(&JoinWaitlistRequest{MemberName: "USER C", Date: "2025-04-14", ClassID: "test-class-1"})
+	require.NoError(t, err, "Should join waitlist without error")
+
+	// Capacity cannot drop below the bookings already made
+	capacity := 1
+	_, err = service.UpdateOccurrence(context.Background(), "test-class-1", "2025-04-14", &UpdateOccurrenceRequest{Capacity: &capacity})
+	assert.ErrorIs(t, err, ErrCapacityBelowBookings, "Should reject capacity below existing bookings")
+
+	// Raising the capacity of one occurrence promotes its waitlist
+	capacity = 3
+	startTime := "07:30"
+	logs := captureLogs(t)
+	occurrence, err := service.UpdateOccurrence(logging.WithRequestID(context.Background(), "req-update"), "test-class-1", "2025-04-14", &UpdateOccurrenceRequest{Capacity: &capacity, StartTime: &startTime})
+	require.NoError(t, err, "Should update occurrence without error")
+	assert.True(t, occurrence.Modified, "Occurrence should be modified")
+	assert.Equal(t, 3, occurrence.Capacity, "Occurrence capacity should be updated")
+	assert.Equal(t, time.Date(2025, 4, 14, 7, 30, 0, 0, time.UTC), *occurrence.Start, "Occurrence start time should be updated")
+	assert.Equal(t, time.Date(2025, 4, 14, 8, 30, 0, 0, time.UTC), *occurrence.End, "Occurrence should keep the class duration")
+
+	_, err = waitlistService.GetPosition(position.ID)
+	assert.ErrorIs(t, err, repository.ErrWaitlistEntryNotFound, "Waitlisted member should be promoted")
+	assert.Equal(t, "req-update", findLogLine(t, logs, "Promoted waitlist entry")["request_id"], "Should log the promotion with the request ID")
+
+	// The rest of the series is untouched
+	other, err := service.GetOccurrence("test-class-1", "2025-04-16")
+	require.NoError(t, err, "Should get occurrence without error")
+	assert.Equal(t, 2, other.Capacity, "Other occurrences should keep the class capacity")
+	assert.Equal(t, time.Date(2025, 4, 16, 18, 0, 0, 0, time.UTC), *other.Start, "Other occurrences should keep the class time")
+
+	// Invalid times and past occurrences are rejected
+	duration := 0
+	_, err = service.UpdateOccurrence(context.Background(), "test-class-1", "2025-04-16", &UpdateOccurrenceRequest{DurationMinutes: &duration, StartTime: &startTime})
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr, "Should reject an invalid duration")
+	assert.Equal(t, "duration_minutes", validationErr.Field, "Error field should match")
+
+	_, err = service.UpdateOccurrence(context.Background(), "test-class-1", "2025-04-07", &UpdateOccurrenceRequest{Capacity: &capacity})
+	require.ErrorAs(t, err, &validationErr, "Should reject changing a past occurrence")
+	assert.Equal(t, "not_past", validationErr.Rule, "Error rule should match")
+}
+
+func TestCancelOccurrence(t *testing.T) {
+	service, bookingService, waitlistService, bookingRepo := setupOccurrences(t)
+
+	for _, name := range []string{"USER A", "USER B"} {
+		_, err := bookingService.CreateBooking(&CreateBookingRequest{MemberName: name, Date: "2025-04-14", ClassID: "test-class-1"})
+		require.NoError(t, err, "Should create booking without error")
+	}
+	position, err := waitlistService.JoinWaitlist(&JoinWaitlistRequest{MemberName: "USER C", Date: "2025-04-14", ClassID: "test-class-1"})
+	require.NoError(t, err, "Should join waitlist without error")
+	other, err := bookingService.CreateBooking(&CreateBookingRequest{MemberName: "USER A", Date: "2025-04-16", ClassID: "test-class-1"})
+	require.NoError(t, err, "Should create booking without error")
+
+	occurrence, err := service.CancelOccurrence("test-class-1", "2025-04-14", &CancelOccurrenceRequest{Reason: "instructor unwell"})
+	require.NoError(t, err, "Should cancel occurrence without error")
+	assert.True(t, occurrence.Cancelled(), "Occurrence should be cancelled")
+	assert.Equal(t, "instructor unwell", occurrence.CancellationReason, "Cancellation reason should be recorded")
+
+	// Bookings for the occurrence are cancelled with the same reason and its waitlist is cleared
+	count, err := bookingRepo.CountByClassAndDate("test-class-1", time.Date(2025, 4, 14, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err, "Should count bookings without error")
+	assert.Zero(t, count, "Bookings for the occurrence should be cancelled")
+
+	bookings, err := bookingRepo.GetBookingsByDate(time.Date(2025, 4, 14, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err, "Should get bookings without error")
+	for _, booking := range bookings {
+		assert.Equal(t, "instructor unwell", booking.CancellationReason, "Booking should carry the cancellation reason")
+	}
+
+	_, err = waitlistService.GetPosition(position.ID)
+	assert.ErrorIs(t, err, repository.ErrWaitlistEntryNotFound, "Waitlist should be cleared")
+
+	// Other occurrences are unaffected
+	booking, err := bookingRepo.GetByID(other.ID)
+	require.NoError(t, err, "Should get booking without error")
+	assert.Equal(t, repository.BookingStatusConfirmed, booking.Status, "Bookings for other occurrences should stay confirmed")
+
+	// A cancelled occurrence can no longer be booked or changed
+	_, err = bookingService.CreateBooking(&CreateBookingRequest{MemberName: "USER D", Date: "2025-04-14", ClassID: "test-class-1"})
+	assert.ErrorIs(t, err, ErrOccurrenceCancelled, "Should reject booking a cancelled occurrence")
+
+	_, err = service.CancelOccurrence("test-class-1", "2025-04-14", &CancelOccurrenceRequest{})
+	assert.ErrorIs(t, err, ErrOccurrenceCancelled, "Should reject cancelling twice")
+
+	_, err = service.CancelOccurrence("test-class-1", "2025-04-15", &CancelOccurrenceRequest{})
+	assert.ErrorIs(t, err, ErrOccurrenceNotFound, "Should reject cancelling a day the class does not meet")
+
+	_, err = bookingService.RescheduleBooking(context.Background(), other.ID, &RescheduleBookingRequest{Date: "2025-04-14"})
+	assert.ErrorIs(t, err, ErrOccurrenceCancelled, "Should reject rescheduling into a cancelled occurrence")
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sanjaykishor/Glofox/internal/repository"
+)
+
+var (
+	// ErrRoomDoubleBooked is returned when a class would be held in a room that another class is using at the same time
+	ErrRoomDoubleBooked = repository.NewConflictError("room_double_booked", "room is already in use by another class at this time")
+	// ErrRoomInUse is returned when deleting a room that classes are still held in
+	ErrRoomInUse = repository.NewConflictError("room_in_use", "room is assigned to classes, move them first")
+	// ErrRoomCapacityBelowClasses is returned when a room would become too small for the classes held in it
+	ErrRoomCapacityBelowClasses = repository.NewConflictError("room_capacity_below_classes", "room capacity cannot be lower than the capacity of the classes held in it")
+)
+
+// RoomService handles business logic for rooms
+type RoomService struct {
+	repo           repository.RoomStore
+	locationRepo   repository.LocationStore
+	classRepo      repository.ClassStore
+	occurrenceRepo repository.OccurrenceStore
+}
+
+// NewRoomService creates a new instance of RoomService
+func NewRoomService(
+	repo repository.RoomStore,
+	locationRepo repository.LocationStore,
+	classRepo repository.ClassStore,
+	occurrenceRepo repository.OccurrenceStore,
+) *RoomService {
+	return &RoomService{
+		repo:           repo,
+		locationRepo:   locationRepo,
+		classRepo:      classRepo,
+		occurrenceRepo: occurrenceRepo,
+	}
+}
+
+// RoomRequest represents the data needed to create or replace a room
+type RoomRequest struct {
+	LocationID string `json:"location_id" binding:"required"`
+	Name       string `json:"name" binding:"required"`
+	Capacity   int    `json:"capacity" binding:"required,min=1"`
+}
+
+// CreateRoom adds a room to an existing location
+func (s *RoomService) CreateRoom(req *RoomRequest) (*repository.Room, error) {
+	if _, err := s.locationRepo.GetByID(req.LocationID); err != nil {
+		return nil, err
+	}
+
+	room := &repository.Room{
+		ID:         uuid.New().String(),
+		LocationID: req.LocationID,
+		Name:       req.Name,
+		Capacity:   req.Capacity,
+		CreatedAt:  time.Now(),
+	}
+
+	if err := s.repo.Create(room); err != nil {
+		return nil, err
+	}
+
+	return room, nil
+}
+
+// GetAllRooms returns all rooms, or only those at a location when
+// locationID is set
+func (s *RoomService) GetAllRooms(locationID string) ([]*repository.Room, error) {
+	if locationID == "" {
+		return s.repo.GetAll()
+	}
+
+	if _, err := s.locationRepo.GetByID(locationID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByLocationID(locationID)
+}
+
+// GetRoomByID retrieves a room by its ID
+func (s *RoomService) GetRoomByID(id string) (*repository.Room, error) {
+	return s.repo.GetByID(id)
+}
+
+// ReplaceRoom replaces the details of an existing room. The room cannot be
+// made smaller than any class or occurrence held in it.
+func (s *RoomService) ReplaceRoom(id string, req *RoomRequest) (*repository.Room, error) {
+	existing, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.locationRepo.GetByID(req.LocationID); err != nil {
+		return nil, err
+	}
+
+	room := &repository.Room{
+		ID:         id,
+		LocationID: req.LocationID,
+		Name:       req.Name,
+		Capacity:   req.Capacity,
+		CreatedAt:  existing.CreatedAt,
+	}
+
+	if room.Capacity < existing.Capacity {
+		if err := s.checkCapacity(room); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.Update(room); err != nil {
+		return nil, err
+	}
+
+	return room, nil
+}
+
+// DeleteRoom removes a room that no class is held in
+func (s *RoomService) DeleteRoom(id string) error {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return err
+	}
+
+	classes, err := s.classRepo.GetAll()
+	if err != nil {
+		return err
+	}
+	for _, class := range classes {
+		if class.RoomID == id {
+			return ErrRoomInUse
+		}
+	}
+
+	return s.repo.Delete(id)
+}
+
+// checkCapacity returns ErrRoomCapacityBelowClasses when a class held in
+// the room, or one of its occurrences, has more places than the room
+func (s *RoomService) checkCapacity(room *repository.Room) error {
+	classes, err := s.classRepo.GetAll()
+	if err != nil {
+		return err
+	}
+
+	for _, class := range classes {
+		if class.RoomID != room.ID {
+			continue
+		}
+		if class.Capacity > room.Capacity {
+			return ErrRoomCapacityBelowClasses
+		}
+
+		overrides, err := s.occurrenceRepo.ListByClass(class.ID, class.StartDate, class.EndDate)
+		if err != nil {
+			return err
+		}
+		for _, override := range overrides {
+			if override.Capacity > room.Capacity {
+				return ErrRoomCapacityBelowClasses
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateRoom returns the room being assigned, or nil for an empty ID,
+// which leaves the class without a room
+func validateRoom(roomRepo repository.RoomStore, id string) (*repository.Room, error) {
+	if id == "" {
+		return nil, nil
+	}
+	return roomRepo.GetByID(id)
+}
+
+// roomCapacityError reports a capacity that does not fit in room
+func roomCapacityError(room *repository.Room) *ValidationError {
+	return &ValidationError{
+		Field:   "capacity",
+		Rule:    "max",
+		Param:   strconv.Itoa(room.Capacity),
+		Message: fmt.Sprintf("capacity cannot be more than the room capacity of %d", room.Capacity),
+	}
+}
+
+// checkRoomConflicts returns ErrRoomDoubleBooked when one of occurrences,
+// all belonging to the class classID, is held in the same room as an
+// overlapping occurrence of another class
+func checkRoomConflicts(
+	classRepo repository.ClassStore,
+	occurrenceRepo repository.OccurrenceStore,
+	loc *time.Location,
+	classID string,
+	occurrences []*repository.ClassOccurrence,
+) error {
+	clash, err := findOverlap(classRepo, occurrenceRepo, loc, classID, occurrences, func(o *repository.ClassOccurrence) string { return o.RoomID })
+	if err != nil {
+		return err
+	}
+	if clash {
+		return ErrRoomDoubleBooked
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sanjaykishor/Glofox/internal/repository"
+	"github.com/sanjaykishor/Glofox/internal/schedule"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoomService(t *testing.T) {
+	locationRepo := repository.NewLocationRepository()
+	classRepo := repository.NewClassRepository()
+	occurrenceRepo := repository.NewOccurrenceRepository()
+	service := NewRoomService(repository.NewRoomRepository(), locationRepo, classRepo, occurrenceRepo)
+
+	for _, id := range []string{"city", "docklands"} {
+		require.NoError(t, locationRepo.Create(&repository.Location{ID: id, Name: id}), "Should create test location without error")
+	}
+
+	_, err := service.CreateRoom(&RoomRequest{LocationID: "nowhere", Name: "Studio A", Capacity: 20})
+	assert.ErrorIs(t, err, repository.ErrLocationNotFound, "Should reject a room at an unknown location")
+
+	room, err := service.CreateRoom(&RoomRequest{LocationID: "city", Name: "Studio A", Capacity: 20})
+	require.NoError(t, err, "Should create room without error")
+	_, err = service.CreateRoom(&RoomRequest{LocationID: "docklands", Name: "Studio B", Capacity: 10})
+	require.NoError(t, err, "Should create room without error")
+
+	rooms, err := service.GetAllRooms("")
+	require.NoError(t, err, "Should retrieve all rooms without error")
+	assert.Len(t, rooms, 2, "Should return 2 rooms")
+
+	rooms, err = service.GetAllRooms("city")
+	require.NoError(t, err, "Should retrieve rooms at a location without error")
+	require.Len(t, rooms, 1, "Should return the rooms at the location")
+	assert.Equal(t, room.ID, rooms[0].ID, "Should return the room at the location")
+
+	require.NoError(t, classRepo.Create(&repository.Class{
+		ID:        "yoga",
+		Name:      "Yoga",
+		StartDate: time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC),
+		Capacity:  12,
+		RoomID:    room.ID,
+	}), "Should create test class without error")
+	require.NoError(t, occurrenceRepo.Save(&repository.OccurrenceOverride{ClassID: "yoga", Date: time.Date(2025, 4, 14, 0, 0, 0, 0, time.UTC), Capacity: 16}))
+
+	_, err = service.ReplaceRoom(room.ID, &RoomRequest{LocationID: "city", Name: "Studio A", Capacity: 15})
+	assert.ErrorIs(t, err, ErrRoomCapacityBelowClasses, "Should not shrink a room below an occurrence held in it")
+
+	updated, err := service.ReplaceRoom(room.ID, &RoomRequest{LocationID: "docklands", Name: "Main Studio", Capacity: 16})
+	require.NoError(t, err, "Should update room without error")
+	assert.Equal(t, "docklands", updated.LocationID, "Room location should be updated")
+	assert.Equal(t, room.CreatedAt, updated.CreatedAt, "Creation time should be kept")
+
+	assert.ErrorIs(t, service.DeleteRoom(room.ID), ErrRoomInUse, "Should not delete a room classes are held in")
+
+	require.NoError(t, classRepo.Delete("yoga"))
+	assert.NoError(t, service.DeleteRoom(room.ID), "Should delete room without error")
+	_, err = service.GetRoomByID(room.ID)
+	assert.ErrorIs(t, err, repository.ErrRoomNotFound, "Deleted room should not be found")
+}
+
+// setupRooms creates two rooms, holding 20 and 10 people, and a class held
+// in the first on Mondays at 18:00 for an hour in April 2025, with the
+// clock on April 1
+func setupRooms(t *testing.T) (*ClassService, *OccurrenceService) {
+	classRepo := repository.NewClassRepository()
+	occurrenceRepo := repository.NewOccurrenceRepository()
+	roomRepo := repository.NewRoomRepository()
+	bookingRepo := repository.NewBookingRepository()
+	instructorRepo := repository.NewInstructorRepository()
+
+	require.NoError(t, roomRepo.Create(&repository.Room{ID: "studio-a", LocationID: "city", Name: "Studio A", Capacity: 20}), "Should create test room without error")
+	require.NoError(t, roomRepo.Create(&repository.Room{ID: "studio-b", LocationID: "city", Name: "Studio B", Capacity: 10}), "Should create test room without error")
+
+	require.NoError(t, classRepo.Create(&repository.Class{
+		ID:        "monday-yoga",
+		Name:      "Yoga",
+		StartDate: time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC),
+		Capacity:  15,
+		Schedule:  &schedule.Recurrence{Weekdays: []string{"monday"}, StartTime: "18:00", DurationMinutes: 60},
+		RoomID:    "studio-a",
+	}), "Should create test class without error")
+
+	now := func() time.Time { return time.Date(2025, 4, 1, 9, 0, 0, 0, time.UTC) }
+
+	classService := NewClassService(classRepo, bookingRepo, repository.NewWaitlistRepository(), occurrenceRepo, instructorRepo, roomRepo)
+	classService.SetClock(now)
+	occurrenceService := NewOccurrenceService(occurrenceRepo, classRepo, bookingRepo, repository.NewWaitlistRepository(), instructorRepo, roomRepo)
+	occurrenceService.SetClock(now)
+
+	return classService, occurrenceService
+}
+
+func TestClassRoomAssignment(t *testing.T) {
+	classService, _ := setupRooms(t)
+
+	request := func(roomID, startTime string, capacity int) *CreateClassRequest {
+		return &CreateClassRequest{
+			Name:      "Pilates",
+			StartDate: "2025-04-01",
+			EndDate:   "2025-04-30",
+			Capacity:  capacity,
+			Schedule:  &schedule.Recurrence{Weekdays: []string{"monday", "thursday"}, StartTime: startTime, DurationMinutes: 45},
+			RoomID:    roomID,
+		}
+	}
+
+	_, err := classService.CreateClass(request("nowhere", "18:30", 10))
+	assert.ErrorIs(t, err, repository.ErrRoomNotFound, "Should reject an unknown room")
+
+	_, err = classService.CreateClass(request("studio-b", "19:00", 12))
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr, "Should reject a class larger than its room")
+	assert.Equal(t, "capacity", validationErr.Field, "Validation error should name the capacity field")
+	assert.Equal(t, "10", validationErr.Param, "Validation error should report the room capacity")
+
+	_, err = classService.CreateClass(request("studio-a", "18:30", 10))
+	assert.ErrorIs(t, err, ErrRoomDoubleBooked, "Should reject a class overlapping another class in the room")
+
+	class, err := classService.CreateClass(request("studio-a", "19:00", 10))
+	require.NoError(t, err, "Should create a class starting when the other ends without error")
+	assert.Equal(t, "studio-a", class.RoomID, "Class should be held in the room")
+
+	other, err := classService.CreateClass(request("studio-b", "18:30", 10))
+	require.NoError(t, err, "Should create an overlapping class in another room without error")
+
+	studioA := "studio-a"
+	_, err = classService.PatchClass(context.Background(), other.ID, &UpdateClassRequest{RoomID: &studioA})
+	assert.ErrorIs(t, err, ErrRoomDoubleBooked, "Should reject moving a class into a room in use")
+
+	capacity := 12
+	_, err = classService.PatchClass(context.Background(), other.ID, &UpdateClassRequest{Capacity: &capacity})
+	assert.ErrorAs(t, err, &validationErr, "Should reject raising the capacity above the room capacity")
+
+	noRoom := ""
+	patched, err := classService.PatchClass(context.Background(), other.ID, &UpdateClassRequest{RoomID: &noRoom, Capacity: &capacity})
+	require.NoError(t, err, "Should take the class out of its room without error")
+	assert.Empty(t, patched.RoomID, "Class should have no room")
+
+	page, err := classService.ListClasses(&ListClassesRequest{RoomID: "studio-a"})
+	require.NoError(t, err, "Should list classes without error")
+	assert.Len(t, page.Classes, 2, "Should list the classes held in the room")
+}
+
+func TestOccurrenceRoomChecks(t *testing.T) {
+	classService, occurrenceService := setupRooms(t)
+
+	thursday, err := classService.CreateClass(&CreateClassRequest{
+		Name:      "Spin",
+		StartDate: "2025-04-01",
+		EndDate:   "2025-04-30",
+		Capacity:  10,
+		Schedule:  &schedule.Recurrence{Weekdays: []string{"thursday"}, StartTime: "18:00", DurationMinutes: 60},
+		RoomID:    "studio-a",
+	})
+	require.NoError(t, err, "Should create class without error")
+
+	capacity := 25
+	_, err = occurrenceService.UpdateOccurrence(context.Background(), "monday-yoga", "2025-04-14", &UpdateOccurrenceRequest{Capacity: &capacity})
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr, "Should reject an occurrence larger than its room")
+
+	capacity = 20
+	_, err = occurrenceService.UpdateOccurrence(context.Background(), "monday-yoga", "2025-04-14", &UpdateOccurrenceRequest{Capacity: &capacity})
+	require.NoError(t, err, "Should raise the occurrence capacity up to the room capacity without error")
+
+	startTime := "18:30"
+	_, err = occurrenceService.UpdateOccurrence(context.Background(), thursday.ID, "2025-04-17", &UpdateOccurrenceRequest{StartTime: &startTime})
+	require.NoError(t, err, "Should move an occurrence to a free slot without error")
+
+	// The Spin class now runs until 19:30 on April 17, so Yoga cannot use the room from 19:00 that evening
+	_, err = classService.PatchClass(context.Background(), "monday-yoga", &UpdateClassRequest{Schedule: &schedule.Recurrence{Weekdays: []string{"monday", "thursday"}, StartTime: "19:00", DurationMinutes: 60}})
+	assert.ErrorIs(t, err, ErrRoomDoubleBooked, "Should reject a class change clashing with a moved occurrence")
+
+	studioB := "studio-b"
+	_, err = classService.PatchClass(context.Background(), "monday-yoga", &UpdateClassRequest{RoomID: &studioB, Capacity: &capacity})
+	assert.ErrorAs(t, err, &validationErr, "Should reject a room too small for the class")
+
+	capacity = 10
+	_, err = classService.PatchClass(context.Background(), "monday-yoga", &UpdateClassRequest{RoomID: &studioB, Capacity: &capacity})
+	assert.ErrorAs(t, err, &validationErr, "Should reject a room too small for an occurrence with its own capacity")
+}
+
+// yieldingClassStore lets other goroutines run after each read of all
+// classes, widening the gap between checking a room and taking it
+type yieldingClassStore struct {
+	repository.ClassStore
+}
+
+func (s *yieldingClassStore) GetAll() ([]*repository.Class, error) {
+	classes, err := s.ClassStore.GetAll()
+	runtime.Gosched()
+	return classes, err
+}
+
+func TestClassRoomConcurrentAssignments(t *testing.T) {
+	classService, _ := setupRooms(t)
+	classService.repo = &yieldingClassStore{ClassStore: classService.repo}
+
+	// Classes created for the same room and time at once are held there only once
+	var wg sync.WaitGroup
+	var created, rejected atomic.Int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, err := classService.CreateClass(&CreateClassRequest{
+				Name:      "Pilates",
+				StartDate: "2025-04-01",
+				EndDate:   "2025-04-30",
+				Capacity:  10,
+				Schedule:  &schedule.Recurrence{Weekdays: []string{"tuesday"}, StartTime: "10:00", DurationMinutes: 45},
+				RoomID:    "studio-b",
+			})
+			if err == nil {
+				created.Add(1)
+			} else if errors.Is(err, ErrRoomDoubleBooked) {
+				rejected.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), created.Load(), "Should create exactly one class in the room")
+	assert.Equal(t, int32(9), rejected.Load(), "Every other class should find the room in use")
+}
@@ -0,0 +1,295 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sanjaykishor/Glofox/internal/repository"
+)
+
+var (
+	// ErrClassNotFull is returned when joining the waitlist of an occurrence that still has places
+	ErrClassNotFull = repository.NewConflictError("class_not_full", "class still has places on this date, book it directly")
+
+	// ErrAlreadyOnWaitlist is returned when a member joins the same waitlist twice
+	ErrAlreadyOnWaitlist = repository.NewConflictError("already_on_waitlist", "member is already on the waitlist for this class and date")
+)
+
+// WaitlistService handles business logic for class waitlists
+type WaitlistService struct {
+	waitlistRepo   repository.WaitlistStore
+	bookingRepo    repository.BookingStore
+	classRepo      repository.ClassStore
+	memberRepo     repository.MemberStore
+	occurrenceRepo repository.OccurrenceStore
+	now            func() time.Time
+	location       *time.Location
+}
+
+// NewWaitlistService creates a new instance of WaitlistService
+func NewWaitlistService(
+	waitlistRepo repository.WaitlistStore,
+	bookingRepo repository.BookingStore,
+	classRepo repository.ClassStore,
+	memberRepo repository.MemberStore,
+	occurrenceRepo repository.OccurrenceStore,
+) *WaitlistService {
+	return &WaitlistService{
+		waitlistRepo:   waitlistRepo,
+		bookingRepo:    bookingRepo,
+		classRepo:      classRepo,
+		memberRepo:     memberRepo,
+		occurrenceRepo: occurrenceRepo,
+		now:            time.Now,
+		location:       time.UTC,
+	}
+}
+
+// SetClock overrides the clock used to decide which dates are in the past
+func (s *WaitlistService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// SetLocation sets the studio's time zone, which decides which day is today
+func (s *WaitlistService) SetLocation(loc *time.Location) {
+	s.location = loc
+}
+
+// localNow returns the current time in the studio's time zone
+func (s *WaitlistService) localNow() time.Time {
+	return s.now().In(s.location)
+}
+
+// JoinWaitlistRequest represents the data needed to join the waitlist of a
+// class occurrence, either for a registered member or by name only
+type JoinWaitlistRequest struct {
+	MemberID   string `json:"member_id"`
+	MemberName string `json:"name" binding:"required_without=MemberID"`
+	ClassID    string `json:"class_id" binding:"required"`
+	Date       string `json:"date" binding:"required"`
+}
+
+// WaitlistPosition is a waitlist entry together with its place in the queue.
+// BookingID is set instead of a position once the entry has been promoted.
+type WaitlistPosition struct {
+	*repository.WaitlistEntry
+	Position  int    `json:"position,omitempty"`
+	BookingID string `json:"booking_id,omitempty"`
+}
+
+// MarshalJSON writes the entry's fields followed by the position and
+// booking ID, which the entry's own MarshalJSON would otherwise leave out
+func (p WaitlistPosition) MarshalJSON() ([]byte, error) {
+	entry, err := json.Marshal(p.WaitlistEntry)
+	if err != nil {
+		return nil, err
+	}
+
+	extra, err := json.Marshal(struct {
+		Position  int    `json:"position,omitempty"`
+		BookingID string `json:"booking_id,omitempty"`
+	}{p.Position, p.BookingID})
+	if err != nil || len(extra) == len("{}") {
+		return entry, err
+	}
+
+	return append(append(entry[:len(entry)-1], ','), extra[1:]...), nil
+}
+
+// JoinWaitlist adds a member to the waitlist of a full class occurrence
+func (s *WaitlistService) JoinWaitlist(req *JoinWaitlistRequest) (*WaitlistPosition, error) {
+	if req.ClassID == "" {
+		return nil, newValidationError("class_id", "required", "class_id is required")
+	}
+
+	memberID, memberName, err := resolveMember(s.memberRepo, req.MemberID, req.MemberName)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := s.addEntry(req, memberID, memberName)
+	if err != nil {
+		return nil, err
+	}
+
+	// A place may have been freed between the capacity check and joining;
+	// promoting now keeps the member from waiting behind an open place
+	promoted, err := promoteWaitlist(s.waitlistRepo, s.bookingRepo, s.classRepo, s.occurrenceRepo, s.localNow, entry.ClassID, entry.Date)
+	if err != nil {
+		return nil, err
+	}
+	if booking, ok := promoted[entry.ID]; ok {
+		return &WaitlistPosition{WaitlistEntry: entry, BookingID: booking.ID}, nil
+	}
+
+	return s.GetPosition(entry.ID)
+}
+
+// addEntry checks that the member can join the waitlist of a full class
+// occurrence and adds them. The class is locked so the checks still hold
+// when the entry is stored.
+func (s *WaitlistService) addEntry(req *JoinWaitlistRequest, memberID, memberName string) (*repository.WaitlistEntry, error) {
+	defer lockClass(req.ClassID)()
+
+	date, occurrence, err := validateBookingDate(s.classRepo, s.occurrenceRepo, s.localNow(), req.Date, req.ClassID)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.bookingRepo.CountByClassAndDate(occurrence.ClassID, date)
+	if err != nil {
+		return nil, err
+	}
+	if count < occurrence.Capacity {
+		return nil, ErrClassNotFull
+	}
+
+	// A member who already holds a place has nothing to wait for
+	candidate := &repository.Booking{MemberID: memberID, MemberName: memberName, ClassID: occurrence.ClassID, Date: date}
+	if err := s.bookingRepo.CheckDuplicate(candidate); err != nil {
+		return nil, err
+	}
+
+	waitlist, err := s.waitlistRepo.GetByOccurrence(occurrence.ClassID, date)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range waitlist {
+		if repository.SameMember(entry.MemberID, entry.MemberName, memberID, memberName) {
+			return nil, ErrAlreadyOnWaitlist
+		}
+	}
+
+	entry := &repository.WaitlistEntry{
+		ID:         uuid.New().String(),
+		MemberID:   memberID,
+		MemberName: memberName,
+		ClassID:    occurrence.ClassID,
+		Date:       date,
+		CreatedAt:  s.now(),
+	}
+
+	if err := s.waitlistRepo.Create(entry); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// GetPosition retrieves a waitlist entry and its current place in the queue
+func (s *WaitlistService) GetPosition(id string) (*WaitlistPosition, error) {
+	entry, err := s.waitlistRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	waitlist, err := s.waitlistRepo.GetByOccurrence(entry.ClassID, entry.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, queued := range waitlist {
+		if queued.ID == entry.ID {
+			return &WaitlistPosition{WaitlistEntry: entry, Position: i + 1}, nil
+		}
+	}
+
+	return nil, repository.ErrWaitlistEntryNotFound
+}
+
+// LeaveWaitlist removes a member from a waitlist
+func (s *WaitlistService) LeaveWaitlist(id string) error {
+	return s.waitlistRepo.Delete(id)
+}
+
+// promoteWaitlist turns waitlist entries for a class occurrence into
+// confirmed bookings, first come first served, until the occurrence is full
+// or the waitlist is empty. It returns the bookings created, keyed by the ID
+// of the waitlist entry they were promoted from. now reports the current
+// time in the studio's time zone. The class is locked while promoting, so
+// the caller must not hold it.
+func promoteWaitlist(
+	waitlistRepo repository.WaitlistStore,
+	bookingRepo repository.BookingStore,
+	classRepo repository.ClassStore,
+	occurrenceRepo repository.OccurrenceStore,
+	now func() time.Time,
+	classID string,
+	date time.Time,
+) (map[string]*repository.Booking, error) {
+	promoted := make(map[string]*repository.Booking)
+
+	defer lockClass(classID)()
+
+	// Nobody can attend an occurrence that has already happened
+	if date.Before(startOfDay(now())) {
+		return promoted, nil
+	}
+
+	_, occurrence, err := loadOccurrence(classRepo, occurrenceRepo, now().Location(), classID, date)
+	if errors.Is(err, repository.ErrNotFound) {
+		// The class has been deleted or no longer meets on this date, so
+		// there is nothing to promote into
+		return promoted, nil
+	}
+	if err != nil {
+		return promoted, err
+	}
+	if occurrence.Cancelled() {
+		return promoted, nil
+	}
+
+	for {
+		count, err := bookingRepo.CountByClassAndDate(classID, date)
+		if err != nil {
+			return promoted, err
+		}
+		if count >= occurrence.Capacity {
+			return promoted, nil
+		}
+
+		waitlist, err := waitlistRepo.GetByOccurrence(classID, date)
+		if err != nil {
+			return promoted, err
+		}
+		if len(waitlist) == 0 {
+			return promoted, nil
+		}
+
+		// Claim the entry first so two concurrent promotions never book the same member
+		next := waitlist[0]
+		if err := waitlistRepo.Delete(next.ID); err != nil {
+			return promoted, err
+		}
+
+		booking := &repository.Booking{
+			ID:         uuid.New().String(),
+			MemberID:   next.MemberID,
+			MemberName: next.MemberName,
+			ClassID:    classID,
+			Date:       date,
+			Status:     repository.BookingStatusConfirmed,
+			CreatedAt:  now(),
+		}
+
+		err = bookingRepo.CreateWithCapacity(booking, occurrence.Capacity)
+		if errors.Is(err, repository.ErrDuplicateBooking) {
+			// The member booked the class directly while waiting
+			continue
+		}
+		if err != nil {
+			// Put the member back; the original join time keeps their place
+			if restoreErr := waitlistRepo.Create(next); restoreErr != nil {
+				return promoted, errors.Join(err, restoreErr)
+			}
+			if errors.Is(err, repository.ErrCapacityExceeded) {
+				return promoted, nil
+			}
+			return promoted, err
+		}
+
+		promoted[next.ID] = booking
+	}
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sanjaykishor/Glofox/internal/config"
+	"github.com/sanjaykishor/Glofox/internal/logging"
+	"github.com/sanjaykishor/Glofox/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureLogs sends the default logger's JSON lines to the returned buffer
+// for the rest of the test
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(logging.New(config.LogConfig{Format: config.LogFormatJSON, Level: slog.LevelDebug}, &buf))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &buf
+}
+
+// findLogLine returns the first JSON log line in buf with the given message
+func findLogLine(t *testing.T, buf *bytes.Buffer, msg string) map[string]any {
+	t.Helper()
+
+	for _, raw := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var line map[string]any
+		require.NoError(t, json.Unmarshal([]byte(raw), &line), "Should decode log line without error")
+		if line["msg"] == msg {
+			return line
+		}
+	}
+	t.Fatalf("no log line %q in %s", msg, buf.String())
+	return nil
+}
+
+func TestWaitlistService(t *testing.T) {
+	bookingRepo := repository.NewBookingRepository()
+	classRepo := repository.NewClassRepository()
+	waitlistRepo := repository.NewWaitlistRepository()
+
+	class := &repository.Class{
+		ID:        "yoga",
+		Name:      "Yoga",
+		StartDate: time.Date(2025, 4, 20, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC),
+		Capacity:  1,
+	}
+	require.NoError(t, classRepo.Create(class), "Should create test class without error")
+
+	now := time.Date(2025, 4, 22, 9, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	bookingService := NewBookingService(bookingRepo, classRepo, waitlistRepo, repository.NewMemberRepository(), repository.NewOccurrenceRepository())
+	bookingService.SetClock(clock)
+	waitlistService := NewWaitlistService(waitlistRepo, bookingRepo, classRepo, repository.NewMemberRepository(), repository.NewOccurrenceRepository())
+	waitlistService.SetClock(clock)
+
+	// The waitlist only opens once the occurrence is full
+	_, err := waitlistService.JoinWaitlist(&JoinWaitlistRequest{MemberName: "USER B", ClassID: "yoga", Date: "2025-04-25"})
+	assert.ErrorIs(t, err, ErrClassNotFull, "Should reject joining the waitlist of a class with places")
+
+	booked, err := bookingService.CreateBooking(&CreateBookingRequest{MemberName: "USER A", Date: "2025-04-25", ClassID: "yoga"})
+	require.NoError(t, err, "Should create booking without error")
+
+	_, err = waitlistService.JoinWaitlist(&JoinWaitlistRequest{MemberName: "USER A", ClassID: "yoga", Date: "2025-04-25"})
+	assert.ErrorIs(t, err, repository.ErrDuplicateBooking, "Should reject joining the waitlist of a class the member is booked for")
+
+	first, err := waitlistService.JoinWaitlist(&JoinWaitlistRequest{MemberName: "USER B", ClassID: "yoga", Date: "2025-04-25"})
+	require.NoError(t, err, "Should join waitlist without error")
+	assert.Equal(t, 1, first.Position, "First member should be first in the queue")
+
+	now = now.Add(time.Minute)
+	second, err := waitlistService.JoinWaitlist(&JoinWaitlistRequest{MemberName: "USER C", ClassID: "yoga", Date: "2025-04-25"})
+	require.NoError(t, err, "Should join waitlist without error")
+	assert.Equal(t, 2, second.Position, "Second member should be second in the queue")
+
+	_, err = waitlistService.JoinWaitlist(&JoinWaitlistRequest{MemberName: "USER B", ClassID: "yoga", Date: "2025-04-25"})
+	assert.ErrorIs(t, err, ErrAlreadyOnWaitlist, "Should reject joining the same waitlist twice")
+
+	_, err = waitlistService.JoinWaitlist(&JoinWaitlistRequest{MemberName: "USER D", ClassID: "yoga", Date: "2025-04-21"})
+	assert.EqualError(t, err, "booking date cannot be in the past")
+
+	// Cancelling promotes the first member on the waitlist, logged with the cancelling request's ID
+	logs := captureLogs(t)
+	_, err = bookingService.CancelBooking(logging.WithRequestID(context.Background(), "req-cancel"), booked.ID, &CancelBookingRequest{})
+	require.NoError(t, err, "Should cancel booking without error")
+
+	line := findLogLine(t, logs, "Promoted waitlist entry")
+	assert.Equal(t, first.ID, line["entry_id"], "Should log the promoted entry")
+	assert.Equal(t, "req-cancel", line["request_id"], "Should log the promotion with the request ID")
+
+	_, err = waitlistService.GetPosition(first.ID)
+	assert.Error(t, err, "Promoted member should leave the waitlist")
+
+	bookings, err := bookingRepo.GetByClassID("yoga")
+	require.NoError(t, err)
+	var promoted *repository.Booking
+	for _, booking := range bookings {
+		if booking.MemberName == "USER B" {
+			promoted = booking
+		}
+	}
+	require.NotNil(t, promoted, "First member on the waitlist should be booked")
+	assert.Equal(t, repository.BookingStatusConfirmed, promoted.Status, "Promoted booking should be confirmed")
+
+	position, err := waitlistService.GetPosition(second.ID)
+	require.NoError(t, err, "Should retrieve waitlist position without error")
+	assert.Equal(t, 1, position.Position, "Remaining member should move up the queue")
+
+	// Rescheduling away from the occurrence also promotes
+	_, err = bookingService.RescheduleBooking(context.Background(), promoted.ID, &RescheduleBookingRequest{Date: "2025-04-26"})
+	require.NoError(t, err, "Should reschedule booking without error")
+
+	_, err = waitlistService.GetPosition(second.ID)
+	assert.Error(t, err, "Remaining member should be promoted after a reschedule")
+
+	count, err := bookingRepo.CountByClassAndDate("yoga", time.Date(2025, 4, 25, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, 1, count, "Promotion should never exceed capacity")
+
+	// Leaving the waitlist
+	left, err := waitlistService.JoinWaitlist(&JoinWaitlistRequest{MemberName: "USER E", ClassID: "yoga", Date: "2025-04-25"})
+	require.NoError(t, err, "Should join waitlist without error")
+	assert.NoError(t, waitlistService.LeaveWaitlist(left.ID), "Should leave waitlist without error")
+	assert.Error(t, waitlistService.LeaveWaitlist(left.ID), "Should return error when leaving twice")
+}
+
+// yieldingWaitlistStore lets other goroutines run after each waitlist read,
+// widening the gap between checking a waitlist and joining it
+type yieldingWaitlistStore struct {
+	repository.WaitlistStore
+}
+
+func (s *yieldingWaitlistStore) GetByOccurrence(classID string, date time.Time) ([]*repository.WaitlistEntry, error) {
+	waitlist, err := s.WaitlistStore.GetByOccurrence(classID, date)
+	runtime.Gosched()
+	return waitlist, err
+}
+
+func TestWaitlistServiceConcurrentJoins(t *testing.T) {
+	bookingRepo := repository.NewBookingRepository()
+	classRepo := repository.NewClassRepository()
+	waitlistRepo := repository.NewWaitlistRepository()
+
+	class := &repository.Class{
+		ID:        "yoga",
+		Name:      "Yoga",
+		StartDate: time.Date(2025, 4, 20, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC),
+		Capacity:  1,
+	}
+	require.NoError(t, classRepo.Create(class), "Should create test class without error")
+	require.NoError(t, bookingRepo.Create(&repository.Booking{ID: "booking-1", MemberName: "USER A", ClassID: "yoga", Date: time.Date(2025, 4, 25, 0, 0, 0, 0, time.UTC)}),
+		"Should create test booking without error")
+
+	service := NewWaitlistService(&yieldingWaitlistStore{WaitlistStore: waitlistRepo}, bookingRepo, classRepo, repository.NewMemberRepository(), repository.NewOccurrenceRepository())
+	service.SetClock(func() time.Time { return time.Date(2025, 4, 22, 9, 0, 0, 0, time.UTC) })
+
+	// The same member joining from several requests at once is queued once
+	var wg sync.WaitGroup
+	var joined, rejected atomic.Int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, err := service.JoinWaitlist(&JoinWaitlistRequest{MemberName: "USER B", ClassID: "yoga", Date: "2025-04-25"})
+			if err == nil {
+				joined.Add(1)
+			} else if errors.Is(err, ErrAlreadyOnWaitlist) {
+				rejected.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), joined.Load(), "Should add the member to the waitlist exactly once")
+	assert.Equal(t, int32(9), rejected.Load(), "Every other attempt should find the member already waiting")
+
+	waitlist, err := waitlistRepo.GetByOccurrence("yoga", time.Date(2025, 4, 25, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err, "Should retrieve waitlist without error")
+	assert.Len(t, waitlist, 1, "Should hold a single entry for the member")
+}
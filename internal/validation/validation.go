@@ -1,64 +1,246 @@
 package validation
 
 import (
+	"encoding/json"
 	"errors"
+	"log/slog"
 	"net/http"
+	"reflect"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
+	"github.com/sanjaykishor/Glofox/internal/logging"
+	"github.com/sanjaykishor/Glofox/internal/repository"
+	"github.com/sanjaykishor/Glofox/internal/service"
 )
 
-// Response is the standard API response structure
+// Error codes for failures that are not domain errors. Domain errors carry
+// their own code, see repository.Error.
+const (
+	CodeValidationFailed = "validation_failed"
+	CodeInvalidRequest   = "invalid_request"
+	CodeInternal         = "internal_error"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeRateLimited      = "rate_limited"
+)
+
+// Response is the standard API response structure. Code is a stable,
+// machine-readable identifier for the error, set whenever Error is. Errors
+// lists the offending fields when the request failed validation. RequestID
+// is set on errors so clients can quote it when reporting a problem.
 type Response struct {
-	Success bool   `json:"success"`
-	Message string `json:"message,omitempty"`
-	Data    any    `json:"data,omitempty"`
-	Error   string `json:"error,omitempty"`
+	Success bool         `json:"success"`
+	Message string       `json:"message,omitempty"`
+	Data    any          `json:"data,omitempty"`
+	Error   string       `json:"error,omitempty"`
+	Code    string       `json:"code,omitempty"`
+	Errors  []FieldError `json:"errors,omitempty"`
+	// RequestID matches the X-Request-ID header and the request's log lines
+	RequestID string `json:"request_id,omitempty"`
+	// Pagination is set on responses holding one page of a list
+	Pagination *Pagination `json:"pagination,omitempty"`
+}
+
+// Pagination describes a page of a list. Pass NextCursor as the cursor
+// query parameter to fetch the next page; it is empty on the last page.
+type Pagination struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+}
+
+// FieldError describes a single request field that failed validation.
+// Field is the JSON name of the field and Rule the check that failed, such
+// as "required" or "min"; Param holds the rule's argument, if any.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+	Param   string `json:"param,omitempty"`
+}
+
+// crossFieldRules are the validator tags whose parameter names another field
+var crossFieldRules = map[string]bool{
+	"required_with":    true,
+	"required_without": true,
+	"eqfield":          true,
+	"nefield":          true,
+	"gtfield":          true,
+	"gtefield":         true,
+	"ltfield":          true,
+	"ltefield":         true,
+}
+
+func init() {
+	// Report fields by the names clients send rather than the Go names
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(jsonFieldName)
+	}
+	binding.Validator = requestValidator{StructValidator: binding.Validator}
+}
+
+// requestValidator validates bound requests like the validator it wraps,
+// but remembers the type of a request that fails, so the field named by a
+// cross-field rule such as required_without=MemberID can be reported by
+// its JSON name too
+type requestValidator struct {
+	binding.StructValidator
+}
+
+// ValidateStruct validates obj, returning a *bindingError if it fails
+func (v requestValidator) ValidateStruct(obj any) error {
+	err := v.StructValidator.ValidateStruct(obj)
+	if ve, ok := err.(validator.ValidationErrors); ok {
+		return &bindingError{ValidationErrors: ve, request: reflect.TypeOf(obj)}
+	}
+	return err
+}
+
+// bindingError holds the validation errors of a request of type request
+type bindingError struct {
+	validator.ValidationErrors
+	request reflect.Type
+}
+
+func (e *bindingError) Unwrap() error {
+	return e.ValidationErrors
+}
+
+// fieldName returns the JSON name of the field called name in the struct
+// holding the field at namespace, such as CreateBookingRequest.MemberName,
+// or name itself if there is no such field
+func (e *bindingError) fieldName(namespace, name string) string {
+	t := e.request
+	path := strings.Split(namespace, ".")
+	// The first element names the request and the last the failed field
+	for _, step := range path[1 : len(path)-1] {
+		step, _, _ = strings.Cut(step, "[")
+		t = structType(t)
+		field, ok := t.FieldByName(step)
+		if !ok {
+			return name
+		}
+		t = field.Type
+	}
+
+	field, ok := structType(t).FieldByName(name)
+	if !ok {
+		return name
+	}
+	if jsonName := jsonFieldName(field); jsonName != "" {
+		return jsonName
+	}
+	return name
+}
+
+// structType returns the struct type reached through any pointers, slices
+// or maps in t
+func structType(t reflect.Type) reflect.Type {
+	for {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+		default:
+			return t
+		}
+	}
+}
+
+// jsonFieldName returns the name clients use for a struct field
+func jsonFieldName(field reflect.StructField) string {
+	tag := field.Tag.Get("json")
+	if tag == "" {
+		// Query parameters are named by their form tag
+		tag = field.Tag.Get("form")
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		name = field.Name
+	}
+	return name
 }
 
-// ValidateRequest handles the validation of the request from the client
-func ValidateRequest(err error) (string, bool) {
+// ValidateRequest converts request binding errors into field errors. It
+// reports false when err does not concern any particular field.
+func ValidateRequest(err error) ([]FieldError, bool) {
 	var ve validator.ValidationErrors
 	if errors.As(err, &ve) {
-		var fieldErrors []string
+		var bindingErr *bindingError
+		errors.As(err, &bindingErr)
+
+		fieldErrors := make([]FieldError, 0, len(ve))
 		for _, e := range ve {
-			fieldName := e.Field()
-			fieldName = mapFieldNameToJSON(fieldName)
+			param := e.Param()
+			if crossFieldRules[e.Tag()] && bindingErr != nil {
+				param = bindingErr.fieldName(e.StructNamespace(), param)
+			}
 
-			message := getValidationErrorMessage(e.Tag(), fieldName)
-			fieldErrors = append(fieldErrors, message)
+			fieldErrors = append(fieldErrors, FieldError{
+				Field:   e.Field(),
+				Rule:    e.Tag(),
+				Message: getValidationErrorMessage(e.Tag(), e.Field(), param),
+				Param:   param,
+			})
 		}
 
-		if len(fieldErrors) > 0 {
-			return strings.Join(fieldErrors, ", "), true
-		}
+		return fieldErrors, len(fieldErrors) > 0
 	}
 
-	return "", false
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		typeName := jsonTypeName(typeErr.Type)
+		return []FieldError{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Message: typeErr.Field + " must be a " + typeName,
+			Param:   typeName,
+		}}, true
+	}
+
+	var serviceErr *service.ValidationError
+	if errors.As(err, &serviceErr) {
+		return []FieldError{{
+			Field:   serviceErr.Field,
+			Rule:    serviceErr.Rule,
+			Message: serviceErr.Message,
+			Param:   serviceErr.Param,
+		}}, true
+	}
+
+	return nil, false
 }
 
-// mapFieldNameToJSON converts struct field names to JSON field names
-func mapFieldNameToJSON(fieldName string) string {
-	switch fieldName {
-	case "MemberName":
-		return "name"
-	case "ClassID":
-		return "class_id"
-	case "StartDate":
-		return "start_date"
-	case "EndDate":
-		return "end_date"
+// jsonTypeName returns the JSON name of the type a field is decoded into
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
 	default:
-		return strings.ToLower(fieldName)
+		return t.Kind().String()
 	}
 }
 
 // getValidationErrorMessage returns an appropriate error message based on the validation tag
-func getValidationErrorMessage(tag string, fieldName string) string {
+func getValidationErrorMessage(tag string, fieldName string, param string) string {
 	switch tag {
 	case "required":
 		return fieldName + " is required"
+	case "required_without":
+		return fieldName + " or " + param + " is required"
 	case "min":
 		return fieldName + " is below minimum value"
 	case "max":
@@ -70,45 +252,107 @@ func getValidationErrorMessage(tag string, fieldName string) string {
 	}
 }
 
-// ErrorResponse sends a standardized error response
+// joinMessages returns the messages of the field errors as a single string
+func joinMessages(fieldErrors []FieldError) string {
+	messages := make([]string, len(fieldErrors))
+	for i, fe := range fieldErrors {
+		messages[i] = fe.Message
+	}
+	return strings.Join(messages, ", ")
+}
+
+// ErrorResponse sends a standardized error response for a request that could
+// not be read, such as malformed JSON or a body failing binding validation
 func ErrorResponse(c *gin.Context, statusCode int, err error) {
-	errorMessage := err.Error()
+	response := Response{
+		Success:   false,
+		Error:     err.Error(),
+		Code:      CodeInvalidRequest,
+		RequestID: logging.RequestID(c.Request.Context()),
+	}
 
-	if customMsg, isValidationErr := ValidateRequest(err); isValidationErr {
-		errorMessage = customMsg
+	if fieldErrors, isValidationErr := ValidateRequest(err); isValidationErr {
+		response.Error = joinMessages(fieldErrors)
+		response.Code = CodeValidationFailed
+		response.Errors = fieldErrors
 	}
 
-	c.JSON(statusCode, Response{
-		Success: false,
-		Error:   errorMessage,
-	})
+	c.JSON(statusCode, response)
 }
 
 // ServiceErrorResponse handles service related errors with appropriate status codes
 func ServiceErrorResponse(c *gin.Context, err error) {
-	statusCode := determineStatusCode(err)
+	statusCode, code := classifyError(err)
 
-	c.JSON(statusCode, Response{
-		Success: false,
-		Error:   err.Error(),
-	})
+	response := Response{
+		Success:   false,
+		Error:     err.Error(),
+		Code:      code,
+		RequestID: logging.RequestID(c.Request.Context()),
+	}
+
+	// Errors outside the domain come from storage and the like; their
+	// details are logged but not worth showing to clients
+	if statusCode == http.StatusInternalServerError {
+		slog.ErrorContext(c.Request.Context(), "Internal error handling request",
+			"method", c.Request.Method, "path", c.Request.URL.Path, "error", err)
+		response.Error = "internal server error"
+	}
+
+	if fieldErrors, isValidationErr := ValidateRequest(err); isValidationErr {
+		response.Errors = fieldErrors
+	}
+
+	// Point clients at the booking they already hold
+	var duplicate *repository.DuplicateBookingError
+	if errors.As(err, &duplicate) {
+		response.Data = gin.H{"existing_booking_id": duplicate.ExistingID}
+	}
+
+	c.JSON(statusCode, response)
 }
 
-// determineStatusCode analyzes the error message and returns an appropriate HTTP status code
-func determineStatusCode(err error) int {
-	errMsg := strings.ToLower(err.Error())
+// classifyError returns the HTTP status code and error code for a service error
+func classifyError(err error) (int, string) {
+	if errors.Is(err, service.ErrValidation) {
+		return http.StatusBadRequest, CodeValidationFailed
+	}
 
-	if strings.Contains(errMsg, "not found") {
-		return http.StatusNotFound
-	} else if strings.Contains(errMsg, "unauthorized") {
-		return http.StatusUnauthorized
-	} else if strings.Contains(errMsg, "forbidden") {
-		return http.StatusForbidden
-	} else if strings.Contains(errMsg, "conflict") || strings.Contains(errMsg, "already exists") {
-		return http.StatusConflict
+	var domainErr *repository.Error
+	if errors.As(err, &domainErr) {
+		switch {
+		case errors.Is(domainErr, repository.ErrNotFound):
+			return http.StatusNotFound, domainErr.Code
+		case errors.Is(domainErr, repository.ErrConflict):
+			return http.StatusConflict, domainErr.Code
+		}
 	}
 
-	return http.StatusBadRequest
+	return http.StatusInternalServerError, CodeInternal
+}
+
+// AbortResponse stops the request with an error response that does not come
+// from a service, such as a rejected credential, so later handlers never run
+func AbortResponse(c *gin.Context, statusCode int, code, message string) {
+	c.AbortWithStatusJSON(statusCode, Response{
+		Success:   false,
+		Error:     message,
+		Code:      code,
+		RequestID: logging.RequestID(c.Request.Context()),
+	})
+}
+
+// PageResponse sends a standardized success response holding one page of a list
+func PageResponse(c *gin.Context, data any, page service.PageInfo) {
+	c.JSON(http.StatusOK, Response{
+		Success: true,
+		Data:    data,
+		Pagination: &Pagination{
+			Limit:      page.Limit,
+			NextCursor: page.NextCursor,
+			HasMore:    page.NextCursor != "",
+		},
+	})
 }
 
 // SuccessResponse sends a standardized success response
@@ -0,0 +1,149 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/sanjaykishor/Glofox/internal/repository"
+	"github.com/sanjaykishor/Glofox/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:       "Not Found",
+			err:        repository.ErrClassNotFound,
+			wantStatus: http.StatusNotFound,
+			wantCode:   "class_not_found",
+		},
+		{
+			name:       "Wrapped Not Found",
+			err:        fmt.Errorf("loading booking: %w", repository.ErrBookingNotFound),
+			wantStatus: http.StatusNotFound,
+			wantCode:   "booking_not_found",
+		},
+		{
+			name:       "Conflict",
+			err:        repository.ErrCapacityExceeded,
+			wantStatus: http.StatusConflict,
+			wantCode:   "capacity_exceeded",
+		},
+		{
+			name:       "Duplicate Booking",
+			err:        &repository.DuplicateBookingError{ExistingID: "booking-1"},
+			wantStatus: http.StatusConflict,
+			wantCode:   "duplicate_booking",
+		},
+		{
+			name:       "Service Conflict",
+			err:        service.ErrClassHasBookings,
+			wantStatus: http.StatusConflict,
+			wantCode:   "class_has_bookings",
+		},
+		{
+			name:       "Validation",
+			err:        &service.ValidationError{Field: "date", Message: "booking date cannot be in the past"},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   CodeValidationFailed,
+		},
+		{
+			// Messages no longer decide the status code
+			name:       "Unknown Error Mentioning Not Found",
+			err:        errors.New("database file not found"),
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   CodeInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, code := classifyError(tt.err)
+			assert.Equal(t, tt.wantStatus, status, "Status code should match the error kind")
+			assert.Equal(t, tt.wantCode, code, "Error code should match")
+		})
+	}
+}
+
+func TestValidateRequest(t *testing.T) {
+	t.Run("Binding Errors Use JSON Names", func(t *testing.T) {
+		err := binding.Validator.ValidateStruct(&service.CreateBookingRequest{ClassID: "class-1"})
+		require.Error(t, err, "Should fail validation")
+
+		fieldErrors, ok := ValidateRequest(err)
+		require.True(t, ok, "Should recognise validation errors")
+		assert.ElementsMatch(t, []FieldError{
+			{Field: "name", Rule: "required_without", Message: "name or member_id is required", Param: "member_id"},
+			{Field: "date", Rule: "required", Message: "date is required"},
+		}, fieldErrors, "Should describe each failed field")
+	})
+
+	t.Run("Cross-Field Names Follow Their Struct", func(t *testing.T) {
+		// Both requests name a field Other, but clients know them apart
+		type first struct {
+			Name  string `json:"name" binding:"required_without=Other"`
+			Other string `json:"first_other"`
+		}
+		type second struct {
+			Name  string `json:"name" binding:"required_without=Other"`
+			Other string `json:"second_other"`
+		}
+
+		for _, tt := range []struct {
+			request any
+			want    string
+		}{
+			{&first{}, "first_other"},
+			{&second{}, "second_other"},
+			{&first{}, "first_other"},
+		} {
+			err := binding.Validator.ValidateStruct(tt.request)
+			require.Error(t, err, "Should fail validation")
+
+			fieldErrors, ok := ValidateRequest(err)
+			require.True(t, ok, "Should recognise validation errors")
+			require.Len(t, fieldErrors, 1, "Should describe the missing name")
+			assert.Equal(t, tt.want, fieldErrors[0].Param, "Should name the other field of the same request")
+		}
+	})
+
+	t.Run("Type Mismatch", func(t *testing.T) {
+		var req service.CreateClassRequest
+		err := json.Unmarshal([]byte(`{"capacity": "ten"}`), &req)
+		require.Error(t, err, "Should fail to decode")
+
+		fieldErrors, ok := ValidateRequest(err)
+		require.True(t, ok, "Should recognise type errors")
+		assert.Equal(t, []FieldError{
+			{Field: "capacity", Rule: "type", Message: "capacity must be a number", Param: "number"},
+		}, fieldErrors, "Should describe the mistyped field")
+	})
+
+	t.Run("Service Validation Error", func(t *testing.T) {
+		err := &service.ValidationError{Field: "date", Rule: "not_past", Message: "booking date cannot be in the past"}
+
+		fieldErrors, ok := ValidateRequest(err)
+		require.True(t, ok, "Should recognise service validation errors")
+		assert.Equal(t, []FieldError{
+			{Field: "date", Rule: "not_past", Message: "booking date cannot be in the past"},
+		}, fieldErrors, "Should describe the failed field")
+	})
+
+	t.Run("Malformed JSON", func(t *testing.T) {
+		var req service.CreateClassRequest
+		err := json.Unmarshal([]byte(`{"capacity":`), &req)
+
+		_, ok := ValidateRequest(err)
+		assert.False(t, ok, "Should not report field errors for malformed JSON")
+	})
+}